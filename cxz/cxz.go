@@ -6,6 +6,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/go-gota/gota/dataframe"
 	"github.com/ulikunitz/xz"
@@ -123,3 +124,66 @@ func SaveDataframeToCSV(df *dataframe.DataFrame, filePath string) error {
 
 	return nil
 }
+
+// ReadCSVxzFile 读取本地 csv.xz 文件并返回内容
+func ReadCSVxzFile(filePath string) ([][]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开文件: %w", err)
+	}
+	defer file.Close()
+
+	xzReader, err := xz.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("创建 xz.Reader 失败: %w", err)
+	}
+
+	records, err := csv.NewReader(xzReader).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("读取 CSV 数据时出错: %w", err)
+	}
+	return records, nil
+}
+
+// SaveCSVxzAtomic 将记录写入 csv.xz 文件
+// 先写入同目录下的临时文件，成功后再重命名，避免中断时留下半个文件
+func SaveCSVxzAtomic(records [][]string, filePath string) error {
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("无法创建目录: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("无法创建临时文件: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // 重命名成功后为空操作
+
+	xzWriter, err := xz.NewWriter(tmp)
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("创建 xz.Writer 失败: %w", err)
+	}
+	csvWriter := csv.NewWriter(xzWriter)
+	if err := csvWriter.WriteAll(records); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入 CSV 数据失败: %w", err)
+	}
+	if err := xzWriter.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入压缩 csv.xz 失败: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("同步文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("关闭文件失败: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		return fmt.Errorf("重命名文件失败: %w", err)
+	}
+	return nil
+}
@@ -1,18 +1,47 @@
 package gm
 
 import (
+	"bytes"
+	"cmp"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/go-gota/gota/dataframe"
+	"github.com/lmzxtek/ths-go/gmfake"
+	"github.com/ulikunitz/xz"
 )
 
-// var gmURL = "http://45.154.14.186:5000" // locVPS-kr
-var gmURL = "http://localhost:5000"
-var gmCSV = "http://localhost:5002"
+// gm-api / gm-csv 地址, 由 TestMain 指向 testdata/gmfake 中的夹具
+var gmURL, gmCSV string
+
+// 测试使用 gmfake 回放 testdata/gmfake 中记录的响应;
+// 设置 GMFAKE_API / GMFAKE_CSV 时转发未命中的请求到真实服务并录制夹具
+func TestMain(m *testing.M) {
+	dir := filepath.Join("testdata", "gmfake")
+	fake := gmfake.New(dir)
+	if api := os.Getenv("GMFAKE_API"); api != "" {
+		fake = gmfake.NewRecorder(dir, api, os.Getenv("GMFAKE_CSV"))
+	}
+	ts := httptest.NewServer(fake)
+	gmURL, gmCSV = ts.URL, ts.URL
+	code := m.Run()
+	ts.Close()
+	os.Exit(code)
+}
 
 func TestKBDataMinute(t *testing.T) {
 	fmt.Println("\n >>> Start read dataframe... ")
@@ -31,7 +60,7 @@ func TestKBDataMinute(t *testing.T) {
 
 	rsp, err := GetGM1m(gmCSV, gmURL, symbol, sdate, edate, istime, isclip, 10)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 
 	// fmt.Println("=" + strings.Repeat("=", 50))
@@ -714,7 +743,7 @@ func TestDFGetTest(t *testing.T) {
 	fmt.Println(" -=> Start fetch df from url(Test) ... ")
 	df, err := DfGetTest(gmURL)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	fmt.Println(df)
 	// cxz.SaveDataframeToCSV(&df, "data.csv")
@@ -725,7 +754,7 @@ func TestDFGetTest2(t *testing.T) {
 	fmt.Println(" -=> Start fetch df from url(Test2) ... ")
 	df, err := DfGetTest2(gmURL)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	fmt.Println(df)
 	// cxz.SaveDataframeToCSV(&df, "data.csv")
@@ -743,7 +772,7 @@ func TestGetCalendar(t *testing.T) {
 
 	resp, err := GetCalendar(url, syear, eyear, exchange, timeoutSeconds)
 	if err != nil {
-		fmt.Printf(" 获取数据失败(gm.GetCalendar): %v\n", err)
+		t.Fatalf("获取数据失败(gm.GetCalendar): %v", err)
 	}
 	fmt.Println(string(resp))
 }
@@ -760,7 +789,7 @@ func TestGetPrevN(t *testing.T) {
 
 	resp, err := GetPrevNByte(url, date, count, include, timeoutSeconds)
 	if err != nil {
-		fmt.Printf(" 获取数据失败(gm.GetPrevN): %v\n", err)
+		t.Fatalf("获取数据失败(gm.GetPrevN): %v", err)
 	}
 	fmt.Println(string(resp))
 }
@@ -776,7 +805,7 @@ func TestGetNextN(t *testing.T) {
 
 	resp, err := GetNextNByte(url, date, count, include, timeoutSeconds)
 	if err != nil {
-		fmt.Printf(" 获取数据失败(gm.GetNextN): %v\n", err)
+		t.Fatalf("获取数据失败(gm.GetNextN): %v", err)
 	}
 	fmt.Println(string(resp))
 }
@@ -790,7 +819,7 @@ func TestGetCurrent(t *testing.T) {
 	// resp, err := GetCurrent(url, symbols, timeoutSeconds, false)
 	resp, err := GetCurrentByte(url, symbols, timeoutSeconds, true)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	fmt.Println(string(resp))
 }
@@ -804,7 +833,7 @@ func TestGetCurrent2(t *testing.T) {
 	resp, err := GetCurrentByte(url, symbols, timeoutSeconds, false)
 	// resp, err := GetCurrent(url, symbols, timeoutSeconds, true)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	fmt.Println(string(resp))
 }
@@ -820,9 +849,9 @@ func TestGetDFKbars(t *testing.T) {
 	edate := "2025-05-12"
 	tag := "1d"
 
-	df, err := DfGetKbars(symbols, tag, sdate, edate, url, timeoutSeconds)
+	df, err := DfGetKbars(url, symbols, tag, sdate, edate, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	fmt.Println(df)
 }
@@ -830,11 +859,11 @@ func TestGetDFKbars(t *testing.T) {
 func TestFetchData(t *testing.T) {
 	fmt.Println(" -=> Start download test.txt file ... ")
 
-	url := "http://localhost:5002/download/test.txt"
+	url := gmCSV + "/download/test.txt"
 
 	rsp, err := FetchData(url)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	fmt.Println(string(rsp))
 }
@@ -842,12 +871,12 @@ func TestFetchData(t *testing.T) {
 func TestDownloadCSV(t *testing.T) {
 	fmt.Println(" -=> Start download csv.xz file month... ")
 
-	url := "http://localhost:5002/download/kbars-month/month-2025/month-2025-05--SH-60/kbars-1m--SHSE.601088--2025-05-.csv.xz"
+	url := gmCSV + "/download/" + getFilePathMonth("SHSE.601088", 2025, 5)
 	istime := true
 
 	rsp, err := DownloadAndConvertToJSON(url, istime, "timestamp")
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	fmt.Println(rsp)
 }
@@ -865,7 +894,7 @@ func TestGetCSVMonth(t *testing.T) {
 
 	rsp, err := GetCSVMonthJson(url, symbol, month, year, istime, 10)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	fmt.Println(string(rsp))
 }
@@ -884,7 +913,7 @@ func TestGetCSVYear(t *testing.T) {
 
 	rsp, err := GetCSVYearJson(url, symbol, tag, year, istime, "timestamp", 10)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	fmt.Println(string(rsp))
 }
@@ -913,14 +942,15 @@ func TestGetCSV1m(t *testing.T) {
 	// tag := "vv"
 	// tag := "pe"
 	// year := 2025
-	istime := true
 	isclip := true
-	// istime = false
 	// isclip = false
 
-	rsp, err := GetCSV1m(url, symbol, "2025-05-29", "2025-05-29", istime, isclip, 10)
+	rsp, err := GetCSV1m(url, symbol, "2025-05-29", "2025-05-29", isclip, 10)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
+	}
+	if len(rsp) < 5 {
+		t.Fatalf("K线数量不足: %d", len(rsp))
 	}
 
 	jsonData, _ := json.Marshal(rsp[len(rsp)-5:])
@@ -946,8 +976,7 @@ func TestGetCSVTag(t *testing.T) {
 
 	rsp, err := GetCSVTag(url, tag, symbol, "2025-06-01", "2025-06-13", istime, isclip, 10)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
-		return
+		t.Fatalf("获取数据失败: %v", err)
 	}
 
 	fmt.Println("=" + strings.Repeat("=", 50))
@@ -972,7 +1001,7 @@ func TestGetGM1m(t *testing.T) {
 
 	rsp, err := GetGM1m(gmCSV, gmURL, symbol, "2025-06-05", "2025-06-09", istime, isclip, 10)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 
 	fmt.Println("=" + strings.Repeat("=", 50))
@@ -1003,7 +1032,7 @@ func TestGetKbarsByte(t *testing.T) {
 
 	resp, err := GetKbarsHisByte(url, symbols, tag, sdate, edate, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	// fmt.Println(string(resp))
 
@@ -1045,7 +1074,7 @@ func TestGetSectorCategory(t *testing.T) {
 
 	resp, err := GetSectorCategory(url, sector_type, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1069,7 +1098,7 @@ func TestGetSymbolSector(t *testing.T) {
 
 	resp, err := GetSymbolsSector(url, symbols, sector_type, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1093,7 +1122,7 @@ func TestGetSectorConstituents(t *testing.T) {
 
 	resp, err := GetSectorConstituents(url, sector_code, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1118,7 +1147,7 @@ func TestGetDividend(t *testing.T) {
 	// resp, err := GetShareChange(url, symbols, sdate, edate, timeoutSeconds)
 	// resp, err := GetRation(url, symbols, sdate, edate, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1142,7 +1171,7 @@ func TestGetRation(t *testing.T) {
 	// resp, err := GetShareChange(url, symbols, sdate, edate, timeoutSeconds)
 	resp, err := GetShareholderNum(url, symbols, sdate, edate, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1166,7 +1195,7 @@ func TestGetAdjFactor(t *testing.T) {
 
 	resp, err := GetAdjFactor(url, symbols, sdate, edate, bdate, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1190,7 +1219,7 @@ func TestGetTopShareholders(t *testing.T) {
 
 	resp, err := GetTopShareholder(url, symbols, sdate, edate, tradable_holder, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1216,7 +1245,7 @@ func TestGetAbnorChange(t *testing.T) {
 	resp, err := GetAbnorChangeDetail(url, symbols, change_types, trade_date, fields, timeoutSeconds)
 	// resp, err := GetAbnorChangeStocks(url, symbols, change_types, trade_date, fields, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1240,7 +1269,7 @@ func TestGetHkInst(t *testing.T) {
 	resp, err := GetHKInstHoldingInfo(url, symbols, trade_date, timeoutSeconds)
 	// resp, err := GetHkInstHoldingDetailInfo(url, symbols, trade_date, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1265,7 +1294,7 @@ func TestGetSHSZHKInfos(t *testing.T) {
 
 	resp, err := GetSHSZHKQuotaInfo(url, types, sdate, edate, count, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1288,7 +1317,7 @@ func TestGetFndConstituents(t *testing.T) {
 
 	resp, err := GetFndConstituents(url, etf, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1312,7 +1341,7 @@ func TestGetFndPortfolio(t *testing.T) {
 
 	resp, err := GetFndPortfolio(url, fund, "", "", sdate, edate, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1339,7 +1368,7 @@ func TestGetFndNetValue(t *testing.T) {
 	resp, err := GetFndSplit(url, fund, sdate, edate, timeoutSeconds)
 	// resp, err := GetFndAdjFactor(url, fund, sdate, edate, "", timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1358,7 +1387,7 @@ func TestGetIndustryCategory(t *testing.T) {
 
 	resp, err := GetIndustryCategory(url, source, level, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1377,7 +1406,7 @@ func TestGetIndustryConstituents(t *testing.T) {
 
 	resp, err := GetIndustryConstituents(url, industry_code, date, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1396,7 +1425,7 @@ func TestGetIndexConstituents(t *testing.T) {
 
 	resp, err := GetIndexConstituents(url, index, trade_date, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1414,7 +1443,7 @@ func TestGetTradingSessions(t *testing.T) {
 
 	resp, err := GetTradingSessions(url, symbols, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1439,7 +1468,7 @@ func TestGetKbars2(t *testing.T) {
 
 	resp, err := GetKbarsHis(url, symbols, tag, sdate, edate, ists, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1461,12 +1490,15 @@ func TestGetKB1mByDateList(t *testing.T) {
 	ists := true
 	ists = false
 
-	datelist, _ := GetDatesList(url, sdate, edate, timeoutSeconds)
+	datelist, err := GetDatesList(url, sdate, edate, timeoutSeconds)
+	if err != nil {
+		t.Fatalf("获取日期列表失败: %v", err)
+	}
 	fmt.Printf("获取日期列表成功: %d天: %s - %s\n", len(datelist), sdate, edate)
 
 	resp, err := Get1mByDatelist(url, symbols, datelist, ists, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1494,7 +1526,7 @@ func TestGetFinance2(t *testing.T) {
 	resp, err := GetFundamentalsIncome(url, symbols, sdate, edate, "", rpt_type, data_type, timeoutSeconds)
 
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1522,7 +1554,7 @@ func TestGetFinancePt(t *testing.T) {
 	resp, err := GetFundamentalsCashflowPt(url, symbols, sdate, "", rpt_type, data_type, timeoutSeconds)
 	// resp, err := GetFundamentalsBalancePt(url, symbols, sdate, "", rpt_type, data_type, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1547,7 +1579,7 @@ func TestGetDaily2(t *testing.T) {
 	resp, err := GetDailyBasic(url, symbols, sdate, edate, "", timeoutSeconds)
 
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1571,7 +1603,7 @@ func TestGetDailyPt(t *testing.T) {
 	resp, err := GetDailyMktvaluePt(url, symbols, sdate, "", timeoutSeconds)
 	// resp, err := GetDailyBasicPt(url, symbols, sdate, "", timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	// fmt.Printf("%s\n", recordsJSON)
@@ -1593,7 +1625,7 @@ func TestGetMarketInfo(t *testing.T) {
 
 	resp, err := GetMarketInfo(url, symbols, sec, exchange, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	fmt.Println(string(recordsJSON))
@@ -1614,7 +1646,7 @@ func TestGetSymbolsInfo(t *testing.T) {
 
 	resp, err := GetSymbolsInfo(url, symbols, sec, exchange, trade_date, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	fmt.Println(string(recordsJSON))
@@ -1634,7 +1666,7 @@ func TestGetHistoryInfo(t *testing.T) {
 
 	resp, err := GetHistoryInfo(url, symbol, sdate, edate, timeoutSeconds)
 	if err != nil {
-		fmt.Printf("获取数据失败: %s\n", err)
+		t.Fatalf("获取数据失败: %v", err)
 	}
 	recordsJSON, _ := json.MarshalIndent(resp[max(0, len(resp)-3):], "", "  ") // 格式化输出 JSON
 	fmt.Println(string(recordsJSON))
@@ -1662,3 +1694,1563 @@ func TestConvertTimestamp(t *testing.T) {
 	fmt.Println(tt.Unix())
 	fmt.Println(tt.UnixMilli())
 }
+
+func TestVVStoreLocal(t *testing.T) {
+	store := NewVVStore(t.TempDir(), gmCSV, gmURL, 10)
+
+	mk := func(day string, close float64) VVData {
+		ts, _ := ParseTimestamp(day)
+		return VVData{TS: ts.UnixMilli(), Open: 1.5, High: 2.25, Low: 1.1, Close: close, Volume: 12300,
+			V931: 100, V935: 500, Hjj: 1.234, Vmed: 88, Nup: 120, Ndown: 90}
+	}
+	vvl := VVList{mk("2025-07-03", 2.01), mk("2025-07-01", 1.99)}
+	if err := store.Save("SHSE.600000", vvl); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Load("SHSE.600000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].DateString() != "2025-07-01" || got[1].Close != 2.01 || got[1].Hjj != 1.234 || got[1].Ndown != 90 {
+		t.Fatalf("读取数据不一致: %+v", got)
+	}
+
+	dates := []string{"2025-07-01", "2025-07-02", "2025-07-03", "2025-07-04", "2025-07-07"}
+	missing := missingDates(dates, got, map[string]bool{"2025-07-04": true})
+	if strings.Join(missing, ",") != "2025-07-02,2025-07-07" {
+		t.Fatalf("缺失日期错误: %v", missing)
+	}
+	missing = missingDates(dates, got, nil)
+	if strings.Join(missing, ",") != "2025-07-02,2025-07-04,2025-07-07" {
+		t.Fatalf("缺失日期错误: %v", missing)
+	}
+	runs := splitDateRuns(dates, missing)
+	if len(runs) != 2 || len(runs[1]) != 2 {
+		t.Fatalf("日期分组错误: %v", runs)
+	}
+
+	merged := mergeVVList(got, VVList{mk("2025-07-03", 2.05), mk("2025-07-02", 2.0)})
+	if len(merged) != 3 || merged[2].Close != 2.05 {
+		t.Fatalf("合并数据错误: %+v", merged)
+	}
+	if between := merged.Between("2025-07-02", "2025-07-02"); len(between) != 1 {
+		t.Fatalf("区间过滤错误: %+v", between)
+	}
+
+	empty, err := store.Load("SZSE.000001")
+	if err != nil || len(empty) != 0 {
+		t.Fatalf("文件不存在时应返回空列表: %v, %v", empty, err)
+	}
+
+	// 不同指标定义的数据分开保存
+	pyStore := NewVVStore(store.Dir, gmCSV, gmURL, 10)
+	pyStore.Spec = PyIndicatorSpec
+	if pyStore.Path("SHSE.600000") == store.Path("SHSE.600000") {
+		t.Fatalf("指标定义不同时文件路径应不同: %s", pyStore.Path("SHSE.600000"))
+	}
+	if got, err := pyStore.Load("SHSE.600000"); err != nil || len(got) != 0 {
+		t.Fatalf("不应读取其他指标定义的数据: %v, %v", got, err)
+	}
+}
+
+func TestVVStoreUpdateEmpty(t *testing.T) {
+	// 07-01 有分时数据, 07-02 停牌: 第二次更新不再请求这两天; 07-03 数据无法解析
+	var his atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/get_dates_prev_n":
+			dates := []string{}
+			for _, d := range []string{"2025-07-01", "2025-07-02", "2025-07-03"} {
+				if d < q.Get("date") {
+					dates = append(dates, d)
+				}
+			}
+			json.NewEncoder(w).Encode(dates)
+		case "/get_his":
+			his.Add(1)
+			rcd := RawColData{Columns: []string{"symbol", "eob", "open", "high", "low", "close", "volume"}}
+			if q.Get("sdate") == "2025-07-01" {
+				rcd.Data = [][]any{
+					{"SHSE.600000", "2025-07-01T09:31:00+08:00", 10.0, 10.2, 9.9, 10.1, 1000},
+					{"SHSE.600000", "2025-07-01T09:32:00+08:00", 10.1, 10.3, 10.0, 10.2, 2000},
+				}
+			}
+			if q.Get("edate") == "2025-07-03" {
+				rcd.Data = [][]any{
+					{"SHSE.600000", "2025-07-03T09:31:00+08:00", 10.0, 10.2, 9.9, "bad", 1000},
+				}
+			}
+			json.NewEncoder(w).Encode(rcd)
+		default:
+			rcd := RawColData{Columns: []string{"date", "trade_date"}}
+			y, _ := time.Parse("2006", q.Get("syear"))
+			for d := y; d.Year() == y.Year(); d = d.AddDate(0, 0, 1) {
+				td := d.Format("2006-01-02")
+				if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+					td = ""
+				}
+				rcd.Data = append(rcd.Data, []any{d.Format("2006-01-02"), td})
+			}
+			json.NewEncoder(w).Encode(rcd)
+		}
+	}))
+	defer srv.Close()
+	old := DefaultCalendar()
+	defer SetDefaultCalendar(old)
+	SetDefaultCalendar(NewCalendar(NewClient(srv.URL, WithRetry(RetryPolicy{})), ""))
+
+	store := NewVVStore(t.TempDir(), "", srv.URL, 10)
+	vvl, err := store.Update("SHSE.600000", "2025-07-01", "2025-07-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vvl) != 1 || vvl[0].DateString() != "2025-07-01" || his.Load() != 2 {
+		t.Fatalf("首次更新: %+v, get_his=%d", vvl, his.Load())
+	}
+	empty, err := store.loadEmpty("SHSE.600000")
+	if err != nil || len(empty) != 1 || !empty["2025-07-02"] {
+		t.Fatalf("停牌日未记录: %v, %v", empty, err)
+	}
+
+	vvl, err = store.Update("SHSE.600000", "2025-07-01", "2025-07-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vvl) != 1 || his.Load() != 2 {
+		t.Fatalf("再次更新不应重复获取: %+v, get_his=%d", vvl, his.Load())
+	}
+
+	// 解析失败时返回错误, 不保存数据也不记为停牌
+	if _, err := store.Update("SHSE.600000", "2025-07-01", "2025-07-03"); !errors.Is(err, ErrDecode) {
+		t.Fatalf("分时数据解析失败应返回 ErrDecode: %v", err)
+	}
+	if got, err := store.Load("SHSE.600000"); err != nil || len(got) != 1 {
+		t.Fatalf("解析失败不应保存数据: %v, %v", got, err)
+	}
+	if empty, err := store.loadEmpty("SHSE.600000"); err != nil || empty["2025-07-03"] {
+		t.Fatalf("解析失败不应记为停牌: %v, %v", empty, err)
+	}
+}
+
+func TestClientRetryAndCancel(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`["2025-07-02","2025-07-03"]`))
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL,
+		WithHTTPClient(ts.Client()),
+		WithRetry(RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}),
+	)
+	dates, err := client.GetPrevN(context.Background(), "2025-07-03", 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dates) != 2 || calls != 3 {
+		t.Fatalf("重试结果错误: dates=%v, calls=%d", dates, calls)
+	}
+
+	// 4xx 不重试
+	notFound := 0
+	ts404 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notFound++
+		http.NotFound(w, r)
+	}))
+	defer ts404.Close()
+	client404 := NewClient(ts404.URL, WithRetry(RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}))
+	if _, err := client404.GetPrevN(context.Background(), "2025-07-03", 2, true); err == nil || notFound != 1 {
+		t.Fatalf("4xx 不应重试: err=%v, calls=%d", err, notFound)
+	}
+
+	// 已取消的请求不再发送
+	calls = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.GetPrevN(ctx, "2025-07-03", 2, true); !errors.Is(err, context.Canceled) || calls != 0 {
+		t.Fatalf("取消请求失败: err=%v, calls=%d", err, calls)
+	}
+}
+
+func TestDecodeRecords(t *testing.T) {
+	records := []map[string]any{
+		{"trade_date": "2025-07-01", "adj_factor_fwd": 0.98, "adj_factor_bwd": "1.5"},
+		{"adj_factor_fwd": 1.0},
+		{"trade_date": "2025-07-03", "adj_factor_fwd": "abc"},
+	}
+	res, err := DecodeRecords[AdjFactor](records)
+	if len(res) != 1 || res[0].AdjFactorFwd != 0.98 || res[0].AdjFactorBwd != 1.5 {
+		t.Fatalf("解码结果错误: %+v", res)
+	}
+	var errs DecodeErrors
+	if !errors.As(err, &errs) || len(errs) != 2 || errs[0].Field != "trade_date" || errs[1].Row != 2 {
+		t.Fatalf("字段错误不符合预期: %v", err)
+	}
+
+	sessions, err := DecodeRecords[TradingSession]([]map[string]any{
+		{"symbol": "SHSE.600000", "time_trading": []any{map[string]any{"start": "09:30", "end": "11:30"}}},
+	})
+	if err != nil || len(sessions[0].TimeTrading) != 1 || sessions[0].TimeTrading[0].End != "11:30" {
+		t.Fatalf("交易时段解码错误: %+v, %v", sessions, err)
+	}
+
+	// 缺失字段不再 panic
+	kbl := OHLCVList{}
+	err = kbl.FromMapList([]map[string]any{
+		{"timestamp": "2025-07-01 09:31:00", "open": 1.0, "high": 1.2, "low": 0.9, "close": 1.1, "volume": 100.0},
+		{"timestamp": "2025-07-01 09:32:00", "open": 1.0, "high": 1.2, "low": 0.9, "close": 1.1},
+	})
+	if len(kbl) != 1 || !errors.As(err, &errs) || errs[0].Field != "volume" || errs[0].Row != 1 {
+		t.Fatalf("读取K线错误: %v, %v", kbl, err)
+	}
+
+	// 空单元格: 必须字段报缺失, 可选字段不设置, 不再当作 0
+	res, err = DecodeRecords[AdjFactor]([]map[string]any{
+		{"trade_date": "", "adj_factor_fwd": 0.98},
+		{"trade_date": "2025-07-02", "adj_factor_fwd": "", "adj_factor_bwd": "1.5"},
+	})
+	if len(res) != 1 || res[0].AdjFactorFwd != 0 || res[0].AdjFactorBwd != 1.5 ||
+		!errors.As(err, &errs) || len(errs) != 1 || !errors.Is(errs[0], errFieldMissing) {
+		t.Fatalf("空单元格解码错误: %+v, %v", res, err)
+	}
+	if _, err := anyToFloat(""); err == nil {
+		t.Fatal("空字符串不应解析为 0")
+	}
+	kbl = OHLCVList{}
+	err = kbl.FromMapList([]map[string]any{
+		{"timestamp": "2025-07-01 09:31:00", "open": 1.0, "high": 1.2, "low": 0.9, "close": "", "volume": 100.0, "amount": ""},
+		{"timestamp": "2025-07-01 09:32:00", "open": 1.0, "high": 1.2, "low": 0.9, "close": 1.1, "volume": 100.0, "amount": ""},
+	})
+	if len(kbl) != 1 || !errors.As(err, &errs) || errs[0].Field != "close" || errs[0].Row != 0 {
+		t.Fatalf("空价格应报错: %v, %v", kbl, err)
+	}
+}
+
+func TestAdjust(t *testing.T) {
+	day := func(s string) time.Time { tt, _ := ParseTimestamp(s); return tt }
+	factors := []AdjFactor{
+		{TradeDate: day("2025-07-02"), AdjFactorFwdAcc: 1.0, AdjFactorBwdAcc: 2.0},
+		{TradeDate: day("2025-07-01"), AdjFactorFwdAcc: 0.5, AdjFactorBwdAcc: 1.0},
+	}
+	records := []map[string]any{
+		{"timestamp": "2025-07-01 09:31:00", "open": 10.0, "close": 10.0, "volume": 100.0},
+		{"timestamp": "2025-07-02 09:31:00", "open": 5.0, "close": 5.0, "hjj": 5.0},
+		{"timestamp": "2025-07-03 09:31:00", "open": 5.0, "close": 5.0},
+	}
+	if err := AdjustRecords(records, factors, AdjustQfq); err != nil {
+		t.Fatal(err)
+	}
+	if records[0]["open"] != 5.0 || records[1]["hjj"] != 5.0 || records[2]["close"] != 5.0 || records[0]["volume"] != 100.0 {
+		t.Fatalf("前复权结果错误: %v", records)
+	}
+
+	vvl := VVList{{TS: day("2025-07-01").UnixMilli(), Close: 10, Cbj: 10}, {TS: day("2025-07-02").UnixMilli(), Close: 5, Cbj: 5}}
+	vvl.Adjust(factors, AdjustHfq)
+	if vvl[0].Close != 10 || vvl[1].Cbj != 10 {
+		t.Fatalf("后复权结果错误: %+v", vvl)
+	}
+
+	if _, err := ParseAdjust("xfq"); err == nil {
+		t.Fatal("应返回复权参数错误")
+	}
+	if !IsFundSymbol("SHSE.510300") || IsFundSymbol("SHSE.600000") || !IsIndexSymbol("SZSE.399001") {
+		t.Fatal("标的类型判断错误")
+	}
+}
+
+func TestIndicatorSpec(t *testing.T) {
+	// 构造一天的分时数据: 09:31 ~ 11:00, 成交量逐渐增加
+	var kbl OHLCVList
+	t0, _ := ParseTimestamp("2025-07-01 09:30:00")
+	for i := 1; i <= 90; i++ {
+		p := 10 + float64(i)*0.01
+		kbl.Add(OHLCVData{Timestamp: t0.Add(time.Duration(i) * time.Minute), Open: p, High: p + 0.02, Low: p - 0.01, Close: p + 0.01, Volume: int64(i * 100)})
+	}
+
+	// 默认定义与原有算法一致
+	var vv0, vv1 VVData
+	vv0.Init(kbl, true, true, true)
+	vv1.InitSpec(kbl, DefaultIndicatorSpec, true, true, true)
+	if vv0 != vv1 || vv0.V931 != 100 || vv0.V935 != 1500 || vv0.V940 != 4000 {
+		t.Fatalf("默认指标定义结果不一致: %+v", vv1)
+	}
+
+	// Python规则: 90根K线取2倍中值, 10:30分割
+	py := kbl.ToCbjDataSpec(PyIndicatorSpec)
+	if py.Vmed != int64(2*4550) {
+		t.Fatalf("adaptive 成交量阈值错误: %d", py.Vmed)
+	}
+	if py.Cb1 != py.Cbj || py.Cb2 != py.Cbj {
+		t.Fatalf("阈值以上K线全部在10:30之后时 cb1 应为默认值: %+v", py)
+	}
+
+	fpath := t.TempDir() + "/indicators.toml"
+	os.WriteFile(fpath, []byte("[[spec]]\nname = \"t1\"\nsplit_time = \"10:30:00\"\nvmed = { mode = \"pct\", pct = 25 }\n"), 0o644)
+	if names, err := LoadIndicatorSpecs(fpath); err != nil || len(names) != 1 {
+		t.Fatalf("读取指标定义失败: %v, %v", names, err)
+	}
+	spec, isOHLC, isV123, isCbj, err := ParseIndicators("t1,cbj")
+	if err != nil || spec.Name != "t1" || spec.Weights.Close != 4 || isOHLC || isV123 || !isCbj {
+		t.Fatalf("解析 indicators 错误: %+v, %v", spec, err)
+	}
+	if _, _, _, _, err := ParseIndicators("unknown"); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("未知指标应返回 ErrInvalidParam: %v", err)
+	}
+	if err := RegisterIndicatorSpec(IndicatorSpec{Name: "cbj"}); err == nil {
+		t.Fatal("指标定义名称不能与指标列名相同")
+	}
+	if err := RegisterIndicatorSpec(IndicatorSpec{Name: "../x"}); err == nil {
+		t.Fatal("指标定义名称不能包含路径字符")
+	}
+}
+
+func TestCalcIndicators(t *testing.T) {
+	var kbl OHLCVList
+	t0, _ := ParseTimestamp("2025-07-01 09:30:00")
+	for i := 1; i <= 40; i++ {
+		p := 10 + float64(i%7)*0.1
+		kbl.Add(OHLCVData{Timestamp: t0.Add(time.Duration(i) * time.Minute), Open: p, High: p + 0.05, Low: p - 0.05, Close: p + 0.02, Volume: int64(i * 100)})
+	}
+
+	series, err := kbl.CalcIndicators("ma,macd,kdj,rsi,boll,atr,obv,vwap,amv", "ma=5,10|rsi=6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, col := range []string{"ma5", "ma10", "dif", "dea", "macd", "k", "d", "j", "rsi6", "boll", "ub", "lb", "atr", "obv", "vwap", "amv5", "amv60"} {
+		if len(series[col]) != len(kbl) {
+			t.Fatalf("缺少指标列: %s", col)
+		}
+	}
+	if _, ok := series["ma20"]; ok {
+		t.Fatal("自定义参数应替换默认参数")
+	}
+	for i := range kbl {
+		if k := series["k"][i]; k < 0 || k > 100 {
+			t.Fatalf("KDJ.K 超出范围: %v", k)
+		}
+		if series["ub"][i] < series["lb"][i] {
+			t.Fatalf("BOLL 上轨小于下轨: %d", i)
+		}
+	}
+
+	records := make([]map[string]any, len(kbl))
+	for i := range kbl {
+		records[i] = map[string]any{"timestamp": kbl[i].Timestamp.UnixMilli(), "close": kbl[i].Close}
+	}
+	if err := series.AppendTo(records); err != nil {
+		t.Fatal(err)
+	}
+	if records[0]["ma5"] != nil || records[4]["ma5"] == nil {
+		t.Fatalf("无效值应输出为 null: %v", records[0]["ma5"])
+	}
+	if _, err := json.Marshal(records); err != nil {
+		t.Fatalf("结果无法序列化: %v", err)
+	}
+
+	if _, err := kbl.CalcIndicators("ma,foo", ""); err == nil {
+		t.Fatal("未知指标应返回错误")
+	}
+	if _, err := kbl.CalcIndicators("macd", "macd=12,26"); err == nil {
+		t.Fatal("参数个数错误应返回错误")
+	}
+}
+
+func TestGetIndicatorsWarmup(t *testing.T) {
+	// 工作日日线, 收盘价逐日加1
+	var days []string
+	for d := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC); d.Month() < 8; d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			days = append(days, d.Format("2006-01-02"))
+		}
+	}
+	var reqStart string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		reqStart = q.Get("sdate")
+		rcd := RawColData{Columns: []string{"symbol", "eob", "open", "high", "low", "close", "volume"}}
+		for i, d := range days {
+			if d >= q.Get("sdate") && d <= q.Get("edate") {
+				p := float64(i + 1)
+				rcd.Data = append(rcd.Data, []any{"SHSE.600000", d, p, p, p, p, 100})
+			}
+		}
+		json.NewEncoder(w).Encode(rcd)
+	}))
+	defer ts.Close()
+
+	old := DefaultCalendar()
+	defer SetDefaultCalendar(old)
+	SetDefaultCalendar(NewCalendar(nil, "")) // 按工作日计算
+
+	c := NewClient(ts.URL, WithRetry(RetryPolicy{}))
+	rows, err := c.GetIndicators(context.Background(), "SHSE.600000", "1d", "2025-07-01", "2025-07-04", "ma,macd", "ma=5", AdjustNone, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reqStart >= "2025-07-01" {
+		t.Fatalf("应从 sdate 之前开始获取预热数据: %s", reqStart)
+	}
+	if len(rows) != 4 || rows[0]["timestamp"] != "2025-07-01" {
+		t.Fatalf("应只返回 sdate 之后的数据: %d %v", len(rows), rows[0]["timestamp"])
+	}
+	i := slices.Index(days, "2025-07-01")
+	if want := float64(i+1+i+i-1+i-2+i-3) / 5; rows[0]["ma5"] != want {
+		t.Fatalf("ma5 应包含预热数据: %v != %v", rows[0]["ma5"], want)
+	}
+	if rows[0]["dea"] == nil {
+		t.Fatal("macd 预热后不应为空")
+	}
+}
+
+func TestResample(t *testing.T) {
+	// 两天完整的分时数据: 09:31~11:30, 13:01~15:00
+	var kbl OHLCVList
+	for _, d := range []string{"2025-07-03", "2025-07-04"} {
+		t0, _ := ParseTimestamp(d + " 09:30:00")
+		t1, _ := ParseTimestamp(d + " 13:00:00")
+		for i := 1; i <= 240; i++ {
+			ts := t0.Add(time.Duration(i) * time.Minute)
+			if i > 120 {
+				ts = t1.Add(time.Duration(i-120) * time.Minute)
+			}
+			p := float64(i)
+			kbl.Add(OHLCVData{Timestamp: ts, Open: p, High: p + 1, Low: p - 1, Close: p + 0.5, Volume: 10})
+		}
+	}
+
+	h1, err := kbl.Resample("60m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"10:30", "11:30", "14:00", "15:00"}
+	if len(h1) != 8 {
+		t.Fatalf("60m K线数量错误: %d", len(h1))
+	}
+	for i, kb := range h1[:4] {
+		if kb.Timestamp.Format("15:04") != want[i] || kb.Volume != 600 {
+			t.Fatalf("60m K线错误: %d %v %d", i, kb.Timestamp, kb.Volume)
+		}
+	}
+	if h1[1].Open != 61 || h1[1].Close != 120.5 || h1[1].High != 121 || h1[1].Low != 60 {
+		t.Fatalf("60m OHLC 错误: %+v", h1[1])
+	}
+
+	m5, _ := kbl.Resample("5m")
+	if len(m5) != 96 || m5[0].Timestamp.Format("15:04") != "09:35" || m5[24].Timestamp.Format("15:04") != "13:05" {
+		t.Fatalf("5m K线错误: %d %v", len(m5), m5[24].Timestamp)
+	}
+	// 不能整除120分钟的周期不跨越午休
+	m50, _ := kbl.Resample("50m")
+	if m50[2].Timestamp.Format("15:04") != "11:30" || m50[2].Volume != 200 {
+		t.Fatalf("50m K线错误: %v %d", m50[2].Timestamp, m50[2].Volume)
+	}
+
+	w, _ := kbl.Resample("W")
+	if len(w) != 1 || w[0].Timestamp.Format("2006-01-02") != "2025-07-04" || w[0].Volume != 4800 || w[0].Open != 1 {
+		t.Fatalf("周线错误: %+v", w)
+	}
+	daily, _ := kbl.Resample("D")
+	mo, _ := daily.Resample("M")
+	if len(daily) != 2 || len(mo) != 1 || mo[0].Volume != 4800 {
+		t.Fatalf("日线/月线错误: %+v %+v", daily, mo)
+	}
+
+	records, err := ResampleRecords(kbl.ToRecords(false, false), "30m", false)
+	if err != nil || len(records) != 16 || records[0]["timestamp"] != "2025-07-03 10:00:00" {
+		t.Fatalf("records 重采样错误: %v %v", err, records[0])
+	}
+	if _, err := kbl.Resample("7x"); err == nil {
+		t.Fatal("无效周期应返回错误")
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	// 2024-02-09 ~ 2024-02-17 春节休市
+	var nreq int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nreq++
+		if r.URL.Path != "/get_dates_by_year" || r.URL.Query().Get("syear") != "2024" {
+			w.Write([]byte(`{"columns":["date","trade_date"],"data":[]}`))
+			return
+		}
+		rcd := RawColData{Columns: []string{"date", "trade_date"}}
+		for d := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); d.Year() == 2024; d = d.AddDate(0, 0, 1) {
+			day := d.Format("2006-01-02")
+			td := day
+			if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday || (day >= "2024-02-09" && day <= "2024-02-17") {
+				td = ""
+			}
+			rcd.Data = append(rcd.Data, []any{day, td})
+		}
+		json.NewEncoder(w).Encode(rcd)
+	}))
+	defer srv.Close()
+
+	fpath := t.TempDir() + "/calendar.json"
+	cal := NewCalendar(NewClient(srv.URL, WithRetry(RetryPolicy{})), fpath)
+	if cal.IsTradingDay("2024-02-14") || !cal.IsTradingDay("2024-02-08") || cal.IsTradingDay("2024-02-18") {
+		t.Fatal("春节假期判断错误")
+	}
+	if d, _ := cal.NextTradingDay("2024-02-08", 1); d != "2024-02-19" {
+		t.Fatalf("NextTradingDay 错误: %s", d)
+	}
+	if d, _ := cal.PrevTradingDay("2024-02-19", 2); d != "2024-02-07" {
+		t.Fatalf("PrevTradingDay 错误: %s", d)
+	}
+	if days, _ := cal.TradingDaysBetween("2024-02-05", "2024-02-20"); len(days) != 6 {
+		t.Fatalf("TradingDaysBetween 错误: %v", days)
+	}
+	tz := time.FixedZone("CST", 8*3600)
+	if s := cal.SessionAt(time.Date(2024, 2, 14, 10, 0, 0, 0, tz)); s != SessionHoliday {
+		t.Fatalf("SessionAt 错误: %s", s)
+	}
+	if s := cal.SessionAt(time.Date(2024, 2, 19, 12, 0, 0, 0, tz)); s != SessionLunch {
+		t.Fatalf("SessionAt 错误: %s", s)
+	}
+	if nreq != 1 {
+		t.Fatalf("同一年份只应请求一次: %d", nreq)
+	}
+
+	// 本地文件离线查询
+	offline := NewCalendar(nil, fpath)
+	if !offline.Covers("2024-01-01", "2024-12-31") || offline.IsTradingDay("2024-02-09") {
+		t.Fatal("本地交易日历读取错误")
+	}
+	// 未加载的年份按工作日处理
+	if !offline.IsTradingDay("2023-12-29") || offline.IsTradingDay("2023-12-30") {
+		t.Fatal("未加载年份应按工作日处理")
+	}
+}
+
+func TestRunOrdered(t *testing.T) {
+	keys := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	var running, peak int
+	var mu sync.Mutex
+	fn := func(ctx context.Context, key string) (string, error) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+		time.Sleep(time.Duration(len(keys)-strings.Index("abcdefgh", key)) * time.Millisecond)
+		if key == "c" || key == "f" {
+			return "", fmt.Errorf("bad %s", key)
+		}
+		return strings.ToUpper(key), ctx.Err()
+	}
+
+	res, err := RunOrdered(context.Background(), keys, PoolOptions{Concurrency: 3}, fn)
+	var ies ItemErrors
+	if !errors.As(err, &ies) || len(ies) != 2 || ies[0].Key != "c" || ies[1].Index != 5 {
+		t.Fatalf("错误汇总不正确: %v", err)
+	}
+	if res[0] != "A" || res[7] != "H" || res[2] != "" {
+		t.Fatalf("结果顺序错误: %v", res)
+	}
+	if peak > 3 {
+		t.Fatalf("并发数超过限制: %d", peak)
+	}
+
+	_, err = RunOrdered(context.Background(), keys, PoolOptions{Concurrency: 2, FailFast: true}, fn)
+	var ie *ItemError
+	if !errors.As(err, &ie) || ie.Key != "c" {
+		t.Fatalf("fail-fast 应返回第一个错误: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := RunOrdered(ctx, keys, PoolOptions{}, fn); !errors.Is(err, context.Canceled) {
+		t.Fatalf("取消后应返回 context.Canceled: %v", err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	now := time.Date(2025, 7, 15, 10, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	dir := t.TempDir()
+	cache := NewCache(10, dir)
+	cache.now = func() time.Time { return now }
+
+	// 按数据类型确定缓存时间
+	bars := `{"columns":["eob","close"],"data":[["2025-07-14 09:31:00",10.0]]}`
+	ttls := []struct {
+		url    string
+		params map[string]string
+		data   string
+		want   time.Duration
+	}{
+		{"http://x/get_his", map[string]string{"edate": "2025-07-14"}, bars, NoExpire},
+		{"http://x/get_his", map[string]string{"edate": "2025-07-14"}, `{"columns":["eob","close"],"data":[]}`, 10 * time.Second},
+		{"http://x/get_his_symbol", map[string]string{"edate": "2025-07-14"}, ` [] `, 10 * time.Second},
+		{"http://x/get_his2", map[string]string{"etime": "2025-07-15 10:00:00"}, bars, 10 * time.Second},
+		{"http://x/get_current", nil, "", 3 * time.Second},
+		{"http://x/get_dates_by_year", nil, "", 24 * time.Hour},
+		{"http://x/download/kbars-1m--SHSE.600000--2025-06-.csv.xz", nil, "", NoExpire},
+		{"http://x/download/kbars-1m--SHSE.600000--2025-07-.csv.xz", nil, "", time.Hour},
+		{"http://x/download/kbars-1m--SHSE.600000--2025-.csv.xz", nil, "", time.Hour},
+	}
+	for _, tt := range ttls {
+		if got := cache.ttl(tt.url, tt.params, []byte(tt.data)); got != tt.want {
+			t.Errorf("缓存时间错误: %s: %v != %v", tt.url, got, tt.want)
+		}
+	}
+
+	// 内存按字节数淘汰, 磁盘保留永久数据
+	cache.set("a", []byte("123456"), NoExpire)
+	cache.set("b", []byte("1234"), 5*time.Second)
+	cache.set("c", []byte("12"), 5*time.Second)
+	if st := cache.Stats(); st.Entries != 2 || st.Evictions != 1 || st.Bytes != 6 {
+		t.Fatalf("LRU 淘汰错误: %+v", st)
+	}
+	if data, ok := cache.get("a"); !ok || string(data) != "123456" || cache.Stats().DiskHits != 1 {
+		t.Fatal("应从磁盘读取被淘汰的数据")
+	}
+	now = now.Add(6 * time.Second)
+	if _, ok := cache.get("b"); ok {
+		t.Fatal("过期数据不应返回")
+	}
+
+	// 磁盘缓存在新实例中可用, 清除后不可用
+	cache2 := NewCache(0, dir)
+	if _, ok := cache2.get("a"); !ok {
+		t.Fatal("磁盘缓存读取失败")
+	}
+	if n := cache2.Purge("a"); n != 1 {
+		t.Fatalf("清除数量错误: %d", n)
+	}
+	if _, ok := NewCache(0, dir).get("a"); ok {
+		t.Fatal("清除后不应读取到缓存")
+	}
+
+	// 并发读写(磁盘读写不持有锁)
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("k%d", i%3)
+			cache2.set(key, []byte(key), NoExpire)
+			if data, ok := cache2.get(key); !ok || string(data) != key {
+				t.Errorf("并发读取错误: %s %q", key, data)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// 客户端: 已收盘数据只请求一次
+	var nreq int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nreq++
+		w.Write([]byte(`{"columns":["eob","close"],"data":[["2024-01-02 09:31:00",10.0]]}`))
+	}))
+	defer srv.Close()
+	client := NewClient(srv.URL, WithCache(NewCache(0, "")), WithRetry(RetryPolicy{}))
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetKbarsHis(context.Background(), "SHSE.600000", "1m", "2024-01-02", "2024-01-02", false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if nreq != 1 || client.Cache.Stats().Hits != 2 {
+		t.Fatalf("缓存未生效: 请求%d次, %+v", nreq, client.Cache.Stats())
+	}
+}
+
+func TestFakeUpstream(t *testing.T) {
+	fake := gmfake.New(t.TempDir())
+	var buf bytes.Buffer
+	w, err := xz.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("timestamp,open,high,low,close,volume\n2025-07-01 09:31:00,10,10.2,9.9,10.1,1000\n"))
+	w.Close()
+	if err := fake.PutFile(getFilePathMonth("SHSE.600000", 2025, 7), buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	q := url.Values{"symbol": {"SHSE.600000"}}
+	if err := fake.PutAPI("get_current", q, []byte(`[{"symbol":"SHSE.600000","price":10.1}]`)); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithCSVURL(ts.URL), WithRetry(RetryPolicy{}))
+	rows, err := c.GetCSVMonth(context.Background(), "SHSE.600000", 7, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Close != 10.1 {
+		t.Fatalf("GetCSVMonth: %v", rows)
+	}
+	if _, err := c.GetCSVMonth(context.Background(), "SHSE.600000", 8, 2025); err == nil {
+		t.Fatal("未录制的文件应返回错误")
+	}
+	body, err := c.fetchURLData(context.Background(), ts.URL+"/get_current", map[string]string{"symbol": "SHSE.600000"})
+	if err != nil || !strings.Contains(string(body), `"price":10.1`) {
+		t.Fatalf("get_current: %s %v", body, err)
+	}
+}
+
+func TestErrorTypes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/bad.csv.xz":
+			w.Write([]byte("not xz"))
+		case "/download/" + getFilePathMonth("SHSE.600000", 2025, 7):
+			// 响应体在中途断开
+			data := xzBytes(t, "timestamp,open,high,low,close,volume\n"+strings.Repeat("2025-07-01 09:31:00,10,10.2,9.9,10.1,1000\n", 100))
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			w.Write(data[:len(data)/2])
+		case "/get_his", "/get_daily_valuation":
+			// symbol 决定上游的行为: 502, 空结果, 其他为 JSON 格式错误
+			switch r.URL.Query().Get("symbol") + r.URL.Query().Get("symbols") {
+			case "DOWN":
+				http.Error(w, "bad gateway", http.StatusBadGateway)
+			case "EMPTY":
+				w.Write([]byte(`{"columns":[],"data":[]}`))
+			default:
+				w.Write([]byte(`{"columns":`))
+			}
+		default:
+			w.Write([]byte(`{"columns":`))
+		}
+	}))
+	defer ts.Close()
+	ctx := context.Background()
+	c := NewClient(ts.URL, WithCSVURL(ts.URL), WithRetry(RetryPolicy{}))
+
+	_, err := c.fetchURLData(ctx, ts.URL+"/missing", nil)
+	var st *ErrUpstreamStatus
+	if !errors.As(err, &st) || st.Code != http.StatusNotFound || !errors.Is(err, &ErrUpstreamStatus{}) {
+		t.Fatalf("404 应为 ErrUpstreamStatus: %v", err)
+	}
+	if errors.Is(err, &ErrUpstreamStatus{Code: 500}) {
+		t.Fatal("状态码不同时不应匹配")
+	}
+
+	_, err = NewClient(ts.URL, WithTimeout(50*time.Millisecond), WithRetry(RetryPolicy{})).
+		fetchURLData(ctx, ts.URL+"/slow", nil)
+	if !errors.Is(err, ErrUpstreamUnavailable) || !IsTimeout(err) {
+		t.Fatalf("超时应为 ErrUpstreamUnavailable 且 IsTimeout: %v", err)
+	}
+
+	_, err = NewClient("http://127.0.0.1:1", WithRetry(RetryPolicy{})).fetchURLData(ctx, "http://127.0.0.1:1/get_his", nil)
+	if !errors.Is(err, ErrUpstreamUnavailable) || IsTimeout(err) {
+		t.Fatalf("连接失败应为 ErrUpstreamUnavailable: %v", err)
+	}
+
+	if _, err = c.downloadAndReadData(ctx, ts.URL+"/bad.csv.xz"); !errors.Is(err, ErrDecode) {
+		t.Fatalf("xz 格式错误应为 ErrDecode: %v", err)
+	}
+	// 未配置缓存时直接读取响应体, 读取中断为 ErrUpstreamUnavailable
+	if _, err = c.GetCSVMonth(ctx, "SHSE.600000", 7, 2025); !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Fatalf("响应体中断应为 ErrUpstreamUnavailable: %v", err)
+	}
+	var rcd RawColData
+	if err = rcd.FromByte([]byte(`{"columns":`)); !errors.Is(err, ErrDecode) {
+		t.Fatalf("JSON 格式错误应为 ErrDecode: %v", err)
+	}
+	if _, err = CSVToRecords(nil, false, "timestamp"); !errors.Is(err, ErrNoData) {
+		t.Fatalf("空CSV应为 ErrNoData: %v", err)
+	}
+	if _, err = ParseAdjust("xx"); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("复权方式错误应为 ErrInvalidParam: %v", err)
+	}
+	if _, err = c.GetDailyValuation(ctx, "", "", "", ""); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("缺少 symbol 应为 ErrInvalidParam: %v", err)
+	}
+	_, err = DecodeRecords[struct {
+		Open float64 `json:"open,required"`
+	}]([]map[string]any{{"close": 1}})
+	if !errors.Is(err, ErrDecode) {
+		t.Fatalf("字段解码错误应为 ErrDecode: %v", err)
+	}
+
+	// 各接口保留错误类型
+	getters := map[string]func(symbol string) error{
+		"GetKbarsHis": func(s string) error {
+			_, err := c.GetKbarsHis(ctx, s, "1m", "2025-07-01", "2025-07-01", false)
+			return err
+		},
+		"GetGM1d": func(s string) error { _, err := c.GetGM1d(ctx, s, "2025-07-01", "2025-07-01", false, true); return err },
+		"GetGMpe": func(s string) error {
+			_, err := c.GetGMpe(ctx, s, "2025-07-01", "2025-07-01", "", false, true)
+			return err
+		},
+		"GetDailyValuation": func(s string) error {
+			_, err := c.GetDailyValuation(ctx, s, "2025-07-01", "2025-07-01", "")
+			return err
+		},
+	}
+	for name, get := range getters {
+		if err := get("DOWN"); !errors.Is(err, &ErrUpstreamStatus{Code: http.StatusBadGateway}) {
+			t.Errorf("%s: 502 应为 ErrUpstreamStatus: %v", name, err)
+		}
+		if err := get("BAD"); !errors.Is(err, ErrDecode) {
+			t.Errorf("%s: JSON 格式错误应为 ErrDecode: %v", name, err)
+		}
+	}
+	for _, name := range []string{"GetGM1d", "GetGMpe"} {
+		if err := getters[name]("EMPTY"); !errors.Is(err, ErrNoData) {
+			t.Errorf("%s: 空结果应为 ErrNoData: %v", name, err)
+		}
+	}
+	if _, err := c.GetCSVTag(ctx, "vv", "SHSE.600000", "2025-01-01", "2025-07-01", false, true); !errors.Is(err, ErrDecode) {
+		t.Errorf("GetCSVTag: 应返回年文件的错误: %v", err)
+	}
+}
+
+func xzBytes(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := xz.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(s))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSVDecoder(t *testing.T) {
+	csvText := "timestamp,open,high,low,close,volume,amount,flag\n" +
+		"2025-06-30 15:00:00,9,9,9,9,100,900,true\n" +
+		"2025-07-01 09:31:00,10,10.2,9.9,10.1,1000,10100,false\n" +
+		"2025-07-01 09:32:00,10.1,10.3,10,10.2,2000,20400,true\n"
+
+	dec, err := NewCSVxzDecoder(bytes.NewReader(xzBytes(t, csvText)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bars, err := dec.ReadOHLCVList()
+	if err != nil || len(bars) != 3 {
+		t.Fatalf("ReadOHLCVList: %v %v", bars, err)
+	}
+	want := time.Date(2025, 7, 1, 9, 31, 0, 0, time.FixedZone("CST", 8*3600))
+	if !bars[1].Timestamp.Equal(want) || bars[1].High != 10.2 || bars[1].Volume != 1000 {
+		t.Fatalf("OHLCV: %+v", bars[1])
+	}
+	if kinds := dec.Columns(); kinds[0].Kind != ColTime || kinds[1].Kind != ColFloat || kinds[7].Kind != ColBool {
+		t.Fatalf("列类型: %+v", kinds)
+	}
+
+	// 与 CSVToRecords 结果一致(数值列统一为 float64)
+	old, err := CSVToRecords([]byte(csvText), true, "timestamp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec, _ = NewCSVDecoder(strings.NewReader(csvText))
+	recs, err := dec.ReadRecords(true, "timestamp", "", "")
+	if err != nil || len(recs) != len(old) {
+		t.Fatalf("ReadRecords: %d %v", len(recs), err)
+	}
+	for i := range recs {
+		for k, v := range old[i] {
+			if fmt.Sprint(recs[i][k]) != fmt.Sprint(v) {
+				t.Fatalf("第%d行 %s: %v != %v", i, k, recs[i][k], v)
+			}
+		}
+	}
+	if _, ok := recs[1]["close"].(float64); !ok {
+		t.Fatalf("close 应为 float64: %T", recs[1]["close"])
+	}
+	if _, ok := recs[1]["volume"].(int64); !ok {
+		t.Fatalf("volume 应为 int64: %T", recs[1]["volume"])
+	}
+
+	dec, _ = NewCSVDecoder(strings.NewReader(csvText))
+	recs, _ = dec.ReadRecords(false, "timestamp", "2025-07-01", "2025-07-01")
+	if len(recs) != 2 || recs[0]["timestamp"] != "2025-07-01 09:31:00" {
+		t.Fatalf("按日期过滤: %v", recs)
+	}
+
+	if _, err := NewCSVDecoder(strings.NewReader("")); !errors.Is(err, ErrNoData) {
+		t.Fatalf("空文件应为 ErrNoData: %v", err)
+	}
+	dec, _ = NewCSVDecoder(strings.NewReader("timestamp,open\n\"2025-07-01,1\n"))
+	for dec.Next() {
+	}
+	if !errors.Is(dec.Err(), ErrDecode) {
+		t.Fatalf("格式错误应为 ErrDecode: %v", dec.Err())
+	}
+
+	// GetCSV1m 跨月获取并截取日期
+	fake := gmfake.New(t.TempDir())
+	fake.PutFile(getFilePathMonth("SHSE.600000", 2025, 6), xzBytes(t, "timestamp,open,high,low,close,volume\n2025-06-30 15:00:00,9,9,9,9,100\n"))
+	fake.PutFile(getFilePathMonth("SHSE.600000", 2025, 7), xzBytes(t, csvText[:strings.Index(csvText, "\n")+1]+csvText[strings.Index(csvText, "\n2025-07")+1:]))
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+	c := NewClient(ts.URL, WithCSVURL(ts.URL), WithRetry(RetryPolicy{}))
+	rows, err := c.GetCSV1m(context.Background(), "SHSE.600000", "2025-06-30", "2025-07-01", true)
+	if err != nil || len(rows) != 3 {
+		t.Fatalf("GetCSV1m: %v %v", rows, err)
+	}
+	rows, err = c.GetCSV1m(context.Background(), "SHSE.600000", "2025-07-01", "2025-07-01", true)
+	if err != nil || len(rows) != 2 || !rows[0].Timestamp.Equal(want) {
+		t.Fatalf("GetCSV1m clip: %v %v", rows, err)
+	}
+	// 没有文件的月份跳过, 其他错误返回
+	rows, err = c.GetCSV1m(context.Background(), "SHSE.600000", "2025-05-30", "2025-07-01", true)
+	if err != nil || len(rows) != 3 {
+		t.Fatalf("GetCSV1m 缺少月份文件: %v %v", rows, err)
+	}
+	fake.PutFile(getFilePathMonth("SHSE.600000", 2025, 5), []byte("bad"))
+	if _, err = c.GetCSV1m(context.Background(), "SHSE.600000", "2025-05-30", "2025-07-01", true); !errors.Is(err, ErrDecode) {
+		t.Fatalf("文件损坏时应返回 ErrDecode: %v", err)
+	}
+}
+
+func TestLocalCSVDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"file://" + filepath.ToSlash(dir), dir, true},
+		{dir, dir, true},
+		{"./gmcsv", "./gmcsv", true},
+		{"http://localhost:5002", "", false},
+		{"localhost:5002", "", false},
+		{"", "", false},
+	} {
+		got, ok := LocalCSVDir(tc.in)
+		if ok != tc.ok || (ok && filepath.Clean(got) != filepath.Clean(tc.want)) {
+			t.Errorf("LocalCSVDir(%q) = %q, %v", tc.in, got, ok)
+		}
+	}
+
+	// 本地目录与 gm-csv 的 /download/ 目录结构相同
+	csvText := "timestamp,open,high,low,close,volume\n2025-07-01 09:31:00,10,10.2,9.9,10.1,1000\n"
+	fpath := filepath.Join(dir, filepath.FromSlash(getFilePathMonth("SHSE.600000", 2025, 7)))
+	os.MkdirAll(filepath.Dir(fpath), 0o755)
+	if err := os.WriteFile(fpath, xzBytes(t, csvText), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ypath := filepath.Join(dir, filepath.FromSlash(getFilePathYear("SHSE.600000", "vv", 2024)))
+	os.MkdirAll(filepath.Dir(ypath), 0o755)
+	if err := os.WriteFile(ypath, xzBytes(t, "timestamp,vv\n2024-12-31,1.5\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, base := range []string{"file://" + filepath.ToSlash(dir), dir} {
+		c := NewClient("", WithCSVURL(base))
+		rows, err := c.GetCSVMonth(context.Background(), "SHSE.600000", 7, 2025)
+		if err != nil || len(rows) != 1 || rows[0].Close != 10.1 {
+			t.Fatalf("%s: GetCSVMonth: %v %v", base, rows, err)
+		}
+		recs, err := c.GetCSVTagYear(context.Background(), "SHSE.600000", "vv", 2024, false, "timestamp")
+		if err != nil || len(recs) != 1 || recs[0]["vv"] != 1.5 {
+			t.Fatalf("%s: GetCSVTagYear: %v %v", base, recs, err)
+		}
+		_, err = c.GetCSVMonth(context.Background(), "SHSE.600000", 8, 2025)
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("%s: 文件不存在应为 ErrNotFound: %v", base, err)
+		}
+	}
+	df, err := DfCSVMonth(dir, "SHSE.600000", 7, 2025, false, 10)
+	if err != nil || df.Nrow() != 1 {
+		t.Fatalf("DfCSVMonth: %v", err)
+	}
+
+	// gm-csv 返回404同样匹配 ErrNotFound
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+	_, err = NewClient("", WithCSVURL(ts.URL), WithRetry(RetryPolicy{})).GetCSVMonth(context.Background(), "SHSE.600000", 7, 2025)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("404 应匹配 ErrNotFound: %v", err)
+	}
+}
+
+func TestArchiveBuilder(t *testing.T) {
+	// 工作日为交易日, 每个交易日2根分时K线
+	var nhis int
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/get_dates_by_year":
+			rcd := RawColData{Columns: []string{"date", "trade_date"}}
+			y, _ := time.Parse("2006", q.Get("syear"))
+			for d := y; d.Year() == y.Year(); d = d.AddDate(0, 0, 1) {
+				td := d.Format("2006-01-02")
+				if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+					td = ""
+				}
+				rcd.Data = append(rcd.Data, []any{d.Format("2006-01-02"), td})
+			}
+			json.NewEncoder(w).Encode(rcd)
+		case "/get_his":
+			mu.Lock()
+			nhis++
+			mu.Unlock()
+			day := q.Get("sdate")
+			rcd := RawColData{Columns: []string{"symbol", "eob", "open", "high", "low", "close", "volume"}, Data: [][]any{}}
+			// SHSE.688001 于 2024-06-03 上市; SHSE.600001 的 2024-03 数据缺失
+			if !(q.Get("symbols") == "SHSE.688001" && day < "2024-06-03") && !(q.Get("symbols") == "SHSE.600001" && day[:7] == "2024-03") {
+				rcd.Data = append(rcd.Data,
+					[]any{q.Get("symbols"), day + " 09:31:00", 10.0, 10.2, 9.9, 10.1, 1000},
+					[]any{q.Get("symbols"), day + " 09:32:00", 10.1, 10.3, 10.0, 10.2, 2000})
+			}
+			json.NewEncoder(w).Encode(rcd)
+		case "/get_infos":
+			rcd := RawColData{Columns: []string{"symbol", "listed_date", "delisted_date"}, Data: [][]any{}}
+			if q.Get("sec") == "stock" {
+				listed := map[string]string{"SHSE.688001": "2024-06-03", "SHSE.600001": "2010-01-04"}[q.Get("symbols")]
+				rcd.Data = append(rcd.Data, []any{q.Get("symbols"), listed, "2038-01-01"})
+			}
+			json.NewEncoder(w).Encode(rcd)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	old := DefaultCalendar()
+	defer SetDefaultCalendar(old)
+	client := NewClient(srv.URL, WithRetry(RetryPolicy{}))
+	SetDefaultCalendar(NewCalendar(client, ""))
+
+	dir := t.TempDir()
+	b := NewArchiveBuilder(dir, client)
+	b.now = func() time.Time { return time.Date(2025, 3, 10, 12, 0, 0, 0, cstZone) }
+
+	tasks, err := b.Plan([]string{"SHSE.600000"}, "1m", "2024-11", "2025-12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2024-11 ~ 2025-03 月文件, 2024年不完整不生成年文件
+	if len(tasks) != 5 || tasks[4].Period != "2025-03" {
+		t.Fatalf("Plan: %v", tasks)
+	}
+	if tasks, _ = b.Plan([]string{"SHSE.600000"}, "1m", "2024", "2024"); len(tasks) != 13 || !tasks[12].IsYear() {
+		t.Fatalf("Plan 年文件: %v", tasks)
+	}
+	if _, err := b.Plan([]string{"SHSE.600000"}, "5m", "2024", "2024"); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("不支持的类型应为参数错误: %v", err)
+	}
+
+	report, err := b.Build(context.Background(), []string{"SHSE.600000"}, "1m", "2024", "2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Written != 13 || report.Skipped != 0 {
+		t.Fatalf("Build: %+v", report)
+	}
+	reader := NewClient("", WithCSVURL(dir))
+	rows, err := reader.GetCSVMonth(context.Background(), "SHSE.600000", 2, 2024)
+	if err != nil || len(rows) != 42 || rows[0].Close != 10.1 {
+		t.Fatalf("月文件: %d %v", len(rows), err)
+	}
+	rows, err = reader.GetCSVYear(context.Background(), "SHSE.600000", 2024)
+	if err != nil || len(rows) != 2*262 {
+		t.Fatalf("年文件: %d %v", len(rows), err)
+	}
+
+	// 断点续传: 已完成的文件不再请求
+	n := nhis
+	b2 := NewArchiveBuilder(dir, client)
+	b2.now = b.now
+	report, err = b2.Build(context.Background(), []string{"SHSE.600000"}, "1m", "2024", "2024")
+	if err != nil || report.Skipped != 13 || nhis != n {
+		t.Fatalf("断点续传: %+v %v", report, err)
+	}
+
+	// 上市前的月份记录为 empty, 年文件合并已上市的月份
+	report, err = b2.Build(context.Background(), []string{"SHSE.688001"}, "1m", "2024", "2024")
+	if err != nil || report.Empty != 5 || report.Written != 8 {
+		t.Fatalf("上市前月份: %+v %v", report, err)
+	}
+	if b2.done[getFilePathMonth("SHSE.688001", 2024, 5)] != "empty" {
+		t.Fatal("上市前的月份应记录为 empty")
+	}
+	rows, err = reader.GetCSVYear(context.Background(), "SHSE.688001", 2024)
+	if err != nil || rows[0].Timestamp.In(cstZone).Format("2006-01-02 15:04:05") != "2024-06-03 09:31:00" {
+		t.Fatalf("年文件: %d %v", len(rows), err)
+	}
+	// 上市期间没有数据的月份按失败处理, 不记录断点
+	report, err = b2.Build(context.Background(), []string{"SHSE.600001"}, "1m", "2024-03", "2024-03")
+	if !errors.Is(err, ErrNoData) || report.Empty != 0 || len(report.Errors) != 1 {
+		t.Fatalf("缺失数据的月份应返回错误: %+v %v", report, err)
+	}
+	if _, ok := b2.done[getFilePathMonth("SHSE.600001", 2024, 3)]; ok {
+		t.Fatal("缺失数据的月份不应记录断点")
+	}
+
+	// 没有留下临时文件
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if strings.HasSuffix(path, ".tmp") {
+			t.Errorf("残留临时文件: %s", path)
+		}
+		return nil
+	})
+}
+
+func TestVerifyArchive(t *testing.T) {
+	// get_his: 工作日均有日K线, suspended 中的日期停牌
+	suspended := map[string]bool{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path == "/get_his" {
+			rcd := RawColData{Columns: []string{"symbol", "eob", "close"}}
+			sd, _ := time.Parse("2006-01-02", q.Get("sdate"))
+			ed, _ := time.Parse("2006-01-02", q.Get("edate"))
+			for d := sd; !d.After(ed); d = d.AddDate(0, 0, 1) {
+				if ds := d.Format("2006-01-02"); d.Weekday() != time.Saturday && d.Weekday() != time.Sunday && !suspended[ds] {
+					rcd.Data = append(rcd.Data, []any{q.Get("symbols"), ds + "T00:00:00+08:00", 10.0})
+				}
+			}
+			json.NewEncoder(w).Encode(rcd)
+			return
+		}
+		rcd := RawColData{Columns: []string{"date", "trade_date"}}
+		y, _ := time.Parse("2006", q.Get("syear"))
+		for d := y; d.Year() == y.Year(); d = d.AddDate(0, 0, 1) {
+			td := d.Format("2006-01-02")
+			if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+				td = ""
+			}
+			rcd.Data = append(rcd.Data, []any{d.Format("2006-01-02"), td})
+		}
+		json.NewEncoder(w).Encode(rcd)
+	}))
+	defer srv.Close()
+	old := DefaultCalendar()
+	defer SetDefaultCalendar(old)
+	SetDefaultCalendar(NewCalendar(NewClient(srv.URL, WithRetry(RetryPolicy{})), ""))
+
+	// 02-05 完整(含30分钟零成交量), 02-06 缺2分钟、重复、OHLC错误、非交易时段, 02-07 缺失, 另有一根3月的K线
+	var sb strings.Builder
+	sb.WriteString("timestamp,open,high,low,close,volume\n")
+	day, _ := time.ParseInLocation("2006-01-02", "2024-02-05", cstZone)
+	for nm := 1; nm <= sessionDayMinutes; nm++ {
+		vol := 100
+		if nm > 60 && nm <= 90 {
+			vol = 0
+		}
+		fmt.Fprintf(&sb, "%s,10,10.2,9.9,10.1,%d\n", sessionTime(day, nm).Format("2006-01-02 15:04:05"), vol)
+	}
+	day = day.AddDate(0, 0, 1)
+	for nm := 1; nm <= sessionDayMinutes; nm++ {
+		ts := sessionTime(day, nm).Format("2006-01-02 15:04:05")
+		switch nm {
+		case 10, 11:
+			continue
+		case 20:
+			fmt.Fprintf(&sb, "%s,10,9.8,9.9,10.1,100\n", ts)
+			continue
+		case 121:
+			fmt.Fprintf(&sb, "2024-02-06 12:00:00,10,10.2,9.9,10.1,100\n")
+		}
+		fmt.Fprintf(&sb, "%s,10,10.2,9.9,10.1,100\n", ts)
+		if nm == 30 {
+			fmt.Fprintf(&sb, "%s,10,10.2,9.9,10.1,100\n", ts)
+		}
+	}
+	sb.WriteString("2024-03-01 09:31:00,10,10.2,9.9,10.1,100\n")
+
+	dir := t.TempDir()
+	fpath := filepath.Join(dir, filepath.FromSlash(getFilePathMonth("SHSE.600000", 2024, 2)))
+	os.MkdirAll(filepath.Dir(fpath), 0o755)
+	if err := os.WriteFile(fpath, xzBytes(t, sb.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClient(srv.URL, WithCSVURL(dir))
+	r, err := c.VerifyArchive(context.Background(), "SHSE.600000", "2024-02-05", "2024-02-07", VerifyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]int{}
+	for _, is := range r.Issues {
+		kinds[is.Kind+" "+is.Date]++
+	}
+	for _, k := range []string{
+		IssueZeroVolume + " 2024-02-05", IssueMissingMinutes + " 2024-02-06", IssueDuplicate + " 2024-02-06",
+		IssueOHLC + " 2024-02-06", IssueOffSession + " 2024-02-06", IssueMissingDay + " 2024-02-07",
+		IssueDayLeak + " 2024-03-01",
+	} {
+		if kinds[k] != 1 {
+			t.Errorf("缺少校验问题 %s: %v", k, kinds)
+		}
+	}
+	if r.TradingDays != 3 || fmt.Sprint(r.BadDays) != "[2024-02-06 2024-02-07 2024-03-01]" {
+		t.Fatalf("BadDays: %v", r.BadDays)
+	}
+	plan := r.RepairPlan()
+	if len(plan.Days) != 3 || len(plan.Files) != 1 || plan.Days[0].File != getFilePathMonth("SHSE.600000", 2024, 2) {
+		t.Fatalf("RepairPlan: %+v", plan)
+	}
+	// 问题按日期、时刻、类型排序
+	if !slices.IsSortedFunc(r.Issues, func(a, b VerifyIssue) int {
+		return cmp.Or(strings.Compare(a.Date, b.Date), strings.Compare(a.Time, b.Time), strings.Compare(a.Kind, b.Kind))
+	}) {
+		t.Errorf("Issues 未排序: %+v", r.Issues)
+	}
+
+	// 02-07 停牌: 仅作提示, 不列入修复计划
+	suspended["2024-02-07"] = true
+	r, err = c.VerifyArchive(context.Background(), "SHSE.600000", "2024-02-05", "2024-02-07", VerifyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(r.BadDays) != "[2024-02-06 2024-03-01]" {
+		t.Fatalf("停牌 BadDays: %v", r.BadDays)
+	}
+	found := false
+	for _, is := range r.Issues {
+		if is.Date == "2024-02-07" {
+			found = is.Kind == IssueSuspended && is.Warn
+		}
+	}
+	if !found {
+		t.Errorf("停牌: %+v", r.Issues)
+	}
+	delete(suspended, "2024-02-07")
+
+	// 文件缺失
+	r, err = c.VerifyArchive(context.Background(), "SHSE.600000", "2024-04-01", "2024-04-03", VerifyOptions{})
+	if err != nil || len(r.BadDays) != 3 || r.Issues[0].Kind != IssueMissingFile {
+		t.Fatalf("文件缺失: %+v %v", r, err)
+	}
+}
+
+func TestGM1mMerge(t *testing.T) {
+	// 合并结果按时间排序, 冲突取优先来源
+	csv := []map[string]any{
+		{"timestamp": "2024-02-05 09:32:00", "open": 10.0, "high": 10.0, "low": 10.0, "close": 10.0, "volume": int64(1)},
+		{"timestamp": "2024-02-05 09:31:00", "open": 10.0, "high": 10.0, "low": 10.0, "close": 10.0, "volume": int64(1)},
+	}
+	api := []map[string]any{
+		{"timestamp": "2024-02-05 09:32:00", "open": 10.0, "high": 10.0, "low": 10.0, "close": 10.5, "volume": 1.0},
+		{"timestamp": "2024-02-05 09:33:00", "open": 10.0, "high": 10.0, "low": 10.0, "close": 10.0, "volume": 1.0},
+	}
+	merged, overlap, conflicts := MergeBars(csv, api, SourceAPI)
+	if len(merged) != 3 || overlap != 1 || conflicts != 1 {
+		t.Fatalf("MergeBars: %d %d %d", len(merged), overlap, conflicts)
+	}
+	if merged[0]["timestamp"] != "2024-02-05 09:31:00" || merged[1]["source"] != SourceAPI || merged[1]["close"] != 10.5 {
+		t.Fatalf("MergeBars: %v", merged)
+	}
+
+	// 02-05 归档完整, 02-06 归档只有上午且收盘价与API不同, 02-07 归档缺失
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/get_dates_by_year":
+			rcd := RawColData{Columns: []string{"date", "trade_date"}}
+			y, _ := time.Parse("2006", q.Get("syear"))
+			for d := y; d.Year() == y.Year(); d = d.AddDate(0, 0, 1) {
+				td := d.Format("2006-01-02")
+				if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+					td = ""
+				}
+				rcd.Data = append(rcd.Data, []any{d.Format("2006-01-02"), td})
+			}
+			json.NewEncoder(w).Encode(rcd)
+		case "/get_his":
+			day, _ := time.ParseInLocation("2006-01-02", q.Get("sdate"), cstZone)
+			rcd := RawColData{Columns: []string{"symbol", "eob", "open", "high", "low", "close", "volume"}}
+			for nm := 1; nm <= sessionDayMinutes; nm++ {
+				ts := sessionTime(day, nm).Format("2006-01-02 15:04:05")
+				rcd.Data = append(rcd.Data, []any{q.Get("symbols"), ts, 10.0, 10.3, 9.9, 10.2, 100})
+			}
+			json.NewEncoder(w).Encode(rcd)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	old := DefaultCalendar()
+	defer SetDefaultCalendar(old)
+	SetDefaultCalendar(NewCalendar(NewClient(srv.URL, WithRetry(RetryPolicy{})), ""))
+
+	var sb strings.Builder
+	sb.WriteString("timestamp,open,high,low,close,volume\n")
+	for _, d := range []string{"2024-02-05", "2024-02-06"} {
+		day, _ := time.ParseInLocation("2006-01-02", d, cstZone)
+		for nm := 1; nm <= sessionDayMinutes; nm++ {
+			if d == "2024-02-06" && nm > sessionAmMinutes {
+				break
+			}
+			fmt.Fprintf(&sb, "%s,10,10.2,9.9,10.1,100\n", sessionTime(day, nm).Format("2006-01-02 15:04:05"))
+		}
+	}
+	dir := t.TempDir()
+	fpath := filepath.Join(dir, filepath.FromSlash(getFilePathMonth("SHSE.600000", 2024, 2)))
+	os.MkdirAll(filepath.Dir(fpath), 0o755)
+	if err := os.WriteFile(fpath, xzBytes(t, sb.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, prefer := range []string{SourceCSV, SourceAPI} {
+		c := NewClient(srv.URL, WithCSVURL(dir), WithRetry(RetryPolicy{}), WithMergePrefer(prefer))
+		rows, cov, err := c.GetGM1mCoverage(context.Background(), "SHSE.600000", "2024-02-05", "2024-02-07", false, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 3*sessionDayMinutes || !cov.Complete() || cov.CSVDays != 1 || cov.APIDays != 2 ||
+			cov.Overlap != sessionAmMinutes || cov.Conflicts != sessionAmMinutes {
+			t.Fatalf("%s: %d %+v", prefer, len(rows), cov)
+		}
+		for i := 1; i < len(rows); i++ {
+			if fmt.Sprint(rows[i-1]["timestamp"]) >= fmt.Sprint(rows[i]["timestamp"]) {
+				t.Fatalf("%s: 未按时间排序: %v %v", prefer, rows[i-1], rows[i])
+			}
+		}
+		// 02-06 上午的K线
+		am := rows[sessionDayMinutes]
+		if am["source"] != prefer {
+			t.Fatalf("%s: 冲突K线来源错误: %v", prefer, am)
+		}
+		if rows[0]["source"] != SourceCSV || rows[len(rows)-1]["source"] != SourceAPI {
+			t.Fatalf("%s: 来源标注错误", prefer)
+		}
+	}
+
+	if _, _, err := NewClient(srv.URL, WithMergePrefer("db")).GetGM1mCoverage(context.Background(),
+		"SHSE.600000", "2024-02-05", "2024-02-07", false, true); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("不支持的来源应为参数错误: %v", err)
+	}
+}
+
+func TestSnapData(t *testing.T) {
+	records := `[{"symbol":"SHSE.600000","open":10,"high":10.3,"low":9.9,"price":10.2,"cum_volume":12300,
+		"cum_amount":125000.5,"created_at":"2025-07-01T10:00:03+08:00","quotes":[{"bid_p":10.19,"bid_v":300,"ask_p":10.2,"ask_v":500}]}]`
+	split := `{"columns":["symbol","price","cum_volume","created_at","quotes"],
+		"data":[["SZSE.000001","11.5",800,"2025-07-01 10:00:03",[{"bid_p":11.49,"bid_v":100.0,"ask_p":11.5,"ask_v":200}]]]}`
+	for _, raw := range []string{records, split} {
+		snaps, err := decodeSnapData([]byte(raw))
+		if err != nil || len(snaps) != 1 {
+			t.Fatalf("decodeSnapData: %v %v", snaps, err)
+		}
+		s := snaps[0]
+		if s.Price <= 0 || s.CumVolume <= 0 || s.CreatedAt.In(cstZone).Format("15:04:05") != "10:00:03" ||
+			len(s.Quotes) != 1 || s.Quotes[0].BidPrice <= 0 || s.Quotes[0].AskVolume <= 0 {
+			t.Fatalf("decodeSnapData: %+v", s)
+		}
+	}
+	if _, err := decodeSnapData([]byte(`[{"symbol":`)); !errors.Is(err, ErrDecode) {
+		t.Fatalf("格式错误应为 ErrDecode: %v", err)
+	}
+}
+
+func TestBarBuilder(t *testing.T) {
+	day := time.Date(2025, 7, 1, 0, 0, 0, 0, cstZone)
+	at := func(hms string) time.Time {
+		tt, _ := time.ParseInLocation("2006-01-02 15:04:05", day.Format("2006-01-02")+" "+hms, cstZone)
+		return tt
+	}
+	b := NewBarBuilder("SHSE.600000")
+	var done OHLCVList
+	for _, s := range []SnapData{
+		{CreatedAt: at("09:20:00"), Price: 10.0, CumVolume: 0}, // 集合竞价撮合前, 忽略
+		{CreatedAt: at("09:25:03"), Price: 10.0, CumVolume: 1000},
+		{CreatedAt: at("09:30:30"), Price: 10.2, CumVolume: 1500},
+		{CreatedAt: at("09:30:30"), Price: 10.9, CumVolume: 9000}, // 重复的快照
+		{CreatedAt: at("09:31:00"), Price: 9.9, CumVolume: 1800},
+		{CreatedAt: at("09:31:20"), Price: 10.1, CumVolume: 2000},
+		{CreatedAt: at("09:34:10"), Price: 10.3, CumVolume: 2600}, // 09:33, 09:34 没有快照
+	} {
+		done = append(done, b.Add(s)...)
+	}
+	want := []struct {
+		hm     string
+		o, h   float64
+		l, c   float64
+		volume int64
+	}{
+		{"09:31", 10.0, 10.2, 9.9, 9.9, 1800},
+		{"09:32", 10.1, 10.1, 10.1, 10.1, 200},
+		{"09:33", 10.1, 10.1, 10.1, 10.1, 0},
+		{"09:34", 10.1, 10.1, 10.1, 10.1, 0},
+		{"09:35", 10.3, 10.3, 10.3, 10.3, 600},
+	}
+	bars := b.Bars()
+	if len(bars) != len(want) || len(done) != len(want)-1 {
+		t.Fatalf("K线数: %d, 完成: %d", len(bars), len(done))
+	}
+	for i, w := range want {
+		k := bars[i]
+		if k.Timestamp.Format("15:04") != w.hm || k.Open != w.o || k.High != w.h || k.Low != w.l || k.Close != w.c || k.Volume != w.volume {
+			t.Errorf("第%d根: %+v, want %+v", i, k, w)
+		}
+	}
+
+	// 开盘后启动: 用 get_his 的K线初始化, 成交量从已有K线累计值继续
+	b2 := NewBarBuilder("SHSE.600000")
+	b2.Seed(bars[:2])
+	b2.Add(SnapData{CreatedAt: at("09:32:30"), Price: 10.4, CumVolume: 2500})
+	if got := b2.Bars(); len(got) != 3 || got[2].Timestamp.Format("15:04") != "09:33" || got[2].Volume != 500 {
+		t.Fatalf("Seed: %+v", got)
+	}
+
+	// 午休和收盘: 11:30/15:00 的K线由 Flush 完成, 之后的快照不再更新, 也不重复完成
+	b3 := NewBarBuilder("SHSE.600000")
+	b3.Add(SnapData{CreatedAt: at("11:29:50"), Price: 10.0, CumVolume: 100})
+	b3.Add(SnapData{CreatedAt: at("11:30:02"), Price: 10.1, CumVolume: 300})
+	if got := b3.Flush(); len(got) != 1 || got[0].Timestamp.Format("15:04") != "11:30" || got[0].Close != 10.1 {
+		t.Fatalf("Flush 11:30: %+v", got)
+	}
+	if got := b3.Flush(); got != nil {
+		t.Fatalf("重复 Flush: %+v", got)
+	}
+	b3.Add(SnapData{CreatedAt: at("12:00:00"), Price: 10.5, CumVolume: 300})
+	if got := b3.Add(SnapData{CreatedAt: at("13:00:30"), Price: 10.2, CumVolume: 400}); len(got) != 0 {
+		t.Fatalf("午后第一个快照不应再完成 11:30: %+v", got)
+	}
+	if last := b3.Bars()[0]; last.Close != 10.1 {
+		t.Fatalf("11:30 在 Flush 后被更新: %+v", last)
+	}
+	b3.Add(SnapData{CreatedAt: at("15:00:01"), Price: 10.3, CumVolume: 900})
+	got := b3.Flush()
+	if len(got) != 1 || got[0].Timestamp.Format("15:04") != "15:00" || got[0].Close != 10.3 || got[0].Volume != 500 {
+		t.Fatalf("Flush 15:00: %+v", got)
+	}
+
+	// SnapPoller.Flush 回调 OnBar
+	p := &SnapPoller{builders: map[string]*BarBuilder{"SHSE.600000": b2}}
+	var flushed []string
+	p.OnBar = func(symbol string, bar OHLCVData) {
+		flushed = append(flushed, symbol+" "+bar.Timestamp.Format("15:04"))
+	}
+	p.Flush()
+	p.Flush()
+	if len(flushed) != 1 || flushed[0] != "SHSE.600000 09:33" {
+		t.Fatalf("SnapPoller.Flush: %v", flushed)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/get_dates_by_year":
+			rcd := RawColData{Columns: []string{"date", "trade_date"}}
+			y, _ := time.Parse("2006", q.Get("syear"))
+			for d := y; d.Year() == y.Year(); d = d.AddDate(0, 0, 1) {
+				td := d.Format("2006-01-02")
+				if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+					td = ""
+				}
+				rcd.Data = append(rcd.Data, []any{d.Format("2006-01-02"), td})
+			}
+			json.NewEncoder(w).Encode(rcd)
+		case "/get_his":
+			day, _ := time.ParseInLocation("2006-01-02", q.Get("sdate"), cstZone)
+			rcd := RawColData{Columns: []string{"symbol", "eob", "open", "high", "low", "close", "volume"}}
+			for nm := 1; nm <= sessionDayMinutes; nm++ {
+				ts := sessionTime(day, nm).Format("2006-01-02 15:04:05")
+				rcd.Data = append(rcd.Data, []any{q.Get("symbols"), ts, 10.0, 10.3, 9.9, 10.0 + float64(nm)/100, 100})
+			}
+			json.NewEncoder(w).Encode(rcd)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	old := DefaultCalendar()
+	defer SetDefaultCalendar(old)
+	client := NewClient(srv.URL, WithRetry(RetryPolicy{}))
+	SetDefaultCalendar(NewCalendar(client, ""))
+
+	var mu sync.Mutex
+	var got []ReplayBar
+	r := NewReplay(client, []string{"SHSE.600000", "SZSE.000001"}, "2024-02-05", 60000)
+	r.OnBar = func(symbol string, bar OHLCVData) {
+		mu.Lock()
+		got = append(got, ReplayBar{Symbol: symbol, Bar: bar})
+		mu.Unlock()
+	}
+	if err := r.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if st := r.State(); st.Total != 480 || st.Pos != 0 || st.Done {
+		t.Fatalf("state: %+v", st)
+	}
+
+	// 单步: 每分钟两个标的, 之后处于暂停状态
+	if out := r.Step(2); len(out) != 4 || out[0].Symbol != "SHSE.600000" || out[1].Symbol != "SZSE.000001" ||
+		out[3].Bar.Timestamp.In(cstZone).Format("15:04") != "09:32" {
+		t.Fatalf("Step: %+v", out)
+	}
+	if st := r.State(); !st.Paused || st.Pos != 4 || st.Time.In(cstZone).Format("15:04") != "09:32" {
+		t.Fatalf("state after step: %+v", st)
+	}
+
+	// 跳转到上午收盘, 之前的K线不回调
+	r.Seek(time.Date(2024, 2, 5, 11, 30, 0, 0, cstZone))
+	if n := len(r.Bars("SHSE.600000")); n != sessionAmMinutes {
+		t.Fatalf("Bars after seek: %d", n)
+	}
+	if gap := tradingGap(time.Date(2024, 2, 5, 11, 30, 0, 0, cstZone), time.Date(2024, 2, 5, 13, 1, 0, 0, cstZone)); gap != time.Minute {
+		t.Errorf("tradingGap over lunch: %v", gap)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- r.Run(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	if len(got) != 4 {
+		t.Errorf("paused replay emitted: %d", len(got))
+	}
+	mu.Unlock()
+	r.Resume()
+	waitDone := func() {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); !r.State().Done; time.Sleep(5 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("replay not done: %+v", r.State())
+			}
+		}
+	}
+	waitDone()
+	mu.Lock()
+	if len(got) != 4+sessionAmMinutes*2 {
+		t.Fatalf("emitted %d bars", len(got))
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].Bar.Timestamp.Before(got[i-1].Bar.Timestamp) {
+			t.Fatalf("not in order at %d", i)
+		}
+	}
+	if first := got[4].Bar.Timestamp.In(cstZone).Format("15:04"); first != "13:01" {
+		t.Errorf("first bar after seek: %s", first)
+	}
+	if st := r.State(); !st.Done || st.Time.In(cstZone).Format("15:04") != "15:00" {
+		t.Errorf("final state: %+v", st)
+	}
+
+	// 回放结束后 Run 仍在运行: 跳回14:50继续发出剩余的10分钟
+	n := len(got)
+	mu.Unlock()
+	r.Seek(time.Date(2024, 2, 5, 14, 50, 0, 0, cstZone))
+	waitDone()
+	mu.Lock()
+	if len(got)-n != 20 || got[n].Bar.Timestamp.In(cstZone).Format("15:04") != "14:51" {
+		t.Errorf("after seek back: %d bars", len(got)-n)
+	}
+	mu.Unlock()
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run: %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package gm
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 复权方式
+const (
+	AdjustNone = "none" // 不复权
+	AdjustQfq  = "qfq"  // 前复权
+	AdjustHfq  = "hfq"  // 后复权
+)
+
+// 需要复权的价格字段
+var adjustPriceKeys = []string{"open", "high", "low", "close", "hjj", "pvj", "cbj", "cb1", "cb2"}
+
+// 检查复权参数, 空字符串视为不复权
+func ParseAdjust(adjust string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(adjust)) {
+	case "", AdjustNone:
+		return AdjustNone, nil
+	case AdjustQfq:
+		return AdjustQfq, nil
+	case AdjustHfq:
+		return AdjustHfq, nil
+	}
+	return "", invalidParam("不支持的复权方式: %s (可选: none, qfq, hfq)", adjust)
+}
+
+// 是否为基金代码(ETF/LOF等): 沪市5开头, 深市15/16/18开头
+func IsFundSymbol(symbol string) bool {
+	code := LastNChars(symbol, 6)
+	switch {
+	case strings.HasPrefix(symbol, "SHSE."):
+		return strings.HasPrefix(code, "5")
+	case strings.HasPrefix(symbol, "SZSE."):
+		return strings.HasPrefix(code, "15") || strings.HasPrefix(code, "16") || strings.HasPrefix(code, "18")
+	}
+	return false
+}
+
+// 是否为指数代码: 沪市000开头, 深市399开头
+func IsIndexSymbol(symbol string) bool {
+	code := LastNChars(symbol, 6)
+	return (strings.HasPrefix(symbol, "SHSE.") && strings.HasPrefix(code, "000")) ||
+		(strings.HasPrefix(symbol, "SZSE.") && strings.HasPrefix(code, "399"))
+}
+
+// 按标的类型获取复权因子: 股票用 get_adj_factor, 基金用 get_adj_factor_fnd, 指数无需复权
+func (c *Client) GetSymbolAdjFactor(ctx context.Context,
+	symbol string, sdate string, edate string) ([]AdjFactor, error) {
+	if IsIndexSymbol(symbol) {
+		return nil, nil
+	}
+	if IsFundSymbol(symbol) {
+		return c.GetFndAdjFactorList(ctx, symbol, sdate, edate, "")
+	}
+	return c.GetAdjFactorList(ctx, symbol, sdate, edate, "")
+}
+
+// 按交易日查询复权因子
+type adjFactorLookup struct {
+	days    []string
+	factors []float64
+}
+
+func newAdjFactorLookup(factors []AdjFactor, adjust string) *adjFactorLookup {
+	tz := time.FixedZone("CST", 8*3600)
+	sorted := make([]AdjFactor, len(factors))
+	copy(sorted, factors)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].TradeDate.Before(sorted[j].TradeDate) })
+
+	lk := &adjFactorLookup{}
+	for _, f := range sorted {
+		v := f.AdjFactorFwdAcc
+		if adjust == AdjustHfq {
+			v = f.AdjFactorBwdAcc
+		}
+		if v <= 0 {
+			continue
+		}
+		lk.days = append(lk.days, f.TradeDate.In(tz).Format("2006-01-02"))
+		lk.factors = append(lk.factors, v)
+	}
+	return lk
+}
+
+// 取不晚于该日的最近一个复权因子，早于首个因子时取首个因子
+func (lk *adjFactorLookup) at(day string) float64 {
+	if len(lk.days) == 0 {
+		return 1
+	}
+	i := sort.SearchStrings(lk.days, day)
+	if i < len(lk.days) && lk.days[i] == day {
+		return lk.factors[i]
+	}
+	if i == 0 {
+		return lk.factors[0]
+	}
+	return lk.factors[i-1]
+}
+
+// 分时/日线数据复权(成交量不变)
+func (k *OHLCVList) Adjust(factors []AdjFactor, adjust string) {
+	if adjust == AdjustNone || adjust == "" {
+		return
+	}
+	lk := newAdjFactorLookup(factors, adjust)
+	tz := time.FixedZone("CST", 8*3600)
+	for i := range *k {
+		kb := &(*k)[i]
+		f := lk.at(kb.Timestamp.In(tz).Format("2006-01-02"))
+		kb.Open *= f
+		kb.High *= f
+		kb.Low *= f
+		kb.Close *= f
+	}
+}
+
+// 日频vv数据复权: 价格类指标(ohlc, hjj, pvj, cbj, cb1, cb2)按当日因子调整
+func (k *VVList) Adjust(factors []AdjFactor, adjust string) {
+	if adjust == AdjustNone || adjust == "" {
+		return
+	}
+	lk := newAdjFactorLookup(factors, adjust)
+	for i := range *k {
+		vv := &(*k)[i]
+		f := lk.at(vv.DateString())
+		vv.Open *= f
+		vv.High *= f
+		vv.Low *= f
+		vv.Close *= f
+		vv.Hjj *= f
+		vv.Pvj *= f
+		vv.Cbj *= f
+		vv.Cb1 *= f
+		vv.Cb2 *= f
+	}
+}
+
+// records 数据复权(原地修改): 按 timestamp 所在交易日调整价格字段
+func AdjustRecords(records []map[string]any, factors []AdjFactor, adjust string) error {
+	if adjust == AdjustNone || adjust == "" {
+		return nil
+	}
+	lk := newAdjFactorLookup(factors, adjust)
+	tz := time.FixedZone("CST", 8*3600)
+	for i, rec := range records {
+		ts, err := ParseTimestamp(rec["timestamp"])
+		if err != nil {
+			return &FieldError{Row: i, Field: "timestamp", Value: rec["timestamp"], Err: err}
+		}
+		f := lk.at(ts.In(tz).Format("2006-01-02"))
+		for _, key := range adjustPriceKeys {
+			val, ok := rec[key]
+			if !ok || val == nil {
+				continue
+			}
+			v, err := anyToFloat(val)
+			if err != nil {
+				return &FieldError{Row: i, Field: key, Value: val, Err: err}
+			}
+			rec[key] = v * f
+		}
+	}
+	return nil
+}
@@ -0,0 +1,481 @@
+package gm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/lmzxtek/ths-go/cxz"
+)
+
+// 归档任务: 一个 csv.xz 文件
+type ArchiveTask struct {
+	Symbol string
+	Tag    string // 1m, vv, pe
+	Period string // 月文件为 2006-01, 年文件为 2006
+	Path   string // 相对路径, 与 getFilePathMonth/getFilePathYear 一致
+	SDate  string // 数据开始日期
+	EDate  string // 数据结束日期
+}
+
+// 是否为年文件
+func (t ArchiveTask) IsYear() bool {
+	return len(t.Period) == 4
+}
+
+// 归档构建结果
+type ArchiveReport struct {
+	Written int        // 写入的文件数
+	Skipped int        // 断点记录中已完成而跳过的文件数
+	Empty   int        // 没有数据的文件数(如上市前)
+	Errors  ItemErrors // 失败的任务
+}
+
+// 断点文件格式: 相对路径 -> "ok" / "empty"
+type archiveCheckpoint struct {
+	Done map[string]string `json:"done"`
+}
+
+// 从 gm-api 生成 gm-csv 归档(kbars-month, kbars-year, kbars-vv, kbars-pe 目录结构)
+//
+// 每个文件先写入临时文件再重命名; 已结束的月份/年份写入后记录到断点文件,
+// 中断后重新运行时跳过。当月/当年的文件每次都重新生成。
+type ArchiveBuilder struct {
+	Dir        string        // 输出目录, 对应 gm-csv 的 /download/ 目录
+	Client     *Client       // gm-api 客户端
+	Checkpoint string        // 断点文件, 为空时取 {Dir}/.archive-checkpoint.json
+	Force      bool          // 忽略断点记录, 重新生成所有文件
+	Spec       IndicatorSpec // vv指标定义
+	PEFields   string        // pe 文件的字段, 为空时取全部字段
+
+	now   func() time.Time
+	done  map[string]string
+	infos map[string]SymbolInfo // 标的上市/退市日期
+}
+
+func NewArchiveBuilder(dir string, client *Client) *ArchiveBuilder {
+	return &ArchiveBuilder{
+		Dir:    dir,
+		Client: client,
+		Spec:   DefaultIndicatorSpec,
+		now:    time.Now,
+	}
+}
+
+func (b *ArchiveBuilder) checkpointPath() string {
+	if b.Checkpoint != "" {
+		return b.Checkpoint
+	}
+	return filepath.Join(b.Dir, ".archive-checkpoint.json")
+}
+
+func (b *ArchiveBuilder) today() string {
+	return b.now().In(cstZone).Format("2006-01-02")
+}
+
+// 解析归档日期: 2006, 2006-01 或 2006-01-02; end 为 true 时取期末
+func parseArchiveDate(s string, end bool) (time.Time, error) {
+	for _, f := range []string{"2006-01-02", "2006-01", "2006"} {
+		t, err := time.Parse(f, s)
+		if err != nil {
+			continue
+		}
+		if end {
+			switch f {
+			case "2006-01":
+				t = t.AddDate(0, 1, -1)
+			case "2006":
+				t = t.AddDate(1, 0, -1)
+			}
+		}
+		return t, nil
+	}
+	return time.Time{}, invalidParam("日期格式错误: %s (示例: 2024, 2024-01, 2024-01-02)", s)
+}
+
+// 生成归档任务列表
+//
+// 1m 按整月生成月文件, [from, to] 完整包含的已结束年份另生成年文件;
+// vv, pe 按整年生成年文件。晚于今天的月份/年份不生成。
+func (b *ArchiveBuilder) Plan(symbols []string, tag string, from string, to string) ([]ArchiveTask, error) {
+	if tag != "1m" && tag != "vv" && tag != "pe" {
+		return nil, invalidParam("不支持的归档类型: %s (可选: 1m, vv, pe)", tag)
+	}
+	start, err := parseArchiveDate(from, false)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseArchiveDate(to, true)
+	if err != nil {
+		return nil, err
+	}
+	if start.After(end) {
+		return nil, invalidParam("开始日期大于结束日期: %s > %s", from, to)
+	}
+	ts, _ := time.Parse("2006-01-02", b.today())
+	if end.After(ts) {
+		end = ts
+	}
+	todayYear := ts.Year()
+
+	var tasks []ArchiveTask
+	for _, symbol := range symbols {
+		if len(symbol) < 7 {
+			return nil, invalidParam("股票代码格式错误: %s (示例: SHSE.600000)", symbol)
+		}
+		if tag != "1m" {
+			for y := start.Year(); y <= end.Year(); y++ {
+				tasks = append(tasks, ArchiveTask{
+					Symbol: symbol, Tag: tag, Period: strconv.Itoa(y),
+					Path:  getFilePathYear(symbol, tag, y),
+					SDate: fmt.Sprintf("%d-01-01", y), EDate: fmt.Sprintf("%d-12-31", y),
+				})
+			}
+			continue
+		}
+		m := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+		for !m.After(end) {
+			y, mm := m.Year(), int(m.Month())
+			tasks = append(tasks, ArchiveTask{
+				Symbol: symbol, Tag: tag, Period: m.Format("2006-01"),
+				Path:  getFilePathMonth(symbol, y, mm),
+				SDate: m.Format("2006-01-02"), EDate: m.AddDate(0, 1, -1).Format("2006-01-02"),
+			})
+			// 年文件由该年各月文件合并, 排在12月之后(只生成已结束且完整包含的年份)
+			if mm == 12 && y < todayYear && (start.Year() < y || start.Month() == 1) {
+				tasks = append(tasks, ArchiveTask{
+					Symbol: symbol, Tag: tag, Period: strconv.Itoa(y),
+					Path:  getFilePathYear(symbol, tag, y),
+					SDate: fmt.Sprintf("%d-01-01", y), EDate: fmt.Sprintf("%d-12-31", y),
+				})
+			}
+			m = m.AddDate(0, 1, 0)
+		}
+	}
+	return tasks, nil
+}
+
+// 生成归档文件, 单个任务失败时继续其余任务; 调用方取消时立即返回
+func (b *ArchiveBuilder) Build(ctx context.Context, symbols []string, tag string, from string, to string) (*ArchiveReport, error) {
+	tasks, err := b.Plan(symbols, tag, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.loadCheckpoint(); err != nil {
+		return nil, err
+	}
+
+	report := &ArchiveReport{}
+	today := b.today()
+	for i, task := range tasks {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if !b.Force && b.isDone(task) {
+			report.Skipped++
+			continue
+		}
+
+		n, err := b.buildTask(ctx, task)
+		if err != nil {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			b.Client.Logger.Printf("归档失败: %s: %v", task.Path, err)
+			report.Errors = append(report.Errors, &ItemError{Index: i, Key: task.Path, Err: err})
+			continue
+		}
+		status := "ok"
+		if n == 0 {
+			// 已结束的期间只有确实没有数据时才记录为 empty, 否则按失败处理, 下次重新获取
+			if task.EDate < today {
+				if err := b.checkEmpty(ctx, task); err != nil {
+					if ctx.Err() != nil {
+						return report, ctx.Err()
+					}
+					b.Client.Logger.Printf("归档失败: %s: %v", task.Path, err)
+					report.Errors = append(report.Errors, &ItemError{Index: i, Key: task.Path, Err: err})
+					continue
+				}
+			}
+			status = "empty"
+			report.Empty++
+		} else {
+			report.Written++
+			b.Client.Logger.Printf("归档完成: %s (%d行)", task.Path, n)
+		}
+		// 只记录已结束的月份/年份
+		if task.EDate < today {
+			b.done[task.Path] = status
+			if err := b.saveCheckpoint(); err != nil {
+				return report, err
+			}
+		}
+	}
+	if len(report.Errors) > 0 {
+		return report, report.Errors
+	}
+	return report, nil
+}
+
+// 确认没有数据的任务: 期间没有交易日, 或在上市前/退市后
+func (b *ArchiveBuilder) checkEmpty(ctx context.Context, task ArchiveTask) error {
+	dates, err := b.Client.tradingDates(ctx, task.SDate, task.EDate)
+	if err != nil {
+		return fmt.Errorf("获取交易日列表失败: %w", err)
+	}
+	if len(dates) == 0 {
+		return nil
+	}
+	info, err := b.symbolInfo(ctx, task.Symbol)
+	if err != nil {
+		return err
+	}
+	if !info.ListedDate.IsZero() && task.EDate < info.ListedDate.In(cstZone).Format("2006-01-02") {
+		return nil
+	}
+	if !info.DelistedDate.IsZero() && task.SDate > info.DelistedDate.In(cstZone).Format("2006-01-02") {
+		return nil
+	}
+	return fmt.Errorf("%w: 上市期间的交易日没有数据: %s - %s", ErrNoData, task.SDate, task.EDate)
+}
+
+// 查询标的信息(按股票、基金、指数依次查找), 结果缓存
+func (b *ArchiveBuilder) symbolInfo(ctx context.Context, symbol string) (SymbolInfo, error) {
+	if info, ok := b.infos[symbol]; ok {
+		return info, nil
+	}
+	for _, sec := range []string{"stock", "fund", "index"} {
+		rsp, err := b.Client.GetMarketInfoList(ctx, symbol, sec, "")
+		if err != nil && len(rsp) == 0 {
+			return SymbolInfo{}, fmt.Errorf("获取标的信息失败: %w", err)
+		}
+		for _, info := range rsp {
+			if info.Symbol == symbol {
+				if b.infos == nil {
+					b.infos = map[string]SymbolInfo{}
+				}
+				b.infos[symbol] = info
+				return info, nil
+			}
+		}
+	}
+	return SymbolInfo{}, fmt.Errorf("%w: 未找到标的信息: %s", ErrNotFound, symbol)
+}
+
+// 断点记录中已完成(且文件仍存在)
+func (b *ArchiveBuilder) isDone(task ArchiveTask) bool {
+	switch b.done[task.Path] {
+	case "empty":
+		return true
+	case "ok":
+		_, err := os.Stat(filepath.Join(b.Dir, filepath.FromSlash(task.Path)))
+		return err == nil
+	}
+	return false
+}
+
+func (b *ArchiveBuilder) loadCheckpoint() error {
+	b.done = map[string]string{}
+	data, err := os.ReadFile(b.checkpointPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取断点文件失败: %w", err)
+	}
+	var cp archiveCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return fmt.Errorf("解析断点文件失败: %s: %w", b.checkpointPath(), err)
+	}
+	if cp.Done != nil {
+		b.done = cp.Done
+	}
+	return nil
+}
+
+// 保存断点文件(临时文件+重命名)
+func (b *ArchiveBuilder) saveCheckpoint() error {
+	data, err := json.MarshalIndent(archiveCheckpoint{Done: b.done}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fpath := b.checkpointPath()
+	if err := os.MkdirAll(filepath.Dir(fpath), 0o755); err != nil {
+		return fmt.Errorf("无法创建目录: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(fpath), filepath.Base(fpath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("无法创建临时文件: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入断点文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入断点文件失败: %w", err)
+	}
+	return os.Rename(tmp.Name(), fpath)
+}
+
+// 生成单个文件, 返回数据行数; 没有数据时不写入文件
+func (b *ArchiveBuilder) buildTask(ctx context.Context, task ArchiveTask) (int, error) {
+	var records [][]string
+	var err error
+	switch {
+	case task.Tag == "pe":
+		records, err = b.peRecords(ctx, task)
+	case task.Tag == "vv":
+		records, err = b.vvRecords(ctx, task)
+	case task.IsYear():
+		records, err = b.mergeMonthFiles(task)
+	default:
+		records, err = b.minuteRecords(ctx, task)
+	}
+	if err != nil || len(records) <= 1 {
+		return 0, err
+	}
+	fpath := filepath.Join(b.Dir, filepath.FromSlash(task.Path))
+	if err := cxz.SaveCSVxzAtomic(records, fpath); err != nil {
+		return 0, err
+	}
+	return len(records) - 1, nil
+}
+
+// 1m 文件的列
+var archive1mColumns = []string{"timestamp", "open", "high", "low", "close", "volume"}
+
+// 从 gm-api 获取分时数据
+func (b *ArchiveBuilder) fetch1m(ctx context.Context, task ArchiveTask) (OHLCVList, error) {
+	dates, err := b.Client.tradingDates(ctx, task.SDate, min(task.EDate, b.today()))
+	if err != nil {
+		return nil, fmt.Errorf("获取交易日列表失败: %w", err)
+	}
+	if len(dates) == 0 {
+		return nil, nil
+	}
+	// 不写入不完整的文件: 任一交易日失败则整个任务失败
+	rawData, err := b.Client.Get1mByDatelist(ctx, task.Symbol, dates, false)
+	if err != nil {
+		return nil, err
+	}
+	var ohlcv OHLCVList
+	if err := ohlcv.FromMapList(rawData); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(ohlcv, func(i, j int) bool { return ohlcv[i].Timestamp.Before(ohlcv[j].Timestamp) })
+	return ohlcv, nil
+}
+
+func ohlcvCSVRow(k *OHLCVData) []string {
+	ff := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return []string{
+		k.Timestamp.In(cstZone).Format("2006-01-02 15:04:05"),
+		ff(k.Open), ff(k.High), ff(k.Low), ff(k.Close),
+		strconv.FormatInt(k.Volume, 10),
+	}
+}
+
+// 1m 月文件
+func (b *ArchiveBuilder) minuteRecords(ctx context.Context, task ArchiveTask) ([][]string, error) {
+	ohlcv, err := b.fetch1m(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	records := make([][]string, 0, len(ohlcv)+1)
+	records = append(records, archive1mColumns)
+	for i := range ohlcv {
+		records = append(records, ohlcvCSVRow(&ohlcv[i]))
+	}
+	return records, nil
+}
+
+// 1m 年文件: 合并该年已生成的月文件
+func (b *ArchiveBuilder) mergeMonthFiles(task ArchiveTask) ([][]string, error) {
+	y, _ := strconv.Atoi(task.Period)
+	records := [][]string{archive1mColumns}
+	for m := 1; m <= 12; m++ {
+		rel := getFilePathMonth(task.Symbol, y, m)
+		fpath := filepath.Join(b.Dir, filepath.FromSlash(rel))
+		rows, err := cxz.ReadCSVxzFile(fpath)
+		if errors.Is(err, os.ErrNotExist) {
+			if b.done[rel] == "empty" {
+				continue // 该月没有数据
+			}
+			return nil, fmt.Errorf("月文件缺失: %s", rel)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取月文件失败: %s: %w", fpath, err)
+		}
+		if len(rows) > 1 {
+			records = append(records, rows[1:]...)
+		}
+	}
+	return records, nil
+}
+
+// vv 年文件, 列与 VVColumns 一致
+func (b *ArchiveBuilder) vvRecords(ctx context.Context, task ArchiveTask) ([][]string, error) {
+	ohlcv, err := b.fetch1m(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	vvl := ohlcv.ToVVListSpec(b.Spec, true, true, true)
+	records := make([][]string, 0, len(vvl)+1)
+	records = append(records, VVColumns)
+	for i := range vvl {
+		records = append(records, vvl[i].ToCSVRow())
+	}
+	return records, nil
+}
+
+// pe 年文件: trade_date 列在前, 其余列按名称排序
+func (b *ArchiveBuilder) peRecords(ctx context.Context, task ArchiveTask) ([][]string, error) {
+	rsp, err := b.Client.GetDailyValuation(ctx, task.Symbol, task.SDate, task.EDate, b.PEFields)
+	if err != nil {
+		return nil, err
+	}
+	if len(rsp) == 0 {
+		return nil, nil
+	}
+	cols := []string{"trade_date"}
+	for k := range rsp[0] {
+		if k != "trade_date" {
+			cols = append(cols, k)
+		}
+	}
+	sort.Strings(cols[1:])
+	sort.SliceStable(rsp, func(i, j int) bool {
+		return fmt.Sprint(rsp[i]["trade_date"]) < fmt.Sprint(rsp[j]["trade_date"])
+	})
+
+	records := make([][]string, 0, len(rsp)+1)
+	records = append(records, cols)
+	for _, rec := range rsp {
+		row := make([]string, len(cols))
+		for i, col := range cols {
+			row[i] = archiveCell(rec[col])
+		}
+		records = append(records, row)
+	}
+	return records, nil
+}
+
+func archiveCell(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case string:
+		return x
+	}
+	return fmt.Sprint(v)
+}
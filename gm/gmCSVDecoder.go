@@ -0,0 +1,341 @@
+package gm
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ulikunitz/xz"
+)
+
+// CSV 列类型
+type ColumnKind int
+
+const (
+	ColString ColumnKind = iota
+	ColInt
+	ColFloat
+	ColBool
+	ColTime
+)
+
+// CSV 列定义
+type CSVColumn struct {
+	Name string
+	Kind ColumnKind
+}
+
+// 已知列的类型, 其余列按第一行数据推断
+var knownColumnKinds = map[string]ColumnKind{
+	"timestamp": ColTime,
+	"open":      ColFloat,
+	"high":      ColFloat,
+	"low":       ColFloat,
+	"close":     ColFloat,
+	"pre_close": ColFloat,
+	"amount":    ColFloat,
+	"volume":    ColInt,
+}
+
+// 逐行解码CSV数据, 不一次性读入整个文件
+//
+//	dec, err := NewCSVxzDecoder(r)
+//	for dec.Next() {
+//		bar, err := dec.OHLCV()
+//		...
+//	}
+//	err = dec.Err()
+//
+// 时间列的格式在第一次解析时确定, 之后各行按同一格式解析。
+type CSVDecoder struct {
+	r    *csv.Reader
+	cols []CSVColumn
+	idx  map[string]int
+	row  []string
+	line int
+	err  error
+
+	inferred bool
+	layouts  map[int]timeLayout // 时间列序号 -> 已确定的格式
+}
+
+// 北京时区
+var cstZone = time.FixedZone("CST", 8*3600)
+
+// 时间格式及时区
+type timeLayout struct {
+	layout string
+	loc    *time.Location
+}
+
+// 新建CSV解码器并读取表头, 空文件返回 ErrNoData
+func NewCSVDecoder(r io.Reader) (*CSVDecoder, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: CSV文件为空", ErrNoData)
+	}
+	if err != nil {
+		return nil, decodeError("解析CSV表头失败", err)
+	}
+	d := &CSVDecoder{
+		r:       cr,
+		cols:    make([]CSVColumn, len(header)),
+		idx:     make(map[string]int, len(header)),
+		layouts: map[int]timeLayout{},
+		line:    1,
+	}
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		d.cols[i] = CSVColumn{Name: name, Kind: knownColumnKinds[name]}
+		d.idx[name] = i
+	}
+	return d, nil
+}
+
+// 新建 csv.xz 解码器, 边解压边解析
+func NewCSVxzDecoder(r io.Reader) (*CSVDecoder, error) {
+	xr, err := xz.NewReader(r)
+	if err != nil {
+		return nil, decodeError("创建XZ解压器失败", err)
+	}
+	return NewCSVDecoder(xr)
+}
+
+// 列定义(首行数据读取后, 未知列的类型已推断)
+func (d *CSVDecoder) Columns() []CSVColumn {
+	return d.cols
+}
+
+// 读取下一行, 结束或出错时返回 false
+func (d *CSVDecoder) Next() bool {
+	if d.err != nil {
+		return false
+	}
+	row, err := d.r.Read()
+	if err == io.EOF {
+		return false
+	}
+	if err != nil {
+		d.err = decodeError(fmt.Sprintf("解析CSV第%d行失败", d.line+1), err)
+		return false
+	}
+	d.line++
+	d.row = row
+	if !d.inferred {
+		d.inferKinds()
+	}
+	return true
+}
+
+// 读取过程中的错误
+func (d *CSVDecoder) Err() error {
+	return d.err
+}
+
+// 当前行的原始值(下一次 Next 后失效)
+func (d *CSVDecoder) Raw() []string {
+	return d.row
+}
+
+// 按第一行数据推断未知列的类型
+func (d *CSVDecoder) inferKinds() {
+	d.inferred = true
+	for i := range d.cols {
+		if _, ok := knownColumnKinds[d.cols[i].Name]; ok || i >= len(d.row) {
+			continue
+		}
+		switch v := parseValue(d.row[i]).(type) {
+		case int64:
+			d.cols[i].Kind = ColInt
+		case float64:
+			d.cols[i].Kind = ColFloat
+		case bool:
+			d.cols[i].Kind = ColBool
+		case string:
+			if _, ok := detectTimeLayout(v); ok && strings.Contains(v, "-") {
+				d.cols[i].Kind = ColTime
+			}
+		}
+	}
+}
+
+// 解析第i列的时间
+func (d *CSVDecoder) parseTime(i int) (time.Time, error) {
+	s := strings.TrimSpace(d.row[i])
+	if tl, ok := d.layouts[i]; ok {
+		if t, err := time.ParseInLocation(tl.layout, s, tl.loc); err == nil {
+			return t, nil
+		}
+	}
+	tl, ok := detectTimeLayout(s)
+	if !ok {
+		// 数字时间戳等其它格式
+		return ParseTimestamp(s)
+	}
+	d.layouts[i] = tl
+	return time.ParseInLocation(tl.layout, s, tl.loc)
+}
+
+// 时间列的格式(与 ParseTimestamp 一致: 不含时区的时间按北京时间解析)
+func detectTimeLayout(s string) (timeLayout, bool) {
+	formats := []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+		"01/02/2006 15:04:05",
+		"01/02/2006",
+	}
+	loc := time.UTC
+	if len(s) <= len("2006-01-02 15:04:05") {
+		loc = cstZone
+	}
+	for _, f := range formats {
+		if _, err := time.ParseInLocation(f, s, loc); err == nil {
+			return timeLayout{layout: f, loc: loc}, true
+		}
+	}
+	return timeLayout{}, false
+}
+
+// 第i列的值(时间列除外), 与列类型不符时按 parseValue 推断
+func (d *CSVDecoder) value(i int) any {
+	s := d.row[i]
+	switch d.cols[i].Kind {
+	case ColInt:
+		if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return v
+		}
+	case ColFloat:
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v
+		}
+	}
+	return parseValue(s)
+}
+
+// 当前行的 timestamp 列
+func (d *CSVDecoder) Time() (time.Time, error) {
+	i, ok := d.idx["timestamp"]
+	if !ok || i >= len(d.row) {
+		return time.Time{}, decodeError("缺少 timestamp 列", nil)
+	}
+	t, err := d.parseTime(i)
+	if err != nil {
+		return time.Time{}, &FieldError{Row: d.line - 2, Field: "timestamp", Value: d.row[i], Err: err}
+	}
+	return t, nil
+}
+
+// 当前行解码为 OHLCVData
+func (d *CSVDecoder) OHLCV() (OHLCVData, error) {
+	var bar OHLCVData
+	ts, err := d.Time()
+	if err != nil {
+		return bar, err
+	}
+	bar.Timestamp = ts
+	for _, f := range []struct {
+		name string
+		dst  *float64
+	}{{"open", &bar.Open}, {"high", &bar.High}, {"low", &bar.Low}, {"close", &bar.Close}} {
+		i, ok := d.idx[f.name]
+		if !ok || i >= len(d.row) {
+			return bar, &FieldError{Row: d.line - 2, Field: f.name, Err: errFieldMissing}
+		}
+		if *f.dst, err = strconv.ParseFloat(d.row[i], 64); err != nil {
+			return bar, &FieldError{Row: d.line - 2, Field: f.name, Value: d.row[i], Err: err}
+		}
+	}
+	if i, ok := d.idx["volume"]; ok && i < len(d.row) {
+		v, err := strconv.ParseFloat(d.row[i], 64)
+		if err != nil {
+			return bar, &FieldError{Row: d.line - 2, Field: "volume", Value: d.row[i], Err: err}
+		}
+		bar.Volume = int64(v)
+	}
+	if i, ok := d.idx["amount"]; ok && i < len(d.row) && d.row[i] != "" {
+		if bar.Amount, err = strconv.ParseFloat(d.row[i], 64); err != nil {
+			return bar, &FieldError{Row: d.line - 2, Field: "amount", Value: d.row[i], Err: err}
+		}
+	}
+	return bar, nil
+}
+
+// 当前行解码为 record
+//
+// istimestamp 为 true 时 tskey 列(及 timestamp 列)转换为毫秒时间戳, 无法解析的时间字段被省略;
+// 否则保留原始字符串。
+func (d *CSVDecoder) Record(istimestamp bool, tskey string) map[string]any {
+	rec := make(map[string]any, len(d.cols))
+	for i, col := range d.cols {
+		if i >= len(d.row) {
+			break
+		}
+		if col.Name == tskey || col.Name == "timestamp" {
+			if !istimestamp {
+				rec[col.Name] = parseValue(d.row[i])
+				continue
+			}
+			if t, err := d.parseTime(i); err == nil {
+				rec[col.Name] = t.UnixMilli()
+			}
+			continue
+		}
+		if col.Kind == ColTime {
+			rec[col.Name] = d.row[i]
+			continue
+		}
+		rec[col.Name] = d.value(i)
+	}
+	return rec
+}
+
+// 读取全部行为 OHLCVList
+func (d *CSVDecoder) ReadOHLCVList() (OHLCVList, error) {
+	return d.ReadOHLCVBetween("", "")
+}
+
+// 读取日期范围(含)内的行为 OHLCVList, sday/eday 为空时不限制
+func (d *CSVDecoder) ReadOHLCVBetween(sday string, eday string) (OHLCVList, error) {
+	var list OHLCVList
+	for d.Next() {
+		bar, err := d.OHLCV()
+		if err != nil {
+			return list, err
+		}
+		if sday != "" || eday != "" {
+			day := bar.Timestamp.In(cstZone).Format("2006-01-02")
+			if (sday != "" && day < sday) || (eday != "" && day > eday) {
+				continue
+			}
+		}
+		list = append(list, bar)
+	}
+	return list, d.Err()
+}
+
+// 读取全部行为 records, sday/eday 不为空时只保留该日期范围(含)内的行
+func (d *CSVDecoder) ReadRecords(istimestamp bool, tskey string, sday string, eday string) ([]map[string]any, error) {
+	var result []map[string]any
+	clip := sday != "" || eday != ""
+	for d.Next() {
+		if clip {
+			t, err := d.Time()
+			if err != nil {
+				continue // 跳过无法解析日期的行
+			}
+			day := t.In(cstZone).Format("2006-01-02")
+			if (sday != "" && day < sday) || (eday != "" && day > eday) {
+				continue
+			}
+		}
+		result = append(result, d.Record(istimestamp, tskey))
+	}
+	return result, d.Err()
+}
@@ -0,0 +1,372 @@
+package gm
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 永不过期(已收盘的历史数据)
+const NoExpire time.Duration = -1
+
+// 各类数据的缓存时间, 0 表示不缓存
+type CachePolicy struct {
+	History  time.Duration // 已收盘交易日的K线/CSV文件
+	Today    time.Duration // 包含当天的K线
+	Snapshot time.Duration // 行情快照
+	Calendar time.Duration // 交易日历
+	CSV      time.Duration // 当月/当年仍在更新的CSV文件
+	Default  time.Duration // 其它数据(财务、板块等)
+}
+
+// 默认缓存策略
+var DefaultCachePolicy = CachePolicy{
+	History:  NoExpire,
+	Today:    10 * time.Second,
+	Snapshot: 3 * time.Second,
+	Calendar: 24 * time.Hour,
+	CSV:      time.Hour,
+	Default:  10 * time.Minute,
+}
+
+// 缓存统计
+type CacheStats struct {
+	Hits      int64  `json:"hits"`
+	DiskHits  int64  `json:"disk_hits"`
+	Misses    int64  `json:"misses"`
+	Evictions int64  `json:"evictions"`
+	Entries   int    `json:"entries"`
+	Bytes     int64  `json:"bytes"`
+	MaxBytes  int64  `json:"max_bytes"`
+	Dir       string `json:"dir"`
+}
+
+type cacheEntry struct {
+	key     string
+	data    []byte
+	expires time.Time // 零值表示永不过期
+}
+
+// 两级缓存: 内存LRU(按字节数限制) + 可选的磁盘存储
+//
+// 磁盘只保存缓存时间不少于1分钟的数据, 进程重启后仍可使用。
+type Cache struct {
+	Policy   CachePolicy
+	MaxBytes int64  // 内存缓存上限(字节)
+	Dir      string // 磁盘缓存目录, 为空时只使用内存
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+	bytes int64
+	stats CacheStats
+	now   func() time.Time
+}
+
+// 新建缓存, maxBytes<=0 时取 256MB
+func NewCache(maxBytes int64, dir string) *Cache {
+	if maxBytes <= 0 {
+		maxBytes = 256 << 20
+	}
+	return &Cache{
+		Policy:   DefaultCachePolicy,
+		MaxBytes: maxBytes,
+		Dir:      dir,
+		ll:       list.New(),
+		items:    map[string]*list.Element{},
+		now:      time.Now,
+	}
+}
+
+// 缓存键: url + 排序后的参数
+func cacheKey(rawurl string, params map[string]string) string {
+	if len(params) == 0 {
+		return rawurl
+	}
+	q := url.Values{}
+	for k, v := range params {
+		q.Set(k, v)
+	}
+	return rawurl + "?" + q.Encode()
+}
+
+var (
+	reCSVMonth = regexp.MustCompile(`--(\d{4})-(\d{2})-\.csv\.xz$`)
+	reCSVYear  = regexp.MustCompile(`--(\d{4})-\.csv\.xz$`)
+)
+
+// 按数据类型确定缓存时间, data 为响应数据
+func (c *Cache) ttl(rawurl string, params map[string]string, data []byte) time.Duration {
+	p := c.Policy
+	today := c.now().In(time.FixedZone("CST", 8*3600)).Format("2006-01-02")
+
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return 0
+	}
+	switch name := path.Base(u.Path); {
+	case strings.Contains(u.Path, "/download/"):
+		// 月/年文件在该月/该年结束后不再变化
+		if m := reCSVMonth.FindStringSubmatch(name); m != nil {
+			if m[1]+"-"+m[2] < today[:7] {
+				return p.History
+			}
+			return p.CSV
+		}
+		if m := reCSVYear.FindStringSubmatch(name); m != nil && m[1] < today[:4] {
+			return p.History
+		}
+		return p.CSV
+	case name == "get_his" || name == "get_his2" || name == "get_his_symbol" || name == "get_his_n":
+		end := params["edate"]
+		if end == "" {
+			end = params["etime"]
+		}
+		// 没有数据的响应可能是上游尚未更新, 不作为永久数据
+		if len(end) >= 10 && end[:10] < today && !emptyBody(data) {
+			return p.History
+		}
+		return p.Today
+	case name == "get_current":
+		return p.Snapshot
+	case strings.HasPrefix(name, "get_dates_"):
+		return p.Calendar
+	}
+	return p.Default
+}
+
+// 响应中没有数据: 空列表, 或 columns/data 格式中 data 为空
+func emptyBody(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	switch string(data) {
+	case "", "[]", "{}", "null":
+		return true
+	}
+	if data[0] != '{' {
+		return false
+	}
+	var rcd struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if json.Unmarshal(data, &rcd) != nil {
+		return false
+	}
+	switch string(bytes.TrimSpace(rcd.Data)) {
+	case "", "[]", "null":
+		return true
+	}
+	return false
+}
+
+// 读取缓存: 先查内存, 再查磁盘(读磁盘时不持有锁)
+func (c *Cache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	now := c.now()
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*cacheEntry)
+		if e.expires.IsZero() || now.Before(e.expires) {
+			c.ll.MoveToFront(el)
+			c.stats.Hits++
+			c.mu.Unlock()
+			return e.data, true
+		}
+		c.removeLocked(el)
+	}
+	c.mu.Unlock()
+
+	if c.Dir != "" {
+		if e, err := c.readDisk(key); err == nil {
+			if e.expires.IsZero() || now.Before(e.expires) {
+				c.mu.Lock()
+				if _, ok := c.items[key]; !ok {
+					c.addLocked(e)
+				}
+				c.stats.DiskHits++
+				c.mu.Unlock()
+				return e.data, true
+			}
+			os.Remove(c.diskPath(key))
+		}
+	}
+	c.mu.Lock()
+	c.stats.Misses++
+	c.mu.Unlock()
+	return nil, false
+}
+
+// 写入缓存, ttl 为 0 时不缓存
+func (c *Cache) set(key string, data []byte, ttl time.Duration) {
+	if ttl == 0 {
+		return
+	}
+	e := &cacheEntry{key: key, data: data}
+	if ttl > 0 {
+		e.expires = c.now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		c.removeLocked(el)
+	}
+	c.addLocked(e)
+	c.mu.Unlock()
+
+	// 写磁盘时不持有锁(临时文件+重命名, 并发写入同一键也是安全的)
+	if c.Dir != "" && (ttl < 0 || ttl >= time.Minute) {
+		c.writeDisk(e) // 磁盘缓存失败不影响返回结果
+	}
+}
+
+func (c *Cache) addLocked(e *cacheEntry) {
+	if int64(len(e.data)) > c.MaxBytes {
+		return
+	}
+	c.items[e.key] = c.ll.PushFront(e)
+	c.bytes += int64(len(e.data))
+	for c.bytes > c.MaxBytes {
+		c.removeLocked(c.ll.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *Cache) removeLocked(el *list.Element) {
+	e := c.ll.Remove(el).(*cacheEntry)
+	delete(c.items, e.key)
+	c.bytes -= int64(len(e.data))
+}
+
+// 清除 key 中包含 match 的缓存(match 为空时全部清除), 返回清除的数量
+func (c *Cache) Purge(match string) int {
+	c.mu.Lock()
+	removed := map[string]bool{}
+	for key, el := range c.items {
+		if strings.Contains(key, match) {
+			c.removeLocked(el)
+			removed[key] = true
+		}
+	}
+	c.mu.Unlock()
+	if c.Dir == "" {
+		return len(removed)
+	}
+	files, _ := filepath.Glob(filepath.Join(c.Dir, "*.cache"))
+	for _, f := range files {
+		// 无法读取文件头的缓存文件一并删除
+		key, err := readDiskKey(f)
+		if err == nil && !strings.Contains(key, match) {
+			continue
+		}
+		if os.Remove(f) == nil && err == nil {
+			removed[key] = true
+		}
+	}
+	return len(removed)
+}
+
+// 缓存统计
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st := c.stats
+	st.Entries = len(c.items)
+	st.Bytes = c.bytes
+	st.MaxBytes = c.MaxBytes
+	st.Dir = c.Dir
+	return st
+}
+
+// 磁盘缓存文件头
+type diskHeader struct {
+	Key     string `json:"key"`
+	Expires int64  `json:"expires"` // 毫秒时间戳, 0 表示永不过期
+}
+
+func (c *Cache) diskPath(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".cache")
+}
+
+// 磁盘文件格式: 第一行为JSON文件头, 之后为数据
+func (c *Cache) writeDisk(e *cacheEntry) error {
+	hdr := diskHeader{Key: e.key}
+	if !e.expires.IsZero() {
+		hdr.Expires = e.expires.UnixMilli()
+	}
+	line, err := json.Marshal(hdr)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return err
+	}
+	fpath := c.diskPath(e.key)
+	tmp, err := os.CreateTemp(c.Dir, filepath.Base(fpath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	w := bufio.NewWriter(tmp)
+	w.Write(line)
+	w.WriteByte('\n')
+	w.Write(e.data)
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), fpath)
+}
+
+func (c *Cache) readDisk(key string) (*cacheEntry, error) {
+	raw, err := os.ReadFile(c.diskPath(key))
+	if err != nil {
+		return nil, err
+	}
+	line, data, ok := bytes.Cut(raw, []byte{'\n'})
+	if !ok {
+		return nil, fmt.Errorf("缓存文件格式错误")
+	}
+	var hdr diskHeader
+	if err := json.Unmarshal(line, &hdr); err != nil {
+		return nil, err
+	}
+	if hdr.Key != key {
+		return nil, fmt.Errorf("缓存文件键不匹配")
+	}
+	e := &cacheEntry{key: key, data: data}
+	if hdr.Expires > 0 {
+		e.expires = time.UnixMilli(hdr.Expires)
+	}
+	return e, nil
+}
+
+func readDiskKey(fpath string) (string, error) {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil {
+		return "", err
+	}
+	var hdr diskHeader
+	if err := json.Unmarshal(line, &hdr); err != nil {
+		return "", err
+	}
+	return hdr.Key, nil
+}
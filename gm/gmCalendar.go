@@ -0,0 +1,364 @@
+package gm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 交易时段
+type Session string
+
+const (
+	SessionHoliday   Session = "holiday"   // 非交易日
+	SessionPreOpen   Session = "pre_open"  // 开盘前(09:15以前)
+	SessionAuction   Session = "auction"   // 集合竞价(09:15-09:30)
+	SessionMorning   Session = "morning"   // 上午连续竞价(09:30-11:30)
+	SessionLunch     Session = "lunch"     // 午休(11:30-13:00)
+	SessionAfternoon Session = "afternoon" // 下午连续竞价(13:00-15:00)
+	SessionClosed    Session = "closed"    // 收盘后
+)
+
+// 是否为连续竞价时段
+func (s Session) IsTrading() bool {
+	return s == SessionMorning || s == SessionAfternoon
+}
+
+// 交易日历: 按年从 get_dates_by_year 获取, 保存到本地文件后离线查询
+//
+// 未能获取某年日历时(如无网络), 该年按周一至周五均为交易日处理。
+type Calendar struct {
+	Path string // 本地缓存文件(JSON), 为空时不保存
+
+	client *Client
+	loadMu sync.Mutex // 同一时间只发起一个日历请求
+	mu     sync.RWMutex
+	days   []string        // 交易日(升序)
+	isDay  map[string]bool // 交易日集合
+	years  map[int]bool    // 已加载的年份
+	failed map[int]time.Time
+}
+
+// 日历文件格式
+type calendarFile struct {
+	Years []int    `json:"years"`
+	Dates []string `json:"dates"`
+}
+
+// 获取失败后的重试间隔
+const calendarRetryInterval = 5 * time.Minute
+
+// 新建交易日历, client 为 nil 时只使用本地文件
+func NewCalendar(client *Client, fpath string) *Calendar {
+	cal := &Calendar{
+		Path:   fpath,
+		client: client,
+		isDay:  map[string]bool{},
+		years:  map[int]bool{},
+		failed: map[int]time.Time{},
+	}
+	if fpath != "" {
+		cal.loadFile()
+	}
+	return cal
+}
+
+var (
+	defaultCalendarMu sync.RWMutex
+	defaultCalendar   = NewCalendar(nil, "")
+)
+
+// 设置默认交易日历(IsAOpen, GetGM1m 等使用)
+func SetDefaultCalendar(cal *Calendar) {
+	defaultCalendarMu.Lock()
+	defer defaultCalendarMu.Unlock()
+	defaultCalendar = cal
+}
+
+// 默认交易日历
+func DefaultCalendar() *Calendar {
+	defaultCalendarMu.RLock()
+	defer defaultCalendarMu.RUnlock()
+	return defaultCalendar
+}
+
+// 读取本地日历文件, 文件不存在时忽略
+func (cal *Calendar) loadFile() error {
+	data, err := os.ReadFile(cal.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("读取交易日历文件失败: %w", err)
+	}
+	var f calendarFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("解析交易日历文件失败: %s: %w", cal.Path, err)
+	}
+	cal.mu.Lock()
+	defer cal.mu.Unlock()
+	cal.addLocked(f.Years, f.Dates)
+	return nil
+}
+
+// 保存日历到本地文件(临时文件+重命名)
+func (cal *Calendar) saveFile() error {
+	cal.mu.RLock()
+	f := calendarFile{Dates: cal.days}
+	for y := range cal.years {
+		f.Years = append(f.Years, y)
+	}
+	sort.Ints(f.Years)
+	data, err := json.Marshal(f)
+	cal.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(cal.Path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("无法创建目录: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(cal.Path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("无法创建临时文件: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入交易日历文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入交易日历文件失败: %w", err)
+	}
+	return os.Rename(tmp.Name(), cal.Path)
+}
+
+func (cal *Calendar) addLocked(years []int, dates []string) {
+	for _, y := range years {
+		cal.years[y] = true
+		delete(cal.failed, y)
+	}
+	for _, d := range dates {
+		if d != "" && !cal.isDay[d] {
+			cal.isDay[d] = true
+			cal.days = append(cal.days, d)
+		}
+	}
+	sort.Strings(cal.days)
+}
+
+// get_dates_by_year 返回的一行, 非交易日 trade_date 为空
+type calendarRow struct {
+	Date      string `json:"date"`
+	TradeDate string `json:"trade_date"`
+}
+
+// 获取 [syear, eyear] 年的交易日历并保存到本地文件
+func (cal *Calendar) Load(ctx context.Context, syear int, eyear int) error {
+	if cal.client == nil {
+		return fmt.Errorf("交易日历未设置数据源")
+	}
+	rawData, err := cal.client.GetCalendar(ctx, strconv.Itoa(syear), strconv.Itoa(eyear), "")
+	if err != nil {
+		cal.markFailed(syear, eyear)
+		return fmt.Errorf("获取交易日历失败: %w", err)
+	}
+	var rcd RawColData
+	if err := rcd.FromByte(rawData); err != nil {
+		cal.markFailed(syear, eyear)
+		return err
+	}
+	rows, err := DecodeRawCol[calendarRow](&rcd)
+	if err != nil {
+		cal.markFailed(syear, eyear)
+		return fmt.Errorf("解析交易日历失败: %w", err)
+	}
+
+	// 只记录有交易日的年份(尚未公布的年份返回为空)
+	var dates []string
+	got := map[int]bool{}
+	for _, row := range rows {
+		if len(row.TradeDate) < 10 {
+			continue
+		}
+		day := row.TradeDate[:10]
+		dates = append(dates, day)
+		if y, err := strconv.Atoi(day[:4]); err == nil && y >= syear && y <= eyear {
+			got[y] = true
+		}
+	}
+	var years []int
+	for y := range got {
+		years = append(years, y)
+	}
+	cal.mu.Lock()
+	cal.addLocked(years, dates)
+	cal.mu.Unlock()
+	if len(years) < eyear-syear+1 {
+		cal.markFailed(syear, eyear)
+	}
+
+	if cal.Path != "" && len(years) > 0 {
+		if err := cal.saveFile(); err != nil {
+			return fmt.Errorf("保存交易日历失败: %w", err)
+		}
+	}
+	return nil
+}
+
+func (cal *Calendar) markFailed(syear int, eyear int) {
+	cal.mu.Lock()
+	defer cal.mu.Unlock()
+	for y := syear; y <= eyear; y++ {
+		if !cal.years[y] {
+			cal.failed[y] = time.Now()
+		}
+	}
+}
+
+// 确保某年的日历已加载, 返回是否可用
+func (cal *Calendar) ensure(year int) bool {
+	cal.mu.RLock()
+	ok := cal.years[year]
+	failedAt, failed := cal.failed[year]
+	cal.mu.RUnlock()
+	if ok {
+		return true
+	}
+	if cal.client == nil || (failed && time.Since(failedAt) < calendarRetryInterval) {
+		return false
+	}
+
+	cal.loadMu.Lock()
+	defer cal.loadMu.Unlock()
+	cal.mu.RLock()
+	ok = cal.years[year]
+	failedAt, failed = cal.failed[year]
+	cal.mu.RUnlock()
+	if !ok && !(failed && time.Since(failedAt) < calendarRetryInterval) {
+		cal.Load(context.Background(), year, year)
+	}
+
+	cal.mu.RLock()
+	defer cal.mu.RUnlock()
+	return cal.years[year]
+}
+
+func calendarYear(day string) int {
+	y, _ := strconv.Atoi(day[:min(4, len(day))])
+	return y
+}
+
+// 是否为交易日, day 格式为 2006-01-02
+func (cal *Calendar) IsTradingDay(day string) bool {
+	if !cal.ensure(calendarYear(day)) {
+		t, err := time.Parse("2006-01-02", day)
+		return err == nil && t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+	}
+	cal.mu.RLock()
+	defer cal.mu.RUnlock()
+	return cal.isDay[day]
+}
+
+// 按日历逐日查找(某年日历不可用时按工作日处理)
+func (cal *Calendar) step(day string, n int, dir int) (string, error) {
+	if n <= 0 {
+		return "", fmt.Errorf("n 须为正整数: %d", n)
+	}
+	t, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return "", fmt.Errorf("日期格式错误: %s", day)
+	}
+	for i := 0; n > 0; i++ {
+		if i > 366*(n+1) {
+			return "", fmt.Errorf("未找到交易日: %s", day)
+		}
+		t = t.AddDate(0, 0, dir)
+		if cal.IsTradingDay(t.Format("2006-01-02")) {
+			n--
+		}
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+// 前第n个交易日(不含当日)
+func (cal *Calendar) PrevTradingDay(day string, n int) (string, error) {
+	return cal.step(day, n, -1)
+}
+
+// 后第n个交易日(不含当日)
+func (cal *Calendar) NextTradingDay(day string, n int) (string, error) {
+	return cal.step(day, n, 1)
+}
+
+// [sdate, edate] 区间内的交易日列表
+func (cal *Calendar) TradingDaysBetween(sdate string, edate string) ([]string, error) {
+	s, err := time.Parse("2006-01-02", sdate)
+	if err != nil {
+		return nil, fmt.Errorf("日期格式错误: %s", sdate)
+	}
+	e, err := time.Parse("2006-01-02", edate)
+	if err != nil {
+		return nil, fmt.Errorf("日期格式错误: %s", edate)
+	}
+	var dates []string
+	for t := s; !t.After(e); t = t.AddDate(0, 0, 1) {
+		if day := t.Format("2006-01-02"); cal.IsTradingDay(day) {
+			dates = append(dates, day)
+		}
+	}
+	return dates, nil
+}
+
+// 是否已加载 [sdate, edate] 区间所有年份的日历(不发起网络请求)
+func (cal *Calendar) Covers(sdate string, edate string) bool {
+	cal.mu.RLock()
+	defer cal.mu.RUnlock()
+	for y := calendarYear(sdate); y <= calendarYear(edate); y++ {
+		if !cal.years[y] {
+			return false
+		}
+	}
+	return true
+}
+
+// 指定时刻所处的交易时段
+func (cal *Calendar) SessionAt(t time.Time) Session {
+	t = t.In(time.FixedZone("CST", 8*3600))
+	if !cal.IsTradingDay(t.Format("2006-01-02")) {
+		return SessionHoliday
+	}
+	hm := t.Hour()*100 + t.Minute()
+	switch {
+	case hm < 915:
+		return SessionPreOpen
+	case hm < 930:
+		return SessionAuction
+	case hm <= 1130:
+		return SessionMorning
+	case hm < 1300:
+		return SessionLunch
+	case hm <= 1500:
+		return SessionAfternoon
+	}
+	return SessionClosed
+}
+
+// 交易日列表: 默认交易日历可用时离线计算, 否则请求 gm-api
+func (c *Client) tradingDates(ctx context.Context, sdate string, edate string) ([]string, error) {
+	cal := DefaultCalendar()
+	for y := calendarYear(sdate); y <= calendarYear(edate); y++ {
+		cal.ensure(y)
+	}
+	if cal.Covers(sdate, edate) {
+		return cal.TradingDaysBetween(sdate, edate)
+	}
+	return c.GetDatesList(ctx, sdate, edate)
+}
@@ -0,0 +1,457 @@
+package gm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ulikunitz/xz"
+)
+
+// 日志接口，兼容标准库 *log.Logger
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(format string, v ...any) {}
+
+// 请求重试策略
+type RetryPolicy struct {
+	MaxRetries int           // 最大重试次数(不含首次请求)
+	Backoff    time.Duration // 首次重试前的等待时间，之后按2倍递增
+	MaxBackoff time.Duration // 单次等待时间上限
+}
+
+// 默认重试策略: 最多重试2次，等待 0.5s, 1s
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 2,
+	Backoff:    500 * time.Millisecond,
+	MaxBackoff: 5 * time.Second,
+}
+
+// 第n次重试前的等待时间(n从1开始)
+func (p RetryPolicy) wait(n int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < n; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return d
+}
+
+// gm-api / gm-csv 客户端
+//
+// 所有 Get* 方法的第一个参数为 context，
+// 调用方取消(如 gin 请求断开)后上游请求随之中止。
+type Client struct {
+	BaseURL    string        // gm-api 服务地址
+	CSVURL     string        // gm-csv 服务地址
+	HTTPClient *http.Client  // 可注入，测试时可指向 httptest 服务
+	Timeout    time.Duration // 单次请求超时时间, 0 表示不限制
+	Retry      RetryPolicy   // 重试策略
+	Logger     Logger        // 日志输出, 默认不输出
+
+	Concurrency int  // 多日期/多文件并发请求数, <=0 时取 DefaultConcurrency
+	FailFast    bool // 并发请求出错时立即返回
+
+	Cache *Cache // 响应缓存, 为 nil 时不缓存
+
+	MergePrefer string // CSV与API分时数据冲突时优先的来源(csv, api), 默认 csv
+}
+
+type ClientOption func(*Client)
+
+// 设置 gm-csv 服务地址
+func WithCSVURL(gmcsv string) ClientOption {
+	return func(c *Client) { c.CSVURL = gmcsv }
+}
+
+// 设置 http.Client
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) { c.HTTPClient = hc }
+}
+
+// 设置 http.RoundTripper
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) { c.HTTPClient = &http.Client{Transport: rt} }
+}
+
+// 设置单次请求超时时间
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) { c.Timeout = timeout }
+}
+
+// 设置重试策略
+func WithRetry(policy RetryPolicy) ClientOption {
+	return func(c *Client) { c.Retry = policy }
+}
+
+// 设置日志输出
+func WithLogger(logger Logger) ClientOption {
+	return func(c *Client) { c.Logger = logger }
+}
+
+// 设置并发请求数
+func WithConcurrency(n int) ClientOption {
+	return func(c *Client) { c.Concurrency = n }
+}
+
+// 设置并发请求出错时是否立即返回
+func WithFailFast(failFast bool) ClientOption {
+	return func(c *Client) { c.FailFast = failFast }
+}
+
+// 设置响应缓存
+func WithCache(cache *Cache) ClientOption {
+	return func(c *Client) { c.Cache = cache }
+}
+
+func (c *Client) poolOptions() PoolOptions {
+	return PoolOptions{Concurrency: c.Concurrency, FailFast: c.FailFast}
+}
+
+func NewClient(gmapi string, opts ...ClientOption) *Client {
+	c := &Client{
+		BaseURL:    gmapi,
+		HTTPClient: &http.Client{},
+		Timeout:    30 * time.Second,
+		Retry:      DefaultRetryPolicy,
+		Logger:     nopLogger{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.HTTPClient == nil {
+		c.HTTPClient = &http.Client{}
+	}
+	if c.Logger == nil {
+		c.Logger = nopLogger{}
+	}
+	return c
+}
+
+// 包级函数使用的客户端: timeoutSeconds<=0 时不限制超时
+func newFuncClient(gmapi string, gmcsv string, timeoutSeconds int) *Client {
+	return NewClient(gmapi,
+		WithCSVURL(gmcsv),
+		WithTimeout(time.Duration(timeoutSeconds)*time.Second),
+	)
+}
+
+// 获取URL数据(带缓存和重试)
+func (c *Client) fetchURLData(ctx context.Context, url string, params map[string]string) ([]byte, error) {
+	if c.Cache == nil {
+		return c.fetchWithRetry(ctx, url, params)
+	}
+	key := cacheKey(url, params)
+	if data, ok := c.Cache.get(key); ok {
+		return data, nil
+	}
+	data, err := c.fetchWithRetry(ctx, url, params)
+	if err != nil {
+		return nil, err
+	}
+	c.Cache.set(key, data, c.Cache.ttl(url, params, data))
+	return data, nil
+}
+
+// 获取URL数据(带重试)
+func (c *Client) fetchWithRetry(ctx context.Context, url string, params map[string]string) ([]byte, error) {
+	var body []byte
+	err := c.withRetry(ctx, url, func() (retry bool, err error) {
+		body, retry, err = c.fetchOnce(ctx, url, params)
+		return retry, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// 打开URL响应体(带重试), 连接及响应状态出错时重试, 读取过程中出错不再重试
+func (c *Client) openWithRetry(ctx context.Context, url string) (io.ReadCloser, error) {
+	var body io.ReadCloser
+	err := c.withRetry(ctx, url, func() (retry bool, err error) {
+		body, retry, err = c.openOnce(ctx, url, nil)
+		return retry, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// 按重试策略执行 attempt, 直到成功或错误不值得重试
+func (c *Client) withRetry(ctx context.Context, url string, attempt func() (retry bool, err error)) error {
+	var lastErr error
+	for i := 0; i <= c.Retry.MaxRetries; i++ {
+		if i > 0 {
+			wait := c.Retry.wait(i)
+			c.Logger.Printf("请求失败，%v 后重试 (%d/%d): %s: %v", wait, i, c.Retry.MaxRetries, url, lastErr)
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		retry, err := attempt()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+// 发送单次请求，返回值 retry 表示该错误是否值得重试
+func (c *Client) fetchOnce(ctx context.Context, url string, params map[string]string) (body []byte, retry bool, err error) {
+	rc, retry, err := c.openOnce(ctx, url, params)
+	if err != nil {
+		return nil, retry, err
+	}
+	defer rc.Close()
+
+	body, err = io.ReadAll(rc)
+	if err != nil {
+		return nil, ctx.Err() == nil, err
+	}
+	return body, false, nil
+}
+
+// 发送单次请求并返回响应体(调用方负责关闭)，返回值 retry 表示该错误是否值得重试
+//
+// Timeout 包括读取响应体的时间; 读取出错时错误匹配 ErrUpstreamUnavailable。
+func (c *Client) openOnce(ctx context.Context, url string, params map[string]string) (body io.ReadCloser, retry bool, err error) {
+	var reqCtx context.Context
+	var cancel context.CancelFunc
+	if c.Timeout > 0 {
+		reqCtx, cancel = context.WithTimeout(ctx, c.Timeout)
+	} else {
+		reqCtx, cancel = context.WithCancel(ctx)
+	}
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, url, nil)
+	if err != nil {
+		cancel()
+		return nil, false, err
+	}
+	if len(params) > 0 {
+		q := req.URL.Query()
+		for k, v := range params {
+			q.Add(k, v)
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		cancel()
+		// 调用方已取消时不再重试
+		return nil, ctx.Err() == nil, fmt.Errorf("%w: %w", ErrUpstreamUnavailable, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return nil, retry, &ErrUpstreamStatus{Code: resp.StatusCode, Status: resp.Status, URL: url}
+	}
+	return &responseBody{ReadCloser: resp.Body, cancel: cancel}, false, nil
+}
+
+// 响应体: 关闭时释放请求的 context, 读取错误匹配 ErrUpstreamUnavailable
+type responseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *responseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("%w: 读取响应体失败: %w", ErrUpstreamUnavailable, err)
+	}
+	return n, err
+}
+
+func (b *responseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// 从指定URL下载xz压缩数据并返回解压后的内容(缓存解压后的内容)
+func (c *Client) downloadAndReadData(ctx context.Context, url string) ([]byte, error) {
+	if c.Cache != nil {
+		if data, ok := c.Cache.get("unxz:" + url); ok {
+			return data, nil
+		}
+	}
+	raw, err := c.fetchWithRetry(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// 使用github.com/ulikunitz/xz库创建XZ解压器
+	reader, err := xz.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, decodeError("创建XZ解压器失败", err)
+	}
+
+	// 读取解压后的数据
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, decodeError("读取数据失败", err)
+	}
+
+	if c.Cache != nil {
+		c.Cache.set("unxz:"+url, data, c.Cache.ttl(url, nil, nil))
+	}
+	return data, nil
+}
+
+// gm-csv 地址为本地目录时返回目录路径
+//
+// 支持 file:///data/gmcsv 及 /data/gmcsv, ./gmcsv 等路径, 以及已存在的相对目录。
+// 目录结构与 gm-csv 的 /download/ 之下相同(kbars-month/..., kbars-year/..., kbars-vv/...)。
+func LocalCSVDir(gmcsv string) (string, bool) {
+	if gmcsv == "" {
+		return "", false
+	}
+	if rest, ok := strings.CutPrefix(gmcsv, "file://"); ok {
+		if u, err := url.Parse(gmcsv); err == nil && u.Path != "" {
+			rest = u.Path
+		}
+		return filepath.FromSlash(rest), true
+	}
+	if strings.Contains(gmcsv, "://") {
+		return "", false
+	}
+	if filepath.IsAbs(gmcsv) || strings.HasPrefix(gmcsv, "/") ||
+		strings.HasPrefix(gmcsv, "./") || strings.HasPrefix(gmcsv, "../") {
+		return gmcsv, true
+	}
+	if fi, err := os.Stat(gmcsv); err == nil && fi.IsDir() {
+		return gmcsv, true
+	}
+	return "", false
+}
+
+// 读取 csv.xz 文件(未解压), fpath 为 /download/ 之后的相对路径
+//
+// 本地目录中文件不存在时返回 ErrNotFound; gm-csv 返回404时错误同样匹配 ErrNotFound。
+func (c *Client) csvFile(ctx context.Context, fpath string) ([]byte, error) {
+	dir, ok := LocalCSVDir(c.CSVURL)
+	if !ok {
+		return c.fetchURLData(ctx, fmt.Sprintf("%s/download/%s", c.CSVURL, fpath), nil)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	full := filepath.Join(dir, filepath.FromSlash(fpath))
+	data, err := os.ReadFile(full)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, full)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	return data, nil
+}
+
+// 打开 csv.xz 文件(未解压), 错误与 csvFile 相同
+//
+// 配置了缓存时经 csvFile 缓存压缩数据; 否则直接读取本地文件或 gm-csv 的响应体, 不整体读入内存。
+func (c *Client) csvOpen(ctx context.Context, fpath string) (io.ReadCloser, error) {
+	dir, ok := LocalCSVDir(c.CSVURL)
+	if !ok && c.Cache == nil {
+		return c.openWithRetry(ctx, fmt.Sprintf("%s/download/%s", c.CSVURL, fpath))
+	}
+	if !ok {
+		raw, err := c.csvFile(ctx, fpath)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(raw)), nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	full := filepath.Join(dir, filepath.FromSlash(fpath))
+	f, err := os.Open(full)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, full)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	return f, nil
+}
+
+// 读取并解压 csv.xz 文件
+func (c *Client) csvData(ctx context.Context, fpath string) ([]byte, error) {
+	raw, err := c.csvFile(ctx, fpath)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := xz.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, decodeError("创建XZ解压器失败", err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, decodeError("读取数据失败", err)
+	}
+	return data, nil
+}
+
+// 读取 csv.xz 文件并逐行解码为K线(读取、解压与解析同时进行)
+//
+// sday/eday 不为空时只保留该日期范围内的K线。
+func (c *Client) csvBars(ctx context.Context, fpath string, sday string, eday string) (OHLCVList, error) {
+	rc, err := c.csvOpen(ctx, fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	dec, err := NewCSVxzDecoder(rc)
+	if err != nil {
+		return nil, err
+	}
+	return dec.ReadOHLCVBetween(sday, eday)
+}
+
+// 读取 csv.xz 文件并逐行解码为 records(用于 vv/pe 等非K线文件)
+func (c *Client) csvRecords(ctx context.Context, fpath string, istimestamp bool, tskey string) ([]map[string]any, error) {
+	rc, err := c.csvOpen(ctx, fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	dec, err := NewCSVxzDecoder(rc)
+	if err != nil {
+		return nil, err
+	}
+	return dec.ReadRecords(istimestamp, tskey, "", "")
+}
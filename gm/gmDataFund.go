@@ -0,0 +1,159 @@
+package gm
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// 个股估值指标每日数据(get_daily_valuation)
+type DailyValuation struct {
+	Symbol     string    `json:"symbol"`
+	TradeDate  time.Time `json:"trade_date,required"`
+	PeTTM      float64   `json:"pe_ttm"`       // 市盈率(TTM)
+	PeLYR      float64   `json:"pe_lyr"`       // 市盈率(最新年报)
+	PeMRQ      float64   `json:"pe_mrq"`       // 市盈率(最新报告期)
+	PbLYR      float64   `json:"pb_lyr"`       // 市净率(最新年报)
+	PbMRQ      float64   `json:"pb_mrq"`       // 市净率(最新报告期)
+	PsTTM      float64   `json:"ps_ttm"`       // 市销率(TTM)
+	PsLYR      float64   `json:"ps_lyr"`       // 市销率(最新年报)
+	PcfTTMOper float64   `json:"pcf_ttm_oper"` // 市现率(经营现金流,TTM)
+	DyTTM      float64   `json:"dy_ttm"`       // 股息率(TTM)
+	DyLFY      float64   `json:"dy_lfy"`       // 股息率(最新年报)
+}
+
+// 个股分红送股数据(get_dividend)
+type Dividend struct {
+	Symbol        string    `json:"symbol"`
+	SchemeType    string    `json:"scheme_type"`     // 方案类型
+	PubDate       time.Time `json:"pub_date"`        // 公告日
+	EquityRegDate time.Time `json:"equity_reg_date"` // 股权登记日
+	ExDate        time.Time `json:"ex_date,required"`
+	CashPayDate   time.Time `json:"cash_pay_date"`   // 派息日
+	ShareAcctDate time.Time `json:"share_acct_date"` // 红股到账日
+	ShareLstDate  time.Time `json:"share_lst_date"`  // 红股上市日
+	CashDiv       float64   `json:"cash_div"`        // 每股派息(元)
+	BonusRatio    float64   `json:"bonus_ratio"`     // 每股送股
+	ConvertRatio  float64   `json:"convert_ratio"`   // 每股转增
+}
+
+// 复权因子(get_adj_factor, get_adj_factor_fnd)
+type AdjFactor struct {
+	TradeDate       time.Time `json:"trade_date,required"`
+	AdjFactorBwd    float64   `json:"adj_factor_bwd"`     // 当日后复权因子
+	AdjFactorBwdAcc float64   `json:"adj_factor_bwd_acc"` // 累计后复权因子
+	AdjFactorFwd    float64   `json:"adj_factor_fwd"`     // 当日前复权因子
+	AdjFactorFwdAcc float64   `json:"adj_factor_fwd_acc"` // 累计前复权因子
+}
+
+// 标的基本信息(get_infos, get_symbols)
+type SymbolInfo struct {
+	Symbol       string    `json:"symbol,required"`
+	SecID        string    `json:"sec_id"`
+	Exchange     string    `json:"exchange"`
+	SecName      string    `json:"sec_name"`
+	SecAbbr      string    `json:"sec_abbr"`
+	SecType1     int64     `json:"sec_type1"` // 1010-股票, 1020-基金, 1060-指数
+	SecType2     int64     `json:"sec_type2"`
+	Board        int64     `json:"board"`
+	PriceTick    float64   `json:"price_tick"`
+	ListedDate   time.Time `json:"listed_date"`
+	DelistedDate time.Time `json:"delisted_date"`
+	TradeDate    time.Time `json:"trade_date"`
+	PreClose     float64   `json:"pre_close"`
+	UpperLimit   float64   `json:"upper_limit"`
+	LowerLimit   float64   `json:"lower_limit"`
+	IsST         bool      `json:"is_st"`
+	IsSuspended  bool      `json:"is_suspended"`
+}
+
+// 交易时段(起止时间, 如 09:30 - 11:30)
+type SessionRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// 标的交易时间(get_trading_sessions)
+type TradingSession struct {
+	Symbol          string         `json:"symbol,required"`
+	Exchange        string         `json:"exchange"`
+	TimeTrading     []SessionRange `json:"time_trading"`
+	TimeCallAuction []SessionRange `json:"time_callauction"`
+}
+
+// 查询个股估值指标每日数据(结构体)
+func (c *Client) GetDailyValuationList(ctx context.Context,
+	symbol string, sdate string, edate string, fields string) ([]DailyValuation, error) {
+	records, err := c.GetDailyValuation(ctx, symbol, sdate, edate, fields)
+	if err != nil {
+		return nil, err
+	}
+	return decodeList[DailyValuation](records, "get_daily_valuation")
+}
+
+// 查询个股分红送股数据(结构体)
+func (c *Client) GetDividendList(ctx context.Context,
+	symbol string, sdate string, edate string) ([]Dividend, error) {
+	records, err := c.GetDividend(ctx, symbol, sdate, edate)
+	if err != nil {
+		return nil, err
+	}
+	return decodeList[Dividend](records, "get_dividend")
+}
+
+// 查询个股复权因子(结构体)
+func (c *Client) GetAdjFactorList(ctx context.Context,
+	symbol string, sdate string, edate string, bdate string) ([]AdjFactor, error) {
+	records, err := c.GetAdjFactor(ctx, symbol, sdate, edate, bdate)
+	if err != nil {
+		return nil, err
+	}
+	return decodeList[AdjFactor](records, "get_adj_factor")
+}
+
+// 查询基金复权因子(结构体)
+func (c *Client) GetFndAdjFactorList(ctx context.Context,
+	fund string, sdate string, edate string, bdate string) ([]AdjFactor, error) {
+	records, err := c.GetFndAdjFactor(ctx, fund, sdate, edate, bdate)
+	if err != nil {
+		return nil, err
+	}
+	return decodeList[AdjFactor](records, "get_adj_factor_fnd")
+}
+
+// 请求标的基本信息(结构体)
+func (c *Client) GetMarketInfoList(ctx context.Context,
+	symbols string, sec string, exchange string) ([]SymbolInfo, error) {
+	records, err := c.GetMarketInfo(ctx, symbols, sec, exchange)
+	if err != nil {
+		return nil, err
+	}
+	return decodeList[SymbolInfo](records, "get_infos")
+}
+
+// 请求标的某交易日的交易信息(结构体)
+func (c *Client) GetSymbolsInfoList(ctx context.Context,
+	symbols string, sec string, exchange string, trade_date string) ([]SymbolInfo, error) {
+	records, err := c.GetSymbolsInfo(ctx, symbols, sec, exchange, trade_date)
+	if err != nil {
+		return nil, err
+	}
+	return decodeList[SymbolInfo](records, "get_symbols")
+}
+
+// 查询标的交易时间(结构体)
+func (c *Client) GetTradingSessionList(ctx context.Context, symbols string) ([]TradingSession, error) {
+	records, err := c.GetTradingSessions(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+	return decodeList[TradingSession](records, "get_trading_sessions")
+}
+
+func decodeList[T any](records []map[string]any, api string) ([]T, error) {
+	res, err := DecodeRecords[T](records)
+	if err != nil {
+		return res, fmt.Errorf("解码数据失败(%s()): %w", api, err)
+	}
+	return res, nil
+}
@@ -17,6 +17,7 @@ type OHLCVData struct {
 	Low       float64   `json:"low"`
 	Close     float64   `json:"close"`
 	Volume    int64     `json:"volume"`
+	Amount    float64   `json:"amount,omitempty"` // 成交额, 数据中没有该字段时为0
 }
 type OHLCVList []OHLCVData
 
@@ -24,19 +25,50 @@ func (k *OHLCVData) ReadJson(data []byte) {
 	json.Unmarshal(data, k)
 }
 
-func (k *OHLCVData) ReadMap(data map[string]any) {
-	ts, _ := ParseTimestamp(data["timestamp"])
+func (k *OHLCVData) ReadMap(data map[string]any) error {
+	ts, err := ParseTimestamp(data["timestamp"])
+	if err != nil {
+		return &FieldError{Field: "timestamp", Value: data["timestamp"], Err: err}
+	}
 	// chinaLocation, _ := time.LoadLocation("Asia/Shanghai")
 	// k.Timestamp = ts.UnixMilli() - 8*3600*1000 // 北京时间转UTC时间
 	tz := time.FixedZone("CST", 8*3600) // 北京时区
 	k.Timestamp = ts.In(tz)
 	// k.Timestamp = ts.Add(-8 * time.Hour).In(tz)
-	k.Open = data["open"].(float64)
-	k.High = data["high"].(float64)
-	k.Low = data["low"].(float64)
-	k.Close = data["close"].(float64)
-	k.Volume = int64(data["volume"].(float64))
-	// k.Volume = data["volume"].(int64)
+
+	prices := []struct {
+		name string
+		dst  *float64
+	}{
+		{"open", &k.Open}, {"high", &k.High}, {"low", &k.Low}, {"close", &k.Close},
+	}
+	for _, p := range prices {
+		val, ok := data[p.name]
+		if isBlank(val, ok) {
+			return &FieldError{Field: p.name, Err: errFieldMissing}
+		}
+		v, err := anyToFloat(val)
+		if err != nil {
+			return &FieldError{Field: p.name, Value: val, Err: err}
+		}
+		*p.dst = v
+	}
+
+	vol, ok := data["volume"]
+	if isBlank(vol, ok) {
+		return &FieldError{Field: "volume", Err: errFieldMissing}
+	}
+	v, err := anyToFloat(vol)
+	if err != nil {
+		return &FieldError{Field: "volume", Value: vol, Err: err}
+	}
+	k.Volume = int64(v)
+	if amt, ok := data["amount"]; !isBlank(amt, ok) {
+		if k.Amount, err = anyToFloat(amt); err != nil {
+			return &FieldError{Field: "amount", Value: amt, Err: err}
+		}
+	}
+	return nil
 }
 
 // 计算黄金价：(4*收盘价 + 2*开盘价 + 最高价 + 最低价) / 8
@@ -61,12 +93,23 @@ func (k *OHLCVList) Add(kbar OHLCVData) {
 }
 
 // 从map列表中读取KBar列表
-func (k *OHLCVList) FromMapList(kbList []map[string]any) {
-	for _, data := range kbList {
+// 字段缺失或类型错误的记录会被跳过，并在返回的 DecodeErrors 中列出
+func (k *OHLCVList) FromMapList(kbList []map[string]any) error {
+	var errs DecodeErrors
+	for i, data := range kbList {
 		kbar := OHLCVData{}
-		kbar.ReadMap(data)
+		if err := kbar.ReadMap(data); err != nil {
+			fe := err.(*FieldError)
+			fe.Row = i
+			errs = append(errs, fe)
+			continue
+		}
 		k.Add(kbar)
 	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
 }
 
 func (k *OHLCVList) Head(n int) {
@@ -138,8 +181,10 @@ func (k *OHLCVList) ToOHLCVData(isDaily bool) OHLCVData {
 	ll := (*k)[0].Low
 	cc := (*k)[nlen-1].Close
 	vv := int64(0)
+	amt := 0.0
 	for _, kb := range *k {
 		vv += kb.Volume
+		amt += kb.Amount
 		hh = max(hh, kb.High)
 		ll = min(ll, kb.Low)
 	}
@@ -150,6 +195,7 @@ func (k *OHLCVList) ToOHLCVData(isDaily bool) OHLCVData {
 		Low:       ll,
 		Close:     cc,
 		Volume:    vv,
+		Amount:    amt,
 	}
 }
 
@@ -267,6 +313,36 @@ func (k *OHLCVList) GetVmed(pct float64) int64 {
 	return int64(vmed)
 }
 
+// 按阈值规则计算成交量阈值
+func (k *OHLCVList) GetVmedRule(rule VmedRule) int64 {
+	if rule.Mode != "adaptive" {
+		return k.GetVmed(rule.Pct)
+	}
+
+	nlen := len(*k)
+	if nlen == 0 {
+		return 0
+	}
+	vols := make([]float64, nlen)
+	sum := 0.0
+	for i, kb := range *k {
+		vols[i] = float64(kb.Volume)
+		sum += vols[i]
+	}
+	if nlen < 30 {
+		return int64(sum / float64(nlen)) // 成交量均值
+	}
+	sort.Float64s(vols)
+	med := vols[nlen/2]
+	if nlen%2 == 0 {
+		med = (vols[nlen/2-1] + vols[nlen/2]) / 2
+	}
+	if nlen < 90 {
+		return int64(med) // 成交量中值
+	}
+	return int64(2 * med) // 成交量中值*2
+}
+
 // 提取成交量加权黄金价 PVJ: rC, rO, rH, rL 为权重
 //
 //	计算方法：(4*收盘价 + 2*开盘价 + 最高价 + 最低价) / 8
@@ -299,6 +375,11 @@ func (k *OHLCVList) ToHjjList(rC, rO, rH, rL float64) []float64 {
 
 // 提取单日特定成交量：v931, v932, v935, v940, v150
 func (k *OHLCVList) ToV123Data() V123Data {
+	return k.ToV123DataSpec(DefaultIndicatorSpec.Windows)
+}
+
+// 按成交量窗口定义提取单日成交量
+func (k *OHLCVList) ToV123DataSpec(windows []VolWindow) V123Data {
 	// var kbar KBarDataMinute
 	nlen := len(*k)
 	if nlen == 0 {
@@ -309,39 +390,23 @@ func (k *OHLCVList) ToV123Data() V123Data {
 	tz := time.FixedZone("CST", 8*3600) // 北京时区
 	tDay := time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, tz)
 
-	v931 := int64(0)
-	v932 := int64(0)
-	v935 := int64(0)
-	v940 := int64(0)
-	v150 := int64(0)
+	sums := make(map[string]int64, len(windows))
 	for _, kb := range *k {
-		tt := kb.Timestamp //.Truncate(24 * time.Hour)
-		vv := kb.Volume
-		tStr := tt.Format("15:04:05")
-		if tStr == "09:31:00" {
-			v931 = vv
-		}
-		if tStr == "09:32:00" {
-			v932 = vv
-		}
-		if tStr <= "09:35:00" {
-			v935 += vv
-		}
-		if tStr > "09:35:00" && tStr <= "09:40:00" {
-			v940 += vv
-		}
-		if tStr == "15:00:00" {
-			v150 = vv
+		tStr := kb.Timestamp.Format("15:04:05")
+		for _, w := range windows {
+			if tStr > w.Start && tStr <= w.End {
+				sums[w.Name] += kb.Volume
+			}
 		}
 	}
 
 	return V123Data{
 		TS:   tDay,
-		V931: v931,
-		V932: v932,
-		V935: v935,
-		V940: v940,
-		V150: v150,
+		V931: sums["v931"],
+		V932: sums["v932"],
+		V935: sums["v935"],
+		V940: sums["v940"],
+		V150: sums["v150"],
 	}
 }
 
@@ -380,6 +445,13 @@ func (k *OHLCVList) ToV123List() V123List {
 
 // 计算单日成本价+成交量中值
 func (k *OHLCVList) ToCbjData(pTime string) CbjData {
+	spec := DefaultIndicatorSpec
+	spec.SplitTime = pTime
+	return k.ToCbjDataSpec(spec)
+}
+
+// 按指标定义计算单日成本价+成交量中值
+func (k *OHLCVList) ToCbjDataSpec(spec IndicatorSpec) CbjData {
 
 	nlen := len(*k)
 	if nlen == 0 {
@@ -390,13 +462,10 @@ func (k *OHLCVList) ToCbjData(pTime string) CbjData {
 	tz := time.FixedZone("CST", 8*3600) // 北京时区
 	tDay := time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, tz)
 
+	w := spec.Weights
 	nup := int64(0)
 	ndown := int64(0)
-	vmed := k.GetVmed(12.5) // 30个最大K线加权价
-
-	// var kCbj OHLCVList
-	// var kCb1 OHLCVList
-	// var kCb2 OHLCVList
+	vmed := k.GetVmedRule(spec.Vmed)
 
 	sumCbj := float64(0.0)
 	sumCb1 := float64(0.0)
@@ -404,26 +473,29 @@ func (k *OHLCVList) ToCbjData(pTime string) CbjData {
 	volCbj := float64(0.0)
 	volCb1 := float64(0.0)
 	volCb2 := float64(0.0)
+	nCbj, nCb1 := 0, 0
 
 	for i, kb := range *k {
 		vv := kb.Volume
 		tStr := kb.Timestamp.Format("15:04:05") //.Truncate(24 * time.Hour)
-		hjj := kb.GetHjj(4, 2, 1, 1)
+		hjj := kb.GetHjj(w.Close, w.Open, w.High, w.Low)
 
-		if vv > vmed {
-			// kCbj.Add(kb)
+		isMain := vv > vmed
+		if isMain {
 			sumCbj += hjj * float64(vv)
 			volCbj += float64(vv)
+			nCbj++
 		}
 
-		if tStr <= pTime {
-			// kCb1.Add(kb)
-			sumCb1 += hjj * float64(vv)
-			volCb1 += float64(vv)
-		} else {
-			// kCb2.Add(kb)
-			sumCb2 += hjj * float64(vv)
-			volCb2 += float64(vv)
+		if !spec.CbFilter || isMain {
+			if tStr <= spec.SplitTime {
+				sumCb1 += hjj * float64(vv)
+				volCb1 += float64(vv)
+				nCb1++
+			} else {
+				sumCb2 += hjj * float64(vv)
+				volCb2 += float64(vv)
+			}
 		}
 
 		if i > 0 {
@@ -436,16 +508,20 @@ func (k *OHLCVList) ToCbjData(pTime string) CbjData {
 			}
 		}
 	}
-	// cbj := kCbj.GetPvj(4, 2, 1, 1)
-	// cb1 := kCb1.GetPvj(4, 2, 1, 1)
-	// cb2 := kCb2.GetPvj(4, 2, 1, 1)
+
 	cbj := float64(0.0)
 	cb1 := float64(0.0)
 	cb2 := float64(0.0)
+	if spec.CbFilter {
+		// 无数据时取最后一根K线的黄金价
+		last := (*k)[nlen-1]
+		cbj = last.GetHjj(w.Close, w.Open, w.High, w.Low)
+		cb1, cb2 = cbj, cbj
+	}
 	if volCbj > 0.0 {
 		cbj = sumCbj / volCbj
 	}
-	if volCb1 > 0.0 {
+	if volCb1 > 0.0 && (!spec.CbFilter || nCb1 < nCbj) {
 		cb1 = sumCb1 / volCb1
 	}
 	if volCb2 > 0.0 {
@@ -495,6 +571,11 @@ func (k *OHLCVList) ToCbjList(pTime string) CbjList {
 
 // 计算日频成本价+成交量中值
 func (k *OHLCVList) ToVVList(isOHLC bool, isV123 bool, isCbj bool) VVList {
+	return k.ToVVListSpec(DefaultIndicatorSpec, isOHLC, isV123, isCbj)
+}
+
+// 按指标定义计算日频指标
+func (k *OHLCVList) ToVVListSpec(spec IndicatorSpec, isOHLC bool, isV123 bool, isCbj bool) VVList {
 	var kbList VVList
 
 	nlen := len(*k)
@@ -517,7 +598,7 @@ func (k *OHLCVList) ToVVList(isOHLC bool, isV123 bool, isCbj bool) VVList {
 
 	for _, v := range kDic {
 		var kd VVData
-		kd.Init(v, isOHLC, isV123, isCbj)
+		kd.InitSpec(v, spec, isOHLC, isV123, isCbj)
 		kbList = append(kbList, kd)
 	}
 
@@ -1,27 +1,31 @@
 package gm
 
+import "time"
+
+// 五档行情(quotes 中的一档)
 type AskBidData struct {
-	BidPrice string  `json:"bid_p"`
-	BidValue float64 `json:"bid_v"`
-	AskPrice float64 `json:"ask_p"`
-	AskValue string  `json:"ask_v"`
+	BidPrice  float64 `json:"bid_p"` // 委买价
+	BidVolume int64   `json:"bid_v"` // 委买量
+	AskPrice  float64 `json:"ask_p"` // 委卖价
+	AskVolume int64   `json:"ask_v"` // 委卖量
 }
 
+// 行情快照(get_current)
 type SnapData struct {
-	Symbol      string       `json:"symbol"`
+	Symbol      string       `json:"symbol,required"`
 	Open        float64      `json:"open"`
 	High        float64      `json:"high"`
 	Low         float64      `json:"low"`
-	Price       float64      `json:"price"`
-	CumVolumn   int64        `json:"cum_volume"`
-	CumAmount   float64      `json:"cum_amount"`
-	TradeType   int64        `json:"trade_type"`
-	CreateAt    string       `json:"create_at"`
-	CumPosition string       `json:"cum_position"`
-	LastAmount  string       `json:"last_amount"`
-	LastVolume  int64        `json:"last_volume"`
+	Price       float64      `json:"price"`        // 最新价
+	CumVolume   int64        `json:"cum_volume"`   // 当日累计成交量
+	CumAmount   float64      `json:"cum_amount"`   // 当日累计成交额
+	TradeType   int64        `json:"trade_type"`   // 交易类型
+	CreatedAt   time.Time    `json:"created_at"`   // 快照时间
+	CumPosition int64        `json:"cum_position"` // 持仓量(期货)
+	LastAmount  float64      `json:"last_amount"`  // 最新一笔成交额
+	LastVolume  int64        `json:"last_volume"`  // 最新一笔成交量
 	Flag        int64        `json:"flag"`
-	Iopv        int64        `json:"iopv"`
+	Iopv        float64      `json:"iopv"` // 基金份额参考净值
 	Quotes      []AskBidData `json:"quotes"`
 }
 
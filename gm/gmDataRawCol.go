@@ -16,7 +16,7 @@ type RawColData struct {
 func (rcd *RawColData) FromByte(bdata []byte) error {
 	unmarshalErr := json.Unmarshal(bdata, rcd)
 	if unmarshalErr != nil {
-		return fmt.Errorf(" 解析原始数据失败: %s", unmarshalErr)
+		return decodeError("解析原始数据失败", unmarshalErr)
 	}
 	return nil
 }
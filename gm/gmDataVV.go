@@ -1,7 +1,10 @@
 package gm
 
 import (
+	"fmt"
 	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -40,6 +43,12 @@ type VVList []VVData
 
 // 计算单日成本价+成交量中值
 func (k *VVData) Init(ohlcv OHLCVList, isOHLC bool, isV123 bool, isCbj bool) {
+	k.InitSpec(ohlcv, DefaultIndicatorSpec, isOHLC, isV123, isCbj)
+}
+
+// 按指标定义计算单日指标
+func (k *VVData) InitSpec(ohlcv OHLCVList, spec IndicatorSpec, isOHLC bool, isV123 bool, isCbj bool) {
+	w := spec.Weights
 	if isOHLC {
 		ohv := ohlcv.ToOHLCVData(true)
 		k.TS = ohv.Timestamp.UnixMilli()
@@ -50,12 +59,12 @@ func (k *VVData) Init(ohlcv OHLCVList, isOHLC bool, isV123 bool, isCbj bool) {
 		k.Close = ohv.Close
 		k.Volume = ohv.Volume
 
-		k.Hjj = (4*k.Close + 2*k.Open + k.High + k.Low) / 8
-		k.Pvj = ohlcv.GetPvj(4, 2, 1, 1)
+		k.Hjj = ohv.GetHjj(w.Close, w.Open, w.High, w.Low)
+		k.Pvj = ohlcv.GetPvj(w.Close, w.Open, w.High, w.Low)
 	}
 
 	if isV123 {
-		v123 := ohlcv.ToV123Data()
+		v123 := ohlcv.ToV123DataSpec(spec.Windows)
 		k.V931 = v123.V931
 		k.V932 = v123.V932
 		k.V935 = v123.V935
@@ -64,7 +73,7 @@ func (k *VVData) Init(ohlcv OHLCVList, isOHLC bool, isV123 bool, isCbj bool) {
 	}
 
 	if isCbj {
-		cbj := ohlcv.ToCbjData("10:00:00")
+		cbj := ohlcv.ToCbjDataSpec(spec)
 		k.Cbj = cbj.Cbj
 		k.Cb1 = cbj.Cb1
 		k.Cb2 = cbj.Cb2
@@ -258,3 +267,97 @@ func (k *VVList) Tail(n int, isohlcv bool, isv123 bool, iscb bool) {
 		cbj.Tail(n)
 	}
 }
+
+func (k *VVList) Sort(descend bool) {
+	// 按时间排序
+	if descend {
+		sort.Slice(*k, func(i, j int) bool {
+			return (*k)[i].TS > (*k)[j].TS
+		})
+	} else {
+		sort.Slice(*k, func(i, j int) bool {
+			return (*k)[i].TS < (*k)[j].TS
+		})
+	}
+}
+
+// 日期字符串(北京时间): 2006-01-02
+func (k *VVData) DateString() string {
+	tz := time.FixedZone("CST", 8*3600)
+	return MillisToTime(k.TS).In(tz).Format("2006-01-02")
+}
+
+// csv文件的列名
+var VVColumns = []string{
+	"timestamp", "open", "high", "low", "close", "volume",
+	"v931", "v932", "v935", "v940", "v150",
+	"hjj", "pvj", "vmed", "cbj", "cb1", "cb2", "nup", "ndown",
+}
+
+// 转换为csv数据行，列顺序与 VVColumns 一致
+func (k *VVData) ToCSVRow() []string {
+	ff := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	fi := func(v int64) string { return strconv.FormatInt(v, 10) }
+	return []string{
+		k.DateString(), ff(k.Open), ff(k.High), ff(k.Low), ff(k.Close), fi(k.Volume),
+		fi(k.V931), fi(k.V932), fi(k.V935), fi(k.V940), fi(k.V150),
+		ff(k.Hjj), ff(k.Pvj), fi(k.Vmed), ff(k.Cbj), ff(k.Cb1), ff(k.Cb2), fi(k.Nup), fi(k.Ndown),
+	}
+}
+
+// 从csv数据行读取，header为列名
+func (k *VVData) FromCSVRow(header []string, row []string) error {
+	if len(header) != len(row) {
+		return fmt.Errorf("数据行长度 (%d) 与列名长度 (%d) 不匹配", len(row), len(header))
+	}
+	for i, col := range header {
+		val := row[i]
+		var err error
+		switch col {
+		case "timestamp":
+			var tt time.Time
+			tt, err = ParseTimestamp(val)
+			k.TS = tt.UnixMilli()
+		case "open":
+			k.Open, err = strconv.ParseFloat(val, 64)
+		case "high":
+			k.High, err = strconv.ParseFloat(val, 64)
+		case "low":
+			k.Low, err = strconv.ParseFloat(val, 64)
+		case "close":
+			k.Close, err = strconv.ParseFloat(val, 64)
+		case "volume":
+			k.Volume, err = strconv.ParseInt(val, 10, 64)
+		case "v931":
+			k.V931, err = strconv.ParseInt(val, 10, 64)
+		case "v932":
+			k.V932, err = strconv.ParseInt(val, 10, 64)
+		case "v935":
+			k.V935, err = strconv.ParseInt(val, 10, 64)
+		case "v940":
+			k.V940, err = strconv.ParseInt(val, 10, 64)
+		case "v150":
+			k.V150, err = strconv.ParseInt(val, 10, 64)
+		case "hjj":
+			k.Hjj, err = strconv.ParseFloat(val, 64)
+		case "pvj":
+			k.Pvj, err = strconv.ParseFloat(val, 64)
+		case "vmed":
+			k.Vmed, err = strconv.ParseInt(val, 10, 64)
+		case "cbj":
+			k.Cbj, err = strconv.ParseFloat(val, 64)
+		case "cb1":
+			k.Cb1, err = strconv.ParseFloat(val, 64)
+		case "cb2":
+			k.Cb2, err = strconv.ParseFloat(val, 64)
+		case "nup":
+			k.Nup, err = strconv.ParseInt(val, 10, 64)
+		case "ndown":
+			k.Ndown, err = strconv.ParseInt(val, 10, 64)
+		}
+		if err != nil {
+			return fmt.Errorf("解析字段 %s 失败: %w", col, err)
+		}
+	}
+	return nil
+}
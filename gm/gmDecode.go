@@ -0,0 +1,234 @@
+package gm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 字段级解码错误
+type FieldError struct {
+	Row   int    // 记录序号(从0开始)
+	Field string // 字段名
+	Value any    // 原始值
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("第%d条记录字段 %s 解析失败(值: %v): %v", e.Row, e.Field, e.Value, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// 字段解码错误属于 ErrDecode
+func (e *FieldError) Is(target error) bool {
+	return target == ErrDecode
+}
+
+// 多个字段级解码错误
+type DecodeErrors []*FieldError
+
+func (e DecodeErrors) Error() string {
+	const maxShow = 3
+	msgs := make([]string, 0, maxShow)
+	for i, fe := range e {
+		if i >= maxShow {
+			break
+		}
+		msgs = append(msgs, fe.Error())
+	}
+	if len(e) > maxShow {
+		msgs = append(msgs, fmt.Sprintf("... 共%d个错误", len(e)))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e DecodeErrors) Is(target error) bool {
+	return target == ErrDecode
+}
+
+var errFieldMissing = fmt.Errorf("缺少必须字段")
+
+// 字段缺失、为 null 或为空字符串(CSV 空单元格)
+func isBlank(val any, exists bool) bool {
+	return !exists || val == nil || val == ""
+}
+
+// 将 records 解码为结构体列表
+//
+// 字段按 json 标签匹配，缺失、null 和空字符串都视为未设置，标签带 required 选项时视为错误，
+// 例如 `json:"open,required"`。支持 string, bool, 整数, 浮点数, time.Time 类型。
+// 出错的记录会被跳过，返回其余记录及 DecodeErrors。
+func DecodeRecords[T any](records []map[string]any) ([]T, error) {
+	var zero T
+	rt := reflect.TypeOf(zero)
+	if rt.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("DecodeRecords 只支持结构体类型: %s", rt)
+	}
+	fields := structFields(rt)
+
+	var errs DecodeErrors
+	res := make([]T, 0, len(records))
+	for i, rec := range records {
+		var item T
+		rv := reflect.ValueOf(&item).Elem()
+		ok := true
+		for _, f := range fields {
+			val, exists := rec[f.name]
+			if isBlank(val, exists) {
+				if f.required {
+					errs = append(errs, &FieldError{Row: i, Field: f.name, Err: errFieldMissing})
+					ok = false
+				}
+				continue
+			}
+			if err := setField(rv.Field(f.index), val); err != nil {
+				errs = append(errs, &FieldError{Row: i, Field: f.name, Value: val, Err: err})
+				ok = false
+			}
+		}
+		if ok {
+			res = append(res, item)
+		}
+	}
+	if len(errs) > 0 {
+		return res, errs
+	}
+	return res, nil
+}
+
+// 将 RawColData 解码为结构体列表
+func DecodeRawCol[T any](rcd *RawColData) ([]T, error) {
+	records, err := rcd.ToRecords()
+	if err != nil {
+		return nil, err
+	}
+	return DecodeRecords[T](records)
+}
+
+type decodeField struct {
+	index    int
+	name     string
+	required bool
+}
+
+func structFields(rt reflect.Type) []decodeField {
+	var fields []decodeField
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+		if name == "" {
+			name = sf.Name
+		}
+		required := false
+		for _, opt := range parts[1:] {
+			if opt == "required" {
+				required = true
+			}
+		}
+		fields = append(fields, decodeField{index: i, name: name, required: required})
+	}
+	return fields
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func setField(fv reflect.Value, val any) error {
+	if fv.Type() == timeType {
+		if str, ok := val.(string); ok && str == "" {
+			return nil
+		}
+		tt, err := ParseTimestamp(val)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(tt))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		switch v := val.(type) {
+		case string:
+			fv.SetString(v)
+		case float64, int, int64, bool, json.Number:
+			fv.SetString(fmt.Sprint(v))
+		default:
+			return fmt.Errorf("类型不匹配: %T -> string", val)
+		}
+	case reflect.Float32, reflect.Float64:
+		f, err := anyToFloat(val)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f, err := anyToFloat(val)
+		if err != nil {
+			return err
+		}
+		if f != math.Trunc(f) {
+			return fmt.Errorf("不是整数: %v", f)
+		}
+		fv.SetInt(int64(f))
+	case reflect.Bool:
+		switch v := val.(type) {
+		case bool:
+			fv.SetBool(v)
+		case float64:
+			fv.SetBool(v != 0)
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return err
+			}
+			fv.SetBool(b)
+		default:
+			return fmt.Errorf("类型不匹配: %T -> bool", val)
+		}
+	default:
+		// 其他类型(切片、结构体等)按JSON转换
+		raw, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(raw, fv.Addr().Interface())
+	}
+	return nil
+}
+
+func anyToFloat(val any) (float64, error) {
+	switch v := val.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		if v == "" {
+			return 0, fmt.Errorf("空值")
+		}
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("类型不匹配: %T -> number", val)
+	}
+}
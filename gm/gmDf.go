@@ -1,6 +1,7 @@
 package gm
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -183,13 +184,11 @@ func DfCSVMonth(gmcsv string, symbol string,
 	month int, year int, istimestamp bool,
 	timeoutSeconds int) (dataframe.DataFrame, error) {
 
-	url := fmt.Sprintf("%s/download/", gmcsv)
-
 	fpath := getFilePathMonth(symbol, year, month)
 	fmt.Println(fpath)
 
-	// 下载并读取数据
-	csvData, err := downloadAndReadData(url + fpath)
+	// 下载(或从本地目录读取)数据
+	csvData, err := newFuncClient("", gmcsv, timeoutSeconds).csvData(context.Background(), fpath)
 	if err != nil {
 		return dataframe.DataFrame{}, err
 	}
@@ -207,12 +206,11 @@ func DfCSVYear(gmcsv string,
 	symbol string, tag string, year int, istimestamp bool, tskey string,
 	timeoutSeconds int) (dataframe.DataFrame, error) {
 
-	url := fmt.Sprintf("%s/download/", gmcsv)
 	fpath := getFilePathYear(symbol, tag, year)
 	fmt.Println(fpath)
 
-	// 下载并读取数据
-	csvData, err := downloadAndReadData(url + fpath)
+	// 下载(或从本地目录读取)数据
+	csvData, err := newFuncClient("", gmcsv, timeoutSeconds).csvData(context.Background(), fpath)
 	if err != nil {
 		return dataframe.DataFrame{}, err
 	}
@@ -0,0 +1,64 @@
+package gm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// 错误分类, 可用 errors.Is 判断:
+//
+//	errors.Is(err, gm.ErrInvalidParam)
+//	errors.Is(err, gm.ErrNoData)
+//	errors.As(err, &st) // st 为 *gm.ErrUpstreamStatus
+var (
+	ErrUpstreamUnavailable = errors.New("上游服务不可用") // 连接失败、超时、读取响应中断
+	ErrDecode              = errors.New("数据解析失败")  // JSON/CSV/xz 格式错误
+	ErrNoData              = errors.New("没有数据")    // 请求成功但结果为空
+	ErrInvalidParam        = errors.New("参数错误")    // 缺少参数或参数格式错误
+	ErrNotFound            = errors.New("文件不存在")   // csv.xz 文件不存在(本地目录或 gm-csv 返回404)
+)
+
+// 上游返回非200状态码
+type ErrUpstreamStatus struct {
+	Code   int    // HTTP 状态码
+	Status string // 如 "404 Not Found"
+	URL    string
+}
+
+func (e *ErrUpstreamStatus) Error() string {
+	return fmt.Sprintf("请求失败: %s: %s", e.Status, e.URL)
+}
+
+// target 的 Code 为 0 时匹配任意状态码; 404 同时匹配 ErrNotFound
+func (e *ErrUpstreamStatus) Is(target error) bool {
+	if target == ErrNotFound {
+		return e.Code == http.StatusNotFound
+	}
+	t, ok := target.(*ErrUpstreamStatus)
+	return ok && (t.Code == 0 || t.Code == e.Code)
+}
+
+// 是否为超时错误(单次请求超时或调用方的 deadline)
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// 参数错误
+func invalidParam(format string, a ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidParam, fmt.Sprintf(format, a...))
+}
+
+// 解析错误, err 可为 nil
+func decodeError(msg string, err error) error {
+	if err == nil {
+		return fmt.Errorf("%w: %s", ErrDecode, msg)
+	}
+	return fmt.Errorf("%w: %s: %w", ErrDecode, msg, err)
+}
@@ -1,17 +1,17 @@
 package gm
 
 import (
+	"bytes"
 	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/ulikunitz/xz"
 )
 
 // makeRequest 发起一个HTTP请求并打印响应状态和响应体
@@ -37,129 +37,28 @@ import (
 func FetchData(url string) ([]byte, error) {
 	resp, err := http.Get(url)
 	if err != nil {
-		return nil, fmt.Errorf("请求失败: %v", err)
+		return nil, fmt.Errorf("%w: %w", ErrUpstreamUnavailable, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP 请求失败，状态码: %d", resp.StatusCode)
+		return nil, &ErrUpstreamStatus{Code: resp.StatusCode, Status: resp.Status, URL: url}
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("读取响应体失败: %v", err)
+		return nil, fmt.Errorf("%w: 读取响应体失败: %w", ErrUpstreamUnavailable, err)
 	}
 
 	return body, nil
 }
 
-// 获取URL数据
-func fetchURLData(url string, timeout time.Duration, params map[string]string) ([]byte, error) {
-	client := &http.Client{
-		Timeout: timeout,
-	}
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	q := req.URL.Query()
-	for k, v := range params {
-		q.Add(k, v)
-	}
-	req.URL.RawQuery = q.Encode()
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("请求失败: %s", resp.Status)
-	}
-
-	return io.ReadAll(resp.Body)
-}
-
-// 发送带重试的 GET 请求
-// 参数：
-//   - url: 请求的 URL
-//   - timeout: 单次请求超时时间（time.Duration 类型，如 5*time.Second）
-//   - maxRetries: 最大重试次数（如 3 次）
-//
-// 返回值：
-//   - 响应内容或错误信息
-func fetchWithRetry(url string, timeout time.Duration, maxRetries int, params map[string]string) ([]byte, error) {
-	var lastErr error
-
-	// 重试循环（总尝试次数 = maxRetries + 1）
-	for i := 0; i <= maxRetries; i++ {
-		// 创建带超时的 Context
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
-
-		// 创建 Request 并绑定 Context
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		if err != nil {
-			lastErr = fmt.Errorf("创建请求失败: %v", err)
-			continue
-		}
-		q := req.URL.Query()
-		for k, v := range params {
-			q.Add(k, v)
-		}
-		req.URL.RawQuery = q.Encode()
-
-		// 发送请求
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		if err != nil {
-			// 记录错误，准备重试
-			lastErr = fmt.Errorf("请求失败 (尝试 %d/%d): %v", i+1, maxRetries+1, err)
-
-			// 如果达到最大重试次数，返回错误
-			if i == maxRetries {
-				return nil, lastErr
-			}
-
-			// 等待一段时间后重试（指数退避）
-			sleepTime := time.Duration(i*i) * time.Second // 示例：二次方退避
-			fmt.Printf("等待 %v 后重试...\n", sleepTime)
-			time.Sleep(sleepTime)
-			continue
-		}
-		defer resp.Body.Close()
-
-		// 检查 HTTP 状态码
-		if resp.StatusCode != http.StatusOK {
-			lastErr = fmt.Errorf("状态码异常 (尝试 %d/%d): %d", i+1, maxRetries+1, resp.StatusCode)
-
-			if i == maxRetries {
-				return nil, lastErr
-			}
-
-			// 等待后重试
-			sleepTime := time.Duration(i*i) * time.Second
-			fmt.Printf("等待 %v 后重试...\n", sleepTime)
-			time.Sleep(sleepTime)
-			continue
-		}
-
-		// 读取响应内容（此处简化处理）
-		return io.ReadAll(resp.Body)
-	}
-
-	return nil, fmt.Errorf("所有尝试均失败: %w", lastErr)
-}
-
 // 测试数据1
-func GetTest(url string, timeoutSeconds int) ([]byte, error) {
-	urlTar := fmt.Sprintf("%s/test", url)
+func (c *Client) GetTest(ctx context.Context) ([]byte, error) {
+	urlTar := fmt.Sprintf("%s/test", c.BaseURL)
 
 	// 获取历史K线数据
-	resp, err := fetchURLData(urlTar, time.Duration(timeoutSeconds)*time.Second, map[string]string{})
+	resp, err := c.fetchURLData(ctx, urlTar, map[string]string{})
 	if err != nil {
 		fmt.Printf("获取数据失败: %s\n", err)
 		return nil, err
@@ -169,11 +68,11 @@ func GetTest(url string, timeoutSeconds int) ([]byte, error) {
 }
 
 // 测试数据1
-func GetTest2(url string, timeoutSeconds int) ([]byte, error) {
-	urlTar := fmt.Sprintf("%s/test2", url)
+func (c *Client) GetTest2(ctx context.Context) ([]byte, error) {
+	urlTar := fmt.Sprintf("%s/test2", c.BaseURL)
 
 	// 获取历史K线数据
-	resp, err := fetchURLData(urlTar, time.Duration(timeoutSeconds)*time.Second, map[string]string{})
+	resp, err := c.fetchURLData(ctx, urlTar, map[string]string{})
 	if err != nil {
 		fmt.Printf("获取数据失败: %s\n", err)
 		return nil, err
@@ -183,8 +82,9 @@ func GetTest2(url string, timeoutSeconds int) ([]byte, error) {
 }
 
 // 获取交易日历
-func GetCalendar(gmapi string, syear string, eyear string, exchange string, timeoutSeconds int) ([]byte, error) {
-	url := fmt.Sprintf("%s/get_dates_by_year", gmapi)
+func (c *Client) GetCalendar(ctx context.Context,
+	syear string, eyear string, exchange string) ([]byte, error) {
+	url := fmt.Sprintf("%s/get_dates_by_year", c.BaseURL)
 	params := map[string]string{
 		"syear": syear,
 		"eyear": eyear,
@@ -194,7 +94,7 @@ func GetCalendar(gmapi string, syear string, eyear string, exchange string, time
 	}
 
 	// 获取历史K线数据
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		// fmt.Printf(" 获取数据失败(gm.GetCalendar): %v\n", err)
 		return nil, err
@@ -204,8 +104,9 @@ func GetCalendar(gmapi string, syear string, eyear string, exchange string, time
 }
 
 // 查询指定日期的前n个交易日
-func GetPrevNByte(gmapi string, date string, count int, include bool, timeoutSeconds int) ([]byte, error) {
-	url := fmt.Sprintf("%s/get_dates_prev_n", gmapi)
+func (c *Client) GetPrevNByte(ctx context.Context,
+	date string, count int, include bool) ([]byte, error) {
+	url := fmt.Sprintf("%s/get_dates_prev_n", c.BaseURL)
 
 	cdate := date
 	if include {
@@ -218,7 +119,7 @@ func GetPrevNByte(gmapi string, date string, count int, include bool, timeoutSec
 		"count": fmt.Sprintf("%d", count),
 	}
 
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		// fmt.Printf(" 获取数据失败(gm.GetCalendar): %v\n", err)
 		return nil, err
@@ -228,9 +129,10 @@ func GetPrevNByte(gmapi string, date string, count int, include bool, timeoutSec
 }
 
 // 查询指定日期的前n个交易日
-func GetPrevN(gmapi string, date string, count int, include bool, timeoutSeconds int) ([]any, error) {
+func (c *Client) GetPrevN(ctx context.Context,
+	date string, count int, include bool) ([]any, error) {
 
-	rawData, err := GetPrevNByte(gmapi, date, count, include, timeoutSeconds)
+	rawData, err := c.GetPrevNByte(ctx, date, count, include)
 	if err != nil {
 		return nil, err
 	}
@@ -238,15 +140,16 @@ func GetPrevN(gmapi string, date string, count int, include bool, timeoutSeconds
 	// 将获取到的字符串数据解析为 JSON 格式
 	var data []any
 	if err = json.Unmarshal(rawData, &data); err != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败: %v", err)
+		return nil, decodeError("解析 JSON 数据失败", err)
 	}
 
 	return data, nil
 }
 
 // 查询指定日期的后n个交易日
-func GetNextNByte(gmapi string, date string, count int, include bool, timeoutSeconds int) ([]byte, error) {
-	url := fmt.Sprintf("%s/get_dates_next_n", gmapi)
+func (c *Client) GetNextNByte(ctx context.Context,
+	date string, count int, include bool) ([]byte, error) {
+	url := fmt.Sprintf("%s/get_dates_next_n", c.BaseURL)
 
 	cdate := date
 	if include {
@@ -259,7 +162,7 @@ func GetNextNByte(gmapi string, date string, count int, include bool, timeoutSec
 		"count": fmt.Sprintf("%d", count),
 	}
 
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		// fmt.Printf(" 获取数据失败(gm.GetCalendar): %v\n", err)
 		return nil, err
@@ -269,9 +172,10 @@ func GetNextNByte(gmapi string, date string, count int, include bool, timeoutSec
 }
 
 // 查询指定日期的前n个交易日
-func GetNextN(gmapi string, date string, count int, include bool, timeoutSeconds int) ([]any, error) {
+func (c *Client) GetNextN(ctx context.Context,
+	date string, count int, include bool) ([]any, error) {
 
-	rawData, err := GetNextNByte(gmapi, date, count, include, timeoutSeconds)
+	rawData, err := c.GetNextNByte(ctx, date, count, include)
 	if err != nil {
 		return nil, err
 	}
@@ -279,14 +183,14 @@ func GetNextN(gmapi string, date string, count int, include bool, timeoutSeconds
 	// 将获取到的字符串数据解析为 JSON 格式
 	var data []any
 	if err = json.Unmarshal(rawData, &data); err != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败: %v", err)
+		return nil, decodeError("解析 JSON 数据失败", err)
 	}
 
 	return data, nil
 }
 
 // 获取给定日期区间的交易日列表
-func GetDatesList(gmapi string, sdate string, edate string, timeoutSeconds int) ([]string, error) {
+func (c *Client) GetDatesList(ctx context.Context, sdate string, edate string) ([]string, error) {
 	if sdate == "" {
 		sdate = "2005-01-01"
 	}
@@ -299,7 +203,7 @@ func GetDatesList(gmapi string, sdate string, edate string, timeoutSeconds int)
 
 	var dates []string
 
-	datesRsp, _ := GetPrevN(gmapi, edate, count+1, true, timeoutSeconds)
+	datesRsp, _ := c.GetPrevN(ctx, edate, count+1, true)
 	for _, date := range datesRsp {
 		strDate := date.(string)
 		if strDate < sdate {
@@ -312,8 +216,8 @@ func GetDatesList(gmapi string, sdate string, edate string, timeoutSeconds int)
 }
 
 // 获取行情快照数据
-func GetCurrentByte(gmapi string, symbols string, timeoutSeconds int, split bool) ([]byte, error) {
-	url := fmt.Sprintf("%s/get_current", gmapi)
+func (c *Client) GetCurrentByte(ctx context.Context, symbols string, split bool) ([]byte, error) {
+	url := fmt.Sprintf("%s/get_current", c.BaseURL)
 	params := map[string]string{
 		"symbols": symbols,
 	}
@@ -323,7 +227,7 @@ func GetCurrentByte(gmapi string, symbols string, timeoutSeconds int, split bool
 		params["split"] = "false"
 	}
 
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		// fmt.Printf("获取数据失败: %s\n", err)
 		return nil, err
@@ -333,18 +237,33 @@ func GetCurrentByte(gmapi string, symbols string, timeoutSeconds int, split bool
 }
 
 // 获取行情快照数据
-func GetCurrent(gmapi string, symbols string, timeoutSeconds int, split bool) ([]any, error) {
-	rawData, err := GetCurrentByte(gmapi, symbols, timeoutSeconds, split)
+//
+// split 为 true 时 gm-api 返回 columns/data 格式, 否则返回记录列表, 两种格式均解码为 []SnapData。
+func (c *Client) GetCurrent(ctx context.Context, symbols string, split bool) ([]SnapData, error) {
+	rawData, err := c.GetCurrentByte(ctx, symbols, split)
 	if err != nil {
-		return nil, fmt.Errorf("获取数据失败(GetCurrentByte()): %v", err)
+		return nil, fmt.Errorf("获取数据失败(GetCurrentByte()): %w", err)
 	}
+	return decodeSnapData(rawData)
+}
 
-	// 将获取到的字符串数据解析为 JSON 格式
-	var data []any
-	if err = json.Unmarshal(rawData, &data); err != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(GetCurrent()): %v", err)
+// 解码行情快照: 记录列表或 columns/data 格式
+func decodeSnapData(rawData []byte) ([]SnapData, error) {
+	var records []map[string]any
+	if trimmed := bytes.TrimSpace(rawData); len(trimmed) > 0 && trimmed[0] == '{' {
+		var rcd RawColData
+		if err := json.Unmarshal(trimmed, &rcd); err != nil {
+			return nil, decodeError("解析 JSON 数据失败(GetCurrent())", err)
+		}
+		rs, err := rcd.ToRecords()
+		if err != nil {
+			return nil, decodeError("转换数据失败(GetCurrent())", err)
+		}
+		records = rs
+	} else if err := json.Unmarshal(rawData, &records); err != nil {
+		return nil, decodeError("解析 JSON 数据失败(GetCurrent())", err)
 	}
-	return data, nil
+	return DecodeRecords[SnapData](records)
 }
 
 // 查询个股估值指标每日数据
@@ -352,14 +271,13 @@ func GetCurrent(gmapi string, symbols string, timeoutSeconds int, split bool) ([
 //   - sdate: 开始日期, 格式: "2021-01-01"
 //   - edate: 结束日期, 格式: "2021-01-01"
 //   - symbol: 股票代码, 如 "SHSE.601088"
-func GetDailyValuation(gmapi string,
-	symbol string, sdate string, edate string, fields string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetDailyValuation(ctx context.Context,
+	symbol string, sdate string, edate string, fields string) ([]map[string]any, error) {
 	if symbol == "" {
-		return nil, fmt.Errorf("Symbol为必须字段")
+		return nil, invalidParam("Symbol为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_daily_valuation", gmapi)
+	url := fmt.Sprintf("%s/get_daily_valuation", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"split":  "true",
@@ -374,19 +292,19 @@ func GetDailyValuation(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_daily_valuation()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_daily_valuation())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_daily_valuation()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_daily_valuation())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -397,14 +315,13 @@ func GetDailyValuation(gmapi string,
 //   - sdate: 开始日期, 格式: "2021-01-01"
 //   - edate: 结束日期, 格式: "2021-01-01"
 //   - symbol: 股票代码, 如 "SHSE.601088"
-func GetDailyBasic(gmapi string,
-	symbol string, sdate string, edate string, fields string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetDailyBasic(ctx context.Context,
+	symbol string, sdate string, edate string, fields string) ([]map[string]any, error) {
 	if symbol == "" {
-		return nil, fmt.Errorf("Symbol为必须字段")
+		return nil, invalidParam("Symbol为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_daily_basic", gmapi)
+	url := fmt.Sprintf("%s/get_daily_basic", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"split":  "true",
@@ -419,19 +336,19 @@ func GetDailyBasic(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_daily_basic()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_daily_basic())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_daily_basic()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_daily_basic())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -442,14 +359,13 @@ func GetDailyBasic(gmapi string,
 //   - sdate: 开始日期, 格式: "2021-01-01"
 //   - edate: 结束日期, 格式: "2021-01-01"
 //   - symbol: 股票代码, 如 "SHSE.601088"
-func GetDailyMktvalue(gmapi string,
-	symbol string, sdate string, edate string, fields string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetDailyMktvalue(ctx context.Context,
+	symbol string, sdate string, edate string, fields string) ([]map[string]any, error) {
 	if symbol == "" {
-		return nil, fmt.Errorf("Symbol为必须字段")
+		return nil, invalidParam("Symbol为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_daily_mktvalue", gmapi)
+	url := fmt.Sprintf("%s/get_daily_mktvalue", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"split":  "true",
@@ -464,19 +380,19 @@ func GetDailyMktvalue(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_daily_mktvalue()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_daily_mktvalue())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_daily_mktvalue()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_daily_mktvalue())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -490,15 +406,13 @@ func GetDailyMktvalue(gmapi string,
 //   - fields: fields不能超过20个字段
 //   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
 //   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
-func GetFinancePrime(gmapi string,
-	symbol string, sdate string, edate string, fields string,
-	rpt_type string, data_type string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetFinancePrime(ctx context.Context,
+	symbol string, sdate string, edate string, fields string, rpt_type string, data_type string) ([]map[string]any, error) {
 	if symbol == "" {
-		return nil, fmt.Errorf("Symbol为必须字段")
+		return nil, invalidParam("Symbol为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_finance_prime", gmapi)
+	url := fmt.Sprintf("%s/get_finance_prime", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"split":  "true",
@@ -519,19 +433,19 @@ func GetFinancePrime(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_finance_prime()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_finance_prime())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_finance_prime()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_finance_prime())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -545,15 +459,13 @@ func GetFinancePrime(gmapi string,
 //   - fields: fields不能超过20个字段
 //   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
 //   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
-func GetFinanceDeriv(gmapi string,
-	symbol string, sdate string, edate string, fields string,
-	rpt_type string, data_type string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetFinanceDeriv(ctx context.Context,
+	symbol string, sdate string, edate string, fields string, rpt_type string, data_type string) ([]map[string]any, error) {
 	if symbol == "" {
-		return nil, fmt.Errorf("Symbol为必须字段")
+		return nil, invalidParam("Symbol为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_finance_deriv", gmapi)
+	url := fmt.Sprintf("%s/get_finance_deriv", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"split":  "true",
@@ -574,19 +486,19 @@ func GetFinanceDeriv(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_finance_deriv()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_finance_deriv())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_finance_deriv()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_finance_deriv())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -600,15 +512,13 @@ func GetFinanceDeriv(gmapi string,
 //   - fields: fields不能超过20个字段
 //   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
 //   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
-func GetFundamentalsBalance(gmapi string,
-	symbol string, sdate string, edate string, fields string,
-	rpt_type string, data_type string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetFundamentalsBalance(ctx context.Context,
+	symbol string, sdate string, edate string, fields string, rpt_type string, data_type string) ([]map[string]any, error) {
 	if symbol == "" {
-		return nil, fmt.Errorf("Symbol为必须字段")
+		return nil, invalidParam("Symbol为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_fundamentals_balance", gmapi)
+	url := fmt.Sprintf("%s/get_fundamentals_balance", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"split":  "true",
@@ -629,19 +539,19 @@ func GetFundamentalsBalance(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_fundamentals_balance()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_fundamentals_balance())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_fundamentals_balance()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_fundamentals_balance())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -655,15 +565,13 @@ func GetFundamentalsBalance(gmapi string,
 //   - fields: fields不能超过20个字段
 //   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
 //   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
-func GetFundamentalsCashflow(gmapi string,
-	symbol string, sdate string, edate string, fields string,
-	rpt_type string, data_type string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetFundamentalsCashflow(ctx context.Context,
+	symbol string, sdate string, edate string, fields string, rpt_type string, data_type string) ([]map[string]any, error) {
 	if symbol == "" {
-		return nil, fmt.Errorf("Symbol为必须字段")
+		return nil, invalidParam("Symbol为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_fundamentals_cashflow", gmapi)
+	url := fmt.Sprintf("%s/get_fundamentals_cashflow", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"split":  "true",
@@ -684,19 +592,19 @@ func GetFundamentalsCashflow(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_fundamentals_cashflow()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_fundamentals_cashflow())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_fundamentals_cashflow()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_fundamentals_cashflow())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -710,15 +618,13 @@ func GetFundamentalsCashflow(gmapi string,
 //   - fields: fields不能超过20个字段
 //   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
 //   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
-func GetFundamentalsIncome(gmapi string,
-	symbol string, sdate string, edate string, fields string,
-	rpt_type string, data_type string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetFundamentalsIncome(ctx context.Context,
+	symbol string, sdate string, edate string, fields string, rpt_type string, data_type string) ([]map[string]any, error) {
 	if symbol == "" {
-		return nil, fmt.Errorf("Symbol为必须字段")
+		return nil, invalidParam("Symbol为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_fundamentals_income", gmapi)
+	url := fmt.Sprintf("%s/get_fundamentals_income", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"split":  "true",
@@ -739,19 +645,19 @@ func GetFundamentalsIncome(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_fundamentals_income()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_fundamentals_income())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_fundamentals_income()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_fundamentals_income())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -764,15 +670,13 @@ func GetFundamentalsIncome(gmapi string,
 //   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
 //   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
 //   - symbols: 股票列表, 如 "SHSE.601088,SZSE.000001"
-func GetFundamentalsBalancePt(gmapi string,
-	symbols string, date string, fields string,
-	rpt_type string, data_type string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetFundamentalsBalancePt(ctx context.Context,
+	symbols string, date string, fields string, rpt_type string, data_type string) ([]map[string]any, error) {
 	if symbols == "" {
-		return nil, fmt.Errorf("Symbols为必须字段")
+		return nil, invalidParam("Symbols为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_fundamentals_balance_pt", gmapi)
+	url := fmt.Sprintf("%s/get_fundamentals_balance_pt", c.BaseURL)
 	params := map[string]string{
 		"symbols": symbols,
 		"split":   "true",
@@ -790,19 +694,19 @@ func GetFundamentalsBalancePt(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_fundamentals_balance_pt()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_fundamentals_balance_pt())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_fundamentals_balance_pt()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_fundamentals_balance_pt())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -815,15 +719,13 @@ func GetFundamentalsBalancePt(gmapi string,
 //   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
 //   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
 //   - symbols: 股票列表, 如 "SHSE.601088,SZSE.000001"
-func GetFundamentalsCashflowPt(gmapi string,
-	symbols string, date string, fields string,
-	rpt_type string, data_type string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetFundamentalsCashflowPt(ctx context.Context,
+	symbols string, date string, fields string, rpt_type string, data_type string) ([]map[string]any, error) {
 	if symbols == "" {
-		return nil, fmt.Errorf("Symbols为必须字段")
+		return nil, invalidParam("Symbols为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_fundamentals_cashflow_pt", gmapi)
+	url := fmt.Sprintf("%s/get_fundamentals_cashflow_pt", c.BaseURL)
 	params := map[string]string{
 		"symbols": symbols,
 		"split":   "true",
@@ -841,19 +743,19 @@ func GetFundamentalsCashflowPt(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_fundamentals_cashflow_pt()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_fundamentals_cashflow_pt())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_fundamentals_cashflow_pt()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_fundamentals_cashflow_pt())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -866,15 +768,13 @@ func GetFundamentalsCashflowPt(gmapi string,
 //   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
 //   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
 //   - symbols: 股票列表, 如 "SHSE.601088,SZSE.000001"
-func GetFundamentalsIncomePt(gmapi string,
-	symbols string, date string, fields string,
-	rpt_type string, data_type string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetFundamentalsIncomePt(ctx context.Context,
+	symbols string, date string, fields string, rpt_type string, data_type string) ([]map[string]any, error) {
 	if symbols == "" {
-		return nil, fmt.Errorf("Symbols为必须字段")
+		return nil, invalidParam("Symbols为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_fundamentals_income_pt", gmapi)
+	url := fmt.Sprintf("%s/get_fundamentals_income_pt", c.BaseURL)
 	params := map[string]string{
 		"symbols": symbols,
 		"split":   "true",
@@ -892,19 +792,19 @@ func GetFundamentalsIncomePt(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_fundamentals_income_pt()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_fundamentals_income_pt())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_fundamentals_income_pt()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_fundamentals_income_pt())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -917,15 +817,13 @@ func GetFundamentalsIncomePt(gmapi string,
 //   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
 //   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
 //   - symbols: 股票列表, 如 "SHSE.601088,SZSE.000001"
-func GetFinancePrimePt(gmapi string,
-	symbols string, date string, fields string,
-	rpt_type string, data_type string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetFinancePrimePt(ctx context.Context,
+	symbols string, date string, fields string, rpt_type string, data_type string) ([]map[string]any, error) {
 	if symbols == "" {
-		return nil, fmt.Errorf("Symbols为必须字段")
+		return nil, invalidParam("Symbols为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_finance_prime_pt", gmapi)
+	url := fmt.Sprintf("%s/get_finance_prime_pt", c.BaseURL)
 	params := map[string]string{
 		"symbols": symbols,
 		"split":   "true",
@@ -943,19 +841,19 @@ func GetFinancePrimePt(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_finance_prime_pt()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_finance_prime_pt())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_finance_prime_pt()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_finance_prime_pt())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -968,15 +866,13 @@ func GetFinancePrimePt(gmapi string,
 //   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
 //   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
 //   - symbols: 股票列表, 如 "SHSE.601088,SZSE.000001"
-func GetFinanceDerivPt(gmapi string,
-	symbols string, date string, fields string,
-	rpt_type string, data_type string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetFinanceDerivPt(ctx context.Context,
+	symbols string, date string, fields string, rpt_type string, data_type string) ([]map[string]any, error) {
 	if symbols == "" {
-		return nil, fmt.Errorf("Symbols为必须字段")
+		return nil, invalidParam("Symbols为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_finance_deriv_pt", gmapi)
+	url := fmt.Sprintf("%s/get_finance_deriv_pt", c.BaseURL)
 	params := map[string]string{
 		"symbols": symbols,
 		"split":   "true",
@@ -994,19 +890,19 @@ func GetFinanceDerivPt(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_finance_deriv_pt()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_finance_deriv_pt())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_finance_deriv_pt()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_finance_deriv_pt())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1016,14 +912,13 @@ func GetFinanceDerivPt(gmapi string,
 // 输入参数：
 //   - date: 交易日期, 格式: "2021-01-01"
 //   - symbols: 股票列表, 如 "SHSE.601088,SZSE.000001"
-func GetDailyBasicPt(gmapi string,
-	symbols string, date string, fields string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetDailyBasicPt(ctx context.Context,
+	symbols string, date string, fields string) ([]map[string]any, error) {
 	if symbols == "" {
-		return nil, fmt.Errorf("Symbols为必须字段")
+		return nil, invalidParam("Symbols为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_daily_basic_pt", gmapi)
+	url := fmt.Sprintf("%s/get_daily_basic_pt", c.BaseURL)
 	params := map[string]string{
 		"symbols": symbols,
 		"split":   "true",
@@ -1035,19 +930,19 @@ func GetDailyBasicPt(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_daily_basic_pt()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_daily_basic_pt())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_daily_basic_pt()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_daily_basic_pt())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1057,14 +952,13 @@ func GetDailyBasicPt(gmapi string,
 // 输入参数：
 //   - date: 交易日期, 格式: "2021-01-01"
 //   - symbols: 股票列表, 如 "SHSE.601088,SZSE.000001"
-func GetDailyMktvaluePt(gmapi string,
-	symbols string, date string, fields string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetDailyMktvaluePt(ctx context.Context,
+	symbols string, date string, fields string) ([]map[string]any, error) {
 	if symbols == "" {
-		return nil, fmt.Errorf("Symbols为必须字段")
+		return nil, invalidParam("Symbols为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_daily_mktvalue_pt", gmapi)
+	url := fmt.Sprintf("%s/get_daily_mktvalue_pt", c.BaseURL)
 	params := map[string]string{
 		"symbols": symbols,
 		"split":   "true",
@@ -1076,19 +970,19 @@ func GetDailyMktvaluePt(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_daily_mktvalue_pt()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_daily_mktvalue_pt())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_daily_mktvalue_pt()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_daily_mktvalue_pt())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1098,14 +992,13 @@ func GetDailyMktvaluePt(gmapi string,
 // 输入参数：
 //   - date: 交易日期, 格式: "2021-01-01"
 //   - symbols: 股票列表, 如 "SHSE.601088,SZSE.000001"
-func GetDailyValuationPt(gmapi string,
-	symbols string, date string, fields string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetDailyValuationPt(ctx context.Context,
+	symbols string, date string, fields string) ([]map[string]any, error) {
 	if symbols == "" {
-		return nil, fmt.Errorf("Symbols为必须字段")
+		return nil, invalidParam("Symbols为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_daily_valuation_pt", gmapi)
+	url := fmt.Sprintf("%s/get_daily_valuation_pt", c.BaseURL)
 	params := map[string]string{
 		"symbols": symbols,
 		"split":   "true",
@@ -1117,19 +1010,19 @@ func GetDailyValuationPt(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_daily_valuation_pt()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_daily_valuation_pt())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_daily_valuation_pt()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_daily_valuation_pt())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1138,32 +1031,31 @@ func GetDailyValuationPt(gmapi string,
 // 查询板块分类
 // 输入参数：
 //   - sector_type: 只能选择一种类型，可选择 1001:市场类 1002:地域类 1003:概念类
-func GetSectorCategory(gmapi string,
-	sector_type string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetSectorCategory(ctx context.Context,
+	sector_type string) ([]map[string]any, error) {
 	if sector_type == "" {
 		return nil, fmt.Errorf("sector_type为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_sector_category", gmapi)
+	url := fmt.Sprintf("%s/get_sector_category", c.BaseURL)
 	params := map[string]string{
 		"sector_type": sector_type,
 		"split":       "true",
 	}
 
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_sector_category()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_sector_category())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_sector_category()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_sector_category())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1172,32 +1064,31 @@ func GetSectorCategory(gmapi string,
 // 查询板块成分股
 // 输入参数：
 //   - sector_code: 需要查询成分股的板块代码，可通过stk_get_sector_category获取
-func GetSectorConstituents(gmapi string,
-	sector_code string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetSectorConstituents(ctx context.Context,
+	sector_code string) ([]map[string]any, error) {
 	if sector_code == "" {
-		return nil, fmt.Errorf("sector_code为必须字段")
+		return nil, invalidParam("sector_code为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_sector_constituents", gmapi)
+	url := fmt.Sprintf("%s/get_sector_constituents", c.BaseURL)
 	params := map[string]string{
 		"sector_code": sector_code,
 		"split":       "true",
 	}
 
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_sector_constituents()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_sector_constituents())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_sector_constituents()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_sector_constituents())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1206,33 +1097,32 @@ func GetSectorConstituents(gmapi string,
 // 查询个股所属板块
 // 输入参数：
 //   - sector_type: 只能选择一种类型，可选择 1001:市场类 1002:地域类 1003:概念类
-func GetSymbolsSector(gmapi string,
-	symbols string, sector_type string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetSymbolsSector(ctx context.Context,
+	symbols string, sector_type string) ([]map[string]any, error) {
 	if sector_type == "" {
-		return nil, fmt.Errorf("sector_code为必须字段")
+		return nil, invalidParam("sector_code为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_symbol_sector", gmapi)
+	url := fmt.Sprintf("%s/get_symbol_sector", c.BaseURL)
 	params := map[string]string{
 		"symbols":     symbols,
 		"sector_type": sector_type,
 		"split":       "true",
 	}
 
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_symbol_sector()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_symbol_sector())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_symbol_sector()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_symbol_sector())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1243,14 +1133,13 @@ func GetSymbolsSector(gmapi string,
 //   - sdate: 开始日期, 格式: "2021-01-01"
 //   - edate: 结束日期, 格式: "2021-01-01"
 //   - symbol: 股票代码, 如 "SHSE.601088"
-func GetDividend(gmapi string,
-	symbol string, sdate string, edate string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetDividend(ctx context.Context,
+	symbol string, sdate string, edate string) ([]map[string]any, error) {
 	if symbol == "" {
-		return nil, fmt.Errorf("Symbol为必须字段")
+		return nil, invalidParam("Symbol为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_dividend", gmapi)
+	url := fmt.Sprintf("%s/get_dividend", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"split":  "true",
@@ -1262,19 +1151,19 @@ func GetDividend(gmapi string,
 	if edate != "" {
 		params["edate"] = edate
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_dividend()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_dividend())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_dividend()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_dividend())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1285,14 +1174,13 @@ func GetDividend(gmapi string,
 //   - sdate: 开始日期, 格式: "2021-01-01"
 //   - edate: 结束日期, 格式: "2021-01-01"
 //   - symbol: 股票代码, 如 "SHSE.601088"
-func GetRation(gmapi string,
-	symbol string, sdate string, edate string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetRation(ctx context.Context,
+	symbol string, sdate string, edate string) ([]map[string]any, error) {
 	if symbol == "" {
-		return nil, fmt.Errorf("Symbol为必须字段")
+		return nil, invalidParam("Symbol为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_ration", gmapi)
+	url := fmt.Sprintf("%s/get_ration", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"split":  "true",
@@ -1304,19 +1192,19 @@ func GetRation(gmapi string,
 	if edate != "" {
 		params["edate"] = edate
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_ration()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_ration())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_ration()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_ration())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1327,14 +1215,13 @@ func GetRation(gmapi string,
 //   - sdate: 开始日期, 格式: "2021-01-01"
 //   - edate: 结束日期, 格式: "2021-01-01"
 //   - symbol: 股票代码, 如 "SHSE.601088"
-func GetShareholderNum(gmapi string,
-	symbol string, sdate string, edate string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetShareholderNum(ctx context.Context,
+	symbol string, sdate string, edate string) ([]map[string]any, error) {
 	if symbol == "" {
-		return nil, fmt.Errorf("Symbol为必须字段")
+		return nil, invalidParam("Symbol为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_shareholder_num", gmapi)
+	url := fmt.Sprintf("%s/get_shareholder_num", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"split":  "true",
@@ -1346,19 +1233,19 @@ func GetShareholderNum(gmapi string,
 	if edate != "" {
 		params["edate"] = edate
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_shareholder_num()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_shareholder_num())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_shareholder_num()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_shareholder_num())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1369,14 +1256,13 @@ func GetShareholderNum(gmapi string,
 //   - sdate: 开始日期, 格式: "2021-01-01"
 //   - edate: 结束日期, 格式: "2021-01-01"
 //   - symbol: 股票代码, 如 "SHSE.601088"
-func GetShareChange(gmapi string,
-	symbol string, sdate string, edate string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetShareChange(ctx context.Context,
+	symbol string, sdate string, edate string) ([]map[string]any, error) {
 	if symbol == "" {
-		return nil, fmt.Errorf("Symbol为必须字段")
+		return nil, invalidParam("Symbol为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_share_change", gmapi)
+	url := fmt.Sprintf("%s/get_share_change", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"split":  "true",
@@ -1388,19 +1274,19 @@ func GetShareChange(gmapi string,
 	if edate != "" {
 		params["edate"] = edate
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_share_change()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_share_change())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_share_change()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_share_change())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1412,14 +1298,13 @@ func GetShareChange(gmapi string,
 //   - edate: 结束日期, 格式: "2021-01-01"
 //   - bdate: 前复权的基准日，%Y-%m-%d 格式，默认""表示最新时间
 //   - symbol: 股票代码, 如 "SHSE.601088"
-func GetAdjFactor(gmapi string,
-	symbol string, sdate string, edate string, bdate string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetAdjFactor(ctx context.Context,
+	symbol string, sdate string, edate string, bdate string) ([]map[string]any, error) {
 	if symbol == "" {
-		return nil, fmt.Errorf("Symbol为必须字段")
+		return nil, invalidParam("Symbol为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_adj_factor", gmapi)
+	url := fmt.Sprintf("%s/get_adj_factor", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"split":  "true",
@@ -1434,19 +1319,19 @@ func GetAdjFactor(gmapi string,
 	if bdate != "" {
 		params["bdate"] = bdate
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_adj_factor()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_adj_factor())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_adj_factor()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_adj_factor())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1458,14 +1343,13 @@ func GetAdjFactor(gmapi string,
 //   - edate: 结束日期, 格式: "2021-01-01"
 //   - tradable_holder: False-十大股东（默认）、True-十大流通股东 默认False表示十大股东
 //   - symbol: 股票代码, 如 "SHSE.601088"
-func GetTopShareholder(gmapi string,
-	symbol string, sdate string, edate string, tradable_holder string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetTopShareholder(ctx context.Context,
+	symbol string, sdate string, edate string, tradable_holder string) ([]map[string]any, error) {
 	if symbol == "" {
-		return nil, fmt.Errorf("Symbol为必须字段")
+		return nil, invalidParam("Symbol为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_top_shareholder", gmapi)
+	url := fmt.Sprintf("%s/get_top_shareholder", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"split":  "true",
@@ -1480,19 +1364,19 @@ func GetTopShareholder(gmapi string,
 	if tradable_holder != "" {
 		params["tradable_holder"] = tradable_holder
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_top_shareholder()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_top_shareholder())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_top_shareholder()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_top_shareholder())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1504,14 +1388,13 @@ func GetTopShareholder(gmapi string,
 //   - change_types: 输入异动类型，可输入多个. 采用 str 格式时，多个异动类型必须用英文逗号分割，如：'106,107'; 采用 list 格式时，多个异动类型示例：['106','107']； 默认None表示所有异动类型。
 //   - trade_date: 交易日期，支持str格式（%Y-%m-%d 格式）和 datetime.date 格式，默认None表示最新交易日期。
 //   - fields: 指定需要返回的字段，如有多个字段，中间用英文逗号分隔，默认 None 返回所有字段。
-func GetAbnorChangeStocks(gmapi string,
-	symbols string, change_types string, trade_date string, fields string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetAbnorChangeStocks(ctx context.Context,
+	symbols string, change_types string, trade_date string, fields string) ([]map[string]any, error) {
 	// if symbols == "" {
-	// 	return nil, fmt.Errorf("Symbols为必须字段")
+	// 	return nil, invalidParam("Symbols为必须字段")
 	// }
 
-	url := fmt.Sprintf("%s/abnor_change_stocks", gmapi)
+	url := fmt.Sprintf("%s/abnor_change_stocks", c.BaseURL)
 	params := map[string]string{
 		"split": "true",
 	}
@@ -1528,19 +1411,19 @@ func GetAbnorChangeStocks(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(abnor_change_stocks()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(abnor_change_stocks())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(abnor_change_stocks()): %v", transformErr)
+		return nil, decodeError("转换数据失败(abnor_change_stocks())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1552,14 +1435,13 @@ func GetAbnorChangeStocks(gmapi string,
 //   - change_types: 输入异动类型，可输入多个. 采用 str 格式时，多个异动类型必须用英文逗号分割，如：'106,107'; 采用 list 格式时，多个异动类型示例：['106','107']； 默认None表示所有异动类型。
 //   - trade_date: 交易日期，支持str格式（%Y-%m-%d 格式）和 datetime.date 格式，默认None表示最新交易日期。
 //   - fields: 指定需要返回的字段，如有多个字段，中间用英文逗号分隔，默认 None 返回所有字段。
-func GetAbnorChangeDetail(gmapi string,
-	symbols string, change_types string, trade_date string, fields string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetAbnorChangeDetail(ctx context.Context,
+	symbols string, change_types string, trade_date string, fields string) ([]map[string]any, error) {
 	// if symbols == "" {
-	// 	return nil, fmt.Errorf("Symbols为必须字段")
+	// 	return nil, invalidParam("Symbols为必须字段")
 	// }
 
-	url := fmt.Sprintf("%s/abnor_change_detail", gmapi)
+	url := fmt.Sprintf("%s/abnor_change_detail", c.BaseURL)
 	params := map[string]string{
 		"split": "true",
 	}
@@ -1576,19 +1458,19 @@ func GetAbnorChangeDetail(gmapi string,
 	if fields != "" {
 		params["fields"] = fields
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(abnor_change_detail()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(abnor_change_detail())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(abnor_change_detail()): %v", transformErr)
+		return nil, decodeError("转换数据失败(abnor_change_detail())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1598,14 +1480,13 @@ func GetAbnorChangeDetail(gmapi string,
 // 输入参数：
 //   - symbols: 输入标的代码，可输入多个. 采用 str 格式时，多个标的代码必须用英文逗号分割，
 //   - trade_date: 交易日期，支持str格式（%Y-%m-%d 格式）和 datetime.date 格式，默认None表示最新交易日期。
-func GetHKInstHoldingInfo(gmapi string,
-	symbols string, trade_date string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetHKInstHoldingInfo(ctx context.Context,
+	symbols string, trade_date string) ([]map[string]any, error) {
 	// if symbols == "" {
-	// 	return nil, fmt.Errorf("Symbols为必须字段")
+	// 	return nil, invalidParam("Symbols为必须字段")
 	// }
 
-	url := fmt.Sprintf("%s/hk_inst_holding_info", gmapi)
+	url := fmt.Sprintf("%s/hk_inst_holding_info", c.BaseURL)
 	params := map[string]string{
 		"split": "true",
 	}
@@ -1616,19 +1497,19 @@ func GetHKInstHoldingInfo(gmapi string,
 	if trade_date != "" {
 		params["trade_date"] = trade_date
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(hk_inst_holding_info()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(hk_inst_holding_info())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(hk_inst_holding_info()): %v", transformErr)
+		return nil, decodeError("转换数据失败(hk_inst_holding_info())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1639,14 +1520,13 @@ func GetHKInstHoldingInfo(gmapi string,
 // 输入参数：
 //   - symbols: 输入标的代码，可输入多个. 采用 str 格式时，多个标的代码必须用英文逗号分割，
 //   - trade_date: 交易日期，支持str格式（%Y-%m-%d 格式）和 datetime.date 格式，默认None表示最新交易日期。
-func GetHKInstHoldingDetailInfo(gmapi string,
-	symbols string, trade_date string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetHKInstHoldingDetailInfo(ctx context.Context,
+	symbols string, trade_date string) ([]map[string]any, error) {
 	// if symbols == "" {
-	// 	return nil, fmt.Errorf("Symbols为必须字段")
+	// 	return nil, invalidParam("Symbols为必须字段")
 	// }
 
-	url := fmt.Sprintf("%s/hk_inst_holding_detail_info", gmapi)
+	url := fmt.Sprintf("%s/hk_inst_holding_detail_info", c.BaseURL)
 	params := map[string]string{
 		"split": "true",
 	}
@@ -1657,19 +1537,19 @@ func GetHKInstHoldingDetailInfo(gmapi string,
 	if trade_date != "" {
 		params["trade_date"] = trade_date
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(hk_inst_holding_detail_info()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(hk_inst_holding_detail_info())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(hk_inst_holding_detail_info()): %v", transformErr)
+		return nil, decodeError("转换数据失败(hk_inst_holding_detail_info())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1680,11 +1560,10 @@ func GetHKInstHoldingDetailInfo(gmapi string,
 // 输入参数：
 //   - types: 类型，可输入多个，采用 str 格式时，多个类型必须用英文逗号分割，如：'SZ,SHHK' 采用 list 格式时，多个标的代码示例：['SZ', 'SHHK']，类型包括：SH - 沪股通 ，SHHK - 沪港股通 ，SZ - 深股通 ，SZHK - 深港股通，NF - 北向资金（沪股通+深股通），默认 None 为全部北向资金。
 //   - trade_date: 交易日期，支持str格式（%Y-%m-%d 格式）和 datetime.date 格式，默认None表示最新交易日期。
-func GetSHSZHKActiveStockTop10Info(gmapi string,
-	types string, trade_date string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetSHSZHKActiveStockTop10Info(ctx context.Context,
+	types string, trade_date string) ([]map[string]any, error) {
 
-	url := fmt.Sprintf("%s/active_stock_top10_shszhk_info", gmapi)
+	url := fmt.Sprintf("%s/active_stock_top10_shszhk_info", c.BaseURL)
 	params := map[string]string{
 		"split": "true",
 	}
@@ -1695,19 +1574,19 @@ func GetSHSZHKActiveStockTop10Info(gmapi string,
 	if trade_date != "" {
 		params["trade_date"] = trade_date
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(active_stock_top10_shszhk_info()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(active_stock_top10_shszhk_info())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(active_stock_top10_shszhk_info()): %v", transformErr)
+		return nil, decodeError("转换数据失败(active_stock_top10_shszhk_info())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1720,11 +1599,10 @@ func GetSHSZHKActiveStockTop10Info(gmapi string,
 //   - sdate: 开始日期，支持str格式（%Y-%m-%d 格式）和 datetime.date 格式，默认None表示最新交易日期。
 //   - edate: 开始日期，支持str格式（%Y-%m-%d 格式）和 datetime.date 格式，默认None表示最新交易日期。
 //   - count: 数量(正整数)，不能与start_date同时使用，否则返回报错；与 end_date 同时使用时，表示获取 end_date 前 count 个交易日的数据(包含 end_date 当日)；默认为 None ，不使用该字段。
-func GetSHSZHKQuotaInfo(gmapi string,
-	types string, sdate string, edate string, count string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetSHSZHKQuotaInfo(ctx context.Context,
+	types string, sdate string, edate string, count string) ([]map[string]any, error) {
 
-	url := fmt.Sprintf("%s/quota_shszhk_infos", gmapi)
+	url := fmt.Sprintf("%s/quota_shszhk_infos", c.BaseURL)
 	params := map[string]string{
 		"split": "true",
 	}
@@ -1741,19 +1619,19 @@ func GetSHSZHKQuotaInfo(gmapi string,
 	if count != "" {
 		params["count"] = count
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(quota_shszhk_infos()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(quota_shszhk_infos())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(quota_shszhk_infos()): %v", transformErr)
+		return nil, decodeError("转换数据失败(quota_shszhk_infos())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1763,14 +1641,12 @@ func GetSHSZHKQuotaInfo(gmapi string,
 //
 // 输入参数：
 //   - etf: 必填，只能输入一个 ETF 的symbol，如：'SZSE.159919'
-func GetFndConstituents(gmapi string,
-	etf string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetFndConstituents(ctx context.Context, etf string) ([]map[string]any, error) {
 	if etf == "" {
 		return nil, fmt.Errorf("etf为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_etf_constituents", gmapi)
+	url := fmt.Sprintf("%s/get_etf_constituents", c.BaseURL)
 	params := map[string]string{
 		"split": "true",
 	}
@@ -1779,19 +1655,19 @@ func GetFndConstituents(gmapi string,
 	// if etf != "" {
 	// 	params["etf"] = etf
 	// }
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_etf_constituents()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_etf_constituents())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_etf_constituents()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_etf_constituents())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1805,14 +1681,13 @@ func GetFndConstituents(gmapi string,
 //   - portfolio_type: 必填，可选以下其中一种组合： 'stk' - 股票投资组合 'bnd' - 债券投资组合 'fnd' - 基金投资组合
 //   - sdate: 开始时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
 //   - edate: 结束时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
-func GetFndPortfolio(gmapi string,
-	fund string, report_type string, portfolio_type string, sdate string, edate string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetFndPortfolio(ctx context.Context,
+	fund string, report_type string, portfolio_type string, sdate string, edate string) ([]map[string]any, error) {
 	if fund == "" {
-		return nil, fmt.Errorf("fund为必须字段")
+		return nil, invalidParam("fund为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_portfolio", gmapi)
+	url := fmt.Sprintf("%s/get_portfolio", c.BaseURL)
 	params := map[string]string{
 		"fund":  fund,
 		"split": "true",
@@ -1838,19 +1713,19 @@ func GetFndPortfolio(gmapi string,
 	if edate != "" {
 		params["edate"] = edate
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_portfolio()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_portfolio())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_portfolio()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_portfolio())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1862,14 +1737,13 @@ func GetFndPortfolio(gmapi string,
 //   - fund: 必填，只能输入一个基金的symbol，如：'SZSE.161133'
 //   - sdate: 开始时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
 //   - edate: 结束时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
-func GetFndNetValue(gmapi string,
-	fund string, sdate string, edate string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetFndNetValue(ctx context.Context,
+	fund string, sdate string, edate string) ([]map[string]any, error) {
 	if fund == "" {
-		return nil, fmt.Errorf("fund为必须字段")
+		return nil, invalidParam("fund为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_net_value", gmapi)
+	url := fmt.Sprintf("%s/get_net_value", c.BaseURL)
 	params := map[string]string{
 		"fund":  fund,
 		"split": "true",
@@ -1881,19 +1755,19 @@ func GetFndNetValue(gmapi string,
 	if edate != "" {
 		params["edate"] = edate
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_net_value()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_net_value())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_net_value()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_net_value())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1906,14 +1780,13 @@ func GetFndNetValue(gmapi string,
 //   - sdate: 开始时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
 //   - edate: 结束时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
 //   - bdate: 前复权的基准日，%Y-%m-%d 格式， 默认""表示最新时间
-func GetFndAdjFactor(gmapi string,
-	fund string, sdate string, edate string, bdate string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetFndAdjFactor(ctx context.Context,
+	fund string, sdate string, edate string, bdate string) ([]map[string]any, error) {
 	if fund == "" {
-		return nil, fmt.Errorf("fund为必须字段")
+		return nil, invalidParam("fund为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_adj_factor_fnd", gmapi)
+	url := fmt.Sprintf("%s/get_adj_factor_fnd", c.BaseURL)
 	params := map[string]string{
 		"fund":  fund,
 		"split": "true",
@@ -1928,19 +1801,19 @@ func GetFndAdjFactor(gmapi string,
 	if bdate != "" {
 		params["bdate"] = bdate
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_adj_factor_fnd()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_adj_factor_fnd())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_adj_factor_fnd()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_adj_factor_fnd())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1952,14 +1825,13 @@ func GetFndAdjFactor(gmapi string,
 //   - fund: 必填，只能输入一个基金的symbol，如：'SZSE.510880'
 //   - sdate: 开始时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
 //   - edate: 结束时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
-func GetFndDividend(gmapi string,
-	fund string, sdate string, edate string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetFndDividend(ctx context.Context,
+	fund string, sdate string, edate string) ([]map[string]any, error) {
 	if fund == "" {
-		return nil, fmt.Errorf("fund为必须字段")
+		return nil, invalidParam("fund为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_dividend_fnd", gmapi)
+	url := fmt.Sprintf("%s/get_dividend_fnd", c.BaseURL)
 	params := map[string]string{
 		"fund":  fund,
 		"split": "true",
@@ -1971,19 +1843,19 @@ func GetFndDividend(gmapi string,
 	if edate != "" {
 		params["edate"] = edate
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_dividend_fnd()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_dividend_fnd())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_dividend_fnd()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_dividend_fnd())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -1995,14 +1867,13 @@ func GetFndDividend(gmapi string,
 //   - fund: 必填，只能输入一个基金的symbol，如：'SZSE.161725'
 //   - sdate: 开始时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
 //   - edate: 结束时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
-func GetFndSplit(gmapi string,
-	fund string, sdate string, edate string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetFndSplit(ctx context.Context,
+	fund string, sdate string, edate string) ([]map[string]any, error) {
 	if fund == "" {
-		return nil, fmt.Errorf("fund为必须字段")
+		return nil, invalidParam("fund为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_split_fnd", gmapi)
+	url := fmt.Sprintf("%s/get_split_fnd", c.BaseURL)
 	params := map[string]string{
 		"fund":  fund,
 		"split": "true",
@@ -2014,19 +1885,19 @@ func GetFndSplit(gmapi string,
 	if edate != "" {
 		params["edate"] = edate
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_split_fnd()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_split_fnd())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_split_fnd()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_split_fnd())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -2036,14 +1907,13 @@ func GetFndSplit(gmapi string,
 // 输入参数：
 //   - source: 'zjh2012'- 证监会行业分类 2012（默认）， 'sw2021'- 申万行业分类 2021
 //   - level: 1 - 一级行业（默认），2 - 二级行业，3 - 三级行业
-func GetIndustryCategory(gmapi string,
-	source string, level string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetIndustryCategory(ctx context.Context,
+	source string, level string) ([]map[string]any, error) {
 	// if fund == "" {
-	// 	return nil, fmt.Errorf("fund为必须字段")
+	// 	return nil, invalidParam("fund为必须字段")
 	// }
 
-	url := fmt.Sprintf("%s/get_industry_category", gmapi)
+	url := fmt.Sprintf("%s/get_industry_category", c.BaseURL)
 	params := map[string]string{
 		"split": "true",
 	}
@@ -2054,19 +1924,19 @@ func GetIndustryCategory(gmapi string,
 	if source != "" {
 		params["source"] = source
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_industry_category()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_industry_category())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_industry_category()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_industry_category())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -2076,14 +1946,13 @@ func GetIndustryCategory(gmapi string,
 // 输入参数：
 //   - industry_code: 需要查询成分股的行业代码，可通过stk_get_industry_category获取
 //   - date: 查询行业成分股的指定日期，%Y-%m-%d 格式，默认""表示最新时间
-func GetIndustryConstituents(gmapi string,
-	industry_code string, date string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetIndustryConstituents(ctx context.Context,
+	industry_code string, date string) ([]map[string]any, error) {
 	if industry_code == "" {
 		return nil, fmt.Errorf("industry_code为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_industry_constituents", gmapi)
+	url := fmt.Sprintf("%s/get_industry_constituents", c.BaseURL)
 	params := map[string]string{
 		"industry_code": industry_code,
 		"split":         "true",
@@ -2095,19 +1964,19 @@ func GetIndustryConstituents(gmapi string,
 	// if source != "" {
 	// 	params["source"] = source
 	// }
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_industry_constituents()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_industry_constituents())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_industry_constituents()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_industry_constituents())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -2118,14 +1987,13 @@ func GetIndustryConstituents(gmapi string,
 //   - source: 'zjh2012'- 证监会行业分类 2012（默认）， 'sw2021'- 申万行业分类 2021
 //   - level: 1 - 一级行业（默认），2 - 二级行业，3 - 三级行业
 //   - date: 查询行业成分股的指定日期，%Y-%m-%d 格式，默认""表示最新时间
-func GetSymbolIndustry(gmapi string,
-	symbols string, source string, level string, date string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetSymbolIndustry(ctx context.Context,
+	symbols string, source string, level string, date string) ([]map[string]any, error) {
 	if symbols == "" {
-		return nil, fmt.Errorf("symbols为必须字段")
+		return nil, invalidParam("symbols为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_symbol_industry", gmapi)
+	url := fmt.Sprintf("%s/get_symbol_industry", c.BaseURL)
 	params := map[string]string{
 		"symbols": symbols,
 		"split":   "true",
@@ -2140,19 +2008,19 @@ func GetSymbolIndustry(gmapi string,
 	if level != "" {
 		params["level"] = level
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_symbol_industry()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_symbol_industry())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_symbol_industry()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_symbol_industry())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -2161,14 +2029,13 @@ func GetSymbolIndustry(gmapi string,
 // 查询指数成分股
 // 输入参数：
 //   - trade_date: 查询行业成分股的指定日期，%Y-%m-%d 格式，默认""表示最新时间
-func GetIndexConstituents(gmapi string,
-	index string, trade_date string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetIndexConstituents(ctx context.Context,
+	index string, trade_date string) ([]map[string]any, error) {
 	if index == "" {
 		return nil, fmt.Errorf("index为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_index_constituents", gmapi)
+	url := fmt.Sprintf("%s/get_index_constituents", c.BaseURL)
 	params := map[string]string{
 		"index": index,
 		"split": "true",
@@ -2177,51 +2044,49 @@ func GetIndexConstituents(gmapi string,
 	if trade_date != "" {
 		params["trade_date"] = trade_date
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_index_constituents()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_index_constituents())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_index_constituents()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_index_constituents())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
 }
 
 // 查询股票的所属行业
-func GetTradingSessions(gmapi string,
-	symbols string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetTradingSessions(ctx context.Context, symbols string) ([]map[string]any, error) {
 	if symbols == "" {
-		return nil, fmt.Errorf("symbols为必须字段")
+		return nil, invalidParam("symbols为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_trading_sessions", gmapi)
+	url := fmt.Sprintf("%s/get_trading_sessions", c.BaseURL)
 	params := map[string]string{
 		"symbols": symbols,
 		"split":   "true",
 	}
 
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_trading_sessions()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_trading_sessions())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_trading_sessions()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_trading_sessions())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -2234,11 +2099,10 @@ func GetTradingSessions(gmapi string,
 //
 // 返回值：
 //   - 市场个股列表
-func GetMarketInfo(gmapi string,
-	symbols string, sec string, exchange string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetMarketInfo(ctx context.Context,
+	symbols string, sec string, exchange string) ([]map[string]any, error) {
 
-	url := fmt.Sprintf("%s/get_infos", gmapi)
+	url := fmt.Sprintf("%s/get_infos", c.BaseURL)
 	params := map[string]string{
 		"split": "true",
 	}
@@ -2253,19 +2117,19 @@ func GetMarketInfo(gmapi string,
 		params["sec"] = sec
 	}
 
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(GetMarketInfo()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(GetMarketInfo())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(GetMarketInfo()): %v", transformErr)
+		return nil, decodeError("转换数据失败(GetMarketInfo())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -2278,11 +2142,10 @@ func GetMarketInfo(gmapi string,
 //
 // 返回值：
 //   - 市场个股列表
-func GetSymbolsInfo(gmapi string,
-	symbols string, sec string, exchange string, trade_date string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetSymbolsInfo(ctx context.Context,
+	symbols string, sec string, exchange string, trade_date string) ([]map[string]any, error) {
 
-	url := fmt.Sprintf("%s/get_symbols", gmapi)
+	url := fmt.Sprintf("%s/get_symbols", c.BaseURL)
 	params := map[string]string{
 		"split": "true",
 	}
@@ -2300,19 +2163,19 @@ func GetSymbolsInfo(gmapi string,
 		params["trade_date"] = trade_date
 	}
 
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(GetSymbolsInfo()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(GetSymbolsInfo())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(GetSymbolsInfo()): %v", transformErr)
+		return nil, decodeError("转换数据失败(GetSymbolsInfo())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
@@ -2323,14 +2186,13 @@ func GetSymbolsInfo(gmapi string,
 //   - sdate: 开始日期, 格式: "2021-01-01"
 //   - edate: 结束日期, 格式: "2021-01-01"
 //   - symbol: 股票代码, 如 "SHSE.601088"
-func GetHistoryInfo(gmapi string,
-	symbol string, sdate string, edate string,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetHistoryInfo(ctx context.Context,
+	symbol string, sdate string, edate string) ([]map[string]any, error) {
 	if symbol == "" {
-		return nil, fmt.Errorf("Symbol为必须字段")
+		return nil, invalidParam("Symbol为必须字段")
 	}
 
-	url := fmt.Sprintf("%s/get_his_symbol", gmapi)
+	url := fmt.Sprintf("%s/get_his_symbol", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"split":  "true",
@@ -2342,31 +2204,29 @@ func GetHistoryInfo(gmapi string,
 	if edate != "" {
 		params["edate"] = edate
 	}
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(resp, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(get_his_symbol()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(get_his_symbol())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(get_his_symbol()): %v", transformErr)
+		return nil, decodeError("转换数据失败(get_his_symbol())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, false), nil
 }
 
 // 获取K线行情数据
-func GetKbarsHisByte(gmapi string,
-	symbols string, tag string,
-	sdate string, edate string,
-	timeoutSeconds int) ([]byte, error) {
+func (c *Client) GetKbarsHisByte(ctx context.Context,
+	symbols string, tag string, sdate string, edate string) ([]byte, error) {
 
-	url := fmt.Sprintf("%s/get_his", gmapi)
+	url := fmt.Sprintf("%s/get_his", c.BaseURL)
 	params := map[string]string{
 		"symbols": symbols,
 		"tag":     tag,
@@ -2374,7 +2234,7 @@ func GetKbarsHisByte(gmapi string,
 		"edate":   edate,
 	}
 
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		// fmt.Printf("获取数据失败: %s\n", err)
 		return nil, err
@@ -2384,12 +2244,10 @@ func GetKbarsHisByte(gmapi string,
 }
 
 // 获取K线行情数据
-func GetKbarsHis2Byte(gmapi string,
-	symbols string, tag string,
-	stime string, etime string,
-	timeoutSeconds int) ([]byte, error) {
+func (c *Client) GetKbarsHis2Byte(ctx context.Context,
+	symbols string, tag string, stime string, etime string) ([]byte, error) {
 
-	url := fmt.Sprintf("%s/get_his2", gmapi)
+	url := fmt.Sprintf("%s/get_his2", c.BaseURL)
 	params := map[string]string{
 		"symbols": symbols,
 		"tag":     tag,
@@ -2397,7 +2255,7 @@ func GetKbarsHis2Byte(gmapi string,
 		"etime":   etime,
 	}
 
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		// fmt.Printf("获取数据失败: %s\n", err)
 		return nil, err
@@ -2407,60 +2265,54 @@ func GetKbarsHis2Byte(gmapi string,
 }
 
 // 获取K线行情数据：输入参数为日期
-func GetKbarsHis(gmapi string,
-	symbols string, tag string,
-	sdate string, edate string, istimestamp bool,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetKbarsHis(ctx context.Context,
+	symbols string, tag string, sdate string, edate string, istimestamp bool) ([]map[string]any, error) {
 
-	rawData, err := GetKbarsHisByte(gmapi, symbols, tag, sdate, edate, timeoutSeconds)
+	rawData, err := c.GetKbarsHisByte(ctx, symbols, tag, sdate, edate)
 	if err != nil {
-		return nil, fmt.Errorf("获取数据失败(GetKbarsHisByte()): %v", err)
+		return nil, fmt.Errorf("获取数据失败(GetKbarsHisByte()): %w", err)
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(rawData, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(GetKbarsHisByte()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(GetKbarsHisByte())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(GetKbarsHisByte()): %v", transformErr)
+		return nil, decodeError("转换数据失败(GetKbarsHisByte())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, istimestamp), nil
 }
 
 // 获取K线行情数据: 输入参数为时间
-func GetKbarsHis2(gmapi string,
-	symbols string, tag string,
-	stime string, etime string, istimestamp bool,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetKbarsHis2(ctx context.Context,
+	symbols string, tag string, stime string, etime string, istimestamp bool) ([]map[string]any, error) {
 
-	rawData, err := GetKbarsHis2Byte(gmapi, symbols, tag, stime, etime, timeoutSeconds)
+	rawData, err := c.GetKbarsHis2Byte(ctx, symbols, tag, stime, etime)
 	if err != nil {
-		return nil, fmt.Errorf("获取数据失败(GetKbarsHis2Byte()): %v", err)
+		return nil, fmt.Errorf("获取数据失败(GetKbarsHis2Byte()): %w", err)
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(rawData, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(GetKbarsHis2Byte()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(GetKbarsHis2Byte())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(GetKbarsHis2()): %v", transformErr)
+		return nil, decodeError("转换数据失败(GetKbarsHis2())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, istimestamp), nil
 }
 
 // 获取K线行情数据
-func GetKbarsHisNByte(gmapi string,
-	symbol string, tag string,
-	count string, edate string,
-	timeoutSeconds int) ([]byte, error) {
+func (c *Client) GetKbarsHisNByte(ctx context.Context,
+	symbol string, tag string, count string, edate string) ([]byte, error) {
 
-	url := fmt.Sprintf("%s/get_his_n", gmapi)
+	url := fmt.Sprintf("%s/get_his_n", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"tag":    tag,
@@ -2470,7 +2322,7 @@ func GetKbarsHisNByte(gmapi string,
 		params["count"] = count
 	}
 
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		// fmt.Printf("获取数据失败: %s\n", err)
 		return nil, err
@@ -2480,12 +2332,10 @@ func GetKbarsHisNByte(gmapi string,
 }
 
 // 获取K线行情数据
-func GetKbarsHis2NByte(gmapi string,
-	symbol string, tag string,
-	count string, etime string,
-	timeoutSeconds int) ([]byte, error) {
+func (c *Client) GetKbarsHis2NByte(ctx context.Context,
+	symbol string, tag string, count string, etime string) ([]byte, error) {
 
-	url := fmt.Sprintf("%s/get_his2_n", gmapi)
+	url := fmt.Sprintf("%s/get_his2_n", c.BaseURL)
 	params := map[string]string{
 		"symbol": symbol,
 		"tag":    tag,
@@ -2495,7 +2345,7 @@ func GetKbarsHis2NByte(gmapi string,
 		params["count"] = count
 	}
 
-	resp, err := fetchURLData(url, time.Duration(timeoutSeconds)*time.Second, params)
+	resp, err := c.fetchURLData(ctx, url, params)
 	if err != nil {
 		// fmt.Printf("获取数据失败: %s\n", err)
 		return nil, err
@@ -2505,48 +2355,44 @@ func GetKbarsHis2NByte(gmapi string,
 }
 
 // 获取K线行情数据：输入参数为日期
-func GetKbarsHisN(gmapi string,
-	symbol string, tag string,
-	count string, edate string, istimestamp bool,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetKbarsHisN(ctx context.Context,
+	symbol string, tag string, count string, edate string, istimestamp bool) ([]map[string]any, error) {
 
-	rawData, err := GetKbarsHisNByte(gmapi, symbol, tag, count, edate, timeoutSeconds)
+	rawData, err := c.GetKbarsHisNByte(ctx, symbol, tag, count, edate)
 	if err != nil {
-		return nil, fmt.Errorf("获取数据失败(GetKbarsHisNByte()): %v", err)
+		return nil, fmt.Errorf("获取数据失败(GetKbarsHisNByte()): %w", err)
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(rawData, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(GetKbarsHisN()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(GetKbarsHisN())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(GetKbarsHisN()): %v", transformErr)
+		return nil, decodeError("转换数据失败(GetKbarsHisN())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, istimestamp), nil
 }
 
 // 获取K线行情数据：输入参数为时间
-func GetKbarsHis2N(gmapi string,
-	symbol string, tag string,
-	count string, etime string, istimestamp bool,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetKbarsHis2N(ctx context.Context,
+	symbol string, tag string, count string, etime string, istimestamp bool) ([]map[string]any, error) {
 
-	rawData, err := GetKbarsHis2NByte(gmapi, symbol, tag, count, etime, timeoutSeconds)
+	rawData, err := c.GetKbarsHis2NByte(ctx, symbol, tag, count, etime)
 	if err != nil {
-		return nil, fmt.Errorf("获取数据失败(GetKbarsHis2NByte()): %v", err)
+		return nil, fmt.Errorf("获取数据失败(GetKbarsHis2NByte()): %w", err)
 	}
 
 	var rcd RawColData
 	if unmarshalErr := json.Unmarshal(rawData, &rcd); unmarshalErr != nil {
-		return nil, fmt.Errorf("解析 JSON 数据失败(GetKbarsHis2NByte()): %v", unmarshalErr)
+		return nil, decodeError("解析 JSON 数据失败(GetKbarsHis2NByte())", unmarshalErr)
 	}
 
 	records, transformErr := rcd.ToRecords()
 	if transformErr != nil {
-		return nil, fmt.Errorf("转换数据失败(GetKbarsHis2N()): %v", transformErr)
+		return nil, decodeError("转换数据失败(GetKbarsHis2N())", transformErr)
 	}
 
 	return ConvertEob2Timestamp(records, istimestamp), nil
@@ -2607,35 +2453,6 @@ func getFilePathMonth(symbol string, year int, month int) string {
 // 	return data, nil
 // }
 
-// downloadAndReadData 从指定URL下载数据并返回内容
-func downloadAndReadData(url string) ([]byte, error) {
-	// 发送HTTP GET请求
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("请求失败: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// 检查HTTP状态码
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP请求失败，状态码: %d", resp.StatusCode)
-	}
-
-	// 使用github.com/ulikunitz/xz库创建XZ解压器
-	reader, err := xz.NewReader(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("创建XZ解压器失败: %w", err)
-	}
-
-	// 读取解压后的数据
-	data, err := io.ReadAll(reader)
-	if err != nil {
-		return nil, fmt.Errorf("读取数据失败: %w", err)
-	}
-
-	return data, nil
-}
-
 // parseValue attempts to convert a string value to its appropriate Go type.
 func parseValue(s string) any {
 	// Try parsing as integer
@@ -2669,11 +2486,11 @@ func CsvToJSON(csvData []byte) ([]byte, error) {
 	// 读取所有CSV记录
 	records, err := reader.ReadAll()
 	if err != nil {
-		return nil, fmt.Errorf("解析CSV失败: %w", err)
+		return nil, decodeError("解析CSV失败", err)
 	}
 
 	if len(records) == 0 {
-		return nil, fmt.Errorf("CSV文件为空")
+		return nil, fmt.Errorf("%w: CSV文件为空", ErrNoData)
 	}
 
 	// 第一行作为表头
@@ -2708,11 +2525,11 @@ func CSVToRecords(csvData []byte, istimestamp bool, tskey string) ([]map[string]
 	// 读取所有CSV记录
 	records, err := reader.ReadAll()
 	if err != nil {
-		return nil, fmt.Errorf("解析CSV失败: %w", err)
+		return nil, decodeError("解析CSV失败", err)
 	}
 
 	if len(records) == 0 {
-		return nil, fmt.Errorf("CSV文件为空")
+		return nil, fmt.Errorf("%w: CSV文件为空", ErrNoData)
 	}
 
 	// 第一行作为表头
@@ -2766,9 +2583,10 @@ func CSVToJson(csvData []byte, istimestamp bool, tskey string) ([]byte, error) {
 }
 
 // downloadAndConvertToJSON 从URL下载CSV数据并转换为JSON
-func DownloadAndConvertToJSON(url string, istimestamp bool, tskey string) ([]byte, error) {
+func (c *Client) DownloadAndConvertToJSON(ctx context.Context,
+	url string, istimestamp bool, tskey string) ([]byte, error) {
 	// 下载并读取数据
-	csvData, err := downloadAndReadData(url)
+	csvData, err := c.downloadAndReadData(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -2784,85 +2602,43 @@ func DownloadAndConvertToJSON(url string, istimestamp bool, tskey string) ([]byt
 }
 
 // 获取Csv.xz按月行情数据
-func GetCSVMonthJson(gmcsv string,
-	symbol string,
-	month int, year int, istimestamp bool,
-	timeoutSeconds int) ([]byte, error) {
-
-	url := fmt.Sprintf("%s/download/", gmcsv)
+func (c *Client) GetCSVMonthJson(ctx context.Context,
+	symbol string, month int, year int, istimestamp bool) ([]byte, error) {
 
-	fpath := getFilePathMonth(symbol, year, month)
-	// fmt.Println(fpath)
-
-	resp, err := DownloadAndConvertToJSON(url+fpath, istimestamp, "timestamp")
+	csvData, err := c.csvData(ctx, getFilePathMonth(symbol, year, month))
 	if err != nil {
-		// fmt.Printf("获取数据失败: %s\n", err)
 		return nil, err
 	}
-	return resp, nil
+	return CSVToJson(csvData, istimestamp, "timestamp")
 }
 
 // 获取Csv.xz按年行情数据
-func GetCSVYearJson(gmcsv string,
-	symbol string, tag string, year int, istimestamp bool, tskey string,
-	timeoutSeconds int) ([]byte, error) {
-
-	url := fmt.Sprintf("%s/download/", gmcsv)
-
-	fpath := getFilePathYear(symbol, tag, year)
-	// fmt.Println(fpath)
+func (c *Client) GetCSVYearJson(ctx context.Context,
+	symbol string, tag string, year int, istimestamp bool, tskey string) ([]byte, error) {
 
-	resp, err := DownloadAndConvertToJSON(url+fpath, istimestamp, tskey)
+	csvData, err := c.csvData(ctx, getFilePathYear(symbol, tag, year))
 	if err != nil {
-		// fmt.Printf("获取数据失败: %s\n", err)
 		return nil, err
 	}
-	return resp, nil
+	return CSVToJson(csvData, istimestamp, tskey)
 }
 
-// 获取Csv.xz按月行情数据
-func GetCSVMonth(gmcsv string,
-	symbol string,
-	month int, year int, istimestamp bool,
-	timeoutSeconds int) ([]map[string]any, error) {
-
-	url := fmt.Sprintf("%s/download/", gmcsv)
-	fpath := getFilePathMonth(symbol, year, month)
-	// fmt.Println(fpath)
+// 获取Csv.xz按月1m行情数据
+func (c *Client) GetCSVMonth(ctx context.Context, symbol string, month int, year int) (OHLCVList, error) {
+	return c.csvBars(ctx, getFilePathMonth(symbol, year, month), "", "")
+}
 
-	// 下载并读取数据
-	csvData, err := downloadAndReadData(url + fpath)
-	if err != nil {
-		return nil, err
-	}
-	result, err := CSVToRecords(csvData, istimestamp, "timestamp")
-	if err != nil {
-		return nil, fmt.Errorf("解析CSV失败: %w", err)
-	}
-	return result, nil
+// 获取Csv.xz按年1m行情数据
+func (c *Client) GetCSVYear(ctx context.Context, symbol string, year int) (OHLCVList, error) {
+	return c.csvBars(ctx, getFilePathYear(symbol, "1m", year), "", "")
 }
 
-// 获取Csv.xz按年行情数据
-func GetCSVYear(gmcsv string,
-	symbol string, tag string, year int, istimestamp bool, tskey string,
-	timeoutSeconds int) ([]map[string]any, error) {
+// 获取Csv.xz按年[vv,pe]日频数据
+func (c *Client) GetCSVTagYear(ctx context.Context,
+	symbol string, tag string, year int, istimestamp bool, tskey string) ([]map[string]any, error) {
 
-	url := fmt.Sprintf("%s/download/", gmcsv)
 	fpath := getFilePathYear(symbol, tag, year)
-	// fmt.Println(url + fpath)
-
-	// 下载并读取数据
-	csvData, err := downloadAndReadData(url + fpath)
-	if err != nil {
-		fmt.Println(url + fpath)
-		return nil, err
-	}
-	result, err := CSVToRecords(csvData, istimestamp, tskey)
-	if err != nil {
-		fmt.Println("解析CSV失败: %w", err)
-		return nil, fmt.Errorf("解析CSV失败: %w", err)
-	}
-	return result, nil
+	return c.csvRecords(ctx, fpath, istimestamp, tskey)
 }
 
 // filterDataByDate 根据sdate和edate筛选数据，日期列为timestamp
@@ -2926,13 +2702,8 @@ func filterDataByDate(data []map[string]any, dateKey, sdateStr, edateStr string)
 }
 
 // 按日期范围获取1m分时行情数据
-func GetCSV1m(gmcsv string,
-	symbol string, sdate string, edate string,
-	istimestamp bool, clip bool,
-	timeoutSeconds int) ([]map[string]any, error) {
-
-	// url := fmt.Sprintf("%s/download/", gmcsv)
-	url := gmcsv
+func (c *Client) GetCSV1m(ctx context.Context,
+	symbol string, sdate string, edate string, clip bool) (OHLCVList, error) {
 
 	now := time.Now()
 	today := now.Format("2006-01-02")
@@ -2946,33 +2717,73 @@ func GetCSV1m(gmcsv string,
 		eday = edate
 	}
 	if sday > eday {
-		return nil, fmt.Errorf("开始日期大于结束日期")
+		return nil, invalidParam("开始日期大于结束日期")
 	}
 
 	// 解析开始和结束日期
 	sdateTime, err := time.Parse("2006-01-02", sday)
 	if err != nil {
-		return nil, fmt.Errorf(" error parsing start date: %v", err)
+		return nil, invalidParam("日期格式错误: %v", err)
 	}
 
 	edateTime, err := time.Parse("2006-01-02", eday)
 	if err != nil {
-		return nil, fmt.Errorf(" error parsing end date: %v", err)
+		return nil, invalidParam("日期格式错误: %v", err)
 	}
 
-	syy := sdateTime.Year()
-	smm := int(sdateTime.Month())
-	eyy := edateTime.Year()
-	emm := int(edateTime.Month())
+	var files []string
+	for _, f := range csv1mFiles(symbol, sdateTime, edateTime, now) {
+		files = append(files, f.Path)
+	}
 
-	tag := "1m"
-	var ddd []map[string]any
+	// 按日期截取时在解码过程中过滤, 不保留范围外的行
+	lo, hi := "", ""
+	if clip {
+		lo, hi = sday, eday
+	}
+	rsps, err := RunOrdered(ctx, files, c.poolOptions(),
+		func(ctx context.Context, fpath string) (OHLCVList, error) {
+			return c.csvBars(ctx, fpath, lo, hi)
+		})
+	if err != nil {
+		var ie *ItemError
+		if (c.FailFast && errors.As(err, &ie)) || ctx.Err() != nil {
+			return nil, fmt.Errorf("获取CSV数据失败: %w", err)
+		}
+		// 没有该月份/年份的文件属正常情况(如上市前), 其他错误返回, 以免缺失的月份被当作没有数据
+		var ies, failed ItemErrors
+		errors.As(err, &ies)
+		for _, ie := range ies {
+			if !errors.Is(ie, ErrNotFound) {
+				failed = append(failed, ie)
+			}
+		}
+		if len(failed) > 0 {
+			return nil, fmt.Errorf("获取CSV数据失败: %w", failed)
+		}
+	}
+
+	var bars OHLCVList
+	for _, rsp := range rsps {
+		bars = append(bars, rsp...)
+	}
+
+	if len(bars) == 0 {
+		return nil, fmt.Errorf("%w: 没有获取到数据: %s - %s", ErrNoData, sday, eday)
+	}
+	return bars, nil
+}
+
+// 日期范围对应的 1m 文件: 首尾年份按月文件, 中间年份按年文件, 不含晚于当月的月文件
+func csv1mFiles(symbol string, sdate time.Time, edate time.Time, now time.Time) []ArchiveTask {
+	syy, smm := sdate.Year(), int(sdate.Month())
+	eyy, emm := edate.Year(), int(edate.Month())
+
+	var files []ArchiveTask
 	for yy := syy; yy <= eyy; yy++ {
 		if yy == syy || yy == eyy {
-			var smonth, emonth int
-
-			smonth = 1
-			emonth = 12
+			smonth := 1
+			emonth := 12
 			if yy == syy {
 				smonth = smm
 			}
@@ -2982,54 +2793,29 @@ func GetCSV1m(gmcsv string,
 			if yy == now.Year() && emonth > int(now.Month()) {
 				emonth = int(now.Month())
 			}
-
-			var ddm []map[string]any
 			for im := smonth; im <= emonth; im++ {
-
-				rsp, err := GetCSVMonth(url, symbol, im, yy, istimestamp, timeoutSeconds)
-				if err != nil {
-					fmt.Printf("获取月CSV数据失败: %s", err)
-				}
-				ddm = append(ddm, rsp...)
-			}
-
-			if ddm != nil {
-				ddd = append(ddd, ddm...)
+				m := time.Date(yy, time.Month(im), 1, 0, 0, 0, 0, time.UTC)
+				files = append(files, ArchiveTask{
+					Symbol: symbol, Tag: "1m", Period: m.Format("2006-01"),
+					Path:  getFilePathMonth(symbol, yy, im),
+					SDate: m.Format("2006-01-02"), EDate: m.AddDate(0, 1, -1).Format("2006-01-02"),
+				})
 			}
 		} else {
-			// 调用年份数据获取函数
-			rsp, err := GetCSVYear(url, symbol, tag, yy, istimestamp, "timestamp", timeoutSeconds)
-			if err != nil {
-				fmt.Printf("没有获取到数据: %d", yy)
-			}
-			if rsp != nil {
-				ddd = append(ddd, rsp...)
-			}
-		}
-	}
-
-	if len(ddd) == 0 {
-		return nil, fmt.Errorf("没有获取到数据: %d - %d", syy, eyy)
-	}
-	if clip {
-		// 过滤数据
-		ddd, err = filterDataByDate(ddd, "timestamp", sday, eday)
-		if err != nil {
-			return nil, fmt.Errorf("过滤数据失败: %w", err)
+			files = append(files, ArchiveTask{
+				Symbol: symbol, Tag: "1m", Period: strconv.Itoa(yy),
+				Path:  getFilePathYear(symbol, "1m", yy),
+				SDate: fmt.Sprintf("%d-01-01", yy), EDate: fmt.Sprintf("%d-12-31", yy),
+			})
 		}
 	}
-	return ddd, nil
+	return files
 }
 
 // 按日期范围获取[vv,pe]日频行情数据
 // tag string: vv,pe
-func GetCSVTag(gmcsv string, tag string,
-	symbol string, sdate string, edate string,
-	istimestamp bool, clip bool,
-	timeoutSeconds int) ([]map[string]any, error) {
-
-	// url := fmt.Sprintf("%s/download/", gmcsv)
-	url := gmcsv
+func (c *Client) GetCSVTag(ctx context.Context,
+	tag string, symbol string, sdate string, edate string, istimestamp bool, clip bool) ([]map[string]any, error) {
 
 	now := time.Now()
 	today := now.Format("2006-01-02")
@@ -3043,18 +2829,18 @@ func GetCSVTag(gmcsv string, tag string,
 		eday = edate
 	}
 	if sday > eday {
-		return nil, fmt.Errorf("开始日期大于结束日期")
+		return nil, invalidParam("开始日期大于结束日期")
 	}
 
 	// 解析开始和结束日期
 	sdateTime, err := time.Parse("2006-01-02", sday)
 	if err != nil {
-		return nil, fmt.Errorf(" error parsing start date: %v", err)
+		return nil, invalidParam("日期格式错误: %v", err)
 	}
 
 	edateTime, err := time.Parse("2006-01-02", eday)
 	if err != nil {
-		return nil, fmt.Errorf(" error parsing end date: %v", err)
+		return nil, invalidParam("日期格式错误: %v", err)
 	}
 
 	syy := sdateTime.Year()
@@ -3069,109 +2855,40 @@ func GetCSVTag(gmcsv string, tag string,
 	// istimestamp = false
 	var ddd []map[string]any
 	for yy := syy; yy <= eyy; yy++ {
-		rsp, err := GetCSVYear(url, symbol, tag, yy, istimestamp, lookuptab[tag], timeoutSeconds)
-		if err != nil {
-			// fmt.Printf("获取年CSV数据失败: %s", err)
-			fmt.Printf("没有获取到数据: %d\n", yy)
-		}
-		if rsp != nil {
-			ddd = append(ddd, rsp...)
+		rsp, err := c.GetCSVTagYear(ctx, symbol, tag, yy, istimestamp, lookuptab[tag])
+		// 没有该年的文件(如上市前)时跳过, 其他错误返回
+		if err != nil && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrNoData) {
+			return nil, fmt.Errorf("获取年CSV数据失败(%s, %d): %w", tag, yy, err)
 		}
+		ddd = append(ddd, rsp...)
 	}
 
 	if len(ddd) == 0 {
-		return nil, fmt.Errorf("没有获取到数据(%s): %d - %d", tag, syy, eyy)
+		return nil, fmt.Errorf("%w: 没有获取到数据(%s): %d - %d", ErrNoData, tag, syy, eyy)
 	}
 	if clip {
 		// 过滤数据
 		ddd, err = filterDataByDate(ddd, lookuptab[tag], sday, eday)
 		if err != nil {
-			fmt.Printf("过滤数据失败: %s\n", err)
 			return nil, fmt.Errorf("过滤数据失败: %w", err)
 		}
 		if len(ddd) == 0 {
-			fmt.Printf("过滤数据后数据为空:(%s): %d - %d\n", tag, syy, eyy)
-			return nil, fmt.Errorf("过滤数据后数据为空(%s): %d - %d", tag, syy, eyy)
+			return nil, fmt.Errorf("%w: 过滤数据后数据为空(%s): %d - %d", ErrNoData, tag, syy, eyy)
 		}
 	}
 	return ddd, nil
 }
 
-// 按日期范围获取1m分时行情数据
-func GetGM1m(gmcsv string, gmapi string,
-	symbol string, sdate string, edate string, istimestamp bool, include bool,
-	timeoutSeconds int) ([]map[string]any, error) {
-
-	var ddd []map[string]any
-
-	sday := sdate
-	eday := edate
-	if !include {
-		etime, _ := time.Parse("2006-01-02", eday)
-		etime = etime.AddDate(0, 0, -1)
-		eday = etime.Format("2006-01-02")
-	}
-	if sday > eday {
-		return nil, fmt.Errorf("开始日期大于结束日期: sdate=%s, edate=%s", sday, eday)
-	}
-
-	isclip := true // 是否根据日期范围裁剪数据
-
-	// 确定上个月的结束日期
-	mStartDate := GetEndOfLastMonth(time.Now()).Format("2006-01-02")
-	// fmt.Printf("上个月结束日期: %s\n", mStartDate)
-
-	// 应该先判断一下开始日期是否是超过当月月初，如果超过则不获取CSV数据：
-	// 只有开始日期小于当月月初时才获取CSV数据，以提高接口数据获取速度
-	if sday < mStartDate {
-		eeday := min(eday, mStartDate)
-		dcsv, _ := GetCSV1m(gmcsv, symbol, sday, eeday, istimestamp, isclip, timeoutSeconds)
-		if len(dcsv) > 0 {
-			ddd = append(ddd, dcsv...)
-
-			tsStr := dcsv[len(dcsv)-1]["timestamp"]
-			etime, _ := ParseTimestamp(tsStr)
-
-			etime = etime.AddDate(0, 0, 1)
-			sday = etime.Format("2006-01-02") // 开始时间设置为下一天
-			// fmt.Printf(" 下个开始日期: %s \n", sday)
-		}
-	}
-
-	if sday > eday {
-		return ddd, nil
-	}
-
-	// dapi, _ := GetKbarsHis(gmapi, symbol, "1m", sday, eday, istimestamp, timeoutSeconds)
-	// for i := range dapi {
-	// 	// 去掉API数据中的symbol字段
-	// 	dd1 := make(map[string]any, 6)
-
-	// 	dd1["timestamp"] = dapi[i]["timestamp"]
-	// 	dd1["open"] = dapi[i]["open"]
-	// 	dd1["high"] = dapi[i]["high"]
-	// 	dd1["low"] = dapi[i]["low"]
-	// 	dd1["close"] = dapi[i]["close"]
-	// 	dd1["volume"] = dapi[i]["volume"]
-	// 	ddd = append(ddd, dd1)
-	// }
-	// fmt.Printf("获取API数据成功: %d条: %s - %s\n", len(dapi), sday, eday)
-
-	// 按日期aq列表从gm-api获取单支股票分时行情数据
-	datelist, _ := GetDatesList(gmapi, sday, eday, timeoutSeconds)
-	// fmt.Printf("获取日期列表成功: %d天: %s - %s\n", len(datelist), sday, eday)
-	dapi, _ := Get1mByDatelist(gmapi, symbol, datelist, istimestamp, timeoutSeconds)
-	if len(dapi) > 0 {
-		ddd = append(ddd, dapi...)
-	}
-
-	return ddd, nil
+// 按日期范围获取1m分时行情数据(CSV归档与API数据按时间戳合并, 见 GetGM1mCoverage)
+func (c *Client) GetGM1m(ctx context.Context,
+	symbol string, sdate string, edate string, istimestamp bool, include bool) ([]map[string]any, error) {
+	ddd, _, err := c.GetGM1mCoverage(ctx, symbol, sdate, edate, istimestamp, include)
+	return ddd, err
 }
 
 // 按日期范围获取日频行情数据
-func GetGM1d(gmcsv string, gmapi string,
-	symbol string, sdate string, edate string, istimestamp bool, include bool,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetGM1d(ctx context.Context,
+	symbol string, sdate string, edate string, istimestamp bool, include bool) ([]map[string]any, error) {
 
 	var ddd []map[string]any
 
@@ -3183,10 +2900,16 @@ func GetGM1d(gmcsv string, gmapi string,
 		eday = etime.Format("2006-01-02")
 	}
 	if sday > eday {
-		return nil, fmt.Errorf("开始日期大于结束日期: sdate=%s, edate=%s", sday, eday)
+		return nil, invalidParam("开始日期大于结束日期: sdate=%s, edate=%s", sday, eday)
 	}
 
-	dapi, _ := GetKbarsHis(gmapi, symbol, "1d", sday, eday, istimestamp, timeoutSeconds)
+	dapi, err := c.GetKbarsHis(ctx, symbol, "1d", sday, eday, istimestamp)
+	if err != nil {
+		return nil, err
+	}
+	if len(dapi) == 0 {
+		return nil, fmt.Errorf("%w: 日频数据(%s): %s - %s", ErrNoData, symbol, sday, eday)
+	}
 	for i := range dapi {
 		// 去掉API数据中的symbol字段
 		dd1 := make(map[string]any, 6)
@@ -3204,9 +2927,8 @@ func GetGM1d(gmcsv string, gmapi string,
 }
 
 // 按日期范围获取财务衍生行情数据
-func GetGMpe(gmcsv string, gmapi string,
-	symbol string, sdate string, edate string, fields string, istimestamp bool, include bool,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) GetGMpe(ctx context.Context,
+	symbol string, sdate string, edate string, fields string, istimestamp bool, include bool) ([]map[string]any, error) {
 
 	// var ddd []map[string]any
 
@@ -3218,57 +2940,72 @@ func GetGMpe(gmcsv string, gmapi string,
 		eday = etime.Format("2006-01-02")
 	}
 	if sday > eday {
-		return nil, fmt.Errorf("开始日期大于结束日期: sdate=%s, edate=%s", sday, eday)
+		return nil, invalidParam("开始日期大于结束日期: sdate=%s, edate=%s", sday, eday)
 	}
 
-	rsp, _ := GetDailyValuation(gmapi, symbol, sday, eday, fields, timeoutSeconds)
+	rsp, err := c.GetDailyValuation(ctx, symbol, sday, eday, fields)
+	if err != nil {
+		return nil, err
+	}
+	if len(rsp) == 0 {
+		return nil, fmt.Errorf("%w: 估值数据(%s): %s - %s", ErrNoData, symbol, sday, eday)
+	}
 	ddd := Records2Timestamp(rsp, istimestamp, "trade_date")
 
 	return ddd, nil
 }
 
 // 按日频行情数据：包括v931,v932,v935,。。。
-func GetGMvv(gmcsv string, gmapi string,
-	symbol string, sdate string, edate string, indicators string, istimestamp bool, include bool, is1m bool,
-	timeoutSeconds int) (map[string]any, error) {
+func (c *Client) GetGMvv(ctx context.Context,
+	symbol string, sdate string, edate string, indicators string, istimestamp bool, include bool, is1m bool) (map[string]any, error) {
+	ddd, _, err := c.GetGMvvCoverage(ctx, symbol, sdate, edate, indicators, istimestamp, include, is1m)
+	return ddd, err
+}
+
+// 同 GetGMvv, 并返回分时数据的覆盖情况(用于判断vv指标是否基于完整数据)
+func (c *Client) GetGMvvCoverage(ctx context.Context,
+	symbol string, sdate string, edate string, indicators string, istimestamp bool, include bool, is1m bool) (map[string]any, *Coverage, error) {
 
 	ddd := make(map[string]any)
 
 	if sdate > edate {
-		return nil, fmt.Errorf("开始日期大于结束日期: sdate=%s, edate=%s", sdate, edate)
+		return nil, nil, invalidParam("开始日期大于结束日期: sdate=%s, edate=%s", sdate, edate)
+	}
+	spec, isOHLC, isV123, isCbj, err := ParseIndicators(indicators)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	rawData, err := GetGM1m(gmcsv, gmapi, symbol, sdate, edate, istimestamp, include, timeoutSeconds)
+	rawData, cov, err := c.GetGM1mCoverage(ctx, symbol, sdate, edate, istimestamp, include)
 	if err != nil {
-		return nil, fmt.Errorf("获取GM数据失败: %w", err)
+		return nil, nil, fmt.Errorf("获取GM数据失败: %w", err)
 	}
 
 	ohlcv := OHLCVList{}
 	ohlcv.FromMapList(rawData)
 
-	isOHLC, isV123, isCbj := CheckIndicators(indicators)
-	vvl := ohlcv.ToVVList(isOHLC, isV123, isCbj)
+	vvl := ohlcv.ToVVListSpec(spec, isOHLC, isV123, isCbj)
 
 	ddd["1dvv"] = vvl.ToRecords(isOHLC, isV123, isCbj, istimestamp)
 	if is1m {
 		ddd["1mkb"] = rawData
 	}
 
-	return ddd, nil
+	return ddd, cov, nil
 }
 
 // 按日期列表从gm-api获取单支股票分时行情数据
-func Get1mByDatelist(gmapi string,
-	symbol string, datelist []string, istimestamp bool,
-	timeoutSeconds int) ([]map[string]any, error) {
+func (c *Client) Get1mByDatelist(ctx context.Context,
+	symbol string, datelist []string, istimestamp bool) ([]map[string]any, error) {
 
-	var ddd []map[string]any
-	for _, date := range datelist {
-		dapi, _ := GetKbarsHis(gmapi, symbol, "1m", date, date, istimestamp, timeoutSeconds)
-		// jsonData, _ := json.Marshal(dapi[:5])
-		// fmt.Println(string(jsonData))
+	// 按日期并发获取, 结果按日期顺序合并
+	days, err := RunOrdered(ctx, datelist, c.poolOptions(),
+		func(ctx context.Context, date string) ([]map[string]any, error) {
+			return c.GetKbarsHis(ctx, symbol, "1m", date, date, istimestamp)
+		})
 
-		// fmt.Printf("获取API数据成功: %d条: %s - %s\n", len(dapi), date, date)
+	var ddd []map[string]any
+	for _, dapi := range days {
 		// 处理一下数据结构，使得CSV数据和API数据合并
 		for i := range dapi {
 			// 去掉API数据中的symbol字段
@@ -3284,5 +3021,8 @@ func Get1mByDatelist(gmapi string,
 			ddd = append(ddd, dd1)
 		}
 	}
+	if err != nil {
+		return ddd, fmt.Errorf("获取分时数据失败: %w", err)
+	}
 	return ddd, nil
 }
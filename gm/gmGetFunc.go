@@ -0,0 +1,783 @@
+package gm
+
+import "context"
+
+// 包级函数: 使用一次性的 Client 调用对应方法，保持原有调用方式不变
+
+// 测试数据1
+func GetTest(url string, timeoutSeconds int) ([]byte, error) {
+	return newFuncClient(url, "", timeoutSeconds).GetTest(context.Background())
+}
+
+// 测试数据1
+func GetTest2(url string, timeoutSeconds int) ([]byte, error) {
+	return newFuncClient(url, "", timeoutSeconds).GetTest2(context.Background())
+}
+
+// 获取交易日历
+func GetCalendar(gmapi string,
+	syear string, eyear string, exchange string,
+	timeoutSeconds int) ([]byte, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetCalendar(context.Background(), syear, eyear, exchange)
+}
+
+// 查询指定日期的前n个交易日
+func GetPrevNByte(gmapi string,
+	date string, count int, include bool,
+	timeoutSeconds int) ([]byte, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetPrevNByte(context.Background(), date, count, include)
+}
+
+// 查询指定日期的前n个交易日
+func GetPrevN(gmapi string,
+	date string, count int, include bool,
+	timeoutSeconds int) ([]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetPrevN(context.Background(), date, count, include)
+}
+
+// 查询指定日期的后n个交易日
+func GetNextNByte(gmapi string,
+	date string, count int, include bool,
+	timeoutSeconds int) ([]byte, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetNextNByte(context.Background(), date, count, include)
+}
+
+// 查询指定日期的前n个交易日
+func GetNextN(gmapi string,
+	date string, count int, include bool,
+	timeoutSeconds int) ([]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetNextN(context.Background(), date, count, include)
+}
+
+// 获取给定日期区间的交易日列表
+func GetDatesList(gmapi string, sdate string, edate string, timeoutSeconds int) ([]string, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetDatesList(context.Background(), sdate, edate)
+}
+
+// 获取行情快照数据
+func GetCurrentByte(gmapi string, symbols string, timeoutSeconds int, split bool) ([]byte, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetCurrentByte(context.Background(), symbols, split)
+}
+
+// 获取行情快照数据
+func GetCurrent(gmapi string, symbols string, timeoutSeconds int, split bool) ([]SnapData, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetCurrent(context.Background(), symbols, split)
+}
+
+// 查询个股估值指标每日数据
+// 输入参数：
+//   - sdate: 开始日期, 格式: "2021-01-01"
+//   - edate: 结束日期, 格式: "2021-01-01"
+//   - symbol: 股票代码, 如 "SHSE.601088"
+func GetDailyValuation(gmapi string,
+	symbol string, sdate string, edate string, fields string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetDailyValuation(context.Background(), symbol, sdate, edate, fields)
+}
+
+// 查询个股基础指标每日数据
+// 输入参数：
+//   - sdate: 开始日期, 格式: "2021-01-01"
+//   - edate: 结束日期, 格式: "2021-01-01"
+//   - symbol: 股票代码, 如 "SHSE.601088"
+func GetDailyBasic(gmapi string,
+	symbol string, sdate string, edate string, fields string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetDailyBasic(context.Background(), symbol, sdate, edate, fields)
+}
+
+// 查询个股市值指标每日数据
+// 输入参数：
+//   - sdate: 开始日期, 格式: "2021-01-01"
+//   - edate: 结束日期, 格式: "2021-01-01"
+//   - symbol: 股票代码, 如 "SHSE.601088"
+func GetDailyMktvalue(gmapi string,
+	symbol string, sdate string, edate string, fields string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetDailyMktvalue(context.Background(), symbol, sdate, edate, fields)
+}
+
+// 查询财务主要指标
+// 输入参数：
+//   - sdate: 开始日期, 格式: "2021-01-01"
+//   - edate: 结束日期, 格式: "2021-01-01"
+//   - symbol: 股票代码, 如 "SHSE.601088"
+//   - fields: fields不能超过20个字段
+//   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
+//   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
+func GetFinancePrime(gmapi string,
+	symbol string, sdate string, edate string, fields string, rpt_type string, data_type string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFinancePrime(context.Background(), symbol, sdate, edate, fields, rpt_type, data_type)
+}
+
+// 查询财务衍生指标
+// 输入参数：
+//   - sdate: 开始日期, 格式: "2021-01-01"
+//   - edate: 结束日期, 格式: "2021-01-01"
+//   - symbol: 股票代码, 如 "SHSE.601088"
+//   - fields: fields不能超过20个字段
+//   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
+//   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
+func GetFinanceDeriv(gmapi string,
+	symbol string, sdate string, edate string, fields string, rpt_type string, data_type string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFinanceDeriv(context.Background(), symbol, sdate, edate, fields, rpt_type, data_type)
+}
+
+// 查询资产负债表
+// 输入参数：
+//   - sdate: 开始日期, 格式: "2021-01-01"
+//   - edate: 结束日期, 格式: "2021-01-01"
+//   - symbol: 股票代码, 如 "SHSE.601088"
+//   - fields: fields不能超过20个字段
+//   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
+//   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
+func GetFundamentalsBalance(gmapi string,
+	symbol string, sdate string, edate string, fields string, rpt_type string, data_type string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFundamentalsBalance(context.Background(), symbol, sdate, edate, fields, rpt_type, data_type)
+}
+
+// 查询现金流量表
+// 输入参数：
+//   - sdate: 开始日期, 格式: "2021-01-01"
+//   - edate: 结束日期, 格式: "2021-01-01"
+//   - symbol: 股票代码, 如 "SHSE.601088"
+//   - fields: fields不能超过20个字段
+//   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
+//   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
+func GetFundamentalsCashflow(gmapi string,
+	symbol string, sdate string, edate string, fields string, rpt_type string, data_type string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFundamentalsCashflow(context.Background(), symbol, sdate, edate, fields, rpt_type, data_type)
+}
+
+// 查询利润表
+// 输入参数：
+//   - sdate: 开始日期, 格式: "2021-01-01"
+//   - edate: 结束日期, 格式: "2021-01-01"
+//   - symbol: 股票代码, 如 "SHSE.601088"
+//   - fields: fields不能超过20个字段
+//   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
+//   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
+func GetFundamentalsIncome(gmapi string,
+	symbol string, sdate string, edate string, fields string, rpt_type string, data_type string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFundamentalsIncome(context.Background(), symbol, sdate, edate, fields, rpt_type, data_type)
+}
+
+// 查询资产负债表单日截面数据(point-in-time, 多标的)
+// 输入参数：
+//   - date: 交易日期, 格式: "2021-01-01"
+//   - fields: fields不能超过20个字段
+//   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
+//   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
+//   - symbols: 股票列表, 如 "SHSE.601088,SZSE.000001"
+func GetFundamentalsBalancePt(gmapi string,
+	symbols string, date string, fields string, rpt_type string, data_type string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFundamentalsBalancePt(context.Background(), symbols, date, fields, rpt_type, data_type)
+}
+
+// 查询资产负债表单日截面数据(point-in-time, 多标的)
+// 输入参数：
+//   - date: 交易日期, 格式: "2021-01-01"
+//   - fields: fields不能超过20个字段
+//   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
+//   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
+//   - symbols: 股票列表, 如 "SHSE.601088,SZSE.000001"
+func GetFundamentalsCashflowPt(gmapi string,
+	symbols string, date string, fields string, rpt_type string, data_type string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFundamentalsCashflowPt(context.Background(), symbols, date, fields, rpt_type, data_type)
+}
+
+// 查询资产负债表单日截面数据(point-in-time, 多标的)
+// 输入参数：
+//   - date: 交易日期, 格式: "2021-01-01"
+//   - fields: fields不能超过20个字段
+//   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
+//   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
+//   - symbols: 股票列表, 如 "SHSE.601088,SZSE.000001"
+func GetFundamentalsIncomePt(gmapi string,
+	symbols string, date string, fields string, rpt_type string, data_type string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFundamentalsIncomePt(context.Background(), symbols, date, fields, rpt_type, data_type)
+}
+
+// 查询财务主要指标单日截面数据(point-in-time, 多标的)
+// 输入参数：
+//   - date: 交易日期, 格式: "2021-01-01"
+//   - fields: fields不能超过20个字段
+//   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
+//   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
+//   - symbols: 股票列表, 如 "SHSE.601088,SZSE.000001"
+func GetFinancePrimePt(gmapi string,
+	symbols string, date string, fields string, rpt_type string, data_type string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFinancePrimePt(context.Background(), symbols, date, fields, rpt_type, data_type)
+}
+
+// 查询财务主要指标单日截面数据(point-in-time, 多标的)
+// 输入参数：
+//   - date: 交易日期, 格式: "2021-01-01"
+//   - fields: fields不能超过20个字段
+//   - rpt_type: 按报告期查询可指定以下报表类型： 1-一季度报; 6-中报; 9-前三季报; 12-年报 默认None为不限
+//   - data_type: 在发布原始财务报告以后，上市公司可能会对数据进行修正。 101-合并原始; 102-合并调整; 201-母公司原始; 202-母公司调整 默认None返回当期合并调整，如果没有调整返回合并原始
+//   - symbols: 股票列表, 如 "SHSE.601088,SZSE.000001"
+func GetFinanceDerivPt(gmapi string,
+	symbols string, date string, fields string, rpt_type string, data_type string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFinanceDerivPt(context.Background(), symbols, date, fields, rpt_type, data_type)
+}
+
+// 查询估值指标单日截面数据(point-in-time, 多标的)
+// 输入参数：
+//   - date: 交易日期, 格式: "2021-01-01"
+//   - symbols: 股票列表, 如 "SHSE.601088,SZSE.000001"
+func GetDailyBasicPt(gmapi string,
+	symbols string, date string, fields string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetDailyBasicPt(context.Background(), symbols, date, fields)
+}
+
+// 查询估值指标单日截面数据(point-in-time, 多标的)
+// 输入参数：
+//   - date: 交易日期, 格式: "2021-01-01"
+//   - symbols: 股票列表, 如 "SHSE.601088,SZSE.000001"
+func GetDailyMktvaluePt(gmapi string,
+	symbols string, date string, fields string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetDailyMktvaluePt(context.Background(), symbols, date, fields)
+}
+
+// 查询估值指标单日截面数据(point-in-time, 多标的)
+// 输入参数：
+//   - date: 交易日期, 格式: "2021-01-01"
+//   - symbols: 股票列表, 如 "SHSE.601088,SZSE.000001"
+func GetDailyValuationPt(gmapi string,
+	symbols string, date string, fields string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetDailyValuationPt(context.Background(), symbols, date, fields)
+}
+
+// 查询板块分类
+// 输入参数：
+//   - sector_type: 只能选择一种类型，可选择 1001:市场类 1002:地域类 1003:概念类
+func GetSectorCategory(gmapi string,
+	sector_type string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetSectorCategory(context.Background(), sector_type)
+}
+
+// 查询板块成分股
+// 输入参数：
+//   - sector_code: 需要查询成分股的板块代码，可通过stk_get_sector_category获取
+func GetSectorConstituents(gmapi string,
+	sector_code string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetSectorConstituents(context.Background(), sector_code)
+}
+
+// 查询个股所属板块
+// 输入参数：
+//   - sector_type: 只能选择一种类型，可选择 1001:市场类 1002:地域类 1003:概念类
+func GetSymbolsSector(gmapi string,
+	symbols string, sector_type string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetSymbolsSector(context.Background(), symbols, sector_type)
+}
+
+// 请求个股分红送转信息(gm-api)
+// 输入参数：
+//   - sdate: 开始日期, 格式: "2021-01-01"
+//   - edate: 结束日期, 格式: "2021-01-01"
+//   - symbol: 股票代码, 如 "SHSE.601088"
+func GetDividend(gmapi string,
+	symbol string, sdate string, edate string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetDividend(context.Background(), symbol, sdate, edate)
+}
+
+// 请求股票配股信息(gm-api)
+// 输入参数：
+//   - sdate: 开始日期, 格式: "2021-01-01"
+//   - edate: 结束日期, 格式: "2021-01-01"
+//   - symbol: 股票代码, 如 "SHSE.601088"
+func GetRation(gmapi string,
+	symbol string, sdate string, edate string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetRation(context.Background(), symbol, sdate, edate)
+}
+
+// 请求股东个数(gm-api)
+// 输入参数：
+//   - sdate: 开始日期, 格式: "2021-01-01"
+//   - edate: 结束日期, 格式: "2021-01-01"
+//   - symbol: 股票代码, 如 "SHSE.601088"
+func GetShareholderNum(gmapi string,
+	symbol string, sdate string, edate string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetShareholderNum(context.Background(), symbol, sdate, edate)
+}
+
+// 请求股本变动(gm-api)
+// 输入参数：
+//   - sdate: 开始日期, 格式: "2021-01-01"
+//   - edate: 结束日期, 格式: "2021-01-01"
+//   - symbol: 股票代码, 如 "SHSE.601088"
+func GetShareChange(gmapi string,
+	symbol string, sdate string, edate string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetShareChange(context.Background(), symbol, sdate, edate)
+}
+
+// 查询股票复权因子(gm-api)
+// 输入参数：
+//   - sdate: 开始日期, 格式: "2021-01-01"
+//   - edate: 结束日期, 格式: "2021-01-01"
+//   - bdate: 前复权的基准日，%Y-%m-%d 格式，默认""表示最新时间
+//   - symbol: 股票代码, 如 "SHSE.601088"
+func GetAdjFactor(gmapi string,
+	symbol string, sdate string, edate string, bdate string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetAdjFactor(context.Background(), symbol, sdate, edate, bdate)
+}
+
+// 查询十大股东
+// 输入参数：
+//   - sdate: 开始日期, 格式: "2021-01-01"
+//   - edate: 结束日期, 格式: "2021-01-01"
+//   - tradable_holder: False-十大股东（默认）、True-十大流通股东 默认False表示十大股东
+//   - symbol: 股票代码, 如 "SHSE.601088"
+func GetTopShareholder(gmapi string,
+	symbol string, sdate string, edate string, tradable_holder string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetTopShareholder(context.Background(), symbol, sdate, edate, tradable_holder)
+}
+
+// 查询龙虎榜股票数据
+// 输入参数：
+//   - symbols: 输入标的代码，可输入多个. 采用 str 格式时，多个标的代码必须用英文逗号分割，
+//   - change_types: 输入异动类型，可输入多个. 采用 str 格式时，多个异动类型必须用英文逗号分割，如：'106,107'; 采用 list 格式时，多个异动类型示例：['106','107']； 默认None表示所有异动类型。
+//   - trade_date: 交易日期，支持str格式（%Y-%m-%d 格式）和 datetime.date 格式，默认None表示最新交易日期。
+//   - fields: 指定需要返回的字段，如有多个字段，中间用英文逗号分隔，默认 None 返回所有字段。
+func GetAbnorChangeStocks(gmapi string,
+	symbols string, change_types string, trade_date string, fields string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetAbnorChangeStocks(context.Background(), symbols, change_types, trade_date, fields)
+}
+
+// 查询龙虎榜营业部数据
+// 输入参数：
+//   - symbols: 输入标的代码，可输入多个. 采用 str 格式时，多个标的代码必须用英文逗号分割，
+//   - change_types: 输入异动类型，可输入多个. 采用 str 格式时，多个异动类型必须用英文逗号分割，如：'106,107'; 采用 list 格式时，多个异动类型示例：['106','107']； 默认None表示所有异动类型。
+//   - trade_date: 交易日期，支持str格式（%Y-%m-%d 格式）和 datetime.date 格式，默认None表示最新交易日期。
+//   - fields: 指定需要返回的字段，如有多个字段，中间用英文逗号分隔，默认 None 返回所有字段。
+func GetAbnorChangeDetail(gmapi string,
+	symbols string, change_types string, trade_date string, fields string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetAbnorChangeDetail(context.Background(), symbols, change_types, trade_date, fields)
+}
+
+// 查询沪深港通标的港股机构持股数据
+// 输入参数：
+//   - symbols: 输入标的代码，可输入多个. 采用 str 格式时，多个标的代码必须用英文逗号分割，
+//   - trade_date: 交易日期，支持str格式（%Y-%m-%d 格式）和 datetime.date 格式，默认None表示最新交易日期。
+func GetHKInstHoldingInfo(gmapi string,
+	symbols string, trade_date string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetHKInstHoldingInfo(context.Background(), symbols, trade_date)
+}
+
+// 查询沪深港通标的港股机构持股明细数据
+//
+// 输入参数：
+//   - symbols: 输入标的代码，可输入多个. 采用 str 格式时，多个标的代码必须用英文逗号分割，
+//   - trade_date: 交易日期，支持str格式（%Y-%m-%d 格式）和 datetime.date 格式，默认None表示最新交易日期。
+func GetHKInstHoldingDetailInfo(gmapi string,
+	symbols string, trade_date string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetHKInstHoldingDetailInfo(context.Background(), symbols, trade_date)
+}
+
+// 查询沪深港通十大活跃成交股数据
+//
+// 输入参数：
+//   - types: 类型，可输入多个，采用 str 格式时，多个类型必须用英文逗号分割，如：'SZ,SHHK' 采用 list 格式时，多个标的代码示例：['SZ', 'SHHK']，类型包括：SH - 沪股通 ，SHHK - 沪港股通 ，SZ - 深股通 ，SZHK - 深港股通，NF - 北向资金（沪股通+深股通），默认 None 为全部北向资金。
+//   - trade_date: 交易日期，支持str格式（%Y-%m-%d 格式）和 datetime.date 格式，默认None表示最新交易日期。
+func GetSHSZHKActiveStockTop10Info(gmapi string,
+	types string, trade_date string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetSHSZHKActiveStockTop10Info(context.Background(), types, trade_date)
+}
+
+// 查询沪深港通额度数据
+//
+// 输入参数：
+//   - types: 类型，可输入多个，采用 str 格式时，多个类型必须用英文逗号分割，如：'SZ,SHHK' 采用 list 格式时，多个标的代码示例：['SZ', 'SHHK']，类型包括：SH - 沪股通 ，SHHK - 沪港股通 ，SZ - 深股通 ，SZHK - 深港股通，NF - 北向资金（沪股通+深股通），默认 None 为全部北向资金。
+//   - sdate: 开始日期，支持str格式（%Y-%m-%d 格式）和 datetime.date 格式，默认None表示最新交易日期。
+//   - edate: 开始日期，支持str格式（%Y-%m-%d 格式）和 datetime.date 格式，默认None表示最新交易日期。
+//   - count: 数量(正整数)，不能与start_date同时使用，否则返回报错；与 end_date 同时使用时，表示获取 end_date 前 count 个交易日的数据(包含 end_date 当日)；默认为 None ，不使用该字段。
+func GetSHSZHKQuotaInfo(gmapi string,
+	types string, sdate string, edate string, count string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetSHSZHKQuotaInfo(context.Background(), types, sdate, edate, count)
+}
+
+// 查询 ETF 最新成分股
+//
+// 输入参数：
+//   - etf: 必填，只能输入一个 ETF 的symbol，如：'SZSE.159919'
+func GetFndConstituents(gmapi string, etf string, timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFndConstituents(context.Background(), etf)
+}
+
+// 查询基金资产组合
+//
+// 输入参数：
+//   - fund: 必填，只能输入一个基金的symbol，如：'SZSE.161133'
+//   - report_type: 公布持仓所在的报表类别，必填，可选： 1:第一季度 2:第二季度 3:第三季报 4:第四季度 6:中报 12:年报
+//   - portfolio_type: 必填，可选以下其中一种组合： 'stk' - 股票投资组合 'bnd' - 债券投资组合 'fnd' - 基金投资组合
+//   - sdate: 开始时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
+//   - edate: 结束时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
+func GetFndPortfolio(gmapi string,
+	fund string, report_type string, portfolio_type string, sdate string, edate string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFndPortfolio(context.Background(), fund, report_type, portfolio_type, sdate, edate)
+}
+
+// 查询基金净值数据
+//
+// 输入参数：
+//   - fund: 必填，只能输入一个基金的symbol，如：'SZSE.161133'
+//   - sdate: 开始时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
+//   - edate: 结束时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
+func GetFndNetValue(gmapi string,
+	fund string, sdate string, edate string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFndNetValue(context.Background(), fund, sdate, edate)
+}
+
+// 查询基金复权因子
+//
+// 输入参数：
+//   - fund: 必填，只能输入一个基金的symbol，如：'SZSE.161133'
+//   - sdate: 开始时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
+//   - edate: 结束时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
+//   - bdate: 前复权的基准日，%Y-%m-%d 格式， 默认""表示最新时间
+func GetFndAdjFactor(gmapi string,
+	fund string, sdate string, edate string, bdate string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFndAdjFactor(context.Background(), fund, sdate, edate, bdate)
+}
+
+// 查询基金分红信息
+//
+// 输入参数：
+//   - fund: 必填，只能输入一个基金的symbol，如：'SZSE.510880'
+//   - sdate: 开始时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
+//   - edate: 结束时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
+func GetFndDividend(gmapi string,
+	fund string, sdate string, edate string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFndDividend(context.Background(), fund, sdate, edate)
+}
+
+// 查询基金拆分折算信息
+//
+// 输入参数：
+//   - fund: 必填，只能输入一个基金的symbol，如：'SZSE.161725'
+//   - sdate: 开始时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
+//   - edate: 结束时间日期（公告日），%Y-%m-%d 格式，默认""表示最新时间
+func GetFndSplit(gmapi string,
+	fund string, sdate string, edate string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFndSplit(context.Background(), fund, sdate, edate)
+}
+
+// 查询行业分类
+// 输入参数：
+//   - source: 'zjh2012'- 证监会行业分类 2012（默认）， 'sw2021'- 申万行业分类 2021
+//   - level: 1 - 一级行业（默认），2 - 二级行业，3 - 三级行业
+func GetIndustryCategory(gmapi string,
+	source string, level string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetIndustryCategory(context.Background(), source, level)
+}
+
+// 查询行业成分股
+// 输入参数：
+//   - industry_code: 需要查询成分股的行业代码，可通过stk_get_industry_category获取
+//   - date: 查询行业成分股的指定日期，%Y-%m-%d 格式，默认""表示最新时间
+func GetIndustryConstituents(gmapi string,
+	industry_code string, date string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetIndustryConstituents(context.Background(), industry_code, date)
+}
+
+// 查询股票的所属行业
+// 输入参数：
+//   - source: 'zjh2012'- 证监会行业分类 2012（默认）， 'sw2021'- 申万行业分类 2021
+//   - level: 1 - 一级行业（默认），2 - 二级行业，3 - 三级行业
+//   - date: 查询行业成分股的指定日期，%Y-%m-%d 格式，默认""表示最新时间
+func GetSymbolIndustry(gmapi string,
+	symbols string, source string, level string, date string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetSymbolIndustry(context.Background(), symbols, source, level, date)
+}
+
+// 查询指数成分股
+// 输入参数：
+//   - trade_date: 查询行业成分股的指定日期，%Y-%m-%d 格式，默认""表示最新时间
+func GetIndexConstituents(gmapi string,
+	index string, trade_date string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetIndexConstituents(context.Background(), index, trade_date)
+}
+
+// 查询股票的所属行业
+func GetTradingSessions(gmapi string,
+	symbols string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetTradingSessions(context.Background(), symbols)
+}
+
+// 请求市场个股列表(gm-api)
+// 输入参数：
+//   - exchange: 交易所代码: SHSE,SZSE,CFFEX,DCE,CZCE,SHFE,INE
+//   - sec: 证券类型代码, 如 "stock"、"fund"、"index"
+//
+// 返回值：
+//   - 市场个股列表
+func GetMarketInfo(gmapi string,
+	symbols string, sec string, exchange string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetMarketInfo(context.Background(), symbols, sec, exchange)
+}
+
+// 请求市场股票列表某交易日的交易数据(gm-api)
+// 输入参数：
+//   - exchange: 交易所代码: SHSE,SZSE,CFFEX,DCE,CZCE,SHFE,INE
+//   - sec: 证券类型代码, 如 "stock"、"fund"、"index"
+//
+// 返回值：
+//   - 市场个股列表
+func GetSymbolsInfo(gmapi string,
+	symbols string, sec string, exchange string, trade_date string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetSymbolsInfo(context.Background(), symbols, sec, exchange, trade_date)
+}
+
+// 请求个股历史交易段的个股信息(gm-api)
+// 输入参数：
+//   - sdate: 开始日期, 格式: "2021-01-01"
+//   - edate: 结束日期, 格式: "2021-01-01"
+//   - symbol: 股票代码, 如 "SHSE.601088"
+func GetHistoryInfo(gmapi string,
+	symbol string, sdate string, edate string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetHistoryInfo(context.Background(), symbol, sdate, edate)
+}
+
+// 获取K线行情数据
+func GetKbarsHisByte(gmapi string,
+	symbols string, tag string, sdate string, edate string,
+	timeoutSeconds int) ([]byte, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetKbarsHisByte(context.Background(), symbols, tag, sdate, edate)
+}
+
+// 获取K线行情数据
+func GetKbarsHis2Byte(gmapi string,
+	symbols string, tag string, stime string, etime string,
+	timeoutSeconds int) ([]byte, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetKbarsHis2Byte(context.Background(), symbols, tag, stime, etime)
+}
+
+// 获取K线行情数据：输入参数为日期
+func GetKbarsHis(gmapi string,
+	symbols string, tag string, sdate string, edate string, istimestamp bool,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetKbarsHis(context.Background(), symbols, tag, sdate, edate, istimestamp)
+}
+
+// 获取K线行情数据: 输入参数为时间
+func GetKbarsHis2(gmapi string,
+	symbols string, tag string, stime string, etime string, istimestamp bool,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetKbarsHis2(context.Background(), symbols, tag, stime, etime, istimestamp)
+}
+
+// 获取K线行情数据
+func GetKbarsHisNByte(gmapi string,
+	symbol string, tag string, count string, edate string,
+	timeoutSeconds int) ([]byte, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetKbarsHisNByte(context.Background(), symbol, tag, count, edate)
+}
+
+// 获取K线行情数据
+func GetKbarsHis2NByte(gmapi string,
+	symbol string, tag string, count string, etime string,
+	timeoutSeconds int) ([]byte, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetKbarsHis2NByte(context.Background(), symbol, tag, count, etime)
+}
+
+// 获取K线行情数据：输入参数为日期
+func GetKbarsHisN(gmapi string,
+	symbol string, tag string, count string, edate string, istimestamp bool,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetKbarsHisN(context.Background(), symbol, tag, count, edate, istimestamp)
+}
+
+// 获取K线行情数据：输入参数为时间
+func GetKbarsHis2N(gmapi string,
+	symbol string, tag string, count string, etime string, istimestamp bool,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetKbarsHis2N(context.Background(), symbol, tag, count, etime, istimestamp)
+}
+
+// downloadAndConvertToJSON 从URL下载CSV数据并转换为JSON
+func DownloadAndConvertToJSON(url string, istimestamp bool, tskey string) ([]byte, error) {
+	return newFuncClient("", "", 0).DownloadAndConvertToJSON(context.Background(), url, istimestamp, tskey)
+}
+
+// 获取Csv.xz按月行情数据
+func GetCSVMonthJson(gmcsv string,
+	symbol string, month int, year int, istimestamp bool,
+	timeoutSeconds int) ([]byte, error) {
+	return newFuncClient("", gmcsv, timeoutSeconds).GetCSVMonthJson(context.Background(), symbol, month, year, istimestamp)
+}
+
+// 获取Csv.xz按年行情数据
+func GetCSVYearJson(gmcsv string,
+	symbol string, tag string, year int, istimestamp bool, tskey string,
+	timeoutSeconds int) ([]byte, error) {
+	return newFuncClient("", gmcsv, timeoutSeconds).GetCSVYearJson(context.Background(), symbol, tag, year, istimestamp, tskey)
+}
+
+// 获取Csv.xz按月1m行情数据
+func GetCSVMonth(gmcsv string,
+	symbol string, month int, year int,
+	timeoutSeconds int) (OHLCVList, error) {
+	return newFuncClient("", gmcsv, timeoutSeconds).GetCSVMonth(context.Background(), symbol, month, year)
+}
+
+// 获取Csv.xz按年1m行情数据
+func GetCSVYear(gmcsv string,
+	symbol string, year int,
+	timeoutSeconds int) (OHLCVList, error) {
+	return newFuncClient("", gmcsv, timeoutSeconds).GetCSVYear(context.Background(), symbol, year)
+}
+
+// 获取Csv.xz按年[vv,pe]日频数据
+func GetCSVTagYear(gmcsv string,
+	symbol string, tag string, year int, istimestamp bool, tskey string,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient("", gmcsv, timeoutSeconds).GetCSVTagYear(context.Background(), symbol, tag, year, istimestamp, tskey)
+}
+
+// 按日期范围获取1m分时行情数据
+func GetCSV1m(gmcsv string,
+	symbol string, sdate string, edate string, clip bool,
+	timeoutSeconds int) (OHLCVList, error) {
+	return newFuncClient("", gmcsv, timeoutSeconds).GetCSV1m(context.Background(), symbol, sdate, edate, clip)
+}
+
+// 按日期范围获取[vv,pe]日频行情数据
+// tag string: vv,pe
+func GetCSVTag(gmcsv string,
+	tag string, symbol string, sdate string, edate string, istimestamp bool, clip bool,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient("", gmcsv, timeoutSeconds).GetCSVTag(context.Background(), tag, symbol, sdate, edate, istimestamp, clip)
+}
+
+// 按日期范围获取1m分时行情数据
+func GetGM1m(gmcsv string,
+	gmapi string, symbol string, sdate string, edate string, istimestamp bool, include bool,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, gmcsv, timeoutSeconds).GetGM1m(context.Background(), symbol, sdate, edate, istimestamp, include)
+}
+
+// 按日期范围获取日频行情数据
+func GetGM1d(gmcsv string,
+	gmapi string, symbol string, sdate string, edate string, istimestamp bool, include bool,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, gmcsv, timeoutSeconds).GetGM1d(context.Background(), symbol, sdate, edate, istimestamp, include)
+}
+
+// 按日期范围获取财务衍生行情数据
+func GetGMpe(gmcsv string,
+	gmapi string, symbol string, sdate string, edate string, fields string, istimestamp bool, include bool,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, gmcsv, timeoutSeconds).GetGMpe(context.Background(), symbol, sdate, edate, fields, istimestamp, include)
+}
+
+// 按日频行情数据：包括v931,v932,v935,。。。
+func GetGMvv(gmcsv string,
+	gmapi string, symbol string, sdate string, edate string, indicators string, istimestamp bool, include bool, is1m bool,
+	timeoutSeconds int) (map[string]any, error) {
+	return newFuncClient(gmapi, gmcsv, timeoutSeconds).GetGMvv(context.Background(), symbol, sdate, edate, indicators, istimestamp, include, is1m)
+}
+
+// 按日期列表从gm-api获取单支股票分时行情数据
+func Get1mByDatelist(gmapi string,
+	symbol string, datelist []string, istimestamp bool,
+	timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).Get1mByDatelist(context.Background(), symbol, datelist, istimestamp)
+}
+
+// 查询个股估值指标每日数据(结构体)
+func GetDailyValuationList(gmapi string,
+	symbol string, sdate string, edate string, fields string,
+	timeoutSeconds int) ([]DailyValuation, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetDailyValuationList(context.Background(), symbol, sdate, edate, fields)
+}
+
+// 查询个股分红送股数据(结构体)
+func GetDividendList(gmapi string,
+	symbol string, sdate string, edate string,
+	timeoutSeconds int) ([]Dividend, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetDividendList(context.Background(), symbol, sdate, edate)
+}
+
+// 查询个股复权因子(结构体)
+func GetAdjFactorList(gmapi string,
+	symbol string, sdate string, edate string, bdate string,
+	timeoutSeconds int) ([]AdjFactor, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetAdjFactorList(context.Background(), symbol, sdate, edate, bdate)
+}
+
+// 查询基金复权因子(结构体)
+func GetFndAdjFactorList(gmapi string,
+	fund string, sdate string, edate string, bdate string,
+	timeoutSeconds int) ([]AdjFactor, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetFndAdjFactorList(context.Background(), fund, sdate, edate, bdate)
+}
+
+// 请求标的基本信息(结构体)
+func GetMarketInfoList(gmapi string,
+	symbols string, sec string, exchange string,
+	timeoutSeconds int) ([]SymbolInfo, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetMarketInfoList(context.Background(), symbols, sec, exchange)
+}
+
+// 请求标的某交易日的交易信息(结构体)
+func GetSymbolsInfoList(gmapi string,
+	symbols string, sec string, exchange string, trade_date string,
+	timeoutSeconds int) ([]SymbolInfo, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetSymbolsInfoList(context.Background(), symbols, sec, exchange, trade_date)
+}
+
+// 查询标的交易时间(结构体)
+func GetTradingSessionList(gmapi string, symbols string, timeoutSeconds int) ([]TradingSession, error) {
+	return newFuncClient(gmapi, "", timeoutSeconds).GetTradingSessionList(context.Background(), symbols)
+}
+
+// 获取行情数据并计算技术指标
+func GetIndicators(gmapi string, gmcsv string,
+	symbol string, tag string, sdate string, edate string,
+	names string, params string, adjust string, istimestamp bool, timeoutSeconds int) ([]map[string]any, error) {
+	return newFuncClient(gmapi, gmcsv, timeoutSeconds).GetIndicators(context.Background(),
+		symbol, tag, sdate, edate, names, params, adjust, istimestamp)
+}
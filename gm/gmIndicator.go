@@ -0,0 +1,503 @@
+package gm
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ta "github.com/lmzxtek/ths-go/math"
+)
+
+// 技术指标计算结果: 列名 -> 序列(与K线一一对应, 无效值为 NaN)
+type IndicatorSeries map[string][]float64
+
+// 技术指标定义
+type indicatorDef struct {
+	params   []float64 // 默认参数
+	multiple bool      // 每个参数单独计算一列, 如 ma5, ma10
+	calc     func(k *OHLCVList, p []float64) IndicatorSeries
+	lookback func(p []float64) int // 预热所需的K线数, nil 表示不需要(累计类指标)
+}
+
+// 支持的技术指标, 公式与同花顺一致
+var indicatorDefs = map[string]indicatorDef{
+	"ma":   {params: []float64{5, 10, 20, 60}, multiple: true, calc: calcMA, lookback: windowLookback},
+	"ema":  {params: []float64{12, 26}, multiple: true, calc: calcEMA, lookback: recursiveLookback},
+	"macd": {params: []float64{12, 26, 9}, calc: calcMACD, lookback: macdLookback},
+	"kdj":  {params: []float64{9, 3, 3}, calc: calcKDJ, lookback: kdjLookback},
+	"rsi":  {params: []float64{6, 12, 24}, multiple: true, calc: calcRSI, lookback: rsiLookback},
+	"boll": {params: []float64{20, 2}, calc: calcBOLL, lookback: windowLookback},
+	"atr":  {params: []float64{14}, calc: calcATR, lookback: atrLookback},
+	"obv":  {calc: calcOBV},
+	"vwap": {calc: calcVWAP},
+	"amv":  {params: []float64{5, 13, 34, 60}, multiple: true, calc: calcAMV, lookback: windowLookback},
+}
+
+// 递推类指标(EMA/SMA)的预热倍数: 初值的权重衰减到 1% 以下
+const recursiveWarmup = 5
+
+// 窗口类指标: 周期
+func windowLookback(p []float64) int {
+	return int(math.Ceil(p[0]))
+}
+
+// 递推类指标: 周期的 recursiveWarmup 倍
+func recursiveLookback(p []float64) int {
+	return int(math.Ceil(p[0] * recursiveWarmup))
+}
+
+// RSI: 需要昨收, 多一根K线
+func rsiLookback(p []float64) int {
+	return 1 + recursiveLookback(p)
+}
+
+// ATR: 需要昨收, 多一根K线
+func atrLookback(p []float64) int {
+	return 1 + windowLookback(p)
+}
+
+func macdLookback(p []float64) int {
+	return recursiveLookback([]float64{max(p[0], p[1])}) + recursiveLookback(p[2:])
+}
+
+func kdjLookback(p []float64) int {
+	return windowLookback(p) + recursiveLookback(p[1:]) + recursiveLookback(p[2:])
+}
+
+// 支持的技术指标名称
+func IndicatorNames() []string {
+	names := make([]string, 0, len(indicatorDefs))
+	for name := range indicatorDefs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 按字段取序列: open, high, low, close, volume
+func (k *OHLCVList) Series(field string) []float64 {
+	out := make([]float64, len(*k))
+	for i, kb := range *k {
+		switch field {
+		case "open":
+			out[i] = kb.Open
+		case "high":
+			out[i] = kb.High
+		case "low":
+			out[i] = kb.Low
+		case "close":
+			out[i] = kb.Close
+		case "volume":
+			out[i] = float64(kb.Volume)
+		default:
+			out[i] = math.NaN()
+		}
+	}
+	return out
+}
+
+// MA: 收盘价N周期简单移动平均
+func (k *OHLCVList) MA(n int) []float64 {
+	return ta.MA(k.Series("close"), n)
+}
+
+// EMA: 收盘价N周期指数移动平均
+func (k *OHLCVList) EMA(n int) []float64 {
+	return ta.EMA(k.Series("close"), n)
+}
+
+// MACD: DIF=EMA(C,SHORT)-EMA(C,LONG); DEA=EMA(DIF,MID); MACD=(DIF-DEA)*2
+func (k *OHLCVList) MACD(short int, long int, mid int) (dif []float64, dea []float64, macd []float64) {
+	close := k.Series("close")
+	dif = sub(ta.EMA(close, short), ta.EMA(close, long))
+	dea = ta.EMA(dif, mid)
+	macd = make([]float64, len(dif))
+	for i := range dif {
+		macd[i] = (dif[i] - dea[i]) * 2
+	}
+	return dif, dea, macd
+}
+
+// KDJ: RSV=(C-LLV(L,N))/(HHV(H,N)-LLV(L,N))*100; K=SMA(RSV,M1,1); D=SMA(K,M2,1); J=3*K-2*D
+func (k *OHLCVList) KDJ(n int, m1 int, m2 int) (kk []float64, dd []float64, jj []float64) {
+	close := k.Series("close")
+	hhv := ta.HHV(k.Series("high"), n)
+	llv := ta.LLV(k.Series("low"), n)
+	rsv := make([]float64, len(close))
+	for i := range close {
+		rsv[i] = div(close[i]-llv[i], hhv[i]-llv[i]) * 100
+	}
+	kk = ta.SMA(rsv, m1, 1)
+	dd = ta.SMA(kk, m2, 1)
+	jj = make([]float64, len(kk))
+	for i := range kk {
+		jj[i] = 3*kk[i] - 2*dd[i]
+	}
+	return kk, dd, jj
+}
+
+// RSI: LC=REF(C,1); RSI=SMA(MAX(C-LC,0),N,1)/SMA(ABS(C-LC),N,1)*100
+func (k *OHLCVList) RSI(n int) []float64 {
+	close := k.Series("close")
+	lc := ta.REF(close, 1)
+	up := make([]float64, len(close))
+	abs := make([]float64, len(close))
+	for i := range close {
+		up[i] = math.Max(close[i]-lc[i], 0)
+		abs[i] = math.Abs(close[i] - lc[i])
+		if ta.IsNaN(lc[i]) {
+			up[i], abs[i] = math.NaN(), math.NaN()
+		}
+	}
+	su := ta.SMA(up, n, 1)
+	sa := ta.SMA(abs, n, 1)
+	out := make([]float64, len(close))
+	for i := range close {
+		out[i] = div(su[i], sa[i]) * 100
+	}
+	return out
+}
+
+// BOLL: MID=MA(C,N); UPPER=MID+P*STD(C,N); LOWER=MID-P*STD(C,N)
+func (k *OHLCVList) BOLL(n int, p float64) (mid []float64, upper []float64, lower []float64) {
+	close := k.Series("close")
+	mid = ta.MA(close, n)
+	std := ta.STD(close, n)
+	upper = make([]float64, len(close))
+	lower = make([]float64, len(close))
+	for i := range close {
+		upper[i] = mid[i] + p*std[i]
+		lower[i] = mid[i] - p*std[i]
+	}
+	return mid, upper, lower
+}
+
+// ATR: MTR=MAX(MAX(H-L,ABS(REF(C,1)-H)),ABS(REF(C,1)-L)); ATR=MA(MTR,N)
+//
+// 第一根K线没有昨收, MTR 取 H-L。
+func (k *OHLCVList) ATR(n int) []float64 {
+	lc := ta.REF(k.Series("close"), 1)
+	mtr := make([]float64, len(*k))
+	for i, kb := range *k {
+		mtr[i] = kb.High - kb.Low
+		if !ta.IsNaN(lc[i]) {
+			mtr[i] = math.Max(mtr[i], math.Max(math.Abs(lc[i]-kb.High), math.Abs(lc[i]-kb.Low)))
+		}
+	}
+	return ta.MA(mtr, n)
+}
+
+// OBV: 收盘价上涨累加成交量, 下跌累减成交量, 持平不变(第一根K线为0)
+func (k *OHLCVList) OBV() []float64 {
+	out := make([]float64, len(*k))
+	obv := 0.0
+	for i, kb := range *k {
+		if i > 0 {
+			switch prev := (*k)[i-1].Close; {
+			case kb.Close > prev:
+				obv += float64(kb.Volume)
+			case kb.Close < prev:
+				obv -= float64(kb.Volume)
+			}
+		}
+		out[i] = obv
+	}
+	return out
+}
+
+// VWAP: 当日累计成交均价 SUM((H+L+C)/3*V)/SUM(V), 每个交易日重新累计
+func (k *OHLCVList) VWAP() []float64 {
+	tz := time.FixedZone("CST", 8*3600)
+	out := make([]float64, len(*k))
+	day := ""
+	amt, vol := 0.0, 0.0
+	for i, kb := range *k {
+		if d := kb.Timestamp.In(tz).Format("2006-01-02"); d != day {
+			day = d
+			amt, vol = 0, 0
+		}
+		amt += (kb.High + kb.Low + kb.Close) / 3 * float64(kb.Volume)
+		vol += float64(kb.Volume)
+		out[i] = div(amt, vol)
+	}
+	return out
+}
+
+// AMV(成本价均线): AMOV=VOL*(O+C)/2; AMV=SUM(AMOV,N)/SUM(VOL,N)
+func (k *OHLCVList) AMV(n int) []float64 {
+	amov := make([]float64, len(*k))
+	for i, kb := range *k {
+		amov[i] = float64(kb.Volume) * (kb.Open + kb.Close) / 2
+	}
+	sa := ta.SUM(amov, n)
+	sv := ta.SUM(k.Series("volume"), n)
+	out := make([]float64, len(*k))
+	for i := range out {
+		out[i] = div(sa[i], sv[i])
+	}
+	return out
+}
+
+func calcMA(k *OHLCVList, p []float64) IndicatorSeries {
+	return IndicatorSeries{fmt.Sprintf("ma%d", int(p[0])): k.MA(int(p[0]))}
+}
+
+func calcEMA(k *OHLCVList, p []float64) IndicatorSeries {
+	return IndicatorSeries{fmt.Sprintf("ema%d", int(p[0])): k.EMA(int(p[0]))}
+}
+
+func calcRSI(k *OHLCVList, p []float64) IndicatorSeries {
+	return IndicatorSeries{fmt.Sprintf("rsi%d", int(p[0])): k.RSI(int(p[0]))}
+}
+
+func calcAMV(k *OHLCVList, p []float64) IndicatorSeries {
+	return IndicatorSeries{fmt.Sprintf("amv%d", int(p[0])): k.AMV(int(p[0]))}
+}
+
+func calcMACD(k *OHLCVList, p []float64) IndicatorSeries {
+	dif, dea, macd := k.MACD(int(p[0]), int(p[1]), int(p[2]))
+	return IndicatorSeries{"dif": dif, "dea": dea, "macd": macd}
+}
+
+func calcKDJ(k *OHLCVList, p []float64) IndicatorSeries {
+	kk, dd, jj := k.KDJ(int(p[0]), int(p[1]), int(p[2]))
+	return IndicatorSeries{"k": kk, "d": dd, "j": jj}
+}
+
+func calcBOLL(k *OHLCVList, p []float64) IndicatorSeries {
+	mid, upper, lower := k.BOLL(int(p[0]), p[1])
+	return IndicatorSeries{"boll": mid, "ub": upper, "lb": lower}
+}
+
+func calcATR(k *OHLCVList, p []float64) IndicatorSeries {
+	return IndicatorSeries{"atr": k.ATR(int(p[0]))}
+}
+
+func calcOBV(k *OHLCVList, p []float64) IndicatorSeries {
+	return IndicatorSeries{"obv": k.OBV()}
+}
+
+func calcVWAP(k *OHLCVList, p []float64) IndicatorSeries {
+	return IndicatorSeries{"vwap": k.VWAP()}
+}
+
+// 逐项相减
+func sub(a []float64, b []float64) []float64 {
+	out := make([]float64, len(a))
+	for i := range a {
+		out[i] = a[i] - b[i]
+	}
+	return out
+}
+
+// 除数为0时返回 NaN
+func div(a float64, b float64) float64 {
+	if b == 0 {
+		return math.NaN()
+	}
+	return a / b
+}
+
+// 解析指标参数, 格式: ma=5,10,20|macd=12,26,9|boll=20,2
+func ParseIndicatorParams(params string) (map[string][]float64, error) {
+	out := map[string][]float64{}
+	for _, item := range strings.Split(params, "|") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, vals, ok := strings.Cut(item, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok {
+			return nil, invalidParam("指标参数格式错误: %s (示例: ma=5,10,20|macd=12,26,9)", item)
+		}
+		if _, ok := indicatorDefs[name]; !ok {
+			return nil, invalidParam("未知的技术指标: %s", name)
+		}
+		var p []float64
+		for _, s := range strings.Split(vals, ",") {
+			v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil || v <= 0 {
+				return nil, invalidParam("指标 %s 参数须为正数: %s", name, s)
+			}
+			p = append(p, v)
+		}
+		out[name] = p
+	}
+	return out, nil
+}
+
+// 计算技术指标, names 以逗号分隔(如 ma,macd,kdj), params 见 ParseIndicatorParams
+func (k *OHLCVList) CalcIndicators(names string, params string) (IndicatorSeries, error) {
+	custom, err := ParseIndicatorParams(params)
+	if err != nil {
+		return nil, err
+	}
+	out := IndicatorSeries{}
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		def, ok := indicatorDefs[name]
+		if !ok {
+			return nil, invalidParam("未知的技术指标: %s (可选: %s)", name, strings.Join(IndicatorNames(), ","))
+		}
+		p := def.params
+		if v, ok := custom[name]; ok {
+			p = v
+		}
+		if !def.multiple && len(p) != len(def.params) {
+			return nil, invalidParam("指标 %s 参数个数错误: 需要%d个, 实际%d个", name, len(def.params), len(p))
+		}
+		if !def.multiple {
+			maps.Copy(out, def.calc(k, p))
+			continue
+		}
+		for _, v := range p {
+			maps.Copy(out, def.calc(k, []float64{v}))
+		}
+	}
+	return out, nil
+}
+
+// 计算指标所需的预热K线数(取各指标的最大值), 参数同 OHLCVList.CalcIndicators
+func indicatorLookback(names string, params string) int {
+	custom, _ := ParseIndicatorParams(params)
+	n := 0
+	for _, name := range strings.Split(names, ",") {
+		def, ok := indicatorDefs[strings.ToLower(strings.TrimSpace(name))]
+		if !ok || def.lookback == nil {
+			continue
+		}
+		p := def.params
+		if v, ok := custom[strings.ToLower(strings.TrimSpace(name))]; ok {
+			p = v
+		}
+		if !def.multiple {
+			if len(p) == len(def.params) {
+				n = max(n, def.lookback(p))
+			}
+			continue
+		}
+		for _, v := range p {
+			n = max(n, def.lookback([]float64{v}))
+		}
+	}
+	return n
+}
+
+// 按日K线计算技术指标, 参数同 OHLCVList.CalcIndicators
+func (k *VVList) CalcIndicators(names string, params string) (IndicatorSeries, error) {
+	ohlcv := k.ToOHLCVList()
+	return ohlcv.CalcIndicators(names, params)
+}
+
+// 将指标序列追加到 records 中(原地修改, 与K线按顺序一一对应), NaN 输出为 null
+func (s IndicatorSeries) AppendTo(records []map[string]any) error {
+	for name, vals := range s {
+		if len(vals) != len(records) {
+			return fmt.Errorf("指标 %s 长度与数据不一致: %d != %d", name, len(vals), len(records))
+		}
+	}
+	cols := make([]string, 0, len(s))
+	for name := range s {
+		cols = append(cols, name)
+	}
+	slices.Sort(cols)
+	for i, rec := range records {
+		for _, name := range cols {
+			if v := s[name][i]; !math.IsNaN(v) && !math.IsInf(v, 0) {
+				rec[name] = v
+			} else {
+				rec[name] = nil
+			}
+		}
+	}
+	return nil
+}
+
+// 获取行情数据并计算技术指标
+//
+// tag: 1m(分时), 1d(日线), vv(日频vv指标); adjust: none, qfq, hfq
+//
+// 从 sdate 之前多取指标预热所需的K线参与计算, 返回结果只包含 [sdate, edate] 的数据。
+func (c *Client) GetIndicators(ctx context.Context,
+	symbol string, tag string, sdate string, edate string,
+	names string, params string, adjust string, istimestamp bool) ([]map[string]any, error) {
+
+	// 先检查指标名称和参数, 避免无效请求获取数据
+	if _, err := (&OHLCVList{}).CalcIndicators(names, params); err != nil {
+		return nil, err
+	}
+
+	fetchStart := sdate
+	if n := indicatorLookback(names, params); n > 0 && sdate != "" {
+		days := n
+		if tag == "1m" {
+			days = (n + sessionDayMinutes - 1) / sessionDayMinutes
+		}
+		if d, err := DefaultCalendar().PrevTradingDay(sdate, days); err == nil {
+			fetchStart = d
+		}
+	}
+
+	var records []map[string]any
+	var err error
+	switch tag {
+	case "1m":
+		records, err = c.GetGM1m(ctx, symbol, fetchStart, edate, istimestamp, true)
+	case "1d":
+		records, err = c.GetGM1d(ctx, symbol, fetchStart, edate, istimestamp, true)
+	case "vv":
+		var rawData map[string]any
+		rawData, err = c.GetGMvv(ctx, symbol, fetchStart, edate, "", istimestamp, true, false)
+		records, _ = rawData["1dvv"].([]map[string]any)
+	default:
+		return nil, invalidParam("不支持的数据类型: tag=%s (可选: 1m, 1d, vv)", tag)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if adjust != AdjustNone && adjust != "" {
+		factors, err := c.GetSymbolAdjFactor(ctx, symbol, fetchStart, edate)
+		if err != nil {
+			return nil, fmt.Errorf("获取复权因子失败: %w", err)
+		}
+		if err := AdjustRecords(records, factors, adjust); err != nil {
+			return nil, err
+		}
+	}
+
+	ohlcv := OHLCVList{}
+	if err := ohlcv.FromMapList(records); err != nil {
+		return nil, fmt.Errorf("解析行情数据失败: %w", err)
+	}
+	series, err := ohlcv.CalcIndicators(names, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := series.AppendTo(records); err != nil {
+		return nil, err
+	}
+
+	// 去掉预热部分
+	first := len(ohlcv)
+	for i := range ohlcv {
+		if ohlcv[i].Timestamp.In(cstZone).Format("2006-01-02") >= sdate {
+			first = i
+			break
+		}
+	}
+	if first == len(ohlcv) {
+		return nil, fmt.Errorf("%w: %s: %s - %s", ErrNoData, symbol, sdate, edate)
+	}
+	return records[first:], nil
+}
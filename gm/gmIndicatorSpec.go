@@ -0,0 +1,214 @@
+package gm
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+)
+
+// 黄金价权重: Hjj = (C*收盘价 + O*开盘价 + H*最高价 + L*最低价) / (C+O+H+L)
+type HjjWeights struct {
+	Close float64 `toml:"close"`
+	Open  float64 `toml:"open"`
+	High  float64 `toml:"high"`
+	Low   float64 `toml:"low"`
+}
+
+// 成交量时间窗口: 统计 Start < 时间 <= End 的K线成交量之和, Start 为空表示从开盘起
+type VolWindow struct {
+	Name  string `toml:"name"` // v931, v932, v935, v940, v150
+	Start string `toml:"start"`
+	End   string `toml:"end"`
+}
+
+// 成交量阈值规则
+//   - pct: 取成交量的 (100-Pct) 百分位
+//   - adaptive: K线数<30取均值, <90取中值, 否则取2倍中值(与Python get_cbj_from_1m一致)
+type VmedRule struct {
+	Mode string  `toml:"mode"`
+	Pct  float64 `toml:"pct"`
+}
+
+// vv日频指标定义
+type IndicatorSpec struct {
+	Name      string      `toml:"name"`
+	Windows   []VolWindow `toml:"windows"`    // 成交量窗口
+	SplitTime string      `toml:"split_time"` // cb1/cb2 的分割时间
+	Vmed      VmedRule    `toml:"vmed"`       // cbj 的成交量阈值
+	Weights   HjjWeights  `toml:"weights"`    // hjj, pvj, cbj 的价格权重
+	// cb1/cb2 只统计成交量高于阈值的K线，无数据时取最后一根K线的黄金价(与Python一致)
+	CbFilter bool `toml:"cb_filter"`
+}
+
+// 成交量窗口可用的名称
+var vvWindowNames = []string{"v931", "v932", "v935", "v940", "v150"}
+
+// 默认指标定义(与原有Go实现一致)
+var DefaultIndicatorSpec = IndicatorSpec{
+	Name: "default",
+	Windows: []VolWindow{
+		{Name: "v931", Start: "09:30:00", End: "09:31:00"},
+		{Name: "v932", Start: "09:31:00", End: "09:32:00"},
+		{Name: "v935", Start: "", End: "09:35:00"},
+		{Name: "v940", Start: "09:35:00", End: "09:40:00"},
+		{Name: "v150", Start: "14:59:00", End: "15:00:00"},
+	},
+	SplitTime: "10:00:00",
+	Vmed:      VmedRule{Mode: "pct", Pct: 12.5},
+	Weights:   HjjWeights{Close: 4, Open: 2, High: 1, Low: 1},
+}
+
+// 与Python脚本(ths_v15.py)一致的指标定义
+var PyIndicatorSpec = IndicatorSpec{
+	Name:      "py",
+	Windows:   DefaultIndicatorSpec.Windows,
+	SplitTime: "10:30:00",
+	Vmed:      VmedRule{Mode: "adaptive"},
+	Weights:   DefaultIndicatorSpec.Weights,
+	CbFilter:  true,
+}
+
+var (
+	specMu sync.RWMutex
+	specs  = map[string]IndicatorSpec{
+		DefaultIndicatorSpec.Name: DefaultIndicatorSpec,
+		PyIndicatorSpec.Name:      PyIndicatorSpec,
+	}
+)
+
+// 补全缺省字段并检查定义是否有效
+func (s *IndicatorSpec) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("指标定义缺少 name")
+	}
+	if slices.Contains(allIndicatorNames(), s.Name) {
+		return fmt.Errorf("指标定义名称不能与指标列名相同: %s", s.Name)
+	}
+	// 名称用于 VVStore 的文件名
+	if strings.ContainsAny(s.Name, `/\:`) || strings.Contains(s.Name, "..") {
+		return fmt.Errorf("指标定义名称不能包含路径字符: %s", s.Name)
+	}
+	if len(s.Windows) == 0 {
+		s.Windows = DefaultIndicatorSpec.Windows
+	}
+	for _, w := range s.Windows {
+		if !slices.Contains(vvWindowNames, w.Name) {
+			return fmt.Errorf("指标定义 %s: 不支持的成交量窗口 %s", s.Name, w.Name)
+		}
+		if w.End == "" {
+			return fmt.Errorf("指标定义 %s: 成交量窗口 %s 缺少 end", s.Name, w.Name)
+		}
+	}
+	if s.SplitTime == "" {
+		s.SplitTime = DefaultIndicatorSpec.SplitTime
+	}
+	switch s.Vmed.Mode {
+	case "":
+		s.Vmed = DefaultIndicatorSpec.Vmed
+	case "pct":
+		if s.Vmed.Pct <= 0 || s.Vmed.Pct >= 100 {
+			return fmt.Errorf("指标定义 %s: vmed.pct 须在 (0, 100) 之间", s.Name)
+		}
+	case "adaptive":
+	default:
+		return fmt.Errorf("指标定义 %s: 不支持的 vmed.mode %s", s.Name, s.Vmed.Mode)
+	}
+	w := s.Weights
+	if w == (HjjWeights{}) {
+		s.Weights = DefaultIndicatorSpec.Weights
+	} else if w.Close+w.Open+w.High+w.Low <= 0 {
+		return fmt.Errorf("指标定义 %s: 价格权重之和须大于0", s.Name)
+	}
+	return nil
+}
+
+// 注册(或覆盖)指标定义
+func RegisterIndicatorSpec(spec IndicatorSpec) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	specMu.Lock()
+	defer specMu.Unlock()
+	specs[spec.Name] = spec
+	return nil
+}
+
+// 按名称查询指标定义
+func GetIndicatorSpec(name string) (IndicatorSpec, bool) {
+	specMu.RLock()
+	defer specMu.RUnlock()
+	spec, ok := specs[name]
+	return spec, ok
+}
+
+// 已注册的指标定义名称
+func IndicatorSpecNames() []string {
+	specMu.RLock()
+	defer specMu.RUnlock()
+	names := make([]string, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 从TOML文件读取并注册指标定义, 文件格式:
+//
+//	[[spec]]
+//	name = "py"
+//	split_time = "10:30:00"
+//	cb_filter = true
+//	vmed = { mode = "adaptive" }
+//	weights = { close = 4, open = 2, high = 1, low = 1 }
+func LoadIndicatorSpecs(fpath string) ([]string, error) {
+	var file struct {
+		Spec []IndicatorSpec `toml:"spec"`
+	}
+	if _, err := toml.DecodeFile(fpath, &file); err != nil {
+		return nil, fmt.Errorf("读取指标定义文件失败: %w", err)
+	}
+	var names []string
+	for _, spec := range file.Spec {
+		if err := RegisterIndicatorSpec(spec); err != nil {
+			return names, err
+		}
+		names = append(names, spec.Name)
+	}
+	return names, nil
+}
+
+// 解析 indicators 参数: 可包含一个指标定义名称(如 py)和若干指标列名
+//
+// 未指定指标定义时使用 default; 未指定指标列名时计算全部指标。
+func ParseIndicators(indicators string) (spec IndicatorSpec, isOHLC bool, isV123 bool, isCbj bool, err error) {
+	spec = DefaultIndicatorSpec
+	var cols []string
+	for _, ind := range strings.Split(indicators, ",") {
+		ind = strings.TrimSpace(ind)
+		if ind == "" {
+			continue
+		}
+		if s, ok := GetIndicatorSpec(ind); ok {
+			spec = s
+			continue
+		}
+		if !slices.Contains(allIndicatorNames(), ind) {
+			return spec, false, false, false, invalidParam("未知的指标或指标定义: %s", ind)
+		}
+		cols = append(cols, ind)
+	}
+	isOHLC, isV123, isCbj = CheckIndicators(strings.Join(cols, ","))
+	return spec, isOHLC, isV123, isCbj, nil
+}
+
+// 所有指标列名
+func allIndicatorNames() []string {
+	names := []string{"hjj", "pvj"}
+	names = append(names, vvWindowNames...)
+	return append(names, "vmed", "cbj", "cb1", "cb2", "nup", "ndown")
+}
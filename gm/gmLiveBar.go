@@ -0,0 +1,303 @@
+package gm
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 由行情快照合成当日1m分时K线
+//
+// K线以结束时刻标记(与 get_his 一致): 09:31 为 09:30-09:31 的K线, 集合竞价并入第一根,
+// 11:30 及 15:00 之后的快照并入上午/下午最后一根。成交量为相邻快照 cum_volume 之差,
+// 没有快照的分钟按前一根收盘价补齐(成交量为0)。
+type BarBuilder struct {
+	Symbol string
+
+	day     string // 交易日, 由第一个快照确定
+	bars    OHLCVList
+	lastNm  int       // 最后一根K线的分钟序号
+	lastCum int64     // 上一个快照的 cum_volume
+	lastAt  time.Time // 上一个快照的时间
+	started bool      // 是否已有 cum_volume 基准
+	flushed bool      // 最后一根K线已由 Flush 完成
+}
+
+func NewBarBuilder(symbol string) *BarBuilder {
+	return &BarBuilder{Symbol: symbol}
+}
+
+// 用 get_his 获取的当日K线初始化(启动时已开盘), 之后的快照从最后一根K线继续
+func (b *BarBuilder) Seed(bars OHLCVList) {
+	if len(bars) == 0 {
+		return
+	}
+	b.bars = append(OHLCVList{}, bars...)
+	last := b.bars[len(b.bars)-1].Timestamp.In(cstZone)
+	b.day = last.Format("2006-01-02")
+	b.lastNm = sessionMinute(last)
+	b.lastCum = 0
+	for i := range b.bars {
+		b.lastCum += b.bars[i].Volume
+	}
+	b.started = true
+}
+
+// 快照所在K线的分钟序号, 非当日或集合竞价撮合(09:25)前的快照返回0
+func (b *BarBuilder) snapMinute(t time.Time) int {
+	t = t.In(cstZone)
+	if t.Format("2006-01-02") != b.day {
+		return 0
+	}
+	hm := t.Hour()*60 + t.Minute()
+	if hm < 9*60+25 {
+		return 0
+	}
+	// 快照时刻向上取整到分钟, 即所在K线的结束时刻
+	end := t.Truncate(time.Minute)
+	if !end.Equal(t) {
+		end = end.Add(time.Minute)
+	}
+	return sessionMinute(end)
+}
+
+// 加入一个快照, 返回本次完成的K线(不含当前未完成的K线)
+func (b *BarBuilder) Add(s SnapData) OHLCVList {
+	if s.CreatedAt.IsZero() || s.Price <= 0 || (!b.lastAt.IsZero() && !s.CreatedAt.After(b.lastAt)) {
+		return nil
+	}
+	// 新的交易日重新开始
+	if day := s.CreatedAt.In(cstZone).Format("2006-01-02"); day > b.day {
+		*b = BarBuilder{Symbol: b.Symbol, day: day}
+	}
+	nm := b.snapMinute(s.CreatedAt)
+	if nm == 0 || nm < b.lastNm {
+		return nil
+	}
+	b.lastAt = s.CreatedAt
+
+	// 成交量增量; 未 Seed 且在开盘后才启动时, 第一个快照只作为基准
+	vol := s.CumVolume - b.lastCum
+	if !b.started {
+		vol = 0
+		if nm == 1 {
+			vol = s.CumVolume
+		}
+		b.started = true
+	}
+	if vol < 0 {
+		vol = 0
+	}
+	b.lastCum = s.CumVolume
+	// 已完成的K线不再更新(午休、收盘后的快照)
+	if b.flushed && nm == b.lastNm {
+		return nil
+	}
+
+	var done OHLCVList
+	if len(b.bars) > 0 && nm > b.lastNm {
+		// 上一根K线完成, 中间没有快照的分钟按收盘价补齐
+		if !b.flushed {
+			done = append(done, b.bars[len(b.bars)-1])
+		}
+		prev := b.bars[len(b.bars)-1].Close
+		day := b.bars[len(b.bars)-1].Timestamp.In(cstZone)
+		for m := b.lastNm + 1; m < nm; m++ {
+			bar := OHLCVData{Timestamp: sessionTime(day, m), Open: prev, High: prev, Low: prev, Close: prev}
+			b.bars = append(b.bars, bar)
+			done = append(done, bar)
+		}
+	}
+	if len(b.bars) == 0 || nm > b.lastNm {
+		day, _ := time.ParseInLocation("2006-01-02", b.day, cstZone)
+		b.bars = append(b.bars, OHLCVData{
+			Timestamp: sessionTime(day, nm),
+			Open:      s.Price, High: s.Price, Low: s.Price, Close: s.Price,
+		})
+		b.lastNm = nm
+		b.flushed = false
+	}
+	cur := &b.bars[len(b.bars)-1]
+	cur.High = max(cur.High, s.Price)
+	cur.Low = min(cur.Low, s.Price)
+	cur.Close = s.Price
+	cur.Volume += vol
+	return done
+}
+
+// 完成当前K线并返回(午休和收盘时调用, 11:30 和 15:00 的K线之后没有新的快照来完成它),
+// 没有未完成的K线时返回 nil
+func (b *BarBuilder) Flush() OHLCVList {
+	if len(b.bars) == 0 || b.flushed {
+		return nil
+	}
+	b.flushed = true
+	return OHLCVList{b.bars[len(b.bars)-1]}
+}
+
+// 当日K线(含当前未完成的K线)
+func (b *BarBuilder) Bars() OHLCVList {
+	return append(OHLCVList{}, b.bars...)
+}
+
+// 定时获取行情快照并合成当日1m分时K线, 用于盘中计算 v931, v935, cbj 等指标,
+// 不必每分钟请求 get_his。
+//
+//	p := NewSnapPoller(client, []string{"SHSE.600000"}, 3*time.Second)
+//	p.OnBar = func(symbol string, bar OHLCVData) { ... }
+//	go p.Run(ctx)
+//	vvl := p.VV("SHSE.600000", DefaultIndicatorSpec)
+type SnapPoller struct {
+	Client   *Client
+	Symbols  []string
+	Interval time.Duration // 请求间隔, 默认3秒
+	Seed     bool          // 启动时用 get_his 获取当日已有的K线
+
+	OnBar  func(symbol string, bar OHLCVData) // K线完成时回调(在 Run 所在的 goroutine 中调用)
+	OnSnap func(s SnapData)                   // 收到快照时回调(在该快照完成的K线回调之后)
+
+	mu       sync.RWMutex
+	builders map[string]*BarBuilder
+}
+
+// 更新标的列表(可在 Run 运行时调用), 已有标的的K线保留
+func (p *SnapPoller) SetSymbols(symbols []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Symbols = append([]string(nil), symbols...)
+}
+
+func NewSnapPoller(client *Client, symbols []string, interval time.Duration) *SnapPoller {
+	return &SnapPoller{Client: client, Symbols: symbols, Interval: interval, Seed: true}
+}
+
+// 循环获取快照直到 ctx 取消; 非交易时段(含午休)不请求
+//
+// 进入午休或收盘时再获取一次快照, 然后完成上午/下午的最后一根K线(Flush)。
+func (p *SnapPoller) Run(ctx context.Context) error {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = 3 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	trading := false
+	for {
+		s := DefaultCalendar().SessionAt(time.Now())
+		if open := s == SessionAuction || s.IsTrading(); open || trading {
+			if err := p.Poll(ctx); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				p.Client.Logger.Printf("获取行情快照失败: %v", err)
+			}
+			if trading && !open {
+				p.Flush()
+			}
+			trading = open
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// 获取一次快照并更新K线
+func (p *SnapPoller) Poll(ctx context.Context) error {
+	p.mu.Lock()
+	if p.builders == nil {
+		p.builders = make(map[string]*BarBuilder, len(p.Symbols))
+	}
+	symbols := strings.Join(p.Symbols, ",")
+	p.mu.Unlock()
+	if symbols == "" {
+		return nil
+	}
+
+	snaps, err := p.Client.GetCurrent(ctx, symbols, false)
+	if err != nil {
+		return err
+	}
+	for _, s := range snaps {
+		b := p.builder(ctx, s)
+		p.mu.Lock()
+		done := b.Add(s)
+		p.mu.Unlock()
+		if p.OnBar != nil {
+			for _, bar := range done {
+				p.OnBar(s.Symbol, bar)
+			}
+		}
+		if p.OnSnap != nil {
+			p.OnSnap(s)
+		}
+	}
+	return nil
+}
+
+// 完成各标的当前的K线并回调 OnBar
+func (p *SnapPoller) Flush() {
+	p.mu.Lock()
+	done := make(map[string]OHLCVList, len(p.builders))
+	for sym, b := range p.builders {
+		done[sym] = b.Flush()
+	}
+	p.mu.Unlock()
+	if p.OnBar == nil {
+		return
+	}
+	for sym, bars := range done {
+		for _, bar := range bars {
+			p.OnBar(sym, bar)
+		}
+	}
+}
+
+// 标的的K线合成器, 首次出现时按需用 get_his 初始化
+func (p *SnapPoller) builder(ctx context.Context, s SnapData) *BarBuilder {
+	p.mu.RLock()
+	b := p.builders[s.Symbol]
+	p.mu.RUnlock()
+	if b != nil {
+		return b
+	}
+	b = NewBarBuilder(s.Symbol)
+	if p.Seed {
+		day := s.CreatedAt.In(cstZone).Format("2006-01-02")
+		rawData, err := p.Client.GetKbarsHis(ctx, s.Symbol, "1m", day, day, false)
+		var bars OHLCVList
+		if err == nil {
+			err = bars.FromMapList(rawData)
+		}
+		if err != nil {
+			p.Client.Logger.Printf("获取当日分时数据失败(%s): %v", s.Symbol, err)
+		}
+		b.Seed(bars)
+	}
+	p.mu.Lock()
+	p.builders[s.Symbol] = b
+	p.mu.Unlock()
+	return b
+}
+
+// 标的当日K线(含当前未完成的K线)
+func (p *SnapPoller) Bars(symbol string) OHLCVList {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if b := p.builders[symbol]; b != nil {
+		return b.Bars()
+	}
+	return nil
+}
+
+// 标的当日vv指标
+func (p *SnapPoller) VV(symbol string, spec IndicatorSpec) VVList {
+	bars := p.Bars(symbol)
+	if len(bars) == 0 {
+		return nil
+	}
+	return bars.ToVVListSpec(spec, true, true, true)
+}
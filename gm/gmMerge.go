@@ -0,0 +1,208 @@
+package gm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// 分时数据来源, 合并后记录在 "source" 字段
+const (
+	SourceCSV = "csv" // gm-csv 归档
+	SourceAPI = "api" // gm-api
+)
+
+// 设置 CSV 与 API 数据时间戳相同时优先的来源(csv 或 api), 默认 csv
+func WithMergePrefer(source string) ClientOption {
+	return func(c *Client) { c.MergePrefer = source }
+}
+
+// 分时数据覆盖情况
+type Coverage struct {
+	SDate       string   `json:"sdate"`
+	EDate       string   `json:"edate"`
+	Prefer      string   `json:"prefer"`       // 冲突时优先的来源
+	TradingDays int      `json:"trading_days"` // 日期范围内的交易日数
+	CSVDays     int      `json:"csv_days"`     // 240根K线全部来自CSV的交易日数
+	APIDays     int      `json:"api_days"`     // 从 gm-api 获取的交易日数
+	Bars        int      `json:"bars"`
+	Overlap     int      `json:"overlap"`   // 两个来源都有的K线数
+	Conflicts   int      `json:"conflicts"` // 两个来源都有且价格/成交量不一致的K线数
+	Missing     []string `json:"missing"`   // 没有数据的交易日(停牌、上市前或数据缺失)
+	Partial     []string `json:"partial"`   // 不足240根K线的交易日(含未收盘的当日)
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// 是否完整覆盖所有交易日
+func (cv *Coverage) Complete() bool {
+	return len(cv.Missing) == 0 && len(cv.Partial) == 0 && len(cv.Errors) == 0
+}
+
+// 按时间戳合并两个来源的分时数据, 结果按时间排序并标注来源
+//
+// 时间戳相同时取 prefer 来源的K线; 无法解析时间戳的记录被丢弃。
+// 返回两个来源都有的K线数及其中价格/成交量不一致的K线数。
+func MergeBars(csv []map[string]any, api []map[string]any, prefer string) (merged []map[string]any, overlap int, conflicts int) {
+	type bar struct {
+		ts  int64
+		rec map[string]any
+	}
+	byTs := make(map[int64]int, len(csv)+len(api))
+	var bars []bar
+	add := func(records []map[string]any, source string) {
+		for _, rec := range records {
+			t, err := ParseTimestamp(rec["timestamp"])
+			if err != nil {
+				continue
+			}
+			ts := t.UnixMilli()
+			rec["source"] = source
+			i, ok := byTs[ts]
+			if !ok {
+				byTs[ts] = len(bars)
+				bars = append(bars, bar{ts: ts, rec: rec})
+				continue
+			}
+			if old := bars[i].rec; old["source"] != source {
+				overlap++
+				if !sameBar(old, rec) {
+					conflicts++
+				}
+			}
+			if source == prefer {
+				bars[i].rec = rec
+			}
+		}
+	}
+	add(csv, SourceCSV)
+	add(api, SourceAPI)
+
+	sort.Slice(bars, func(i, j int) bool { return bars[i].ts < bars[j].ts })
+	merged = make([]map[string]any, len(bars))
+	for i := range bars {
+		merged[i] = bars[i].rec
+	}
+	return merged, overlap, conflicts
+}
+
+// 价格和成交量是否一致
+func sameBar(a map[string]any, b map[string]any) bool {
+	for _, key := range []string{"open", "high", "low", "close", "volume"} {
+		va, errA := anyToFloat(a[key])
+		vb, errB := anyToFloat(b[key])
+		if errA != nil || errB != nil || va != vb {
+			return false
+		}
+	}
+	return true
+}
+
+// 每个交易日的K线数
+func barsPerDay(records []map[string]any) map[string]int {
+	n := map[string]int{}
+	for _, rec := range records {
+		if t, err := ParseTimestamp(rec["timestamp"]); err == nil {
+			n[t.In(cstZone).Format("2006-01-02")]++
+		}
+	}
+	return n
+}
+
+// 按日期范围获取1m分时行情数据, 并返回覆盖情况
+//
+// 上月及以前的交易日先从 gm-csv 归档读取; 当月以及归档中不足240根K线的交易日
+// (首个归档交易日之前的除外, 通常为上市前)从 gm-api 获取。两个来源按时间戳合并,
+// 冲突时取 Client.MergePrefer 指定的来源, 每根K线的 "source" 字段标注来源。
+func (c *Client) GetGM1mCoverage(ctx context.Context,
+	symbol string, sdate string, edate string, istimestamp bool, include bool) ([]map[string]any, *Coverage, error) {
+
+	sday := sdate
+	eday := edate
+	if !include {
+		etime, err := time.Parse("2006-01-02", eday)
+		if err != nil {
+			return nil, nil, invalidParam("日期格式错误: %v", err)
+		}
+		eday = etime.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	if sday > eday {
+		return nil, nil, invalidParam("开始日期大于结束日期: sdate=%s, edate=%s", sday, eday)
+	}
+	prefer := c.MergePrefer
+	if prefer == "" {
+		prefer = SourceCSV
+	}
+	if prefer != SourceCSV && prefer != SourceAPI {
+		return nil, nil, invalidParam("不支持的数据来源: %s (可选: csv, api)", prefer)
+	}
+	cov := &Coverage{SDate: sday, EDate: eday, Prefer: prefer}
+
+	dates, err := c.tradingDates(ctx, sday, eday)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取交易日列表失败: %w", err)
+	}
+	cov.TradingDays = len(dates)
+
+	// 上月及以前的数据从归档读取, 归档缺失或读取失败的交易日由 gm-api 补齐
+	var dcsv []map[string]any
+	mEndDate := GetEndOfLastMonth(time.Now()).Format("2006-01-02")
+	if sday <= mEndDate && c.CSVURL != "" {
+		bars, err := c.GetCSV1m(ctx, symbol, sday, min(eday, mEndDate), true)
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		if err != nil && !errors.Is(err, ErrNoData) {
+			cov.Errors = append(cov.Errors, fmt.Sprintf("%s: %v", SourceCSV, err))
+		}
+		dcsv = bars.ToRecords(istimestamp, false)
+	}
+	csvDay := barsPerDay(dcsv)
+
+	first := ""
+	for _, d := range dates {
+		if csvDay[d] > 0 {
+			first = d
+			break
+		}
+	}
+	var apiDates []string
+	for _, d := range dates {
+		if csvDay[d] >= sessionDayMinutes {
+			cov.CSVDays++
+			continue
+		}
+		if d > mEndDate || (first != "" && d >= first) || len(dcsv) == 0 {
+			apiDates = append(apiDates, d)
+		}
+	}
+	cov.APIDays = len(apiDates)
+
+	var dapi []map[string]any
+	if len(apiDates) > 0 {
+		dapi, err = c.Get1mByDatelist(ctx, symbol, apiDates, istimestamp)
+		if err != nil {
+			if ctx.Err() != nil || c.FailFast || len(dapi) == 0 {
+				return nil, nil, err
+			}
+			cov.Errors = append(cov.Errors, fmt.Sprintf("%s: %v", SourceAPI, err))
+		}
+	}
+
+	merged, overlap, conflicts := MergeBars(dcsv, dapi, prefer)
+	cov.Bars, cov.Overlap, cov.Conflicts = len(merged), overlap, conflicts
+	perDay := barsPerDay(merged)
+	for _, d := range dates {
+		switch n := perDay[d]; {
+		case n == 0:
+			cov.Missing = append(cov.Missing, d)
+		case n < sessionDayMinutes:
+			cov.Partial = append(cov.Partial, d)
+		}
+	}
+	if conflicts > 0 {
+		c.Logger.Printf("%s: CSV与API数据不一致的K线 %d 根, 取 %s 数据", symbol, conflicts, prefer)
+	}
+	return merged, cov, nil
+}
@@ -0,0 +1,128 @@
+package gm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// 默认并发请求数
+const DefaultConcurrency = 8
+
+// 单个任务的错误
+type ItemError struct {
+	Index int    // 任务序号
+	Key   string // 任务标识, 如日期
+	Err   error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Key, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// 多个任务的错误(按任务序号排列)
+type ItemErrors []*ItemError
+
+func (e ItemErrors) Error() string {
+	msgs := make([]string, 0, min(len(e), 5))
+	for i, fe := range e {
+		if i == 5 {
+			msgs = append(msgs, fmt.Sprintf("...(共%d个错误)", len(e)))
+			break
+		}
+		msgs = append(msgs, fe.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// 支持 errors.Is/As 匹配其中任一任务的错误
+func (e ItemErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, fe := range e {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// 并发执行任务的设置
+type PoolOptions struct {
+	Concurrency int  // 最大并发数, <=0 时取 DefaultConcurrency
+	FailFast    bool // 出现错误时取消其余任务并立即返回
+}
+
+// 并发执行 keys 对应的任务, 结果与 keys 顺序一致
+//
+// 失败任务的结果为零值, 错误汇总为 ItemErrors 返回;
+// FailFast 时返回第一个错误(*ItemError), 其余任务的 ctx 被取消。
+func RunOrdered[T any](ctx context.Context, keys []string, opts PoolOptions,
+	fn func(ctx context.Context, key string) (T, error)) ([]T, error) {
+
+	n := opts.Concurrency
+	if n <= 0 {
+		n = DefaultConcurrency
+	}
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]T, len(keys))
+	errs := make([]*ItemError, len(keys))
+	var firstErr *ItemError
+	var mu sync.Mutex
+
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res, err := fn(ctx, key)
+			if err != nil {
+				ie := &ItemError{Index: i, Key: key, Err: err}
+				errs[i] = ie
+				if opts.FailFast {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = ie
+						cancel()
+					}
+					mu.Unlock()
+				}
+				return
+			}
+			results[i] = res
+		}(i, key)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return results, firstErr
+	}
+	// 调用方取消时返回 ctx 的错误
+	if err := parent.Err(); err != nil {
+		return results, err
+	}
+	var all ItemErrors
+	for _, e := range errs {
+		if e != nil {
+			all = append(all, e)
+		}
+	}
+	if len(all) > 0 {
+		return results, all
+	}
+	return results, nil
+}
@@ -0,0 +1,271 @@
+package gm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 回放的一根K线
+type ReplayBar struct {
+	Symbol string
+	Bar    OHLCVData
+}
+
+// 回放状态
+type ReplayState struct {
+	Date    string    `json:"date"`
+	Symbols []string  `json:"symbols"`
+	Speed   float64   `json:"speed"`
+	Paused  bool      `json:"paused"`
+	Time    time.Time `json:"time"` // 回放时钟: 最后发出的K线时间, 未开始时为零值
+	Pos     int       `json:"pos"`  // 已发出的K线数
+	Total   int       `json:"total"`
+	Done    bool      `json:"done"`
+}
+
+// 历史分时数据回放: 按时间顺序发出某个交易日的1m K线, 用于收盘后测试盘中工具
+//
+// 相邻K线的间隔按交易时间(午休不计入)除以 Speed, 同一分钟的多个标的同时发出。
+// Pause/Resume/Step/Seek/SetSpeed 可在 Run 运行时从其他 goroutine 调用。
+//
+//	r := NewReplay(client, []string{"SHSE.600000"}, "2025-07-01", 60)
+//	r.OnBar = func(symbol string, bar OHLCVData) { ... }
+//	if err := r.Load(ctx); err != nil { ... }
+//	go r.Run(ctx) // 一直运行到 ctx 取消
+type Replay struct {
+	Client  *Client
+	Symbols []string
+	Date    string
+	Speed   float64 // 回放倍速, 默认1
+
+	OnBar func(symbol string, bar OHLCVData) // 发出K线时回调(在 Run 或 Step 所在的 goroutine 中调用)
+
+	mu     sync.Mutex
+	events []ReplayBar
+	pos    int
+	clock  time.Time
+	paused bool
+	wake   chan struct{} // 控制变化时唤醒 Run
+}
+
+func NewReplay(client *Client, symbols []string, date string, speed float64) *Replay {
+	return &Replay{Client: client, Symbols: symbols, Date: date, Speed: speed, wake: make(chan struct{}, 1)}
+}
+
+// 获取回放日期的分时数据(GetGM1m: 归档优先, 缺失部分从 gm-api 获取)
+func (r *Replay) Load(ctx context.Context) error {
+	if _, err := time.Parse("2006-01-02", r.Date); err != nil {
+		return invalidParam("日期格式错误: %v", err)
+	}
+	if len(r.Symbols) == 0 {
+		return invalidParam("symbols 为必须参数")
+	}
+	var events []ReplayBar
+	for _, sym := range r.Symbols {
+		records, err := r.Client.GetGM1m(ctx, sym, r.Date, r.Date, false, true)
+		if err != nil && !errors.Is(err, ErrNoData) {
+			return fmt.Errorf("获取分时数据失败(%s): %w", sym, err)
+		}
+		var bars OHLCVList
+		if err := bars.FromMapList(records); err != nil {
+			return decodeError("解析分时数据失败("+sym+")", err)
+		}
+		for _, bar := range bars {
+			events = append(events, ReplayBar{Symbol: sym, Bar: bar})
+		}
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("%w: %s 没有分时数据", ErrNoData, r.Date)
+	}
+	r.setEvents(events)
+	return nil
+}
+
+// 设置回放数据并回到开头
+func (r *Replay) setEvents(events []ReplayBar) {
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Bar.Timestamp.Before(events[j].Bar.Timestamp) })
+	r.mu.Lock()
+	r.events, r.pos, r.clock = events, 0, time.Time{}
+	r.mu.Unlock()
+	r.notify()
+}
+
+func (r *Replay) notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// 按时间发出K线, 直到 ctx 取消; 全部发出后等待 Seek 跳回之前的时间再继续
+func (r *Replay) Run(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		if r.pos >= len(r.events) {
+			r.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-r.wake:
+			}
+			continue
+		}
+		var delay time.Duration
+		if r.pos > 0 {
+			delay = tradingGap(r.events[r.pos-1].Bar.Timestamp, r.events[r.pos].Bar.Timestamp)
+			if r.Speed > 0 {
+				delay = time.Duration(float64(delay) / r.Speed)
+			}
+		}
+		paused, pos := r.paused, r.pos
+		r.mu.Unlock()
+
+		if paused {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-r.wake:
+			}
+			continue
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-r.wake:
+			timer.Stop() // 控制变化, 重新计算
+			continue
+		case <-timer.C:
+		}
+
+		r.mu.Lock()
+		if r.pos != pos || r.paused {
+			r.mu.Unlock()
+			continue
+		}
+		batch := r.next()
+		r.mu.Unlock()
+		r.emit(batch)
+	}
+}
+
+// 取出下一分钟的K线(需持有锁)
+func (r *Replay) next() []ReplayBar {
+	if r.pos >= len(r.events) {
+		return nil
+	}
+	ts := r.events[r.pos].Bar.Timestamp
+	end := r.pos
+	for end < len(r.events) && r.events[end].Bar.Timestamp.Equal(ts) {
+		end++
+	}
+	batch := r.events[r.pos:end]
+	r.pos, r.clock = end, ts
+	return batch
+}
+
+func (r *Replay) emit(batch []ReplayBar) {
+	if r.OnBar == nil {
+		return
+	}
+	for _, e := range batch {
+		r.OnBar(e.Symbol, e.Bar)
+	}
+}
+
+// 两根K线之间的交易时间
+func tradingGap(a time.Time, b time.Time) time.Duration {
+	a, b = a.In(cstZone), b.In(cstZone)
+	if a.Format("2006-01-02") != b.Format("2006-01-02") {
+		return b.Sub(a)
+	}
+	return time.Duration(max(sessionMinute(b)-sessionMinute(a), 0)) * time.Minute
+}
+
+// 暂停
+func (r *Replay) Pause() {
+	r.mu.Lock()
+	r.paused = true
+	r.mu.Unlock()
+	r.notify()
+}
+
+// 继续
+func (r *Replay) Resume() {
+	r.mu.Lock()
+	r.paused = false
+	r.mu.Unlock()
+	r.notify()
+}
+
+// 暂停并立即发出之后 n 分钟的K线, 返回发出的K线
+func (r *Replay) Step(n int) []ReplayBar {
+	r.mu.Lock()
+	r.paused = true
+	var out []ReplayBar
+	for i := 0; i < n; i++ {
+		batch := r.next()
+		if len(batch) == 0 {
+			break
+		}
+		out = append(out, batch...)
+	}
+	r.mu.Unlock()
+	r.notify()
+	r.emit(out)
+	return out
+}
+
+// 跳转到 t: t 及之前的K线视为已发出(不回调), 之后从 t 之后的第一根继续
+func (r *Replay) Seek(t time.Time) {
+	r.mu.Lock()
+	r.pos = sort.Search(len(r.events), func(i int) bool { return r.events[i].Bar.Timestamp.After(t) })
+	r.clock = time.Time{}
+	if r.pos > 0 {
+		r.clock = r.events[r.pos-1].Bar.Timestamp
+	}
+	r.mu.Unlock()
+	r.notify()
+}
+
+// 修改回放倍速
+func (r *Replay) SetSpeed(speed float64) {
+	r.mu.Lock()
+	r.Speed = speed
+	r.mu.Unlock()
+	r.notify()
+}
+
+// 已发出的K线(Seek 跳过的也计入)
+func (r *Replay) Bars(symbol string) OHLCVList {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var bars OHLCVList
+	for _, e := range r.events[:r.pos] {
+		if e.Symbol == symbol {
+			bars = append(bars, e.Bar)
+		}
+	}
+	return bars
+}
+
+// 当前状态
+func (r *Replay) State() ReplayState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return ReplayState{
+		Date:    r.Date,
+		Symbols: r.Symbols,
+		Speed:   r.Speed,
+		Paused:  r.paused,
+		Time:    r.clock,
+		Pos:     r.pos,
+		Total:   len(r.events),
+		Done:    r.pos >= len(r.events),
+	}
+}
@@ -0,0 +1,201 @@
+package gm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A股交易时段(分钟): 上午 09:30-11:30 共120分钟, 下午 13:00-15:00 共120分钟
+const (
+	sessionAmMinutes  = 120
+	sessionDayMinutes = 240
+)
+
+// 重采样周期
+type resampleFreq struct {
+	minutes int    // 分钟周期, 0 表示日/周/月
+	period  string // D, W, M
+}
+
+// 解析周期参数:
+//   - 1m, 5m, 15m, 30m, 60m(1h), 120m 等N分钟周期(1 <= N <= 240)
+//   - 1d(D), 1w(W), 1M(M)
+func parseResampleFreq(freq string) (resampleFreq, error) {
+	f := strings.TrimSpace(freq)
+	switch f {
+	case "D", "d", "1d":
+		return resampleFreq{period: "D"}, nil
+	case "W", "w", "1w":
+		return resampleFreq{period: "W"}, nil
+	case "M", "1M", "mo", "1mo":
+		return resampleFreq{period: "M"}, nil
+	case "1h":
+		return resampleFreq{minutes: 60}, nil
+	}
+	if n, ok := strings.CutSuffix(f, "m"); ok {
+		nm, err := strconv.Atoi(n)
+		if err == nil && nm >= 1 && nm <= sessionDayMinutes {
+			return resampleFreq{minutes: nm}, nil
+		}
+	}
+	return resampleFreq{}, invalidParam("不支持的周期: %s (可选: 1m, 5m, 15m, 30m, 60m, 1d, W, M)", freq)
+}
+
+// 是否为有效周期参数
+func IsResampleFreq(freq string) bool {
+	_, err := parseResampleFreq(freq)
+	return err == nil
+}
+
+// 分时K线所在交易时段的分钟序号(1~240), 09:30 集合竞价并入第一根, 15:00 以后并入最后一根
+func sessionMinute(ts time.Time) int {
+	hm := ts.Hour()*60 + ts.Minute()
+	switch {
+	case hm <= 9*60+30:
+		return 1
+	case hm <= 11*60+30:
+		return hm - (9*60 + 30)
+	case hm <= 13*60:
+		return sessionAmMinutes
+	case hm <= 15*60:
+		return sessionAmMinutes + hm - 13*60
+	}
+	return sessionDayMinutes
+}
+
+// 分钟序号转为时刻
+func sessionTime(day time.Time, nm int) time.Time {
+	start := 9*60 + 30
+	if nm > sessionAmMinutes {
+		start = 13*60 - sessionAmMinutes
+	}
+	hm := start + nm
+	return time.Date(day.Year(), day.Month(), day.Day(), hm/60, hm%60, 0, 0, day.Location())
+}
+
+// 分钟序号所在N分钟K线的结束序号, 不跨越午休
+func sessionBucket(nm int, n int) int {
+	if nm <= sessionAmMinutes {
+		return min((nm+n-1)/n*n, sessionAmMinutes)
+	}
+	off := nm - sessionAmMinutes
+	return sessionAmMinutes + min((off+n-1)/n*n, sessionAmMinutes)
+}
+
+// 按周期重采样
+//
+// 分钟周期按交易时段切分(不跨越午休), 以K线结束时刻标记, 如60m为 10:30/11:30/14:00/15:00;
+// 日/周/月周期以该周期内最后一个交易日标记。开盘价取周期内第一根K线, 收盘价取最后一根, 成交量求和。
+func (k *OHLCVList) Resample(freq string) (OHLCVList, error) {
+	f, err := parseResampleFreq(freq)
+	if err != nil {
+		return nil, err
+	}
+
+	tz := time.FixedZone("CST", 8*3600) // 北京时区
+	sorted := make(OHLCVList, len(*k))
+	copy(sorted, *k)
+	sorted.Sort(false)
+
+	var kbList OHLCVList
+	var group OHLCVList
+	var label time.Time
+	key := ""
+	flush := func() {
+		if len(group) == 0 {
+			return
+		}
+		kb := group.ToOHLCVData(false)
+		kb.Timestamp = label
+		kbList = append(kbList, kb)
+		group = nil
+	}
+	for _, kb := range sorted {
+		ts := kb.Timestamp.In(tz)
+		day := time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, tz)
+		var gkey string
+		var glabel time.Time
+		switch f.period {
+		case "D":
+			gkey, glabel = day.Format("2006-01-02"), day
+		case "W":
+			y, w := ts.ISOWeek()
+			gkey, glabel = fmt.Sprintf("%d-W%02d", y, w), day
+		case "M":
+			gkey, glabel = day.Format("2006-01"), day
+		default:
+			glabel = sessionTime(day, sessionBucket(sessionMinute(ts), f.minutes))
+			gkey = glabel.Format("2006-01-02 15:04")
+		}
+		if gkey != key {
+			flush()
+			key = gkey
+		}
+		label = glabel
+		group = append(group, kb)
+	}
+	flush()
+	return kbList, nil
+}
+
+// 转换为 records, 日/周/月周期的时间格式为 2006-01-02
+func (k *OHLCVList) ToRecords(istimestamp bool, isDaily bool) []map[string]any {
+	tz := time.FixedZone("CST", 8*3600) // 北京时区
+	records := make([]map[string]any, 0, len(*k))
+	for _, kb := range *k {
+		rec := map[string]any{
+			"open":   kb.Open,
+			"high":   kb.High,
+			"low":    kb.Low,
+			"close":  kb.Close,
+			"volume": kb.Volume,
+		}
+		if kb.Amount != 0 {
+			rec["amount"] = kb.Amount
+		}
+		switch {
+		case istimestamp:
+			rec["timestamp"] = kb.Timestamp.UnixMilli()
+		case isDaily:
+			rec["timestamp"] = kb.Timestamp.In(tz).Format("2006-01-02")
+		default:
+			rec["timestamp"] = kb.Timestamp.In(tz).Format("2006-01-02 15:04:05")
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+// 对分时 records 按周期重采样, freq 为 1m 时原样返回
+func ResampleRecords(records []map[string]any, freq string, istimestamp bool) ([]map[string]any, error) {
+	f, err := parseResampleFreq(freq)
+	if err != nil {
+		return nil, err
+	}
+	if f.minutes == 1 {
+		return records, nil
+	}
+	ohlcv := OHLCVList{}
+	if err := ohlcv.FromMapList(records); err != nil {
+		return nil, fmt.Errorf("解析分时数据失败: %w", err)
+	}
+	return ohlcv.ResampleRecords(freq, istimestamp)
+}
+
+// 按周期重采样并转换为 records, freq 为 1m 时不重采样
+func (k *OHLCVList) ResampleRecords(freq string, istimestamp bool) ([]map[string]any, error) {
+	f, err := parseResampleFreq(freq)
+	if err != nil {
+		return nil, err
+	}
+	if f.minutes == 1 {
+		return k.ToRecords(istimestamp, false), nil
+	}
+	kbList, err := k.Resample(freq)
+	if err != nil {
+		return nil, err
+	}
+	return kbList.ToRecords(istimestamp, f.minutes == 0), nil
+}
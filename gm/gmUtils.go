@@ -33,7 +33,11 @@ func Records2Timestamp(records []map[string]any, istimestamp bool, tskey string)
 		for k, v := range records[i] {
 			if k == tskey {
 				key := "timestamp"
-				tstr := v.(string)
+				tstr, ok := v.(string)
+				if !ok {
+					dd1[key] = v
+					continue
+				}
 				tt, err := ParseTimestamp(tstr)
 				if err != nil {
 					continue
@@ -65,7 +69,11 @@ func ConvertEob2Timestamp(records []map[string]any, istimestamp bool) []map[stri
 			// if k == "timestamp" || k == "eob" || k == "trade_date" {
 			if k == "timestamp" || k == "eob" {
 				key := "timestamp"
-				tstr := v.(string)
+				tstr, ok := v.(string)
+				if !ok {
+					dd1[key] = v
+					continue
+				}
 				tt, err := ParseTimestamp(tstr)
 				if err != nil {
 					continue
@@ -557,14 +565,12 @@ func IsChineseStockMarketOpen() bool {
 	return IsChineseStockMarketOpenAt(now)
 }
 
-// 判断当前时间是否开盘
+// 判断当前时间是否开盘(按默认交易日历判断是否为交易日)
 func IsAOpen() bool {
 	// 获取当前中国时间
 	chinaLocation, _ := time.LoadLocation("Asia/Shanghai")
 	now := time.Now().In(chinaLocation)
-	// 检查是否为工作日（周一到周五）
-	weekday := now.Weekday()
-	if weekday == time.Saturday || weekday == time.Sunday {
+	if !DefaultCalendar().IsTradingDay(now.Format("2006-01-02")) {
 		return false
 	}
 
@@ -577,14 +583,12 @@ func IsAOpen() bool {
 	return currentTime >= morningStart
 }
 
-// 判断当前时间是否开盘
+// 判断当前时间是否收盘(按默认交易日历判断是否为交易日)
 func IsAClose() bool {
 	// 获取当前中国时间
 	chinaLocation, _ := time.LoadLocation("Asia/Shanghai")
 	now := time.Now().In(chinaLocation)
-	// 检查是否为工作日（周一到周五）
-	weekday := now.Weekday()
-	if weekday == time.Saturday || weekday == time.Sunday {
+	if !DefaultCalendar().IsTradingDay(now.Format("2006-01-02")) {
 		return false
 	}
 
@@ -592,73 +596,39 @@ func IsAClose() bool {
 	minute := now.Minute()
 	currentTime := hour*100 + minute // 转换为HHMM格式便于比较
 	// 下午收盘时间：15:00
-	afternoonEnd := 1500 // 9:30
+	afternoonEnd := 1500 // 15:00
 
 	return currentTime >= afternoonEnd
 }
 
 // IsChineseStockMarketOpenAt 判断指定时间是否为中国股市开市时间
+//
+// 上午 9:30-11:30, 下午 13:00-15:00, 节假日按默认交易日历判断
 func IsChineseStockMarketOpenAt(t time.Time) bool {
-	// 检查是否为工作日（周一到周五）
-	weekday := t.Weekday()
-	if weekday == time.Saturday || weekday == time.Sunday {
-		return false
-	}
-
-	// 获取当前时间的小时和分钟
-	hour := t.Hour()
-	minute := t.Minute()
-	currentTime := hour*100 + minute // 转换为HHMM格式便于比较
-
-	// 上午交易时间：9:30-11:30
-	morningStart := 930 // 9:30
-	morningEnd := 1130  // 11:30
-
-	// 下午交易时间：13:00-15:00
-	afternoonStart := 1300 // 13:00
-	afternoonEnd := 1500   // 15:00
-
-	// 判断是否在交易时间内
-	isInMorningSession := currentTime >= morningStart && currentTime <= morningEnd
-	isInAfternoonSession := currentTime >= afternoonStart && currentTime <= afternoonEnd
-
-	return isInMorningSession || isInAfternoonSession
+	return DefaultCalendar().SessionAt(t).IsTrading()
 }
 
-// GetNextTradingTime 获取下一个交易时间
+// GetNextTradingTime 获取下一个交易时间(交易时段内返回当前时间)
 func GetNextTradingTime() time.Time {
 	chinaLocation, _ := time.LoadLocation("Asia/Shanghai")
 	now := time.Now().In(chinaLocation)
-
-	// 如果当前是工作日
-	if now.Weekday() >= time.Monday && now.Weekday() <= time.Friday {
-		hour := now.Hour()
-		minute := now.Minute()
-		currentTime := hour*100 + minute
-
-		// 如果在上午开市前
-		if currentTime < 930 {
-			return time.Date(now.Year(), now.Month(), now.Day(), 9, 30, 0, 0, chinaLocation)
-		}
-		// 如果在午休时间
-		if currentTime > 1130 && currentTime < 1300 {
-			return time.Date(now.Year(), now.Month(), now.Day(), 13, 0, 0, 0, chinaLocation)
-		}
-		// 如果在收市后，返回下一个交易日上午开市时间
-		if currentTime > 1500 {
-			nextDay := now.AddDate(0, 0, 1)
-			for nextDay.Weekday() == time.Saturday || nextDay.Weekday() == time.Sunday {
-				nextDay = nextDay.AddDate(0, 0, 1)
-			}
-			return time.Date(nextDay.Year(), nextDay.Month(), nextDay.Day(), 9, 30, 0, 0, chinaLocation)
-		}
+	cal := DefaultCalendar()
+
+	switch cal.SessionAt(now) {
+	case SessionMorning, SessionAfternoon:
+		return now
+	case SessionPreOpen, SessionAuction:
+		return time.Date(now.Year(), now.Month(), now.Day(), 9, 30, 0, 0, chinaLocation)
+	case SessionLunch:
+		return time.Date(now.Year(), now.Month(), now.Day(), 13, 0, 0, 0, chinaLocation)
 	}
 
-	// 如果是周末，找到下周一
-	nextDay := now
-	for nextDay.Weekday() != time.Monday {
-		nextDay = nextDay.AddDate(0, 0, 1)
+	// 收盘后或非交易日，返回下一个交易日上午开市时间
+	next, err := cal.NextTradingDay(now.Format("2006-01-02"), 1)
+	if err != nil {
+		return time.Time{}
 	}
+	nextDay, _ := time.ParseInLocation("2006-01-02", next, chinaLocation)
 	return time.Date(nextDay.Year(), nextDay.Month(), nextDay.Day(), 9, 30, 0, 0, chinaLocation)
 }
 
@@ -0,0 +1,292 @@
+package gm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lmzxtek/ths-go/cxz"
+)
+
+// vv日线指标的本地增量存储
+//
+// 每支股票保存为 {Dir}/{symbol}-vv.csv.xz (非默认指标定义为 {symbol}-vv-{spec}.csv.xz)，
+// 更新时只获取缺失交易日的分时数据，合并后原子写回文件。
+// 已获取但没有数据的交易日(停牌)记录在同名的 -empty.json 文件中，之后不再重复获取。
+type VVStore struct {
+	Dir     string // 数据目录
+	Gmcsv   string // gm-csv 服务地址
+	Gmapi   string // gm-api 服务地址
+	Timeout int    // 请求超时时间(秒)
+
+	Spec IndicatorSpec // 指标定义
+}
+
+func NewVVStore(dir string, gmcsv string, gmapi string, timeoutSeconds int) *VVStore {
+	return &VVStore{
+		Dir:     dir,
+		Gmcsv:   gmcsv,
+		Gmapi:   gmapi,
+		Timeout: timeoutSeconds,
+		Spec:    DefaultIndicatorSpec,
+	}
+}
+
+// 单支股票的文件路径, 不同指标定义的数据分开保存
+func (s *VVStore) Path(symbol string) string {
+	name := symbol + "-vv"
+	if s.Spec.Name != "" && s.Spec.Name != DefaultIndicatorSpec.Name {
+		name += "-" + s.Spec.Name
+	}
+	return filepath.Join(s.Dir, name+".csv.xz")
+}
+
+// 记录没有数据的交易日的文件路径
+func (s *VVStore) EmptyPath(symbol string) string {
+	return strings.TrimSuffix(s.Path(symbol), ".csv.xz") + "-empty.json"
+}
+
+// 没有数据的交易日文件格式
+type vvEmptyDays struct {
+	Empty []string `json:"empty"`
+}
+
+// 读取没有数据的交易日，文件不存在时返回空集合
+func (s *VVStore) loadEmpty(symbol string) (map[string]bool, error) {
+	fpath := s.EmptyPath(symbol)
+	data, err := os.ReadFile(fpath)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %s: %w", fpath, err)
+	}
+	var ed vvEmptyDays
+	if err := json.Unmarshal(data, &ed); err != nil {
+		return nil, fmt.Errorf("解析文件失败: %s: %w", fpath, err)
+	}
+	empty := make(map[string]bool, len(ed.Empty))
+	for _, day := range ed.Empty {
+		empty[day] = true
+	}
+	return empty, nil
+}
+
+// 按日期升序写入没有数据的交易日(临时文件+重命名)
+func (s *VVStore) saveEmpty(symbol string, empty map[string]bool) error {
+	ed := vvEmptyDays{Empty: make([]string, 0, len(empty))}
+	for day := range empty {
+		ed.Empty = append(ed.Empty, day)
+	}
+	sort.Strings(ed.Empty)
+	data, err := json.MarshalIndent(ed, "", "  ")
+	if err != nil {
+		return err
+	}
+	fpath := s.EmptyPath(symbol)
+	if err := os.MkdirAll(filepath.Dir(fpath), 0o755); err != nil {
+		return fmt.Errorf("无法创建目录: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(fpath), filepath.Base(fpath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("无法创建临时文件: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	return os.Rename(tmp.Name(), fpath)
+}
+
+// 读取本地已保存的数据，文件不存在时返回空列表
+func (s *VVStore) Load(symbol string) (VVList, error) {
+	fpath := s.Path(symbol)
+	if _, err := os.Stat(fpath); os.IsNotExist(err) {
+		return VVList{}, nil
+	}
+
+	records, err := cxz.ReadCSVxzFile(fpath)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %s: %w", fpath, err)
+	}
+	if len(records) == 0 {
+		return VVList{}, nil
+	}
+
+	header := records[0]
+	vvl := make(VVList, 0, len(records)-1)
+	for i, row := range records[1:] {
+		var vv VVData
+		if err := vv.FromCSVRow(header, row); err != nil {
+			return nil, fmt.Errorf("解析文件第%d行失败: %s: %w", i+2, fpath, err)
+		}
+		vvl = append(vvl, vv)
+	}
+	return vvl, nil
+}
+
+// 按日期升序写入文件(临时文件+重命名)
+func (s *VVStore) Save(symbol string, vvl VVList) error {
+	vvl.Sort(false)
+	records := make([][]string, 0, len(vvl)+1)
+	records = append(records, VVColumns)
+	for i := range vvl {
+		records = append(records, vvl[i].ToCSVRow())
+	}
+	return cxz.SaveCSVxzAtomic(records, s.Path(symbol))
+}
+
+// 更新单支股票 [sdate, edate] 区间内的数据，并返回该区间的数据(按日期升序)
+//
+// 只获取本地缺失且未记录为没有数据的交易日; 当天未收盘时当天数据会返回但不写入文件。
+func (s *VVStore) Update(symbol string, sdate string, edate string) (VVList, error) {
+	if sdate > edate {
+		return nil, fmt.Errorf("开始日期大于结束日期: sdate=%s, edate=%s", sdate, edate)
+	}
+
+	local, err := s.Load(symbol)
+	if err != nil {
+		return nil, err
+	}
+	empty, err := s.loadEmpty(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	dates, err := GetDatesList(s.Gmapi, sdate, edate, s.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易日列表失败: %w", err)
+	}
+
+	today := time.Now().In(time.FixedZone("CST", 8*3600)).Format("2006-01-02")
+	missing := missingDates(dates, local, empty)
+
+	client := newFuncClient(s.Gmapi, s.Gmcsv, s.Timeout)
+	fetched := VVList{}
+	newEmpty := 0
+	for _, run := range splitDateRuns(dates, missing) {
+		rawData, cov, err := client.GetGM1mCoverage(context.Background(), symbol, run[0], run[len(run)-1], false, true)
+		if err != nil {
+			return nil, fmt.Errorf("获取分时数据失败: %s - %s: %w", run[0], run[len(run)-1], err)
+		}
+		ohlcv := OHLCVList{}
+		if err := ohlcv.FromMapList(rawData); err != nil {
+			return nil, fmt.Errorf("解析分时数据失败: %w", err)
+		}
+		fetched = append(fetched, ohlcv.ToVVListSpec(s.Spec, true, true, true)...)
+
+		// 所有来源都正常返回时, 没有数据的交易日(当天除外)记为停牌
+		if len(cov.Errors) > 0 {
+			continue
+		}
+		for _, day := range cov.Missing {
+			if day < today && !empty[day] {
+				empty[day] = true
+				newEmpty++
+			}
+		}
+	}
+	if newEmpty > 0 {
+		if err := s.saveEmpty(symbol, empty); err != nil {
+			return nil, fmt.Errorf("保存数据失败: %w", err)
+		}
+	}
+
+	// 当天未收盘的数据不写入文件
+	toSave := VVList{}
+	for i := range fetched {
+		if fetched[i].DateString() == today && !IsAClose() {
+			continue
+		}
+		toSave = append(toSave, fetched[i])
+	}
+
+	merged := mergeVVList(local, toSave)
+	if len(toSave) > 0 {
+		if err := s.Save(symbol, merged); err != nil {
+			return nil, fmt.Errorf("保存数据失败: %w", err)
+		}
+	}
+
+	result := mergeVVList(merged, fetched)
+	return result.Between(sdate, edate), nil
+}
+
+// 返回日期在 [sdate, edate] 区间内的数据(按日期升序)
+func (k VVList) Between(sdate string, edate string) VVList {
+	var vvl VVList
+	for i := range k {
+		day := k[i].DateString()
+		if day >= sdate && day <= edate {
+			vvl = append(vvl, k[i])
+		}
+	}
+	vvl.Sort(false)
+	return vvl
+}
+
+// 计算本地数据中缺失的交易日(不含已记录为没有数据的交易日)
+func missingDates(dates []string, local VVList, empty map[string]bool) []string {
+	have := make(map[string]bool, len(local))
+	for i := range local {
+		have[local[i].DateString()] = true
+	}
+	var missing []string
+	for _, day := range dates {
+		if !have[day] && !empty[day] {
+			missing = append(missing, day)
+		}
+	}
+	return missing
+}
+
+// 将缺失日期按交易日列表中的连续区间分组，每组只请求一次
+func splitDateRuns(dates []string, missing []string) [][]string {
+	isMissing := make(map[string]bool, len(missing))
+	for _, day := range missing {
+		isMissing[day] = true
+	}
+	var runs [][]string
+	var run []string
+	for _, day := range dates {
+		if isMissing[day] {
+			run = append(run, day)
+			continue
+		}
+		if len(run) > 0 {
+			runs = append(runs, run)
+			run = nil
+		}
+	}
+	if len(run) > 0 {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// 合并两组数据，同一交易日以 newer 为准，结果按日期升序
+func mergeVVList(older VVList, newer VVList) VVList {
+	byDay := make(map[string]VVData, len(older)+len(newer))
+	for i := range older {
+		byDay[older[i].DateString()] = older[i]
+	}
+	for i := range newer {
+		byDay[newer[i].DateString()] = newer[i]
+	}
+	merged := make(VVList, 0, len(byDay))
+	for _, vv := range byDay {
+		merged = append(merged, vv)
+	}
+	merged.Sort(false)
+	return merged
+}
@@ -0,0 +1,382 @@
+package gm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// 归档校验问题类型
+const (
+	IssueMissingFile    = "missing_file"    // 文件不存在
+	IssueDecode         = "decode"          // 文件无法解析
+	IssueMissingDay     = "missing_day"     // 交易日没有数据
+	IssueSuspended      = "suspended"       // 交易日没有数据, get_his 也没有日K线(停牌, 仅作提示)
+	IssueMissingMinutes = "missing_minutes" // 交易日分钟数不足240
+	IssueDuplicate      = "duplicate"       // 时间戳重复
+	IssueNotMonotonic   = "not_monotonic"   // 时间戳未递增
+	IssueOffSession     = "off_session"     // 非交易时段的K线
+	IssueDayLeak        = "day_leak"        // 不属于该文件月份/年份或非交易日的K线
+	IssueOHLC           = "ohlc"            // 价格不满足 low <= open,close <= high 或价格/成交量为负
+	IssueZeroVolume     = "zero_volume_run" // 连续零成交量(停牌、涨跌停封板时属正常, 仅作提示)
+)
+
+// 校验问题
+type VerifyIssue struct {
+	Kind   string `json:"kind"`
+	File   string `json:"file"`
+	Date   string `json:"date,omitempty"`
+	Time   string `json:"time,omitempty"`
+	Count  int    `json:"count,omitempty"`
+	Detail string `json:"detail,omitempty"`
+	Warn   bool   `json:"warn,omitempty"` // 仅提示, 不列入修复计划
+}
+
+// 归档校验结果
+type VerifyReport struct {
+	Symbol      string        `json:"symbol"`
+	SDate       string        `json:"sdate"`
+	EDate       string        `json:"edate"`
+	Files       int           `json:"files"`
+	TradingDays int           `json:"trading_days"`
+	Bars        int           `json:"bars"`
+	Issues      []VerifyIssue `json:"issues"`
+	BadDays     []string      `json:"bad_days"` // 需要重新获取的交易日
+}
+
+// 没有需要修复的问题
+func (r *VerifyReport) OK() bool {
+	return len(r.BadDays) == 0
+}
+
+// 修复计划: 需要从 gm-api 重新获取的交易日, 及需要重新生成的文件
+type RepairPlan struct {
+	Symbol string      `json:"symbol"`
+	Days   []RepairDay `json:"days"`
+	Files  []string    `json:"files"`
+}
+
+type RepairDay struct {
+	Date    string   `json:"date"`
+	File    string   `json:"file"`
+	Reasons []string `json:"reasons"`
+}
+
+// 由校验结果生成修复计划(不含仅提示的问题)
+func (r *VerifyReport) RepairPlan() *RepairPlan {
+	plan := &RepairPlan{Symbol: r.Symbol}
+	idx := map[string]int{}
+	files := map[string]bool{}
+	for _, is := range r.Issues {
+		if is.Warn || is.Date == "" {
+			continue
+		}
+		i, ok := idx[is.Date]
+		if !ok {
+			i = len(plan.Days)
+			idx[is.Date] = i
+			plan.Days = append(plan.Days, RepairDay{Date: is.Date, File: is.File})
+		}
+		d := &plan.Days[i]
+		if len(d.Reasons) == 0 || d.Reasons[len(d.Reasons)-1] != is.Kind {
+			d.Reasons = append(d.Reasons, is.Kind)
+		}
+		if !files[is.File] {
+			files[is.File] = true
+			plan.Files = append(plan.Files, is.File)
+		}
+	}
+	sort.Slice(plan.Days, func(i, j int) bool { return plan.Days[i].Date < plan.Days[j].Date })
+	return plan
+}
+
+// 校验参数
+type VerifyOptions struct {
+	MonthOnly     bool // 只校验月文件(默认与 GetCSV1m 一致: 首尾年份按月文件, 中间年份按年文件)
+	ZeroVolumeRun int  // 连续零成交量分钟数达到该值时提示, 默认30
+}
+
+// 单个交易日的统计
+type verifyDay struct {
+	minutes [sessionDayMinutes + 1]bool
+	zeroRun int    // 当前连续零成交量的分钟数
+	zeroAt  string // 开始时刻
+}
+
+// 校验 gm-csv 归档中的分时数据(本地目录或 gm-csv 服务, 取决于 CSVURL)
+//
+// 按交易日历逐日检查240根分钟K线是否齐全, 以及时间戳递增、OHLC 关系、连续零成交量、
+// 跨日/跨文件数据。没有数据的交易日用 get_his 日K线核对, 停牌日仅作提示。
+// 当日数据尚不完整, edate 不早于今天时只校验到昨天。
+func (c *Client) VerifyArchive(ctx context.Context, symbol string, sdate string, edate string, opts VerifyOptions) (*VerifyReport, error) {
+	if len(symbol) < 7 {
+		return nil, invalidParam("股票代码格式错误: %s (示例: SHSE.600000)", symbol)
+	}
+	now := time.Now().In(cstZone)
+	if yesterday := now.AddDate(0, 0, -1).Format("2006-01-02"); edate == "" || edate > yesterday {
+		edate = yesterday
+	}
+	st, err := time.Parse("2006-01-02", sdate)
+	if err != nil {
+		return nil, invalidParam("日期格式错误: %v", err)
+	}
+	et, err := time.Parse("2006-01-02", edate)
+	if err != nil {
+		return nil, invalidParam("日期格式错误: %v", err)
+	}
+	if st.After(et) {
+		return nil, invalidParam("开始日期大于结束日期: %s > %s", sdate, edate)
+	}
+	if opts.ZeroVolumeRun <= 0 {
+		opts.ZeroVolumeRun = 30
+	}
+
+	dates, err := c.tradingDates(ctx, sdate, edate)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易日列表失败: %w", err)
+	}
+	isDay := make(map[string]bool, len(dates))
+	for _, d := range dates {
+		isDay[d] = true
+	}
+
+	var files []ArchiveTask
+	if opts.MonthOnly {
+		// 逐年生成, 每年均按月文件
+		for y := st.Year(); y <= et.Year(); y++ {
+			ys, ye := time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(y, 12, 31, 0, 0, 0, 0, time.UTC)
+			if y == st.Year() {
+				ys = st
+			}
+			if y == et.Year() {
+				ye = et
+			}
+			files = append(files, csv1mFiles(symbol, ys, ye, now)...)
+		}
+	} else {
+		files = csv1mFiles(symbol, st, et, now)
+	}
+
+	report := &VerifyReport{Symbol: symbol, SDate: sdate, EDate: edate, TradingDays: len(dates)}
+	days := map[string]*verifyDay{}
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		report.Files++
+		if err := c.verifyFile(ctx, f, sdate, edate, isDay, days, report, opts); err != nil {
+			return report, err
+		}
+	}
+
+	// 逐个交易日检查分钟数
+	bad := map[string]bool{}
+	for _, is := range report.Issues {
+		if !is.Warn && is.Date != "" {
+			bad[is.Date] = true
+		}
+	}
+	var absent []string
+	for _, d := range dates {
+		if days[d] == nil {
+			absent = append(absent, d)
+		}
+	}
+	traded, tradedErr := c.tradedDays(ctx, symbol, absent)
+	for _, d := range dates {
+		file := verifyFileOf(files, d)
+		vd := days[d]
+		if vd == nil {
+			is := VerifyIssue{Kind: IssueMissingDay, File: file, Date: d}
+			switch {
+			case tradedErr != nil:
+				is.Detail = "无法核对停牌: " + tradedErr.Error()
+			case !traded[d]:
+				is.Kind, is.Warn = IssueSuspended, true
+			}
+			report.Issues = append(report.Issues, is)
+			if !is.Warn {
+				bad[d] = true
+			}
+			continue
+		}
+		var missing []string
+		for nm := 1; nm <= sessionDayMinutes; nm++ {
+			if !vd.minutes[nm] {
+				missing = append(missing, sessionTime(time.Time{}, nm).Format("15:04"))
+			}
+		}
+		if len(missing) > 0 {
+			detail := fmt.Sprint(missing)
+			if len(missing) > 10 {
+				detail = fmt.Sprint(missing[:10]) + " ..."
+			}
+			report.Issues = append(report.Issues, VerifyIssue{
+				Kind: IssueMissingMinutes, File: file, Date: d, Count: len(missing), Detail: detail,
+			})
+			bad[d] = true
+		}
+	}
+
+	for d := range bad {
+		report.BadDays = append(report.BadDays, d)
+	}
+	sort.Strings(report.BadDays)
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Time != b.Time {
+			return a.Time < b.Time
+		}
+		return a.Kind < b.Kind
+	})
+	return report, nil
+}
+
+// 归档中没有数据的交易日里 get_his 有日K线的日期; 没有日K线的视为停牌
+func (c *Client) tradedDays(ctx context.Context, symbol string, days []string) (map[string]bool, error) {
+	if len(days) == 0 {
+		return nil, nil
+	}
+	bars, err := c.GetKbarsHis(ctx, symbol, "1d", days[0], days[len(days)-1], true)
+	if err != nil {
+		return nil, err
+	}
+	traded := make(map[string]bool, len(bars))
+	for _, bar := range bars {
+		if ms, ok := bar["timestamp"].(int64); ok {
+			traded[time.UnixMilli(ms).In(cstZone).Format("2006-01-02")] = true
+		}
+	}
+	return traded, nil
+}
+
+// 日期所在的文件
+func verifyFileOf(files []ArchiveTask, day string) string {
+	for _, f := range files {
+		if day >= f.SDate && day <= f.EDate {
+			return f.Path
+		}
+	}
+	return ""
+}
+
+// 校验单个文件, 统计结果写入 days; 文件缺失或无法解析时记为问题, 读取失败(如上游不可用)时返回错误
+func (c *Client) verifyFile(ctx context.Context, f ArchiveTask, sdate string, edate string,
+	isDay map[string]bool, days map[string]*verifyDay, report *VerifyReport, opts VerifyOptions) error {
+
+	addIssue := func(is VerifyIssue) {
+		is.File = f.Path
+		report.Issues = append(report.Issues, is)
+	}
+	rc, err := c.csvOpen(ctx, f.Path)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("读取文件失败: %s: %w", f.Path, err)
+		}
+		addIssue(VerifyIssue{Kind: IssueMissingFile, Detail: err.Error()})
+		return nil
+	}
+	defer rc.Close()
+	dec, err := NewCSVxzDecoder(rc)
+	if err != nil {
+		if errors.Is(err, ErrNoData) {
+			return nil // 空文件按缺失的交易日处理
+		}
+		if errors.Is(err, ErrUpstreamUnavailable) {
+			return fmt.Errorf("读取文件失败: %s: %w", f.Path, err)
+		}
+		addIssue(VerifyIssue{Kind: IssueDecode, Detail: err.Error()})
+		return nil
+	}
+
+	flushZero := func(day string, vd *verifyDay) {
+		if vd.zeroRun >= opts.ZeroVolumeRun {
+			addIssue(VerifyIssue{Kind: IssueZeroVolume, Date: day, Time: vd.zeroAt, Count: vd.zeroRun, Warn: true})
+		}
+		vd.zeroRun = 0
+	}
+
+	var prev time.Time
+	for dec.Next() {
+		bar, err := dec.OHLCV()
+		if err != nil {
+			addIssue(VerifyIssue{Kind: IssueDecode, Detail: err.Error()})
+			continue
+		}
+		ts := bar.Timestamp.In(cstZone)
+		day, hm := ts.Format("2006-01-02"), ts.Format("15:04:05")
+
+		// 时间戳顺序
+		if !prev.IsZero() && !ts.After(prev) {
+			kind := IssueNotMonotonic
+			if ts.Equal(prev) {
+				kind = IssueDuplicate
+			}
+			addIssue(VerifyIssue{Kind: kind, Date: day, Time: hm, Detail: "前一根: " + prev.In(cstZone).Format("2006-01-02 15:04:05")})
+		}
+		if ts.After(prev) {
+			prev = ts
+		}
+
+		// 跨文件/非交易日
+		if day < f.SDate || day > f.EDate {
+			addIssue(VerifyIssue{Kind: IssueDayLeak, Date: day, Time: hm, Detail: "不属于文件期间 " + f.Period})
+			continue
+		}
+		if day < sdate || day > edate {
+			continue
+		}
+		report.Bars++
+		if !isDay[day] {
+			addIssue(VerifyIssue{Kind: IssueDayLeak, Date: day, Time: hm, Detail: "非交易日"})
+			continue
+		}
+
+		// 交易时段(09:31-11:30, 13:01-15:00)
+		clock := ts.Hour()*60 + ts.Minute()
+		if ts.Second() != 0 || clock <= 9*60+30 || (clock > 11*60+30 && clock <= 13*60) || clock > 15*60 {
+			addIssue(VerifyIssue{Kind: IssueOffSession, Date: day, Time: hm})
+			continue
+		}
+
+		vd := days[day]
+		if vd == nil {
+			vd = &verifyDay{}
+			days[day] = vd
+		}
+		vd.minutes[sessionMinute(ts)] = true
+
+		if bar.High < max(bar.Open, bar.Close, bar.Low) || bar.Low > min(bar.Open, bar.Close) ||
+			bar.Low <= 0 || bar.Volume < 0 {
+			addIssue(VerifyIssue{Kind: IssueOHLC, Date: day, Time: hm,
+				Detail: fmt.Sprintf("O=%g H=%g L=%g C=%g V=%d", bar.Open, bar.High, bar.Low, bar.Close, bar.Volume)})
+		}
+
+		// 连续零成交量
+		if bar.Volume == 0 {
+			if vd.zeroRun == 0 {
+				vd.zeroAt = hm
+			}
+			vd.zeroRun++
+		} else {
+			flushZero(day, vd)
+		}
+	}
+	for day, vd := range days {
+		if vd.zeroRun > 0 && day >= f.SDate && day <= f.EDate {
+			flushZero(day, vd)
+		}
+	}
+	if err := dec.Err(); err != nil {
+		if errors.Is(err, ErrUpstreamUnavailable) {
+			return fmt.Errorf("读取文件失败: %s: %w", f.Path, err)
+		}
+		addIssue(VerifyIssue{Kind: IssueDecode, Detail: err.Error()})
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+// gm-api / gm-csv 的离线替身服务
+//
+// 回放模式下从夹具目录返回记录的响应; 录制模式下未命中的请求转发到真实服务,
+// 并把响应保存为夹具文件。目录结构:
+//
+//	{Dir}/api/{接口名}/{参数}.json   gm-api 接口响应, 如 api/get_his/edate=2025-07-01&sdate=...json
+//	{Dir}/api/{接口名}/default.json  该接口未匹配到参数时的默认响应(需设置 Default)
+//	{Dir}/csv/{文件路径}             gm-csv 文件, 与 /download/ 之后的路径一致
+package gmfake
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 替身服务
+type Server struct {
+	Dir         string // 夹具目录
+	Record      bool   // 录制模式: 未命中时转发到上游并保存
+	UpstreamAPI string // 上游 gm-api 地址(录制模式使用)
+	UpstreamCSV string // 上游 gm-csv 地址(录制模式使用)
+	Default     bool   // 回放模式下未匹配参数时使用 default.json, 否则返回 404
+
+	HTTPClient *http.Client
+}
+
+// 新建回放服务
+func New(dir string) *Server {
+	return &Server{
+		Dir:        dir,
+		HTTPClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// 新建录制服务: 未命中的请求转发到 gmapi / gmcsv
+func NewRecorder(dir string, gmapi string, gmcsv string) *Server {
+	s := New(dir)
+	s.Record = true
+	s.UpstreamAPI = strings.TrimRight(gmapi, "/")
+	s.UpstreamCSV = strings.TrimRight(gmcsv, "/")
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "只支持 GET 请求")
+		return
+	}
+	if rel, ok := strings.CutPrefix(r.URL.Path, "/download/"); ok {
+		s.serveFile(w, r, rel)
+		return
+	}
+	s.serveAPI(w, r)
+}
+
+// gm-csv 文件
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request, rel string) {
+	fpath, err := s.CSVPath(rel)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	data, err := os.ReadFile(fpath)
+	if os.IsNotExist(err) && s.Record && s.UpstreamCSV != "" {
+		data, err = s.fetch(s.UpstreamCSV+r.URL.Path, "")
+		if err == nil {
+			err = writeFile(fpath, data)
+		}
+	}
+	if err != nil {
+		s.writeMiss(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(data)
+}
+
+// gm-api 接口
+func (s *Server) serveAPI(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(path.Clean(r.URL.Path), "/")
+	if name == "" || strings.Contains(name, "/") {
+		writeError(w, http.StatusNotFound, "未知接口: "+r.URL.Path)
+		return
+	}
+	fpath := s.APIPath(name, r.URL.Query())
+	data, err := os.ReadFile(fpath)
+	if os.IsNotExist(err) {
+		switch {
+		case s.Record && s.UpstreamAPI != "":
+			data, err = s.fetch(s.UpstreamAPI+"/"+name, r.URL.RawQuery)
+			if err == nil {
+				err = writeFile(fpath, data)
+			}
+		case s.Default:
+			data, err = os.ReadFile(filepath.Join(s.Dir, "api", name, "default.json"))
+		}
+	}
+	if err != nil {
+		s.writeMiss(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// 接口夹具文件路径, 参数按名称排序; 过长时取哈希值
+func (s *Server) APIPath(name string, query url.Values) string {
+	key := query.Encode()
+	if key == "" {
+		key = "default"
+	}
+	key = strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_").Replace(key)
+	if len(key) > 150 {
+		sum := sha1.Sum([]byte(key))
+		key = hex.EncodeToString(sum[:])
+	}
+	return filepath.Join(s.Dir, "api", name, key+".json")
+}
+
+// 文件夹具路径, 不允许访问夹具目录以外的文件
+func (s *Server) CSVPath(rel string) (string, error) {
+	clean := path.Clean("/" + rel)
+	if clean == "/" || strings.Contains(rel, "..") {
+		return "", fmt.Errorf("文件路径错误: %s", rel)
+	}
+	return filepath.Join(s.Dir, "csv", filepath.FromSlash(clean)), nil
+}
+
+// 保存接口夹具
+func (s *Server) PutAPI(name string, query url.Values, data []byte) error {
+	return writeFile(s.APIPath(name, query), data)
+}
+
+// 保存文件夹具, rel 为 /download/ 之后的路径
+func (s *Server) PutFile(rel string, data []byte) error {
+	fpath, err := s.CSVPath(rel)
+	if err != nil {
+		return err
+	}
+	return writeFile(fpath, data)
+}
+
+// 从上游获取
+func (s *Server) fetch(rawurl string, rawQuery string) ([]byte, error) {
+	if rawQuery != "" {
+		rawurl += "?" + rawQuery
+	}
+	resp, err := s.HTTPClient.Get(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("请求上游失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &upstreamError{code: resp.StatusCode, status: resp.Status}
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// 上游返回非200状态
+type upstreamError struct {
+	code   int
+	status string
+}
+
+func (e *upstreamError) Error() string {
+	return "上游请求失败: " + e.status
+}
+
+func (s *Server) writeMiss(w http.ResponseWriter, r *http.Request, err error) {
+	if ue, ok := err.(*upstreamError); ok {
+		writeError(w, ue.code, ue.Error())
+		return
+	}
+	if os.IsNotExist(err) {
+		writeError(w, http.StatusNotFound, "没有记录的数据: "+r.URL.RequestURI())
+		return
+	}
+	writeError(w, http.StatusBadGateway, err.Error())
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+// 写入文件(临时文件+重命名)
+func writeFile(fpath string, data []byte) error {
+	dir := filepath.Dir(fpath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("无法创建目录: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(fpath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("无法创建临时文件: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), fpath)
+}
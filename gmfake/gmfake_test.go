@@ -0,0 +1,114 @@
+package gmfake
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+)
+
+func get(t *testing.T, rawurl string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(rawurl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestReplay(t *testing.T) {
+	fake := New(t.TempDir())
+	q := url.Values{"symbol": {"SHSE.600000"}, "sdate": {"2025-07-01"}, "edate": {"2025-07-02"}}
+	if err := fake.PutAPI("get_his", q, []byte(`{"close":[10.1]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.PutAPI("get_dates_by_year", nil, []byte(`{"date":[]}`)); err != nil {
+		t.Fatal(err)
+	}
+	rel := "kbars-month/month-2025-07/month-2025-07--SH-60/kbars-1m--SHSE.600000--2025-07-.csv.xz"
+	if err := fake.PutFile(rel, []byte("xz")); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+
+	// 参数顺序不影响匹配
+	code, body := get(t, ts.URL+"/get_his?edate=2025-07-02&symbol=SHSE.600000&sdate=2025-07-01")
+	if code != 200 || body != `{"close":[10.1]}` {
+		t.Fatalf("get_his: %d %s", code, body)
+	}
+	// 未匹配参数时默认返回404, 设置 Default 后使用 default.json
+	if code, _ = get(t, ts.URL+"/get_dates_by_year?syear=2025"); code != 404 {
+		t.Fatalf("get_dates_by_year: 应返回404, 实际 %d", code)
+	}
+	fake.Default = true
+	code, body = get(t, ts.URL+"/get_dates_by_year?syear=2025")
+	if code != 200 || body != `{"date":[]}` {
+		t.Fatalf("get_dates_by_year: %d %s", code, body)
+	}
+	code, body = get(t, ts.URL+"/download/"+rel)
+	if code != 200 || body != "xz" {
+		t.Fatalf("download: %d %s", code, body)
+	}
+	if code, _ = get(t, ts.URL+"/get_current?symbols=SHSE.600000"); code != 404 {
+		t.Fatalf("get_current: 应返回404, 实际 %d", code)
+	}
+	if code, _ = get(t, ts.URL+"/download/kbars-year/none.csv.xz"); code != 404 {
+		t.Fatalf("download: 应返回404, 实际 %d", code)
+	}
+	if _, err := fake.CSVPath("../secret"); err == nil {
+		t.Fatal("应拒绝夹具目录以外的路径")
+	}
+}
+
+func TestRecord(t *testing.T) {
+	calls := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/get_current":
+			w.Write([]byte(`[{"symbol":"` + r.URL.Query().Get("symbols") + `"}]`))
+		case "/download/kbars-year/a.csv.xz":
+			w.Write([]byte("data"))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer upstream.Close()
+
+	dir := t.TempDir()
+	rec := NewRecorder(dir, upstream.URL, upstream.URL)
+	ts := httptest.NewServer(rec)
+	defer ts.Close()
+
+	for range 2 {
+		code, body := get(t, ts.URL+"/get_current?symbols=SZSE.000001")
+		if code != 200 || body != `[{"symbol":"SZSE.000001"}]` {
+			t.Fatalf("get_current: %d %s", code, body)
+		}
+		if code, body = get(t, ts.URL+"/download/kbars-year/a.csv.xz"); code != 200 || body != "data" {
+			t.Fatalf("download: %d %s", code, body)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("已录制的请求不应再转发, 上游请求次数: %d", calls)
+	}
+	if code, _ := get(t, ts.URL+"/get_his?symbol=x"); code != 404 {
+		t.Fatalf("上游404应原样返回, 实际 %d", code)
+	}
+	if _, err := os.Stat(rec.APIPath("get_his", url.Values{"symbol": {"x"}})); !os.IsNotExist(err) {
+		t.Fatal("失败的响应不应保存")
+	}
+
+	// 录制的夹具可直接回放
+	ts2 := httptest.NewServer(New(dir))
+	defer ts2.Close()
+	upstream.Close()
+	if code, body := get(t, ts2.URL+"/get_current?symbols=SZSE.000001"); code != 200 || body != `[{"symbol":"SZSE.000001"}]` {
+		t.Fatalf("replay: %d %s", code, body)
+	}
+}
@@ -0,0 +1,248 @@
+// A股分时行情模拟数据生成
+//
+// 按种子生成确定性的1m K线(gm.OHLCVList), 用于离线测试和演示:
+//   - 交易时段 09:30-11:30, 13:00-15:00, 每个交易日240根K线, 时间戳为K线结束时刻(与 get_his 一致)
+//   - 涨跌停价按板块计算: 主板 ±10%, 创业板/科创板 ±20%, 北交所 ±30%, 主板 ST ±5%, 指数不限
+//   - 成交量为100股整数倍, 日内呈U形分布(开盘、收盘放量), 封板时大幅缩量
+//
+// 同一种子、标的、日期和昨收价生成的数据完全相同, 与生成顺序无关。
+package gmsim
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/lmzxtek/ths-go/gm"
+)
+
+// 板块
+type Board int
+
+const (
+	BoardMain    Board = iota // 沪深主板
+	BoardChiNext              // 创业板 300/301
+	BoardSTAR                 // 科创板 688/689
+	BoardBSE                  // 北交所
+	BoardIndex                // 指数
+)
+
+// 由股票代码判断板块, 如 SHSE.600000, SZSE.300750, SHSE.000001(指数)
+func BoardOf(symbol string) Board {
+	exchange, code, _ := strings.Cut(symbol, ".")
+	switch {
+	case exchange == "BJSE":
+		return BoardBSE
+	case exchange == "SHSE" && strings.HasPrefix(code, "000"),
+		exchange == "SZSE" && strings.HasPrefix(code, "399"):
+		return BoardIndex
+	case exchange == "SHSE" && (strings.HasPrefix(code, "688") || strings.HasPrefix(code, "689")):
+		return BoardSTAR
+	case exchange == "SZSE" && (strings.HasPrefix(code, "300") || strings.HasPrefix(code, "301")):
+		return BoardChiNext
+	}
+	return BoardMain
+}
+
+// 涨跌幅限制, 0 表示不限
+func (b Board) LimitRate(st bool) float64 {
+	switch b {
+	case BoardChiNext, BoardSTAR:
+		return 0.2
+	case BoardBSE:
+		return 0.3
+	case BoardIndex:
+		return 0
+	}
+	if st {
+		return 0.05
+	}
+	return 0.1
+}
+
+// 涨停价和跌停价(四舍五入到分), rate 为0时返回 +Inf 和 0
+func LimitPrices(preClose float64, rate float64) (up float64, down float64) {
+	if rate <= 0 {
+		return math.Inf(1), 0
+	}
+	return roundCent(preClose * (1 + rate)), roundCent(preClose * (1 - rate))
+}
+
+func roundCent(p float64) float64 {
+	return math.Floor(p*100+0.5) / 100
+}
+
+// 标的参数, 未设置的字段使用默认值
+type Symbol struct {
+	Symbol     string
+	PreClose   float64 // 第一个交易日的昨收价, 默认10
+	ST         bool    // ST 股(主板涨跌幅 ±5%)
+	Volatility float64 // 日波动率, 默认0.02
+	Drift      float64 // 日收益率均值, 默认0
+	AvgVolume  int64   // 日均成交量(股), 默认 10,000,000
+}
+
+func (s Symbol) withDefaults() Symbol {
+	if s.PreClose <= 0 {
+		s.PreClose = 10
+	}
+	if s.Volatility <= 0 {
+		s.Volatility = 0.02
+	}
+	if s.AvgVolume <= 0 {
+		s.AvgVolume = 10_000_000
+	}
+	return s
+}
+
+// 模拟数据生成器
+type Generator struct {
+	Seed    uint64
+	symbols map[string]Symbol
+}
+
+// 新建生成器, 未列出的标的使用默认参数
+func New(seed uint64, symbols ...Symbol) *Generator {
+	g := &Generator{Seed: seed, symbols: make(map[string]Symbol, len(symbols))}
+	for _, s := range symbols {
+		g.symbols[s.Symbol] = s.withDefaults()
+	}
+	return g
+}
+
+// 标的参数
+func (g *Generator) Symbol(symbol string) Symbol {
+	if s, ok := g.symbols[symbol]; ok {
+		return s
+	}
+	return Symbol{Symbol: symbol}.withDefaults()
+}
+
+var cstZone = time.FixedZone("CST", 8*3600)
+
+const (
+	amMinutes  = 120
+	dayMinutes = 240
+	ticks      = 4 // 每分钟的价格步数
+)
+
+// 第 nm (1..240) 根K线的结束时刻
+func barTime(day time.Time, nm int) time.Time {
+	start := 9*60 + 30
+	if nm > amMinutes {
+		start = 13*60 - amMinutes
+	}
+	hm := start + nm
+	return time.Date(day.Year(), day.Month(), day.Day(), hm/60, hm%60, 0, 0, cstZone)
+}
+
+// 日内成交量权重(U形): 开盘第一分钟含集合竞价, 收盘前放量, 午后开盘略有放量
+func volumeWeights() [dayMinutes]float64 {
+	var w [dayMinutes]float64
+	sum := 0.0
+	for i := range w {
+		x := float64(i)/float64(dayMinutes-1)*2 - 1 // -1..1
+		w[i] = 0.4 + 2.2*x*x
+		if i == amMinutes {
+			w[i] *= 1.5
+		}
+		sum += w[i]
+	}
+	w[0] *= 3
+	sum += w[0] * 2 / 3
+	for i := range w {
+		w[i] /= sum
+	}
+	return w
+}
+
+var weights = volumeWeights()
+
+// 由种子、标的和日期确定的随机数源
+func (g *Generator) rng(symbol string, date string) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(symbol))
+	h.Write([]byte{0})
+	h.Write([]byte(date))
+	return rand.New(rand.NewPCG(g.Seed, h.Sum64()))
+}
+
+// 生成一个交易日的240根1m K线
+//
+// 开盘价相对昨收有跳空, 日内按几何布朗运动变化, 价格限制在涨跌停价之间并取整到分。
+// preClose <= 0 时使用标的参数中的昨收价。
+func (g *Generator) Day(symbol string, date string, preClose float64) (gm.OHLCVList, error) {
+	day, err := time.ParseInLocation("2006-01-02", date, cstZone)
+	if err != nil {
+		return nil, fmt.Errorf("日期格式错误: %v", err)
+	}
+	s := g.Symbol(symbol)
+	if preClose <= 0 {
+		preClose = s.PreClose
+	}
+	up, down := LimitPrices(preClose, BoardOf(symbol).LimitRate(s.ST))
+	down = max(down, 0.01)
+	clamp := func(p float64) float64 { return min(max(roundCent(p), down), up) }
+	r := g.rng(symbol, date)
+
+	sigma := s.Volatility / math.Sqrt(dayMinutes*ticks)
+	mu := s.Drift / (dayMinutes * ticks)
+	p := preClose * math.Exp(r.NormFloat64()*s.Volatility*0.3) // 集合竞价跳空
+	price := clamp(p)
+
+	bars := make(gm.OHLCVList, dayMinutes)
+	for i := range bars {
+		bar := gm.OHLCVData{Timestamp: barTime(day, i+1), Open: price, High: price, Low: price}
+		for range ticks {
+			p *= math.Exp(mu - sigma*sigma/2 + sigma*r.NormFloat64())
+			p = min(max(p, down), up) // 封板后从涨跌停价继续
+			price = clamp(p)
+			bar.High = max(bar.High, price)
+			bar.Low = min(bar.Low, price)
+		}
+		bar.Close = price
+
+		// 成交量: U形权重 x 随机波动 x 价格波动放大, 封板(一字)时缩量
+		v := float64(s.AvgVolume) * weights[i] * math.Exp(0.35*r.NormFloat64()-0.06)
+		v *= 1 + 20*math.Abs(bar.High-bar.Low)/preClose
+		if bar.High == bar.Low && (bar.Close == up || bar.Close == down) {
+			v *= 0.05
+		}
+		bar.Volume = int64(v/100) * 100
+		bars[i] = bar
+	}
+	return bars, nil
+}
+
+// 生成多个交易日的K线, 每日的昨收价为前一日收盘价
+func (g *Generator) Days(symbol string, dates []string) (gm.OHLCVList, error) {
+	var all gm.OHLCVList
+	preClose := g.Symbol(symbol).PreClose
+	for _, date := range dates {
+		bars, err := g.Day(symbol, date, preClose)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, bars...)
+		preClose = bars[len(bars)-1].Close
+	}
+	return all, nil
+}
+
+// 从 sdate 开始的 n 个工作日(不含周末, 不考虑节假日)
+func Weekdays(sdate string, n int) ([]string, error) {
+	d, err := time.Parse("2006-01-02", sdate)
+	if err != nil {
+		return nil, fmt.Errorf("日期格式错误: %v", err)
+	}
+	var dates []string
+	for ; len(dates) < n; d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			dates = append(dates, d.Format("2006-01-02"))
+		}
+	}
+	return dates, nil
+}
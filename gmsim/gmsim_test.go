@@ -0,0 +1,143 @@
+package gmsim
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	cases := []struct {
+		symbol   string
+		st       bool
+		board    Board
+		preClose float64
+		up, down float64
+	}{
+		{"SHSE.600000", false, BoardMain, 10.05, 11.06, 9.05},
+		{"SZSE.000001", true, BoardMain, 10, 10.5, 9.5},
+		{"SZSE.300750", false, BoardChiNext, 200, 240, 160},
+		{"SHSE.688981", true, BoardSTAR, 50, 60, 40},
+		{"BJSE.830799", false, BoardBSE, 10, 13, 7},
+	}
+	for _, tc := range cases {
+		b := BoardOf(tc.symbol)
+		up, down := LimitPrices(tc.preClose, b.LimitRate(tc.st))
+		if b != tc.board || up != tc.up || down != tc.down {
+			t.Errorf("%s: board=%d up=%g down=%g", tc.symbol, b, up, down)
+		}
+	}
+	if b := BoardOf("SHSE.000001"); b != BoardIndex || b.LimitRate(false) != 0 {
+		t.Errorf("index: %d", b)
+	}
+}
+
+func TestDay(t *testing.T) {
+	g := New(42, Symbol{Symbol: "SHSE.600000", PreClose: 8.5})
+	bars, err := g.Day("SHSE.600000", "2025-07-01", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bars) != 240 {
+		t.Fatalf("bars: %d", len(bars))
+	}
+	// 交易时段: 09:31-11:30, 13:01-15:00
+	for i, want := range map[int]string{0: "09:31", 119: "11:30", 120: "13:01", 239: "15:00"} {
+		if got := bars[i].Timestamp.Format("15:04"); got != want {
+			t.Errorf("bar %d: %s, want %s", i, got, want)
+		}
+	}
+	up, down := LimitPrices(8.5, 0.1)
+	for i, b := range bars {
+		if b.Low < down || b.High > up || b.Low > min(b.Open, b.Close) || b.High < max(b.Open, b.Close) {
+			t.Fatalf("bar %d: %+v", i, b)
+		}
+		if b.Close != math.Round(b.Close*100)/100 {
+			t.Fatalf("bar %d: close not in cents: %v", i, b.Close)
+		}
+		if b.Volume < 0 || b.Volume%100 != 0 {
+			t.Fatalf("bar %d: volume %d", i, b.Volume)
+		}
+		if i > 0 && b.Open != bars[i-1].Close {
+			t.Fatalf("bar %d: open %v != prev close %v", i, b.Open, bars[i-1].Close)
+		}
+	}
+
+	// 同一种子结果相同, 不同种子结果不同
+	again, _ := New(42, Symbol{Symbol: "SHSE.600000", PreClose: 8.5}).Day("SHSE.600000", "2025-07-01", 0)
+	if !reflect.DeepEqual(bars, again) {
+		t.Error("same seed gives different bars")
+	}
+	other, _ := New(43, Symbol{Symbol: "SHSE.600000", PreClose: 8.5}).Day("SHSE.600000", "2025-07-01", 0)
+	if reflect.DeepEqual(bars, other) {
+		t.Error("different seeds give same bars")
+	}
+
+	if _, err := g.Day("SHSE.600000", "20250701", 0); err == nil {
+		t.Error("bad date accepted")
+	}
+}
+
+func TestVolumeShape(t *testing.T) {
+	// 多日平均: 开盘和收盘30分钟的成交量高于盘中
+	g := New(1)
+	dates, _ := Weekdays("2025-06-02", 20)
+	var open, mid, close float64
+	for _, d := range dates {
+		bars, _ := g.Day("SZSE.000001", d, 10)
+		for i, b := range bars {
+			switch {
+			case i < 30:
+				open += float64(b.Volume)
+			case i >= 90 && i < 150:
+				mid += float64(b.Volume) / 2
+			case i >= 210:
+				close += float64(b.Volume)
+			}
+		}
+	}
+	if open < 1.5*mid || close < 1.5*mid {
+		t.Errorf("not U-shaped: open=%.0f mid=%.0f close=%.0f", open, mid, close)
+	}
+}
+
+func TestLimitUp(t *testing.T) {
+	// 强势上涨: 封在涨停价, 不超过涨停价, 封板后缩量
+	g := New(7, Symbol{Symbol: "SHSE.600000", Volatility: 0.05, Drift: 0.5})
+	bars, _ := g.Day("SHSE.600000", "2025-07-01", 10)
+	up, _ := LimitPrices(10, 0.1)
+	last := bars[len(bars)-1]
+	if last.Close != up {
+		t.Fatalf("close %v, want limit up %v", last.Close, up)
+	}
+	var sealed, open int64
+	var ns, no int64
+	for i, b := range bars {
+		if b.High > up {
+			t.Fatalf("bar %d above limit: %+v", i, b)
+		}
+		if i < 30 || i >= 210 {
+			continue // 排除开盘/收盘放量
+		}
+		if b.High == up && b.Low == up {
+			sealed += b.Volume
+			ns++
+		} else {
+			open += b.Volume
+			no++
+		}
+	}
+	if ns == 0 || (no > 0 && sealed/ns >= open/no) {
+		t.Errorf("sealed volume not reduced: sealed=%d/%d open=%d/%d", sealed, ns, open, no)
+	}
+
+	// 多日: 昨收为前一日收盘价, 跌停价随之变化
+	days, err := g.Days("SHSE.600000", []string{"2025-07-01", "2025-07-02"})
+	if err != nil || len(days) != 480 {
+		t.Fatalf("Days: %d %v", len(days), err)
+	}
+	up2, _ := LimitPrices(days[239].Close, 0.1)
+	if days[479].High > up2 {
+		t.Errorf("day 2 above limit %v: %v", up2, days[479].High)
+	}
+}
@@ -0,0 +1,177 @@
+package math
+
+import (
+	stdmath "math"
+)
+
+// 同花顺公式函数(序列版)
+//
+// 约定: 数据不足或无意义的位置为 NaN; 输入中的 NaN 视为无效值。
+
+// 无效值
+func NaN() float64 {
+	return stdmath.NaN()
+}
+
+// 是否为无效值
+func IsNaN(v float64) bool {
+	return stdmath.IsNaN(v)
+}
+
+// REF(X,N): N周期前的值
+func REF(x []float64, n int) []float64 {
+	out := nanSlice(len(x))
+	if n < 0 {
+		return out
+	}
+	for i := n; i < len(x); i++ {
+		out[i] = x[i-n]
+	}
+	return out
+}
+
+// SUM(X,N): N周期累加, N=0 时从第一个有效值起累加
+func SUM(x []float64, n int) []float64 {
+	out := nanSlice(len(x))
+	if n <= 0 {
+		sum, ok := 0.0, false
+		for i, v := range x {
+			if IsNaN(v) {
+				if ok {
+					out[i] = sum
+				}
+				continue
+			}
+			sum += v
+			ok = true
+			out[i] = sum
+		}
+		return out
+	}
+	sum, cnt := 0.0, 0
+	for i, v := range x {
+		if !IsNaN(v) {
+			sum += v
+			cnt++
+		}
+		if i >= n {
+			if old := x[i-n]; !IsNaN(old) {
+				sum -= old
+				cnt--
+			}
+		}
+		if cnt == n {
+			out[i] = sum
+		}
+	}
+	return out
+}
+
+// MA(X,N): N周期简单移动平均, 不足N个有效值时为 NaN
+func MA(x []float64, n int) []float64 {
+	out := SUM(x, max(n, 1))
+	for i := range out {
+		out[i] /= float64(max(n, 1))
+	}
+	return out
+}
+
+// EMA(X,N): 指数移动平均 Y=(2*X+(N-1)*Y')/(N+1), 首个有效值为初值
+func EMA(x []float64, n int) []float64 {
+	return SMA(x, n+1, 2)
+}
+
+// SMA(X,N,M): 扩展移动平均 Y=(M*X+(N-M)*Y')/N, 首个有效值为初值
+func SMA(x []float64, n int, m int) []float64 {
+	out := nanSlice(len(x))
+	if n <= 0 {
+		return out
+	}
+	prev, ok := 0.0, false
+	for i, v := range x {
+		if IsNaN(v) {
+			if ok {
+				out[i] = prev
+			}
+			continue
+		}
+		if !ok {
+			prev, ok = v, true
+		} else {
+			prev = (float64(m)*v + float64(n-m)*prev) / float64(n)
+		}
+		out[i] = prev
+	}
+	return out
+}
+
+// HHV(X,N): N周期内最高值, 不足N周期时取已有数据, N=0 时取全部数据
+func HHV(x []float64, n int) []float64 {
+	return extremum(x, n, func(a, b float64) bool { return a > b })
+}
+
+// LLV(X,N): N周期内最低值, 不足N周期时取已有数据, N=0 时取全部数据
+func LLV(x []float64, n int) []float64 {
+	return extremum(x, n, func(a, b float64) bool { return a < b })
+}
+
+// STD(X,N): N周期样本标准差(同花顺STD为估算标准差)
+func STD(x []float64, n int) []float64 {
+	out := nanSlice(len(x))
+	if n < 2 {
+		return out
+	}
+	for i := n - 1; i < len(x); i++ {
+		sum, ok := 0.0, true
+		for _, v := range x[i-n+1 : i+1] {
+			if IsNaN(v) {
+				ok = false
+				break
+			}
+			sum += v
+		}
+		if !ok {
+			continue
+		}
+		mean := sum / float64(n)
+		ss := 0.0
+		for _, v := range x[i-n+1 : i+1] {
+			ss += (v - mean) * (v - mean)
+		}
+		out[i] = stdmath.Sqrt(ss / float64(n-1))
+	}
+	return out
+}
+
+func extremum(x []float64, n int, better func(a, b float64) bool) []float64 {
+	out := nanSlice(len(x))
+	if n <= 0 {
+		best := stdmath.NaN()
+		for i, v := range x {
+			if !IsNaN(v) && (IsNaN(best) || better(v, best)) {
+				best = v
+			}
+			out[i] = best
+		}
+		return out
+	}
+	for i := range x {
+		for _, v := range x[max(0, i-n+1) : i+1] {
+			if IsNaN(v) {
+				continue
+			}
+			if IsNaN(out[i]) || better(v, out[i]) {
+				out[i] = v
+			}
+		}
+	}
+	return out
+}
+
+func nanSlice(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = stdmath.NaN()
+	}
+	return out
+}
@@ -0,0 +1,40 @@
+package math
+
+import (
+	"testing"
+)
+
+func TestTAFuncs(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5}
+
+	if r := REF(x, 2); !IsNaN(r[1]) || r[2] != 1 || r[4] != 3 {
+		t.Errorf("REF 结果错误: %v", r)
+	}
+	if r := MA(x, 3); !IsNaN(r[1]) || r[2] != 2 || r[4] != 4 {
+		t.Errorf("MA 结果错误: %v", r)
+	}
+	if r := SUM(x, 0); r[4] != 15 {
+		t.Errorf("SUM(X,0) 结果错误: %v", r)
+	}
+	// SMA(X,3,1): 1, (2+2*1)/3, ...
+	if r := SMA(x, 3, 1); r[0] != 1 || r[1] != 4.0/3 {
+		t.Errorf("SMA 结果错误: %v", r)
+	}
+	// EMA(X,N) == SMA(X,N+1,2)
+	if r := EMA(x, 3); r[1] != 1.5 || r[2] != 2.25 {
+		t.Errorf("EMA 结果错误: %v", r)
+	}
+	// 不足N周期时取已有数据
+	if h, l := HHV(x, 3), LLV(x, 3); h[0] != 1 || h[4] != 5 || l[1] != 1 || l[4] != 3 {
+		t.Errorf("HHV/LLV 结果错误: %v %v", h, l)
+	}
+	if r := STD(x, 5); r[4] < 1.5811 || r[4] > 1.5812 {
+		t.Errorf("STD 结果错误: %v", r)
+	}
+
+	// 前导无效值被跳过
+	y := []float64{NaN(), 2, 4}
+	if r := SMA(y, 2, 1); !IsNaN(r[0]) || r[1] != 2 || r[2] != 3 {
+		t.Errorf("SMA 跳过无效值错误: %v", r)
+	}
+}
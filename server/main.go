@@ -2,10 +2,14 @@ package main
 
 import (
 	"fmt"
+	"net"
+	"net/http"
 	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/gin-gonic/gin"
+	"github.com/lmzxtek/ths-go/gm"
+	"github.com/lmzxtek/ths-go/gmfake"
 	"github.com/lmzxtek/ths-go/srv"
 )
 
@@ -16,6 +20,12 @@ type Config struct {
 		Gmapi     string `toml:"gmapi"`
 		Gmcsv     string `toml:"gmcsv"`
 		ServerTag string `toml:"server_tag"`
+		Indicator string `toml:"indicators"`  // vv指标定义文件(TOML)
+		Calendar  string `toml:"calendar"`    // 交易日历缓存文件(JSON)
+		CacheMB   int    `toml:"cache_mb"`    // 内存缓存上限(MB), 0 表示不缓存
+		CacheDir  string `toml:"cache_dir"`   // 磁盘缓存目录
+		FakeDir   string `toml:"fake_dir"`    // 离线夹具目录, 设置后使用本地替身服务
+		FakeRec   bool   `toml:"fake_record"` // 录制模式: 未命中的请求转发到 gmapi/gmcsv 并保存
 	} `toml:"api"`
 }
 
@@ -43,6 +53,28 @@ func main() {
 	}
 
 	srv.SetURL(cfg.API.Gmapi, cfg.API.Gmcsv)
+	if cfg.API.FakeDir != "" {
+		addr, err := startFake(cfg.API.FakeDir, cfg.API.FakeRec)
+		if err != nil {
+			fmt.Println("Error starting fake upstream:", err)
+			return
+		}
+		fmt.Println(" >>> Fake upstream at: "+addr, "record:", cfg.API.FakeRec)
+		srv.SetURL(addr, addr)
+	}
+	if cfg.API.CacheMB > 0 {
+		srv.SetCache(cfg.API.CacheMB, cfg.API.CacheDir)
+	}
+	srv.SetCalendar(cfg.API.Calendar)
+
+	if cfg.API.Indicator != "" {
+		names, err := gm.LoadIndicatorSpecs(cfg.API.Indicator)
+		if err != nil {
+			fmt.Println("Error loading indicators file:", err)
+			return
+		}
+		fmt.Println(" >>> Load indicators from: "+cfg.API.Indicator, names)
+	}
 
 	fmt.Println("")
 	now := time.Now()
@@ -56,11 +88,14 @@ func main() {
 	fmt.Println(" server_tag -> " + cfg.API.ServerTag)
 	fmt.Println(" gmapi -> " + cfg.API.Gmapi)
 	fmt.Println(" gmcsv -> " + cfg.API.Gmcsv)
+	fmt.Println(" calendar -> " + cfg.API.Calendar)
+	fmt.Println(" cache -> ", cfg.API.CacheMB, "MB", cfg.API.CacheDir)
 	fmt.Println("")
 
 	r := gin.Default()
 	//====================================================
 	r.GET("/usage", srv.RouteUsage)
+	r.GET("/dashboard/*filepath", srv.RouteDashboard)
 
 	r.GET("/test", srv.RouteTest)
 	r.GET("/test2", srv.RouteTest2)
@@ -72,6 +107,7 @@ func main() {
 	r.GET("/gmvv", srv.RouteGMvv)
 	r.GET("/gm1m", srv.RouteGM1m)
 	r.GET("/api1m", srv.RouteGMApi1m)
+	r.GET("/indicators", srv.RouteIndicators)
 
 	r.GET("/csv1m", srv.RouteCSVxz1m)
 	r.GET("/csvtag", srv.RouteCSVxzTag)
@@ -87,6 +123,10 @@ func main() {
 	r.GET("/kbars2n", srv.RouteKbars2N)
 
 	r.GET("/current", srv.RouteCurrent)
+	r.GET("/stream", srv.RouteStream)
+
+	r.GET("/cache/stats", srv.RouteCacheStats)
+	r.GET("/cache/purge", srv.RouteCachePurge)
 	//====================================================
 	r.GET("/prevn", srv.RouteDatesPrevN)
 	r.GET("/nextn", srv.RouteDatesNextN)
@@ -159,3 +199,18 @@ func main() {
 	fmt.Printf("\nServer running at http://*%s\n\n", addr)
 	r.Run(addr)
 }
+
+// 启动本地替身服务(gmfake), 返回其地址
+func startFake(dir string, record bool) (string, error) {
+	fake := gmfake.New(dir)
+	if record {
+		fake = gmfake.NewRecorder(dir,
+			srv.SmartURLHandler(cfg.API.Gmapi, false), srv.SmartURLHandler(cfg.API.Gmcsv, false))
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	go http.Serve(ln, fake)
+	return "http://" + ln.Addr().String(), nil
+}
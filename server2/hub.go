@@ -0,0 +1,426 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// 推送消息类型
+const (
+	MsgSnapshot = "snapshot" // 订阅时的最新K线
+	MsgBar      = "bar"      // K线更新(时间戳与上一条相同时为当前K线的更新)
+	MsgError    = "error"
+	MsgAck      = "ack"    // 订阅/退订的确认, 带当前订阅列表
+	MsgReplay   = "replay" // 回放状态, 控制后推送给所有连接
+)
+
+// Message 服务端推送的消息
+type Message struct {
+	Type    string     `json:"type"`
+	Symbol  string     `json:"symbol,omitempty"`
+	Data    *StockData `json:"data,omitempty"`
+	ID      string     `json:"id,omitempty"`      // ack/error 对应的请求ID
+	Action  string     `json:"action,omitempty"`  // ack 对应的请求
+	Symbols []string   `json:"symbols,omitempty"` // ack: 当前订阅列表
+	Error   string     `json:"error,omitempty"`
+	State   any        `json:"state,omitempty"` // replay: 回放状态
+	Reset   bool       `json:"reset,omitempty"` // replay: 回放跳转, 需重新获取历史数据
+}
+
+// Request 客户端请求
+//
+//	{"action":"subscribe","symbols":["SHSE.600000","SZSE.000001"],"id":"1"}
+//	{"action":"unsubscribe","symbols":["SHSE.600000"]}
+//	{"symbol":"SHSE.600000"}  // 兼容旧版本: 只订阅该股票
+//
+// 其他 action 交给 Hub.Control 处理(如回放的 pause/resume/step/seek/speed)。
+type Request struct {
+	Action  string   `json:"action"`
+	Symbols []string `json:"symbols"`
+	Symbol  string   `json:"symbol"`
+	ID      string   `json:"id"`
+	Time    string   `json:"time"`  // seek
+	Speed   float64  `json:"speed"` // speed
+	N       int      `json:"n"`     // step
+}
+
+// 客户端发送缓冲区满时的处理方式
+type OverflowPolicy int
+
+const (
+	PolicyDrop       OverflowPolicy = iota // 丢弃该消息
+	PolicyDisconnect                       // 断开连接, 客户端重连后重新订阅
+)
+
+// Hub WebSocket 连接管理: 连接的注册/注销、订阅关系及广播都在 Run 所在的 goroutine 中处理,
+// 每个连接有独立的发送缓冲区和写 goroutine, 慢客户端不影响其他连接。
+type Hub struct {
+	Buffer     int            // 每个连接的发送缓冲数
+	Policy     OverflowPolicy // 缓冲区满时的处理方式
+	WriteWait  time.Duration  // 单条消息的写超时
+	PongWait   time.Duration  // 超过该时间没有收到消息(含 pong)时断开
+	PingPeriod time.Duration  // ping 间隔, 应小于 PongWait
+
+	Load     func(ctx context.Context, symbol string) error // 订阅前加载数据, 返回错误时拒绝订阅
+	Snapshot func(symbol string) (StockData, bool)          // 订阅时发送的最新K线
+	Control  func(req Request) (Message, error)             // 处理其他 action, 返回的消息推送给所有连接
+
+	register   chan *Client
+	unregister chan *Client
+	requests   chan hubRequest
+	broadcast  chan Message
+	done       chan struct{}
+
+	clients map[*Client]bool
+
+	mu      sync.Mutex
+	refs    map[string]int // 股票 -> 订阅的连接数
+	nclient atomic.Int64
+	dropped atomic.Int64
+}
+
+// 连接的订阅变更或直接回复
+type hubRequest struct {
+	client  *Client
+	action  string
+	symbols []string
+	reply   []Message
+}
+
+// Client 一个 WebSocket 连接
+type Client struct {
+	hub     *Hub
+	conn    *websocket.Conn
+	send    chan Message
+	symbols map[string]bool // 仅在 Hub.Run 中访问
+	reason  string          // 服务端断开的原因, 在关闭 send 前设置
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		Buffer:     64,
+		Policy:     PolicyDisconnect,
+		WriteWait:  10 * time.Second,
+		PongWait:   60 * time.Second,
+		PingPeriod: 50 * time.Second,
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+		requests:   make(chan hubRequest),
+		broadcast:  make(chan Message, 256),
+		done:       make(chan struct{}),
+		clients:    make(map[*Client]bool),
+		refs:       make(map[string]int),
+	}
+}
+
+// Run 处理连接和消息直到 ctx 取消, 退出时关闭所有连接
+func (h *Hub) Run(ctx context.Context) {
+	defer close(h.done)
+	for {
+		select {
+		case c := <-h.register:
+			h.clients[c] = true
+			h.nclient.Add(1)
+		case c := <-h.unregister:
+			h.remove(c, "")
+		case req := <-h.requests:
+			h.handle(req)
+		case msg := <-h.broadcast:
+			for c := range h.clients {
+				if msg.Symbol == "" || c.symbols[msg.Symbol] {
+					h.enqueue(c, msg)
+				}
+			}
+		case <-ctx.Done():
+			for c := range h.clients {
+				h.remove(c, "server shutdown")
+			}
+			return
+		}
+	}
+}
+
+// Broadcast 向订阅了 msg.Symbol 的连接推送消息(Symbol 为空时推送给所有连接), Hub 已停止时丢弃
+func (h *Hub) Broadcast(msg Message) {
+	select {
+	case h.broadcast <- msg:
+	case <-h.done:
+	}
+}
+
+// Symbols 有连接订阅的股票
+func (h *Hub) Symbols() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	symbols := make([]string, 0, len(h.refs))
+	for s := range h.refs {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// ClientCount 当前连接数
+func (h *Hub) ClientCount() int { return int(h.nclient.Load()) }
+
+// Dropped 因缓冲区满丢弃的消息数
+func (h *Hub) Dropped() int64 { return h.dropped.Load() }
+
+// 注销连接并关闭其发送通道(写 goroutine 随之退出)
+func (h *Hub) remove(c *Client, reason string) {
+	if !h.clients[c] {
+		return
+	}
+	c.reason = reason
+	delete(h.clients, c)
+	h.nclient.Add(-1)
+	h.mu.Lock()
+	for s := range c.symbols {
+		h.unref(s)
+	}
+	h.mu.Unlock()
+	close(c.send)
+}
+
+func (h *Hub) unref(symbol string) {
+	if h.refs[symbol]--; h.refs[symbol] <= 0 {
+		delete(h.refs, symbol)
+	}
+}
+
+// 非阻塞发送, 缓冲区满时按 Policy 丢弃消息或断开连接
+func (h *Hub) enqueue(c *Client, msg Message) {
+	select {
+	case c.send <- msg:
+	default:
+		h.dropped.Add(1)
+		if h.Policy == PolicyDisconnect {
+			h.remove(c, "slow consumer")
+		}
+	}
+}
+
+func (h *Hub) handle(req hubRequest) {
+	c := req.client
+	if !h.clients[c] {
+		return
+	}
+	h.mu.Lock()
+	switch req.action {
+	case "replace":
+		for s := range c.symbols {
+			delete(c.symbols, s)
+			h.unref(s)
+		}
+		fallthrough
+	case "subscribe":
+		for _, s := range req.symbols {
+			if !c.symbols[s] {
+				c.symbols[s] = true
+				h.refs[s]++
+			}
+		}
+	case "unsubscribe":
+		for _, s := range req.symbols {
+			if c.symbols[s] {
+				delete(c.symbols, s)
+				h.unref(s)
+			}
+		}
+	}
+	h.mu.Unlock()
+
+	for _, msg := range req.reply {
+		if msg.Type == MsgAck {
+			msg.Symbols = c.list()
+		}
+		h.enqueue(c, msg)
+		if !h.clients[c] {
+			return
+		}
+	}
+	if (req.action == "subscribe" || req.action == "replace") && h.Snapshot != nil {
+		for _, s := range req.symbols {
+			if bar, ok := h.Snapshot(s); ok && h.clients[c] {
+				h.enqueue(c, Message{Type: MsgSnapshot, Symbol: s, Data: &bar})
+			}
+		}
+	}
+}
+
+// 当前订阅列表
+func (c *Client) list() []string {
+	symbols := make([]string, 0, len(c.symbols))
+	for s := range c.symbols {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// 提交给 Hub 处理, Hub 已停止时返回 false
+func (h *Hub) submit(req hubRequest) bool {
+	select {
+	case h.requests <- req:
+		return true
+	case <-h.done:
+		return false
+	}
+}
+
+var hubUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		return true // 允许跨域
+	},
+}
+
+// ServeWS WebSocket 处理器, 连接时订阅 symbols(逗号分隔)或 symbol 参数指定的股票
+func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := hubUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade error: %v", err)
+		return
+	}
+	c := &Client{hub: h, conn: conn, send: make(chan Message, h.Buffer), symbols: make(map[string]bool)}
+	select {
+	case h.register <- c:
+	case <-h.done:
+		conn.Close()
+		return
+	}
+	go c.writePump()
+
+	var symbols []string
+	for _, s := range strings.Split(r.URL.Query().Get("symbols"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			symbols = append(symbols, s)
+		}
+	}
+	if s := r.URL.Query().Get("symbol"); s != "" {
+		symbols = append(symbols, s)
+	}
+	if len(symbols) > 0 {
+		c.request(Request{Action: "subscribe", Symbols: symbols})
+	}
+	c.readPump()
+}
+
+// 读取客户端请求, 连接断开或超时后注销
+func (c *Client) readPump() {
+	h := c.hub
+	defer func() {
+		select {
+		case h.unregister <- c:
+		case <-h.done:
+		}
+		c.conn.Close()
+	}()
+	c.conn.SetReadLimit(4096)
+	c.conn.SetReadDeadline(time.Now().Add(h.PongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(h.PongWait))
+	})
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("WebSocket read error: %v", err)
+			}
+			return
+		}
+		c.conn.SetReadDeadline(time.Now().Add(h.PongWait))
+
+		var req Request
+		if err := json.Unmarshal(data, &req); err != nil {
+			if !h.submit(hubRequest{client: c, reply: []Message{{Type: MsgError, Error: "消息格式错误: " + err.Error()}}}) {
+				return
+			}
+			continue
+		}
+		if !c.request(req) {
+			return
+		}
+	}
+}
+
+// 处理一个请求: 订阅前逐个加载数据, 加载失败的股票回复 error 并跳过
+func (c *Client) request(req Request) bool {
+	h := c.hub
+	action := req.Action
+	if action == "" && req.Symbol != "" {
+		// 兼容旧版本: 切换到该股票
+		action, req.Action, req.Symbols = "replace", "subscribe", []string{req.Symbol}
+	}
+
+	var reply []Message
+	switch req.Action {
+	case "subscribe":
+		var ok []string
+		for _, s := range req.Symbols {
+			if h.Load != nil {
+				if err := h.Load(context.Background(), s); err != nil {
+					reply = append(reply, Message{Type: MsgError, Symbol: s, ID: req.ID, Error: err.Error()})
+					continue
+				}
+			}
+			ok = append(ok, s)
+		}
+		req.Symbols = ok
+	case "unsubscribe":
+	default:
+		if h.Control == nil {
+			return h.submit(hubRequest{client: c, reply: []Message{{Type: MsgError, ID: req.ID,
+				Error: "不支持的 action: " + req.Action + " (可选: subscribe, unsubscribe)"}}})
+		}
+		msg, err := h.Control(req)
+		if err != nil {
+			return h.submit(hubRequest{client: c, reply: []Message{{Type: MsgError, ID: req.ID, Error: err.Error()}}})
+		}
+		if !h.submit(hubRequest{client: c, reply: []Message{{Type: MsgAck, ID: req.ID, Action: req.Action}}}) {
+			return false
+		}
+		h.Broadcast(msg)
+		return true
+	}
+	reply = append(reply, Message{Type: MsgAck, ID: req.ID, Action: req.Action})
+	return h.submit(hubRequest{client: c, action: action, symbols: req.Symbols, reply: reply})
+}
+
+// 发送消息及定时 ping, 发送通道关闭时断开连接
+func (c *Client) writePump() {
+	h := c.hub
+	ticker := time.NewTicker(h.PingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+	for {
+		select {
+		case msg, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(h.WriteWait))
+			if !ok {
+				if c.reason != "" {
+					c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, c.reason))
+				}
+				return
+			}
+			if err := c.conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(h.WriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/lmzxtek/ths-go/gm"
+)
+
+// 启动 Hub 及 WebSocket 服务, 返回连接函数
+func startHub(t *testing.T, h *Hub) func(query string) *websocket.Conn {
+	ctx, cancel := context.WithCancel(context.Background())
+	go h.Run(ctx)
+	ts := httptest.NewServer(http.HandlerFunc(h.ServeWS))
+	t.Cleanup(func() {
+		ts.Close()
+		cancel()
+	})
+	return func(query string) *websocket.Conn {
+		t.Helper()
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws"+query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+}
+
+func readMsg(t *testing.T, conn *websocket.Conn) Message {
+	t.Helper()
+	var msg Message
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+// 等待条件成立
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for i := 0; i < 500; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timeout waiting for %s", what)
+}
+
+func TestHubSubscribe(t *testing.T) {
+	h := NewHub()
+	h.Load = func(ctx context.Context, symbol string) error {
+		if symbol == "BAD" {
+			return fmt.Errorf("没有数据: %s", symbol)
+		}
+		return nil
+	}
+	h.Snapshot = func(symbol string) (StockData, bool) {
+		return StockData{Symbol: symbol, Timestamp: 1, Close: 10}, true
+	}
+	dial := startHub(t, h)
+
+	conn := dial("?symbols=A")
+	if msg := readMsg(t, conn); msg.Type != MsgAck || msg.Action != "subscribe" || fmt.Sprint(msg.Symbols) != "[A]" {
+		t.Fatalf("ack: %+v", msg)
+	}
+	if msg := readMsg(t, conn); msg.Type != MsgSnapshot || msg.Symbol != "A" || msg.Data.Close != 10 {
+		t.Fatalf("snapshot: %+v", msg)
+	}
+
+	// 多个股票, 加载失败的股票回复 error
+	conn.WriteJSON(Request{Action: "subscribe", Symbols: []string{"B", "BAD"}, ID: "1"})
+	if msg := readMsg(t, conn); msg.Type != MsgError || msg.Symbol != "BAD" || msg.ID != "1" {
+		t.Fatalf("error: %+v", msg)
+	}
+	if msg := readMsg(t, conn); msg.Type != MsgAck || msg.ID != "1" || fmt.Sprint(msg.Symbols) != "[A B]" {
+		t.Fatalf("ack: %+v", msg)
+	}
+	if msg := readMsg(t, conn); msg.Type != MsgSnapshot || msg.Symbol != "B" {
+		t.Fatalf("snapshot: %+v", msg)
+	}
+	if got := fmt.Sprint(h.Symbols()); got != "[A B]" {
+		t.Errorf("hub symbols: %s", got)
+	}
+
+	// 只推送订阅的股票
+	for _, s := range []string{"C", "A", "B"} {
+		h.Broadcast(Message{Type: MsgBar, Symbol: s, Data: &StockData{Symbol: s}})
+	}
+	for _, want := range []string{"A", "B"} {
+		if msg := readMsg(t, conn); msg.Type != MsgBar || msg.Symbol != want {
+			t.Fatalf("bar %s: %+v", want, msg)
+		}
+	}
+
+	conn.WriteJSON(Request{Action: "unsubscribe", Symbols: []string{"A"}})
+	if msg := readMsg(t, conn); msg.Type != MsgAck || msg.Action != "unsubscribe" || fmt.Sprint(msg.Symbols) != "[B]" {
+		t.Fatalf("ack: %+v", msg)
+	}
+	h.Broadcast(Message{Type: MsgBar, Symbol: "A"})
+	h.Broadcast(Message{Type: MsgBar, Symbol: "B"})
+	if msg := readMsg(t, conn); msg.Symbol != "B" {
+		t.Fatalf("after unsubscribe: %+v", msg)
+	}
+
+	// 旧版本消息: 切换到该股票
+	conn.WriteJSON(map[string]string{"symbol": "C"})
+	if msg := readMsg(t, conn); msg.Type != MsgAck || fmt.Sprint(msg.Symbols) != "[C]" {
+		t.Fatalf("legacy: %+v", msg)
+	}
+	readMsg(t, conn) // snapshot
+
+	conn.WriteMessage(websocket.TextMessage, []byte("{"))
+	if msg := readMsg(t, conn); msg.Type != MsgError {
+		t.Fatalf("bad json: %+v", msg)
+	}
+	conn.WriteJSON(Request{Action: "foo"})
+	if msg := readMsg(t, conn); msg.Type != MsgError {
+		t.Fatalf("bad action: %+v", msg)
+	}
+
+	conn.Close()
+	waitFor(t, "unregister", func() bool { return h.ClientCount() == 0 && len(h.Symbols()) == 0 })
+}
+
+func TestHubSlowClient(t *testing.T) {
+	for _, policy := range []OverflowPolicy{PolicyDrop, PolicyDisconnect} {
+		h := NewHub()
+		h.Buffer = 4
+		h.Policy = policy
+		dial := startHub(t, h)
+
+		// 慢客户端不读取, 快客户端持续读取
+		slow := dial("?symbol=A")
+		fast := dial("?symbol=A")
+		readMsg(t, fast) // ack
+		waitFor(t, "subscribe", func() bool { return h.ClientCount() == 2 })
+
+		// 写满 TCP 缓冲区后慢客户端的发送缓冲区溢出, 不影响快客户端
+		data := &StockData{Symbol: strings.Repeat("A", 1<<16)}
+		for i := 0; i < 200 && h.Dropped() == 0; i++ {
+			h.Broadcast(Message{Type: MsgBar, Symbol: "A", Data: data})
+			if msg := readMsg(t, fast); msg.Type != MsgBar {
+				t.Fatalf("fast: %+v", msg)
+			}
+		}
+		if h.Dropped() == 0 {
+			t.Fatalf("policy %d: no message dropped", policy)
+		}
+		switch policy {
+		case PolicyDrop:
+			if h.ClientCount() != 2 {
+				t.Errorf("drop: clients = %d", h.ClientCount())
+			}
+		case PolicyDisconnect:
+			waitFor(t, "disconnect", func() bool { return h.ClientCount() == 1 })
+		}
+		slow.Close()
+	}
+}
+
+func TestHubPingPong(t *testing.T) {
+	h := NewHub()
+	h.PongWait = 200 * time.Millisecond
+	h.PingPeriod = 50 * time.Millisecond
+	dial := startHub(t, h)
+
+	// 读取中的客户端自动回复 pong, 保持连接
+	alive := dial("?symbol=A")
+	go func() {
+		for {
+			if _, _, err := alive.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	// 不读取的客户端不回复 pong, 超时后断开
+	dial("?symbol=B")
+	waitFor(t, "connect", func() bool { return h.ClientCount() == 2 })
+	waitFor(t, "timeout", func() bool { return fmt.Sprint(h.Symbols()) == "[A]" })
+	time.Sleep(3 * h.PongWait)
+	if h.ClientCount() != 1 || fmt.Sprint(h.Symbols()) != "[A]" {
+		t.Errorf("clients = %d, symbols = %v", h.ClientCount(), h.Symbols())
+	}
+}
+
+func TestHubConcurrent(t *testing.T) {
+	h := NewHub()
+	h.Snapshot = func(symbol string) (StockData, bool) { return StockData{Symbol: symbol}, true }
+	dial := startHub(t, h)
+
+	symbols := []string{"A", "B", "C", "D"}
+	stop := make(chan struct{})
+	var bg sync.WaitGroup
+	bg.Add(1)
+	go func() {
+		defer bg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				h.Broadcast(Message{Type: MsgBar, Symbol: symbols[i%len(symbols)]})
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		conn := dial("")
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			go func() {
+				for {
+					if _, _, err := conn.ReadMessage(); err != nil {
+						return
+					}
+				}
+			}()
+			for j := 0; j < 20; j++ {
+				action := "subscribe"
+				if j%2 == 1 {
+					action = "unsubscribe"
+				}
+				conn.WriteJSON(Request{Action: action, Symbols: symbols[j%3 : j%3+2]})
+			}
+			conn.Close()
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	bg.Wait()
+	waitFor(t, "all unregistered", func() bool { return h.ClientCount() == 0 && len(h.Symbols()) == 0 })
+}
+
+func TestReplayControl(t *testing.T) {
+	// gm-api: 工作日为交易日, 每个交易日240根1m K线, 收盘价按分钟递增
+	cst := time.FixedZone("CST", 8*3600)
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/get_dates_by_year":
+			rcd := gm.RawColData{Columns: []string{"date", "trade_date"}}
+			y, _ := time.Parse("2006", q.Get("syear"))
+			for d := y; d.Year() == y.Year(); d = d.AddDate(0, 0, 1) {
+				td := d.Format("2006-01-02")
+				if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+					td = ""
+				}
+				rcd.Data = append(rcd.Data, []any{d.Format("2006-01-02"), td})
+			}
+			json.NewEncoder(w).Encode(rcd)
+		case "/get_his":
+			day, _ := time.ParseInLocation("2006-01-02", q.Get("sdate"), cst)
+			rcd := gm.RawColData{Columns: []string{"symbol", "eob", "open", "high", "low", "close", "volume"}}
+			for i := 0; i < 240; i++ {
+				ts := day.Add(9*time.Hour + 31*time.Minute + time.Duration(i)*time.Minute)
+				if i >= 120 {
+					ts = day.Add(13*time.Hour + time.Minute + time.Duration(i-120)*time.Minute)
+				}
+				rcd.Data = append(rcd.Data, []any{q.Get("symbols"), ts.Format("2006-01-02 15:04:05"), 10.0, 10.3, 9.9, 10 + float64(i)/100, 100})
+			}
+			json.NewEncoder(w).Encode(rcd)
+		case "/get_symbols":
+			json.NewEncoder(w).Encode(gm.RawColData{Columns: []string{"symbol", "sec_name"}, Data: [][]any{{"SHSE.600000", "浦发银行"}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer up.Close()
+	defer gm.SetDefaultCalendar(gm.DefaultCalendar())
+
+	// 倍速很低, 只有第一根K线自动发出
+	src, err := NewReplaySource(up.URL, "", []string{"SHSE.600000"}, "2024-02-05", 0.0001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "first bar", func() bool { return src.replay.State().Pos == 1 })
+	sm := NewStockManager(src)
+	if len(sm.symbols) != 1 || sm.symbols[0].Name != "浦发银行" {
+		t.Errorf("symbols: %+v", sm.symbols)
+	}
+	conn := startHub(t, sm.hub)("?symbol=SHSE.600000")
+	readMsg(t, conn) // ack
+	at := func(hm string) int64 {
+		t, _ := time.ParseInLocation("2006-01-02 15:04", "2024-02-05 "+hm, cst)
+		return t.UnixMilli()
+	}
+	if msg := readMsg(t, conn); msg.Type != MsgSnapshot || msg.Data.Timestamp != at("09:31") {
+		t.Fatalf("snapshot: %+v", msg)
+	}
+
+	// 单步: 回复 ack, 所有连接收到回放状态, 之后的更新推送新的K线
+	conn.WriteJSON(Request{Action: "step", N: 2})
+	if msg := readMsg(t, conn); msg.Type != MsgAck || msg.Action != "step" {
+		t.Fatalf("ack: %+v", msg)
+	}
+	if msg := readMsg(t, conn); msg.Type != MsgReplay || msg.Reset || msg.State.(map[string]any)["pos"] != 3.0 ||
+		msg.State.(map[string]any)["paused"] != true {
+		t.Fatalf("replay: %+v", msg)
+	}
+	sm.update(sm.hub.Symbols())
+	for _, hm := range []string{"09:32", "09:33"} {
+		if msg := readMsg(t, conn); msg.Type != MsgBar || msg.Data.Timestamp != at(hm) {
+			t.Fatalf("bar %s: %+v", hm, msg)
+		}
+	}
+
+	// 跳转: 重新加载历史数据
+	conn.WriteJSON(Request{Action: "seek", Time: "11:30"})
+	readMsg(t, conn) // ack
+	if msg := readMsg(t, conn); msg.Type != MsgReplay || !msg.Reset {
+		t.Fatalf("seek: %+v", msg)
+	}
+	data, err := sm.loadHistory(context.Background(), "SHSE.600000")
+	if err != nil || len(data) != 100 || data[99].Timestamp != at("11:30") {
+		t.Fatalf("history after seek: %d %v", len(data), err)
+	}
+
+	conn.WriteJSON(Request{Action: "speed", Speed: -1})
+	if msg := readMsg(t, conn); msg.Type != MsgError {
+		t.Fatalf("bad speed: %+v", msg)
+	}
+}
+
+func TestSimSource(t *testing.T) {
+	ctx := context.Background()
+	a, b := NewSimSource(1), NewSimSource(1)
+	ha, _ := a.History(ctx, "SHSE.600000", 100)
+	hb, _ := b.History(ctx, "SHSE.600000", 100)
+	if len(ha) != 100 || fmt.Sprint(ha) != fmt.Sprint(hb) {
+		t.Fatalf("history: %d bars, deterministic=%v", len(ha), fmt.Sprint(ha) == fmt.Sprint(hb))
+	}
+	// 第100根K线为第一个交易日的 11:10, 之后推进一分钟
+	if got := time.UnixMilli(ha[99].Timestamp).In(cstZone).Format("2006-01-02 15:04"); got != "2025-01-06 11:10" {
+		t.Errorf("last history bar: %s", got)
+	}
+	for range 140 {
+		a.Latest(ctx, []string{"SHSE.600000"})
+	}
+	bars, _ := a.Latest(ctx, []string{"SHSE.600000", "SZSE.300750", "AAPL"})
+	if len(bars) != 2 || time.UnixMilli(bars[0].Timestamp).In(cstZone).Format("2006-01-02 15:04") != "2025-01-07 09:31" {
+		t.Fatalf("latest: %+v", bars)
+	}
+	if bars[0].Volume%100 != 0 {
+		t.Errorf("volume not in lots: %d", bars[0].Volume)
+	}
+	if _, err := a.History(ctx, "AAPL", 100); err == nil {
+		t.Error("unknown symbol accepted")
+	}
+}
@@ -1,19 +1,18 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
-	"math"
-	"math/rand"
 	"net/http"
+	"slices"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/gorilla/mux"
-	"github.com/gorilla/websocket"
 )
 
 // StockData 股票数据结构
@@ -34,153 +33,171 @@ type StockResponse struct {
 	Timestamp int64       `json:"timestamp"`
 }
 
-// WebSocketMessage WebSocket消息结构
-type WebSocketMessage struct {
-	Type string    `json:"type"`
-	Data StockData `json:"data"`
-}
-
 // StockManager 股票数据管理器
 type StockManager struct {
 	mu           sync.RWMutex
+	source       DataSource
+	symbols      []SymbolInfo
 	stockData    map[string][]StockData
-	clients      map[*websocket.Conn]string
-	basePrices   map[string]float64
-	upgrader     websocket.Upgrader
+	hub          *Hub
 	updateTicker *time.Ticker
+	updateMu     sync.Mutex // 实时更新与回放控制互斥
 }
 
+// 每个股票保留的K线数
+const maxBars = 200
+
 // NewStockManager 创建新的股票管理器
-func NewStockManager() *StockManager {
+func NewStockManager(source DataSource) *StockManager {
 	sm := &StockManager{
-		stockData:  make(map[string][]StockData),
-		clients:    make(map[*websocket.Conn]string),
-		basePrices: make(map[string]float64),
-		upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				return true // 允许跨域
-			},
-		},
+		source:    source,
+		stockData: make(map[string][]StockData),
+		hub:       NewHub(),
+	}
+	sm.hub.Load = func(ctx context.Context, symbol string) error {
+		_, err := sm.loadHistory(ctx, symbol)
+		return err
+	}
+	sm.hub.Snapshot = sm.lastBar
+	if _, ok := source.(Controller); ok {
+		sm.hub.Control = sm.control
 	}
 
-	// 初始化基础价格
-	sm.basePrices["AAPL"] = 150.0
-	sm.basePrices["GOOGL"] = 2800.0
-	sm.basePrices["MSFT"] = 330.0
-	sm.basePrices["TSLA"] = 200.0
-	sm.basePrices["AMZN"] = 3200.0
-
-	// 生成初始数据
-	sm.generateInitialData()
+	// 加载股票列表及初始数据
+	ctx := context.Background()
+	symbols, err := source.Symbols(ctx)
+	if err != nil {
+		log.Printf("获取股票列表失败: %v", err)
+	}
+	sm.symbols = symbols
+	for _, s := range symbols {
+		if _, err := sm.loadHistory(ctx, s.Symbol); err != nil {
+			log.Printf("获取历史数据失败(%s): %v", s.Symbol, err)
+		}
+	}
 
 	return sm
 }
 
-// generateInitialData 生成初始历史数据
-func (sm *StockManager) generateInitialData() {
-	symbols := []string{"AAPL", "GOOGL", "MSFT", "TSLA", "AMZN"}
-	now := time.Now()
-
-	for _, symbol := range symbols {
-		basePrice := sm.basePrices[symbol]
-		data := make([]StockData, 100)
-
-		for i := range 100 {
-			timestamp := now.Add(time.Duration(-100+i) * time.Minute)
-
-			// 生成价格变动
-			change := (rand.Float64() - 0.5) * basePrice * 0.02
-			basePrice = math.Max(basePrice+change, basePrice*0.9)
-
-			open := basePrice
-			high := open + rand.Float64()*open*0.01
-			low := open - rand.Float64()*open*0.01
-			close := low + rand.Float64()*(high-low)
-			volume := rand.Int63n(900000) + 100000
-
-			data[i] = StockData{
-				Timestamp: timestamp.UnixMilli(),
-				Symbol:    symbol,
-				Open:      math.Round(open*100) / 100,
-				High:      math.Round(high*100) / 100,
-				Low:       math.Round(low*100) / 100,
-				Close:     math.Round(close*100) / 100,
-				Volume:    volume,
-			}
-
-			basePrice = close
-		}
+// loadHistory 获取股票的历史数据, 已加载时直接返回
+func (sm *StockManager) loadHistory(ctx context.Context, symbol string) ([]StockData, error) {
+	sm.mu.RLock()
+	data, exists := sm.stockData[symbol]
+	sm.mu.RUnlock()
+	if exists {
+		return slices.Clone(data), nil
+	}
 
-		sm.stockData[symbol] = data
-		sm.basePrices[symbol] = basePrice
+	data, err := sm.source.History(ctx, symbol, 100)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("没有数据: %s", symbol)
 	}
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if cur, exists := sm.stockData[symbol]; exists {
+		return slices.Clone(cur), nil
+	}
+	sm.stockData[symbol] = data
+	return slices.Clone(data), nil
 }
 
-// generateNewData 生成新的实时数据
-func (sm *StockManager) generateNewData(symbol string) StockData {
+// applyUpdate 合并最新K线: 时间戳相同时替换当前K线, 否则追加; 返回是否有变化
+func (sm *StockManager) applyUpdate(bar StockData) bool {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	lastData := sm.stockData[symbol][len(sm.stockData[symbol])-1]
-	now := time.Now()
+	data := sm.stockData[bar.Symbol]
+	if n := len(data); n > 0 {
+		switch last := data[n-1]; {
+		case bar.Timestamp < last.Timestamp:
+			return false
+		case bar.Timestamp == last.Timestamp:
+			if bar == last {
+				return false
+			}
+			data[n-1] = bar
+			return true
+		}
+	}
+	data = append(data, bar)
 
-	// 生成价格变动
-	change := (rand.Float64() - 0.5) * lastData.Close * 0.01
-	newPrice := math.Max(lastData.Close+change, lastData.Close*0.99)
+	// 保持最多 maxBars 个数据点
+	if len(data) > maxBars {
+		data = data[len(data)-maxBars:]
+	}
+	sm.stockData[bar.Symbol] = data
+	return true
+}
 
-	newData := StockData{
-		Timestamp: now.UnixMilli(),
-		Symbol:    symbol,
-		Open:      lastData.Close,
-		High:      math.Max(lastData.Close, newPrice),
-		Low:       math.Min(lastData.Close, newPrice),
-		Close:     math.Round(newPrice*100) / 100,
-		Volume:    rand.Int63n(500000) + 50000,
+// lastBar 最新K线
+func (sm *StockManager) lastBar(symbol string) (StockData, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	data := sm.stockData[symbol]
+	if len(data) == 0 {
+		return StockData{}, false
 	}
+	return data[len(data)-1], true
+}
 
-	// 添加新数据
-	sm.stockData[symbol] = append(sm.stockData[symbol], newData)
+// control 处理回放控制, 跳转后重新加载历史数据
+func (sm *StockManager) control(req Request) (Message, error) {
+	sm.updateMu.Lock()
+	defer sm.updateMu.Unlock()
 
-	// 保持最多200个数据点
-	if len(sm.stockData[symbol]) > 200 {
-		sm.stockData[symbol] = sm.stockData[symbol][1:]
+	state, reset, err := sm.source.(Controller).Control(req)
+	if err != nil {
+		return Message{}, err
 	}
-
-	return newData
+	if reset {
+		sm.mu.Lock()
+		symbols := make([]string, 0, len(sm.stockData))
+		for symbol := range sm.stockData {
+			symbols = append(symbols, symbol)
+		}
+		clear(sm.stockData)
+		sm.mu.Unlock()
+		for _, symbol := range symbols {
+			if _, err := sm.loadHistory(context.Background(), symbol); err != nil {
+				log.Printf("获取历史数据失败(%s): %v", symbol, err)
+			}
+		}
+	}
+	return Message{Type: MsgReplay, State: state, Reset: reset}, nil
 }
 
 // startRealTimeUpdate 启动实时数据更新
 func (sm *StockManager) startRealTimeUpdate() {
-	sm.updateTicker = time.NewTicker(10 * time.Second)
+	sm.updateTicker = time.NewTicker(sm.source.Interval())
 	go func() {
 		for range sm.updateTicker.C {
-			symbols := []string{"AAPL", "GOOGL", "MSFT", "TSLA", "AMZN"}
-			for _, symbol := range symbols {
-				newData := sm.generateNewData(symbol)
-				sm.broadcastToClients(symbol, newData)
+			symbols := sm.hub.Symbols()
+			if len(symbols) == 0 {
+				continue
 			}
+			sm.update(symbols)
 		}
 	}()
 }
 
-// broadcastToClients 向订阅的客户端广播数据
-func (sm *StockManager) broadcastToClients(symbol string, data StockData) {
-	sm.mu.RLock()
-	defer sm.mu.RUnlock()
+// update 获取并推送一次最新数据
+func (sm *StockManager) update(symbols []string) {
+	sm.updateMu.Lock()
+	defer sm.updateMu.Unlock()
 
-	message := WebSocketMessage{
-		Type: "update",
-		Data: data,
+	ctx, cancel := context.WithTimeout(context.Background(), sm.source.Interval()*3)
+	bars, err := sm.source.Latest(ctx, symbols)
+	cancel()
+	if err != nil {
+		log.Printf("获取实时数据失败: %v", err)
+		return
 	}
-
-	for client, clientSymbol := range sm.clients {
-		if clientSymbol == symbol {
-			err := client.WriteJSON(message)
-			if err != nil {
-				log.Printf("WebSocket write error: %v", err)
-				client.Close()
-				delete(sm.clients, client)
-			}
+	for _, bar := range bars {
+		if sm.applyUpdate(bar) {
+			sm.hub.Broadcast(Message{Type: MsgBar, Symbol: bar.Symbol, Data: &bar})
 		}
 	}
 }
@@ -200,11 +217,9 @@ func (sm *StockManager) getStockDataHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	sm.mu.RLock()
-	data, exists := sm.stockData[symbol]
-	sm.mu.RUnlock()
-
-	if !exists {
+	data, err := sm.loadHistory(r.Context(), symbol)
+	if err != nil {
+		log.Printf("获取历史数据失败(%s): %v", symbol, err)
 		http.Error(w, "Symbol not found", http.StatusNotFound)
 		return
 	}
@@ -233,76 +248,6 @@ func (sm *StockManager) getStockDataHandler(w http.ResponseWriter, r *http.Reque
 	json.NewEncoder(w).Encode(response)
 }
 
-// websocketHandler WebSocket处理器
-func (sm *StockManager) websocketHandler(w http.ResponseWriter, r *http.Request) {
-	conn, err := sm.upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		log.Printf("WebSocket upgrade error: %v", err)
-		return
-	}
-	defer conn.Close()
-
-	// 获取订阅的股票符号
-	symbol := r.URL.Query().Get("symbol")
-	if symbol == "" {
-		symbol = "SHSE.000001" // 默认订阅上证指数
-	}
-
-	sm.mu.Lock()
-	sm.clients[conn] = symbol
-	sm.mu.Unlock()
-
-	log.Printf("Client connected for symbol: %s", symbol)
-
-	// 发送初始数据
-	sm.mu.RLock()
-	if data, exists := sm.stockData[symbol]; exists && len(data) > 0 {
-		lastData := data[len(data)-1]
-		message := WebSocketMessage{
-			Type: "initial",
-			Data: lastData,
-		}
-		conn.WriteJSON(message)
-	}
-	sm.mu.RUnlock()
-
-	// 监听客户端消息
-	for {
-		var msg map[string]any
-		err := conn.ReadJSON(&msg)
-		if err != nil {
-			log.Printf("WebSocket read error: %v", err)
-			break
-		}
-
-		// 处理订阅变更
-		if newSymbol, ok := msg["symbol"].(string); ok {
-			sm.mu.Lock()
-			sm.clients[conn] = newSymbol
-			sm.mu.Unlock()
-			log.Printf("Client switched to symbol: %s", newSymbol)
-
-			// 发送新股票的最新数据
-			sm.mu.RLock()
-			if data, exists := sm.stockData[newSymbol]; exists && len(data) > 0 {
-				lastData := data[len(data)-1]
-				message := WebSocketMessage{
-					Type: "initial",
-					Data: lastData,
-				}
-				conn.WriteJSON(message)
-			}
-			sm.mu.RUnlock()
-		}
-	}
-
-	// 清理连接
-	sm.mu.Lock()
-	delete(sm.clients, conn)
-	sm.mu.Unlock()
-	log.Printf("Client disconnected")
-}
-
 // getSymbolsHandler 获取所有可用的股票符号
 func (sm *StockManager) getSymbolsHandler(w http.ResponseWriter, r *http.Request) {
 	// 设置CORS头
@@ -315,13 +260,16 @@ func (sm *StockManager) getSymbolsHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	symbols := []map[string]any{
-		{"symbol": "AAPL", "name": "Apple Inc.", "price": sm.basePrices["AAPL"]},
-		{"symbol": "GOOGL", "name": "Alphabet Inc.", "price": sm.basePrices["GOOGL"]},
-		{"symbol": "MSFT", "name": "Microsoft Corporation", "price": sm.basePrices["MSFT"]},
-		{"symbol": "TSLA", "name": "Tesla, Inc.", "price": sm.basePrices["TSLA"]},
-		{"symbol": "AMZN", "name": "Amazon.com, Inc.", "price": sm.basePrices["AMZN"]},
+	// 价格取最新收盘价
+	sm.mu.RLock()
+	symbols := make([]SymbolInfo, len(sm.symbols))
+	for i, s := range sm.symbols {
+		symbols[i] = s
+		if data := sm.stockData[s.Symbol]; len(data) > 0 {
+			symbols[i].Price = data[len(data)-1].Close
+		}
 	}
+	sm.mu.RUnlock()
 
 	json.NewEncoder(w).Encode(symbols)
 }
@@ -331,11 +279,18 @@ func (sm *StockManager) healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Type", "application/json")
 
+	sm.mu.RLock()
 	status := map[string]any{
 		"status":    "ok",
+		"source":    sm.source.Name(),
 		"timestamp": time.Now().UnixMilli(),
 		"symbols":   len(sm.stockData),
-		"clients":   len(sm.clients),
+		"clients":   sm.hub.ClientCount(),
+		"dropped":   sm.hub.Dropped(),
+	}
+	sm.mu.RUnlock()
+	if ctl, ok := sm.source.(Controller); ok {
+		status["replay"], _, _ = ctl.Control(Request{Action: "state"})
 	}
 
 	json.NewEncoder(w).Encode(status)
@@ -344,9 +299,13 @@ func (sm *StockManager) healthHandler(w http.ResponseWriter, r *http.Request) {
 // 配置结构体
 type Config struct {
 	API struct {
-		Port  int    `toml:"port"`
-		gmapi string `toml:"gmapi"`
-		gmcsv string `toml:"gmcsv"`
+		Port    int      `toml:"port"`
+		Gmapi   string   `toml:"gmapi"`   // gm-api 地址, 为空时使用模拟数据
+		Gmcsv   string   `toml:"gmcsv"`   // gm-csv 地址或本地目录
+		Symbols []string `toml:"symbols"` // 股票列表, 为空时使用默认列表
+		Replay  string   `toml:"replay"`  // 回放日期(如 2025-07-01), 设置时回放该日的分时数据
+		Speed   float64  `toml:"speed"`   // 回放倍速, 默认60
+		Seed    uint64   `toml:"seed"`    // 模拟数据的随机种子
 	} `toml:"api"`
 }
 
@@ -363,9 +322,16 @@ func main() {
 	// rand.Seed(time.Now().UnixNano())
 
 	// 创建股票管理器
-	stockManager := NewStockManager()
+	source, err := newDataSource(cfg)
+	if err != nil {
+		fmt.Println("Error creating data source:", err)
+		return
+	}
+	fmt.Println(" -=> Data source:", source.Name())
+	stockManager := NewStockManager(source)
 
-	// 启动实时数据更新
+	// 启动 WebSocket 连接管理及实时数据更新
+	go stockManager.hub.Run(context.Background())
 	stockManager.startRealTimeUpdate()
 
 	// 创建路由
@@ -378,7 +344,7 @@ func main() {
 	api.HandleFunc("/health", stockManager.healthHandler).Methods("GET")
 
 	// WebSocket路由
-	r.HandleFunc("/ws", stockManager.websocketHandler)
+	r.HandleFunc("/ws", stockManager.hub.ServeWS)
 
 	// 静态文件服务（可选）
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir("./static/")))
@@ -391,10 +357,16 @@ func main() {
 	fmt.Println("  GET  /api/v1/stocks/{symbol} - 获取股票历史数据")
 	fmt.Println("  GET  /api/v1/symbols - 获取所有股票符号")
 	fmt.Println("  GET  /api/v1/health - 健康检查")
-	fmt.Println("  WS   /ws?symbol={symbol} - WebSocket实时数据")
+	fmt.Println("  WS   /ws?symbols={symbol,...} - WebSocket实时数据")
+	fmt.Println(`       {"action":"subscribe|unsubscribe","symbols":[...],"id":"..."}`)
+	if _, ok := source.(Controller); ok {
+		fmt.Println(`       {"action":"pause|resume|step|seek|speed|state","n":1,"time":"10:30","speed":60}`)
+	}
 	fmt.Println()
 	fmt.Println("示例请求:")
-	fmt.Printf("  curl http://localhost%s/api/v1/stocks/AAPL\n", port)
+	if len(stockManager.symbols) > 0 {
+		fmt.Printf("  curl http://localhost%s/api/v1/stocks/%s\n", port, stockManager.symbols[0].Symbol)
+	}
 	fmt.Printf("  curl http://localhost%s/api/v1/symbols\n", port)
 	// fmt.Println("  curl http://localhost:8080/api/v1/symbols")
 
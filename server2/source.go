@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// SymbolInfo 可选的股票
+type SymbolInfo struct {
+	Symbol string  `json:"symbol"`
+	Name   string  `json:"name"`
+	Price  float64 `json:"price"`
+}
+
+// DataSource 行情数据源
+//
+// StockManager 启动时用 History 加载历史K线, 之后每隔 Interval 调用 Latest 获取有客户端订阅的
+// 股票的最新K线: 时间戳与最后一根相同时为当前K线的更新, 否则为新的K线。
+type DataSource interface {
+	Name() string
+	Interval() time.Duration
+	Symbols(ctx context.Context) ([]SymbolInfo, error)
+	History(ctx context.Context, symbol string, limit int) ([]StockData, error)
+	Latest(ctx context.Context, symbols []string) ([]StockData, error)
+}
+
+// 按配置选择数据源: 设置了 gmapi 时使用 gm-api(设置了 replay 时回放该日数据), 否则使用模拟数据
+func newDataSource(cfg Config) (DataSource, error) {
+	if cfg.API.Gmapi == "" {
+		return NewSimSource(cfg.API.Seed), nil
+	}
+	if cfg.API.Replay != "" {
+		speed := cfg.API.Speed
+		if speed <= 0 {
+			speed = 60
+		}
+		return NewReplaySource(cfg.API.Gmapi, cfg.API.Gmcsv, cfg.API.Symbols, cfg.API.Replay, speed)
+	}
+	return NewGMSource(cfg.API.Gmapi, cfg.API.Gmcsv, cfg.API.Symbols), nil
+}
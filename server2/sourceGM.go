@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lmzxtek/ths-go/gm"
+)
+
+// 未配置 symbols 时的默认股票
+var defaultSymbols = []string{"SHSE.000001", "SHSE.600000", "SHSE.600519", "SZSE.000001", "SZSE.300750"}
+
+// GMSource gm-api 数据源: 历史K线来自 get_his_n, 盘中由 get_current 快照合成当前1m K线
+type GMSource struct {
+	client  *gm.Client
+	symbols []string
+	poller  *gm.SnapPoller
+}
+
+func NewGMSource(gmapi string, gmcsv string, symbols []string) *GMSource {
+	client := gm.NewClient(strings.TrimRight(gmapi, "/"),
+		gm.WithCSVURL(strings.TrimRight(gmcsv, "/")),
+		gm.WithTimeout(10*time.Second),
+	)
+	// 交易日历用于判断是否在交易时段
+	gm.SetDefaultCalendar(gm.NewCalendar(client, ""))
+	if len(symbols) == 0 {
+		symbols = defaultSymbols
+	}
+	return &GMSource{
+		client:  client,
+		symbols: symbols,
+		poller:  gm.NewSnapPoller(client, nil, 0),
+	}
+}
+
+func (s *GMSource) Name() string { return "gm" }
+
+func (s *GMSource) Interval() time.Duration { return 3 * time.Second }
+
+// Symbols 配置的股票及名称(获取名称失败时使用代码)
+func (s *GMSource) Symbols(ctx context.Context) ([]SymbolInfo, error) {
+	names := map[string]string{}
+	records, err := s.client.GetSymbolsInfo(ctx, strings.Join(s.symbols, ","), "", "", "")
+	if err != nil {
+		log.Printf("获取股票名称失败: %v", err)
+	}
+	for _, rec := range records {
+		sym, _ := rec["symbol"].(string)
+		name, _ := rec["sec_name"].(string)
+		names[sym] = name
+	}
+	list := make([]SymbolInfo, len(s.symbols))
+	for i, sym := range s.symbols {
+		list[i] = SymbolInfo{Symbol: sym, Name: names[sym]}
+		if list[i].Name == "" {
+			list[i].Name = sym
+		}
+	}
+	return list, nil
+}
+
+// History 截至今天的最近 limit 根1m K线
+func (s *GMSource) History(ctx context.Context, symbol string, limit int) ([]StockData, error) {
+	today := time.Now().Format("2006-01-02")
+	records, err := s.client.GetKbarsHisN(ctx, symbol, "1m", strconv.Itoa(limit), today, false)
+	if err != nil {
+		return nil, err
+	}
+	var bars gm.OHLCVList
+	if err := bars.FromMapList(records); err != nil {
+		return nil, fmt.Errorf("解析K线数据失败(%s): %w", symbol, err)
+	}
+	return toStockData(symbol, bars), nil
+}
+
+// Latest 请求快照并返回各股票当前的1m K线, 非交易时段不请求
+func (s *GMSource) Latest(ctx context.Context, symbols []string) ([]StockData, error) {
+	if ss := gm.DefaultCalendar().SessionAt(time.Now()); ss != gm.SessionAuction && !ss.IsTrading() {
+		return nil, nil
+	}
+	s.poller.SetSymbols(symbols)
+	if err := s.poller.Poll(ctx); err != nil {
+		return nil, err
+	}
+	var list []StockData
+	for _, sym := range symbols {
+		if bars := s.poller.Bars(sym); len(bars) > 0 {
+			list = append(list, toStockData(sym, bars[len(bars)-1:])...)
+		}
+	}
+	return list, nil
+}
+
+func toStockData(symbol string, bars gm.OHLCVList) []StockData {
+	data := make([]StockData, len(bars))
+	for i, b := range bars {
+		data[i] = StockData{
+			Timestamp: b.Timestamp.UnixMilli(),
+			Symbol:    symbol,
+			Open:      b.Open,
+			High:      b.High,
+			Low:       b.Low,
+			Close:     b.Close,
+			Volume:    b.Volume,
+		}
+	}
+	return data
+}
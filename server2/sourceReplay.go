@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lmzxtek/ths-go/gm"
+)
+
+// Controller 可由客户端控制的数据源(回放), 返回控制后的状态及是否需要重新加载历史数据
+type Controller interface {
+	Control(req Request) (state any, reset bool, err error)
+}
+
+// ReplaySource 回放数据源: 按倍速发出历史交易日的1m K线, 收盘后也可测试前端及盘中指标
+//
+// 客户端通过 WebSocket 控制回放:
+//
+//	{"action":"pause"}  {"action":"resume"}  {"action":"step","n":5}
+//	{"action":"seek","time":"10:30"}  {"action":"speed","speed":120}  {"action":"state"}
+type ReplaySource struct {
+	*GMSource
+	replay *gm.Replay
+
+	mu      sync.Mutex
+	pending map[string][]StockData // 已发出但尚未被 Latest 取走的K线
+}
+
+func NewReplaySource(gmapi string, gmcsv string, symbols []string, date string, speed float64) (*ReplaySource, error) {
+	src := NewGMSource(gmapi, gmcsv, symbols)
+	s := &ReplaySource{
+		GMSource: src,
+		replay:   gm.NewReplay(src.client, src.symbols, date, speed),
+		pending:  make(map[string][]StockData),
+	}
+	s.replay.OnBar = func(symbol string, bar gm.OHLCVData) {
+		// 跳转前已取出的K线
+		if bar.Timestamp.After(s.replay.State().Time) {
+			return
+		}
+		s.mu.Lock()
+		s.pending[symbol] = append(s.pending[symbol], toStockData(symbol, gm.OHLCVList{bar})...)
+		s.mu.Unlock()
+	}
+	if err := s.replay.Load(context.Background()); err != nil {
+		return nil, err
+	}
+	go s.replay.Run(context.Background())
+	return s, nil
+}
+
+func (s *ReplaySource) Name() string { return "replay" }
+
+func (s *ReplaySource) Interval() time.Duration { return 200 * time.Millisecond }
+
+// History 回放时钟之前的K线
+func (s *ReplaySource) History(ctx context.Context, symbol string, limit int) ([]StockData, error) {
+	if !s.has(symbol) {
+		return nil, fmt.Errorf("不在回放列表中: %s", symbol)
+	}
+	// 与 Latest 互斥, 避免同一根K线既在历史数据中又被推送
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, symbol)
+	bars := s.replay.Bars(symbol)
+	if len(bars) > limit {
+		bars = bars[len(bars)-limit:]
+	}
+	if len(bars) == 0 {
+		return nil, fmt.Errorf("回放尚未开始: %s", symbol)
+	}
+	return toStockData(symbol, bars), nil
+}
+
+// Latest 取出上次调用之后发出的K线
+func (s *ReplaySource) Latest(ctx context.Context, symbols []string) ([]StockData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []StockData
+	for _, sym := range symbols {
+		list = append(list, s.pending[sym]...)
+		delete(s.pending, sym)
+	}
+	return list, nil
+}
+
+func (s *ReplaySource) has(symbol string) bool {
+	for _, sym := range s.symbols {
+		if sym == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+var cstZone = time.FixedZone("CST", 8*3600)
+
+func (s *ReplaySource) Control(req Request) (any, bool, error) {
+	reset := false
+	switch req.Action {
+	case "pause":
+		s.replay.Pause()
+	case "resume":
+		s.replay.Resume()
+	case "step":
+		s.replay.Step(max(req.N, 1))
+	case "seek":
+		hm := strings.TrimSpace(req.Time)
+		t, err := time.ParseInLocation("2006-01-02 15:04", s.replay.Date+" "+hm, cstZone)
+		if err != nil {
+			return nil, false, fmt.Errorf("时间格式错误: %s (示例: 10:30)", req.Time)
+		}
+		s.mu.Lock()
+		s.replay.Seek(t)
+		clear(s.pending)
+		s.mu.Unlock()
+		reset = true
+	case "speed":
+		if req.Speed <= 0 {
+			return nil, false, fmt.Errorf("倍速必须大于0: %g", req.Speed)
+		}
+		s.replay.SetSpeed(req.Speed)
+	case "state":
+	default:
+		return nil, false, fmt.Errorf("不支持的 action: %s (可选: subscribe, unsubscribe, pause, resume, step, seek, speed, state)", req.Action)
+	}
+	return s.replay.State(), reset, nil
+}
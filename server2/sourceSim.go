@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lmzxtek/ths-go/gm"
+	"github.com/lmzxtek/ths-go/gmsim"
+)
+
+// 模拟数据的起始日期(固定, 同一种子每次启动的数据相同)
+const simStartDate = "2025-01-06"
+
+// SimSource 模拟数据源: 由 gmsim 按种子生成A股分时K线, 不依赖外部服务
+//
+// 启动时已有 simHistory 根历史K线, 之后每次 Latest 推进一分钟(不等待真实时间),
+// 当日K线用完后生成下一个工作日。
+type SimSource struct {
+	gen *gmsim.Generator
+
+	mu      sync.Mutex
+	symbols []SymbolInfo
+	dates   []string
+	bars    map[string]gm.OHLCVList // 已生成的K线
+	pos     int                     // 已发出的K线数
+}
+
+const simHistory = 100
+
+func NewSimSource(seed uint64) *SimSource {
+	s := &SimSource{
+		gen: gmsim.New(seed,
+			gmsim.Symbol{Symbol: "SHSE.000001", PreClose: 3400, Volatility: 0.01, AvgVolume: 40_000_000_000},
+			gmsim.Symbol{Symbol: "SHSE.600000", PreClose: 10.5},
+			gmsim.Symbol{Symbol: "SHSE.600519", PreClose: 1500, Volatility: 0.015, AvgVolume: 3_000_000},
+			gmsim.Symbol{Symbol: "SZSE.000001", PreClose: 11.2, AvgVolume: 80_000_000},
+			gmsim.Symbol{Symbol: "SZSE.300750", PreClose: 250, Volatility: 0.03, AvgVolume: 20_000_000},
+		),
+		symbols: []SymbolInfo{
+			{Symbol: "SHSE.000001", Name: "上证指数"},
+			{Symbol: "SHSE.600000", Name: "浦发银行"},
+			{Symbol: "SHSE.600519", Name: "贵州茅台"},
+			{Symbol: "SZSE.000001", Name: "平安银行"},
+			{Symbol: "SZSE.300750", Name: "宁德时代"},
+		},
+		bars: make(map[string]gm.OHLCVList),
+		pos:  simHistory,
+	}
+	return s
+}
+
+func (s *SimSource) Name() string { return "sim" }
+
+func (s *SimSource) Interval() time.Duration { return 10 * time.Second }
+
+func (s *SimSource) Symbols(ctx context.Context) ([]SymbolInfo, error) {
+	return s.symbols, nil
+}
+
+// 按交易日生成K线直到每个股票至少有 n 根(需持有锁)
+func (s *SimSource) ensure(n int) error {
+	for len(s.dates)*240 < n {
+		dates, err := gmsim.Weekdays(simStartDate, len(s.dates)+1)
+		if err != nil {
+			return err
+		}
+		date := dates[len(dates)-1]
+		for _, sym := range s.symbols {
+			bars := s.bars[sym.Symbol]
+			preClose := 0.0 // 第一个交易日使用生成器中的昨收价
+			if len(bars) > 0 {
+				preClose = bars[len(bars)-1].Close
+			}
+			day, err := s.gen.Day(sym.Symbol, date, preClose)
+			if err != nil {
+				return err
+			}
+			s.bars[sym.Symbol] = append(bars, day...)
+		}
+		s.dates = dates
+	}
+	return nil
+}
+
+// History 已发出的最近 limit 根K线
+func (s *SimSource) History(ctx context.Context, symbol string, limit int) ([]StockData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.has(symbol) {
+		return nil, fmt.Errorf("不支持的股票代码: %s", symbol)
+	}
+	if err := s.ensure(s.pos); err != nil {
+		return nil, err
+	}
+	bars := s.bars[symbol][:s.pos]
+	if len(bars) > limit {
+		bars = bars[len(bars)-limit:]
+	}
+	return toStockData(symbol, bars), nil
+}
+
+// Latest 推进一分钟, 返回各股票的新K线
+func (s *SimSource) Latest(ctx context.Context, symbols []string) ([]StockData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ensure(s.pos + 1); err != nil {
+		return nil, err
+	}
+	s.pos++
+	var list []StockData
+	for _, symbol := range symbols {
+		if s.has(symbol) {
+			list = append(list, toStockData(symbol, s.bars[symbol][s.pos-1:s.pos])...)
+		}
+	}
+	return list, nil
+}
+
+func (s *SimSource) has(symbol string) bool {
+	for _, sym := range s.symbols {
+		if sym.Symbol == symbol {
+			return true
+		}
+	}
+	return false
+}
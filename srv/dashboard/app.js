@@ -0,0 +1,228 @@
+// 行情看板: K线来自 /gm1d(日线) 或 /gm1m(分钟线), 叠加线和vv副图来自 /gmvv 的日频指标
+//
+// 页面地址可带参数, 如 /dashboard/?symbol=SHSE.601088&tag=1m&sdate=2025-07-01&edate=2025-07-04
+(function () {
+    'use strict';
+
+    var API = '../';
+    var OVERLAY_COLORS = { hjj: '#f5a623', pvj: '#4aa3ff', cbj: '#c86dd7', cb1: '#3cc7b4', cb2: '#e8e35a' };
+    var VV_COLORS = ['#f5a623', '#4aa3ff'];
+    var VV_INDICATORS = 'hjj,pvj,cbj,cb1,cb2,vmed,v931,v932,v935,v940,v150,nup,ndown';
+
+    var $ = function (id) { return document.getElementById(id); };
+    var form = $('query');
+    var chart = new KChart($('chart'), [
+        { id: 'main', weight: 3 },
+        { id: 'volume', weight: 1, title: 'VOL' },
+        { id: 'vv', weight: 1, title: 'VV' }
+    ]);
+
+    var state = { bars: [], vv: {} }; // vv: 日期 -> /gmvv 的1dvv记录
+    var symbols = [];                  // [{symbol, name}]
+
+    function pad(n) { return n < 10 ? '0' + n : '' + n; }
+    function ymd(d) { return d.getFullYear() + '-' + pad(d.getMonth() + 1) + '-' + pad(d.getDate()); }
+    function daysAgo(n) { var d = new Date(); d.setDate(d.getDate() - n); return ymd(d); }
+
+    function setStatus(text, isError) {
+        var el = $('status');
+        el.textContent = text;
+        el.className = isError ? 'status error' : 'status';
+    }
+
+    // 请求接口, 错误响应为 {"code": ..., "error": ...}
+    function getJSON(path, params) {
+        var qs = Object.keys(params).map(function (k) {
+            return encodeURIComponent(k) + '=' + encodeURIComponent(params[k]);
+        }).join('&');
+        return fetch(API + path + '?' + qs).then(function (resp) {
+            return resp.json().catch(function () { return null; }).then(function (body) {
+                if (!resp.ok) {
+                    var msg = body && body.error ? body.error : resp.status + ' ' + resp.statusText;
+                    throw new Error('/' + path + ': ' + msg);
+                }
+                return body;
+            });
+        });
+    }
+
+    //====================================================
+    // 股票搜索: 按代码或名称匹配, 最多显示50条
+
+    function loadSymbols() {
+        ['stock', 'index'].forEach(function (sec) {
+            getJSON('symbols_info', { sec: sec }).then(function (records) {
+                (records || []).forEach(function (r) {
+                    symbols.push({ symbol: r.symbol, name: r.sec_name || '' });
+                });
+            }).catch(function () {
+                // 无法获取列表时仍可直接输入代码
+            });
+        });
+    }
+
+    function searchSymbols() {
+        var q = $('symbol').value.trim().toUpperCase();
+        var list = $('symbol-list');
+        list.innerHTML = '';
+        if (q === '') {
+            return;
+        }
+        var n = 0;
+        for (var i = 0; i < symbols.length && n < 50; i++) {
+            var s = symbols[i];
+            if (s.symbol.toUpperCase().indexOf(q) >= 0 || s.name.indexOf(q) >= 0) {
+                var opt = document.createElement('option');
+                opt.value = s.symbol + ' ' + s.name;
+                list.appendChild(opt);
+                n++;
+            }
+        }
+    }
+
+    // 输入框的值可能为 "SHSE.601088 中国神华", 也可能只是名称
+    function currentSymbol() {
+        var v = $('symbol').value.trim();
+        var code = v.split(/\s+/)[0];
+        if (/^[A-Z]{4}\.\w+$/i.test(code)) {
+            return code.toUpperCase();
+        }
+        for (var i = 0; i < symbols.length; i++) {
+            if (symbols[i].name === v) {
+                return symbols[i].symbol;
+            }
+        }
+        return code;
+    }
+
+    //====================================================
+    // 查询和绘图
+
+    function toBars(records) {
+        return (records || []).map(function (r) {
+            return { t: String(r.timestamp), o: +r.open, h: +r.high, l: +r.low, c: +r.close, v: +r.volume };
+        });
+    }
+
+    // 日频指标按日期对应到每根K线(分钟线上为每日一段水平线)
+    function daily(field) {
+        return state.bars.map(function (b) {
+            var rec = state.vv[b.t.slice(0, 10)];
+            var v = rec ? rec[field] : null;
+            return v === null || v === undefined || v === 0 ? null : +v;
+        });
+    }
+
+    function overlays() {
+        return Array.prototype.filter.call(document.getElementsByName('overlay'), function (el) {
+            return el.checked;
+        }).map(function (el) {
+            return { name: el.value, color: OVERLAY_COLORS[el.value], values: daily(el.value) };
+        });
+    }
+
+    function vvLines() {
+        return $('vv').value.split(',').map(function (f, i) {
+            return { name: f, color: VV_COLORS[i % VV_COLORS.length], values: daily(f) };
+        });
+    }
+
+    function redraw() {
+        chart.setLines('main', overlays());
+        chart.setLines('vv', vvLines());
+        showLegend(-1);
+    }
+
+    function load() {
+        var symbol = currentSymbol();
+        var tag = $('tag').value;
+        var params = { symbol: symbol, sdate: $('sdate').value, edate: $('edate').value, adjust: $('adjust').value };
+        var kline = tag === '1d'
+            ? getJSON('gm1d', params)
+            : getJSON('gm1m', Object.assign({ tag: tag }, params));
+        var vv = getJSON('gmvv', Object.assign({ is1m: 'false', indicators: VV_INDICATORS }, params));
+
+        form.querySelector('button').disabled = true;
+        setStatus('加载中: ' + symbol + ' ...');
+        history.replaceState(null, '', '?' + new URLSearchParams(Object.assign({ tag: tag }, params)));
+
+        Promise.all([kline, vv]).then(function (res) {
+            state.bars = toBars(res[0]);
+            state.vv = {};
+            ((res[1] && res[1]['1dvv']) || []).forEach(function (r) {
+                state.vv[String(r.timestamp).slice(0, 10)] = r;
+            });
+            chart.setData(state.bars);
+            redraw();
+            setStatus(symbol + '  ' + tag + '  ' + state.bars.length + ' 根K线, ' +
+                Object.keys(state.vv).length + ' 个交易日');
+        }).catch(function (err) {
+            setStatus(err.message, true);
+        }).then(function () {
+            form.querySelector('button').disabled = false;
+        });
+    }
+
+    // 图例: 十字光标所在K线(或最后一根)的数据
+    function showLegend(i) {
+        var bars = state.bars;
+        if (i < 0) {
+            i = bars.length - 1;
+        }
+        var el = $('legend');
+        el.innerHTML = '';
+        if (i < 0) {
+            return;
+        }
+        var b = bars[i];
+        var f = KChart.formatNumber;
+        var items = [
+            [b.t, '#d8d9dc'],
+            ['开 ' + f(b.o), null], ['高 ' + f(b.h), null], ['低 ' + f(b.l), null], ['收 ' + f(b.c), null],
+            ['量 ' + f(b.v), null]
+        ];
+        if (i > 0) {
+            var chg = (b.c / bars[i - 1].c - 1) * 100;
+            items.push([(chg >= 0 ? '+' : '') + chg.toFixed(2) + '%', chg >= 0 ? '#e8493f' : '#1fa865']);
+        }
+        overlays().concat(vvLines()).forEach(function (line) {
+            var v = line.values[i];
+            items.push([line.name + ' ' + (v === null ? '-' : f(v)), line.color]);
+        });
+        items.forEach(function (it) {
+            var span = document.createElement('span');
+            span.textContent = it[0];
+            if (it[1]) {
+                span.style.color = it[1];
+            }
+            el.appendChild(span);
+        });
+    }
+
+    //====================================================
+
+    chart.onCross = showLegend;
+    $('symbol').addEventListener('input', searchSymbols);
+    $('vv').addEventListener('change', redraw);
+    Array.prototype.forEach.call(document.getElementsByName('overlay'), function (el) {
+        el.addEventListener('change', redraw);
+    });
+    // 切换到分钟线时缩短默认日期范围
+    $('tag').addEventListener('change', function () {
+        $('sdate').value = $('tag').value === '1d' ? daysAgo(180) : daysAgo(7);
+    });
+    form.addEventListener('submit', function (e) {
+        e.preventDefault();
+        load();
+    });
+
+    var q = new URLSearchParams(location.search);
+    $('symbol').value = q.get('symbol') || 'SHSE.601088';
+    $('tag').value = q.get('tag') || '1d';
+    $('adjust').value = q.get('adjust') || 'none';
+    $('sdate').value = q.get('sdate') || ($('tag').value === '1d' ? daysAgo(180) : daysAgo(7));
+    $('edate').value = q.get('edate') || ymd(new Date());
+
+    loadSymbols();
+    load();
+})();
@@ -0,0 +1,337 @@
+// 轻量K线图(canvas 绘制, 无外部依赖, 可在内网离线使用)
+//
+//   var chart = new KChart(el, [{id: 'main', weight: 3}, {id: 'volume', weight: 1}, {id: 'vv', weight: 1}]);
+//   chart.setData(bars);                      // bars: [{t: '2025-07-01 09:31:00', o, h, l, c, v}]
+//   chart.setLines('main', [{name: 'hjj', color: '#f5a623', values: [...]}]);  // values 与 bars 一一对应, null 为断点
+//   chart.onCross = function (index) { ... }; // 十字光标所在K线, -1 表示离开
+//
+// 'main' 面板绘制蜡烛线, 'volume' 面板绘制成交量柱, 其他面板只绘制线条。
+// 鼠标滚轮缩放, 拖动平移, 红涨绿跌。
+//
+// 未复用 server2 的 KLineChart 页面: 该页面从 CDN 加载 KLineChart 和 Vue, 内网无法访问;
+// 看板只需要蜡烛线、成交量、指标线和十字光标, 用这几百行代替打包第三方库及其许可证。
+// app.js 只使用 setData/setLines/onCross, 以后改用 KLineChart 时只需替换这一层。
+(function (global) {
+    'use strict';
+
+    var UP = '#e8493f';
+    var DOWN = '#1fa865';
+    var GRID = '#2a2d33';
+    var TEXT = '#8a8d96';
+    var CROSS = '#9a9da6';
+    var AXIS_W = 64;
+    var AXIS_H = 22;
+    var GAP = 6;
+
+    function KChart(el, panes) {
+        this.el = el;
+        this.panes = panes || [{ id: 'main', weight: 3 }, { id: 'volume', weight: 1 }];
+        this.canvas = document.createElement('canvas');
+        this.ctx = this.canvas.getContext('2d');
+        el.appendChild(this.canvas);
+
+        this.bars = [];
+        this.lines = {};
+        this.barWidth = 8;
+        this.offset = 0; // 右侧隐藏的K线数
+        this.cross = -1;
+        this.crossY = -1;
+        this.onCross = null;
+        this._bind();
+        this.resize();
+    }
+
+    KChart.prototype.setData = function (bars) {
+        this.bars = bars || [];
+        this.offset = 0;
+        this.cross = -1;
+        // 初始显示最近约240根
+        var w = this.width - AXIS_W;
+        if (this.bars.length > 0 && w > 0) {
+            this.barWidth = clamp(w / Math.min(this.bars.length, 240), 2, 16);
+        }
+        this.draw();
+    };
+
+    KChart.prototype.setLines = function (pane, lines) {
+        this.lines[pane] = lines || [];
+        this.draw();
+    };
+
+    KChart.prototype.resize = function () {
+        var dpr = global.devicePixelRatio || 1;
+        this.width = this.el.clientWidth;
+        this.height = this.el.clientHeight;
+        this.canvas.width = this.width * dpr;
+        this.canvas.height = this.height * dpr;
+        this.canvas.style.width = this.width + 'px';
+        this.canvas.style.height = this.height + 'px';
+        this.ctx.setTransform(dpr, 0, 0, dpr, 0, 0);
+        this.draw();
+    };
+
+    // 可见K线范围 [start, end)
+    KChart.prototype.range = function () {
+        var n = Math.max(1, Math.floor((this.width - AXIS_W) / this.barWidth));
+        var end = Math.max(0, this.bars.length - this.offset);
+        return { start: Math.max(0, end - n), end: end };
+    };
+
+    KChart.prototype._bind = function () {
+        var self = this;
+        var drag = null;
+
+        global.addEventListener('resize', function () { self.resize(); });
+
+        this.canvas.addEventListener('wheel', function (e) {
+            e.preventDefault();
+            self.barWidth = clamp(self.barWidth * (e.deltaY < 0 ? 1.2 : 1 / 1.2), 1, 40);
+            self.draw();
+        }, { passive: false });
+
+        this.canvas.addEventListener('mousedown', function (e) {
+            drag = { x: e.offsetX, offset: self.offset };
+        });
+        global.addEventListener('mouseup', function () { drag = null; });
+
+        this.canvas.addEventListener('mousemove', function (e) {
+            if (drag) {
+                var shift = Math.round((e.offsetX - drag.x) / self.barWidth);
+                self.offset = clamp(drag.offset + shift, 0, Math.max(0, self.bars.length - 10));
+            }
+            var r = self.range();
+            var i = r.start + Math.floor(e.offsetX / self.barWidth);
+            self._setCross(e.offsetX < self.width - AXIS_W && i < r.end ? i : -1, e.offsetY);
+        });
+
+        this.canvas.addEventListener('mouseleave', function () {
+            drag = null;
+            self._setCross(-1, -1);
+        });
+    };
+
+    KChart.prototype._setCross = function (i, y) {
+        var changed = i !== this.cross;
+        this.cross = i;
+        this.crossY = y;
+        this.draw();
+        if (changed && this.onCross) {
+            this.onCross(i);
+        }
+    };
+
+    // 各面板的位置
+    KChart.prototype._layout = function () {
+        var total = 0;
+        this.panes.forEach(function (p) { total += p.weight; });
+        var h = this.height - AXIS_H - GAP * (this.panes.length - 1);
+        var top = 0;
+        return this.panes.map(function (p) {
+            var ph = h * p.weight / total;
+            var box = { pane: p, top: top, height: ph };
+            top += ph + GAP;
+            return box;
+        });
+    };
+
+    KChart.prototype.draw = function () {
+        var ctx = this.ctx;
+        ctx.clearRect(0, 0, this.width, this.height);
+        if (this.width <= AXIS_W || this.height <= AXIS_H) {
+            return;
+        }
+        var r = this.range();
+        var self = this;
+        this._layout().forEach(function (box) {
+            self._drawPane(box, r);
+        });
+        this._drawTimeAxis(r);
+        this._drawCross(r);
+    };
+
+    KChart.prototype._drawPane = function (box, r) {
+        var ctx = this.ctx;
+        var id = box.pane.id;
+        var lines = this.lines[id] || [];
+        var bars = this.bars;
+
+        // 取值范围
+        var lo = Infinity;
+        var hi = -Infinity;
+        for (var i = r.start; i < r.end; i++) {
+            if (id === 'main') {
+                lo = Math.min(lo, bars[i].l);
+                hi = Math.max(hi, bars[i].h);
+            } else if (id === 'volume') {
+                lo = 0;
+                hi = Math.max(hi, bars[i].v);
+            }
+            lines.forEach(function (line) {
+                var v = line.values[i];
+                if (v !== null && v !== undefined && isFinite(v)) {
+                    lo = Math.min(lo, v);
+                    hi = Math.max(hi, v);
+                }
+            });
+        }
+        if (!isFinite(lo) || !isFinite(hi)) {
+            lo = 0;
+            hi = 1;
+        }
+        if (hi === lo) {
+            hi += Math.abs(hi) * 0.01 || 1;
+            lo -= Math.abs(lo) * 0.01 || 1;
+        }
+        var pad = id === 'volume' ? 0 : (hi - lo) * 0.05;
+        lo -= pad;
+        hi += pad;
+        box.lo = lo;
+        box.hi = hi;
+        var y = function (v) { return box.top + (hi - v) / (hi - lo) * box.height; };
+
+        // 网格和右侧坐标
+        ctx.strokeStyle = GRID;
+        ctx.lineWidth = 1;
+        ctx.strokeRect(0.5, box.top + 0.5, this.width - AXIS_W, box.height);
+        ctx.fillStyle = TEXT;
+        ctx.font = '11px Consolas, Menlo, monospace';
+        ctx.textBaseline = 'middle';
+        var ticks = box.height > 150 ? 4 : 2;
+        for (var k = 1; k < ticks; k++) {
+            var v = lo + (hi - lo) * k / ticks;
+            var py = Math.round(y(v)) + 0.5;
+            ctx.beginPath();
+            ctx.moveTo(0, py);
+            ctx.lineTo(this.width - AXIS_W, py);
+            ctx.stroke();
+            ctx.fillText(formatNumber(v), this.width - AXIS_W + 4, py);
+        }
+
+        var bw = this.barWidth;
+        var body = Math.max(1, Math.floor(bw * 0.7));
+        for (i = r.start; i < r.end; i++) {
+            var b = bars[i];
+            var x = Math.floor((i - r.start) * bw + bw / 2);
+            ctx.fillStyle = ctx.strokeStyle = b.c >= b.o ? UP : DOWN;
+            if (id === 'main') {
+                ctx.beginPath();
+                ctx.moveTo(x + 0.5, y(b.h));
+                ctx.lineTo(x + 0.5, y(b.l));
+                ctx.stroke();
+                var top = y(Math.max(b.o, b.c));
+                ctx.fillRect(x - Math.floor(body / 2), top, body, Math.max(1, y(Math.min(b.o, b.c)) - top));
+            } else if (id === 'volume') {
+                var vy = y(b.v);
+                ctx.fillRect(x - Math.floor(body / 2), vy, body, box.top + box.height - vy);
+            }
+        }
+
+        lines.forEach(function (line) {
+            ctx.strokeStyle = line.color;
+            ctx.beginPath();
+            var pen = false;
+            for (var i = r.start; i < r.end; i++) {
+                var v = line.values[i];
+                if (v === null || v === undefined || !isFinite(v)) {
+                    pen = false;
+                    continue;
+                }
+                var px = (i - r.start) * bw + bw / 2;
+                if (pen) {
+                    ctx.lineTo(px, y(v));
+                } else {
+                    ctx.moveTo(px, y(v));
+                    pen = true;
+                }
+            }
+            ctx.stroke();
+        });
+
+        // 面板标题
+        if (box.pane.title) {
+            ctx.fillStyle = TEXT;
+            ctx.textBaseline = 'top';
+            ctx.fillText(box.pane.title, 6, box.top + 4);
+        }
+        this._boxes = this._boxes || {};
+        this._boxes[id] = box;
+    };
+
+    KChart.prototype._drawTimeAxis = function (r) {
+        var ctx = this.ctx;
+        var bw = this.barWidth;
+        var y = this.height - AXIS_H;
+        ctx.fillStyle = TEXT;
+        ctx.textBaseline = 'top';
+        var step = Math.max(1, Math.ceil(110 / bw));
+        for (var i = r.start; i < r.end; i += step) {
+            ctx.fillText(timeLabel(this.bars[i].t), (i - r.start) * bw, y + 5);
+        }
+    };
+
+    KChart.prototype._drawCross = function (r) {
+        if (this.cross < r.start || this.cross >= r.end) {
+            return;
+        }
+        var ctx = this.ctx;
+        var x = Math.floor((this.cross - r.start) * this.barWidth + this.barWidth / 2) + 0.5;
+        ctx.save();
+        ctx.strokeStyle = CROSS;
+        ctx.setLineDash([4, 3]);
+        ctx.beginPath();
+        ctx.moveTo(x, 0);
+        ctx.lineTo(x, this.height - AXIS_H);
+        if (this.crossY >= 0 && this.crossY < this.height - AXIS_H) {
+            ctx.moveTo(0, this.crossY + 0.5);
+            ctx.lineTo(this.width - AXIS_W, this.crossY + 0.5);
+        }
+        ctx.stroke();
+        ctx.restore();
+
+        // 光标所在面板的值
+        var self = this;
+        Object.keys(this._boxes || {}).forEach(function (id) {
+            var box = self._boxes[id];
+            if (self.crossY < box.top || self.crossY > box.top + box.height) {
+                return;
+            }
+            var v = box.hi - (self.crossY - box.top) / box.height * (box.hi - box.lo);
+            label(ctx, formatNumber(v), self.width - AXIS_W, self.crossY);
+        });
+        label(ctx, this.bars[this.cross].t, Math.max(0, x - 60), this.height - AXIS_H + 11);
+    };
+
+    function label(ctx, text, x, y) {
+        ctx.font = '11px Consolas, Menlo, monospace';
+        var w = ctx.measureText(text).width + 8;
+        ctx.fillStyle = '#44474f';
+        ctx.fillRect(x, y - 9, w, 18);
+        ctx.fillStyle = '#ffffff';
+        ctx.textBaseline = 'middle';
+        ctx.fillText(text, x + 4, y);
+    }
+
+    function clamp(v, lo, hi) {
+        return Math.min(Math.max(v, lo), hi);
+    }
+
+    // 时间轴标签: 日线显示日期, 分钟线显示 月-日 时:分
+    function timeLabel(t) {
+        t = String(t);
+        return t.length > 10 ? t.slice(5, 16) : t;
+    }
+
+    function formatNumber(v) {
+        var a = Math.abs(v);
+        if (a >= 1e8) {
+            return (v / 1e8).toFixed(2) + '亿';
+        }
+        if (a >= 1e4) {
+            return (v / 1e4).toFixed(2) + '万';
+        }
+        return v.toFixed(a >= 100 ? 1 : 2);
+    }
+
+    KChart.formatNumber = formatNumber;
+    global.KChart = KChart;
+})(window);
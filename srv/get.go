@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"regexp"
 	"time"
+
+	"github.com/lmzxtek/ths-go/gm"
 )
 
 // SmartURLHandler 智能URL处理器，可以根据端口号判断协议
@@ -108,23 +110,23 @@ func GetURLWithoutRetry(url string, params map[string]string, connectTimeout tim
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("%w: failed to send request: %w", gm.ErrUpstreamUnavailable, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("HTTP error: status code %d", resp.StatusCode)
+		return nil, &gm.ErrUpstreamStatus{Code: resp.StatusCode, Status: resp.Status, URL: req.URL.String()}
 	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("%w: failed to read response body: %w", gm.ErrUpstreamUnavailable, err)
 	}
 
 	var result map[string]any
 	err = json.Unmarshal(bodyBytes, &result)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal JSON response: %w", err)
+		return nil, fmt.Errorf("%w: failed to unmarshal JSON response: %w", gm.ErrDecode, err)
 	}
 
 	return result, nil
@@ -170,21 +172,21 @@ func GetURLWithRetry(url string, params map[string]string, connectTimeout time.D
 
 		resp, err := client.Do(req)
 		if err != nil {
-			lastErr = fmt.Errorf("failed to send request: %w", err)
+			lastErr = fmt.Errorf("%w: failed to send request: %w", gm.ErrUpstreamUnavailable, err)
 			time.Sleep(delay)
 			continue
 		}
 		defer resp.Body.Close() // Ensure body is closed after each attempt
 
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			lastErr = fmt.Errorf("HTTP error: status code %d", resp.StatusCode)
+			lastErr = &gm.ErrUpstreamStatus{Code: resp.StatusCode, Status: resp.Status, URL: req.URL.String()}
 			time.Sleep(delay)
 			continue
 		}
 
 		bodyBytes, err := io.ReadAll(resp.Body)
 		if err != nil {
-			lastErr = fmt.Errorf("failed to read response body: %w", err)
+			lastErr = fmt.Errorf("%w: failed to read response body: %w", gm.ErrUpstreamUnavailable, err)
 			time.Sleep(delay)
 			continue
 		}
@@ -192,7 +194,7 @@ func GetURLWithRetry(url string, params map[string]string, connectTimeout time.D
 		var result map[string]any
 		err = json.Unmarshal(bodyBytes, &result)
 		if err != nil {
-			lastErr = fmt.Errorf("failed to unmarshal JSON response: %w", err)
+			lastErr = fmt.Errorf("%w: failed to unmarshal JSON response: %w", gm.ErrDecode, err)
 			time.Sleep(delay)
 			continue
 		}
@@ -215,13 +217,13 @@ func (rcd *RawColData) TransformToRecords() ([]map[string]any, error) {
 	var records []map[string]any
 
 	if len(rcd.Columns) == 0 && len(rcd.Data) > 0 {
-		return nil, fmt.Errorf("当存在数据时，列名不能为空")
+		return nil, fmt.Errorf("%w: 当存在数据时，列名不能为空", gm.ErrDecode)
 	}
 
 	for _, row := range rcd.Data {
 		// 检查数据行长度是否与列名长度匹配
 		if len(row) != len(rcd.Columns) {
-			return nil, fmt.Errorf("数据行长度 (%d) 与列名长度 (%d) 不匹配", len(row), len(rcd.Columns))
+			return nil, fmt.Errorf("%w: 数据行长度 (%d) 与列名长度 (%d) 不匹配", gm.ErrDecode, len(row), len(rcd.Columns))
 		}
 		record := make(map[string]any)
 		for i, colName := range rcd.Columns {
@@ -244,13 +246,13 @@ func TransformToStCalendar(input RawColData) ([]map[string]any, error) {
 	var records []map[string]any
 
 	if len(input.Columns) == 0 && len(input.Data) > 0 {
-		return nil, fmt.Errorf("当存在数据时，列名不能为空")
+		return nil, fmt.Errorf("%w: 当存在数据时，列名不能为空", gm.ErrDecode)
 	}
 
 	for _, row := range input.Data {
 		// 检查数据行长度是否与列名长度匹配
 		if len(row) != len(input.Columns) {
-			return nil, fmt.Errorf("数据行长度 (%d) 与列名长度 (%d) 不匹配", len(row), len(input.Columns))
+			return nil, fmt.Errorf("%w: 数据行长度 (%d) 与列名长度 (%d) 不匹配", gm.ErrDecode, len(row), len(input.Columns))
 		}
 		record := make(map[string]any)
 		for i, colName := range input.Columns {
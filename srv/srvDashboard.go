@@ -0,0 +1,29 @@
+package srv
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 行情看板的页面和脚本(含K线图脚本, 不依赖外网 CDN, 可在内网使用)
+//
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+var dashboardFS = func() http.FileSystem {
+	sub, err := fs.Sub(dashboardFiles, "dashboard")
+	if err != nil {
+		panic(err)
+	}
+	return http.FS(sub)
+}()
+
+// 行情看板: K线、成交量和vv指标, 数据来自 /gm1m, /gm1d, /gmvv 和 /symbols_info
+//
+// 路由为 /dashboard/*filepath, 页面地址 /dashboard/
+func RouteDashboard(c *gin.Context) {
+	c.FileFromFS(c.Param("filepath"), dashboardFS)
+}
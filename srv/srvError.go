@@ -0,0 +1,78 @@
+package srv
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lmzxtek/ths-go/gm"
+)
+
+// 错误响应格式(所有接口一致):
+//
+//	{"code": "invalid_param", "error": "参数错误: symbol 参数为必须"}
+//
+// 上游返回非200状态时另有 "upstream_status" 字段。
+type ErrorBody struct {
+	Code           string `json:"code"`
+	Error          string `json:"error"`
+	UpstreamStatus int    `json:"upstream_status,omitempty"`
+}
+
+// 错误码
+const (
+	CodeInvalidParam        = "invalid_param"        // 400
+	CodeNoData              = "no_data"              // 404
+	CodeNotFound            = "not_found"            // 404, csv.xz 文件或上游接口不存在
+	CodeUpstreamStatus      = "upstream_status"      // 502
+	CodeUpstreamUnavailable = "upstream_unavailable" // 502
+	CodeDecode              = "decode_error"         // 502
+	CodeTimeout             = "timeout"              // 504
+	CodeInternal            = "internal"             // 500
+)
+
+// 按 gm 错误类型确定 HTTP 状态码和错误码
+func errorStatus(err error) (int, ErrorBody) {
+	body := ErrorBody{Error: err.Error()}
+	var st *gm.ErrUpstreamStatus
+	switch {
+	case errors.Is(err, gm.ErrInvalidParam):
+		body.Code = CodeInvalidParam
+		return http.StatusBadRequest, body
+	case errors.Is(err, gm.ErrNoData):
+		body.Code = CodeNoData
+		return http.StatusNotFound, body
+	case errors.Is(err, gm.ErrNotFound):
+		body.Code = CodeNotFound
+		if errors.As(err, &st) {
+			body.UpstreamStatus = st.Code
+		}
+		return http.StatusNotFound, body
+	case errors.As(err, &st):
+		body.UpstreamStatus = st.Code
+		if st.Code == http.StatusGatewayTimeout {
+			body.Code = CodeTimeout
+			return http.StatusGatewayTimeout, body
+		}
+		body.Code = CodeUpstreamStatus
+		return http.StatusBadGateway, body
+	case gm.IsTimeout(err) || errors.Is(err, context.Canceled):
+		body.Code = CodeTimeout
+		return http.StatusGatewayTimeout, body
+	case errors.Is(err, gm.ErrUpstreamUnavailable):
+		body.Code = CodeUpstreamUnavailable
+		return http.StatusBadGateway, body
+	case errors.Is(err, gm.ErrDecode):
+		body.Code = CodeDecode
+		return http.StatusBadGateway, body
+	}
+	body.Code = CodeInternal
+	return http.StatusInternalServerError, body
+}
+
+// 返回错误响应
+func respondError(c *gin.Context, err error) {
+	code, body := errorStatus(err)
+	c.JSON(code, body)
+}
@@ -29,11 +29,69 @@ func SetURL(gmAPI, gmCSV string) {
 	if gmAPI != "" {
 		gmapi = SmartURLHandler(gmAPI, false)
 	}
-	if gmCSV != "" {
+	if _, ok := gm.LocalCSVDir(gmCSV); ok {
+		gmcsv = gmCSV // 本地 csv.xz 目录
+	} else if gmCSV != "" {
 		gmcsv = SmartURLHandler(gmCSV, false)
 	}
 }
 
+// 响应缓存，为 nil 时不缓存
+var cache *gm.Cache
+
+// 设置响应缓存: maxMB 为内存缓存上限(MB)，dir 为磁盘缓存目录(为空时只使用内存)
+func SetCache(maxMB int, dir string) {
+	cache = gm.NewCache(int64(maxMB)<<20, dir)
+}
+
+// gm数据客户端，调用时传入 c.Request.Context()，请求断开后上游请求随之取消
+func gmClient(timeoutSeconds int) *gm.Client {
+	return gm.NewClient(gmapi,
+		gm.WithCSVURL(gmcsv),
+		gm.WithTimeout(time.Duration(timeoutSeconds)*time.Second),
+		gm.WithCache(cache),
+	)
+}
+
+// 缓存统计
+func RouteCacheStats(c *gin.Context) {
+	if cache == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "stats": cache.Stats()})
+}
+
+// 清除缓存: match 为空时清除全部，否则清除键中包含 match 的缓存(如股票代码)
+func RouteCachePurge(c *gin.Context) {
+	if cache == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	match := c.DefaultQuery("match", "")
+	n := cache.Purge(match)
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "purged": n, "stats": cache.Stats()})
+}
+
+// 设置交易日历的本地缓存文件，日历数据从 gmapi 获取
+func SetCalendar(fpath string) {
+	gm.SetDefaultCalendar(gm.NewCalendar(gmClient(30), fpath))
+}
+
+// 默认查询日期: 包含当天且已开盘时为当天，否则为前一交易日
+func defaultTradeDay(include bool) string {
+	now := time.Now()
+	today := now.Format("2006-01-02")
+	if include && gm.IsAOpen() {
+		return today
+	}
+	cday, err := gm.DefaultCalendar().PrevTradingDay(today, 1)
+	if err != nil {
+		return now.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	return cday
+}
+
 var serverTag string = "/api"
 
 func SetServerTag(srvtag string) {
@@ -120,12 +178,14 @@ func BuildHTML2(cfg HTMLConfig) string {
 	strTest := fmt.Sprintf(`
     <ul>
         <li>说明: <a href="http://%s/usage" target="_blank">http://%s/usage</a></li>
+        <li>看板: <a href="http://%s/dashboard/?symbol=%s" target="_blank">http://%s/dashboard/</a></li>
         <li>测试1: <a href="http://%s/test" target="_blank">http://%s/test</a></li>
         <li>测试2: <a href="http://%s/test2" target="_blank">http://%s/test2</a></li>
         <li>测试3: <a href="http://%s/test3" target="_blank">http://%s/test3</a></li>
     </ul>
 `,
 		url, url,
+		url, sym, url,
 		url, url,
 		url, url,
 		url, url,
@@ -183,12 +243,12 @@ func BuildHTML2(cfg HTMLConfig) string {
 	//=============================================================
 	kb1d1 := "gm1d?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + today
 	kb1d2 := "gm1d?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + today + "&time_stamp=true"
-	kb1d3 := "gm1d?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + today + "&isdic=true"
+	kb1d3 := "gm1d?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + today + "&isdic=true" + "&adjust=qfq"
 	kb1d4 := "gm1d?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + today + "&time_stamp=true" + "&isdic=true"
 
 	kbvv1 := "gmvv?symbol=" + sym
-	kbvv2 := "gmvv?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + today
-	kbvv3 := "gmvv?symbol=" + sym + "&sdate=" + strPreDay5 + "&edate=" + today + "&is1m=false"
+	kbvv2 := "gmvv?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + today + "&adjust=qfq"
+	kbvv3 := "gmvv?symbol=" + sym + "&sdate=" + strPreDay5 + "&edate=" + today + "&is1m=false" + "&coverage=true"
 	kbvv4 := "gmvv?symbol=" + sym + "&sdate=" + strPreDay5 + "&edate=" + today + "&is1m=false" + "&time_stamp=true"
 
 	kbpe1 := "gmpe?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + today
@@ -198,9 +258,9 @@ func BuildHTML2(cfg HTMLConfig) string {
 
 	kbGM1 := "gm1m?symbol=" + sym
 	kbGM2 := "gm1m?symbol=" + sym + "&time_stamp=true"
-	kbGM3 := "gm1m?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + today
-	kbGM4 := "gm1m?symbol=" + sym + "&sdate=" + strPreYear + "&edate=" + today
-	kbGM5 := "gm1m?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + today + "&include=false"
+	kbGM3 := "gm1m?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + today + "&coverage=true" + "&prefer=api"
+	kbGM4 := "gm1m?symbol=" + sym + "&sdate=" + strPreYear + "&edate=" + today + "&adjust=hfq"
+	kbGM5 := "gm1m?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + today + "&include=false" + "&tag=60m"
 	kbGM6 := "api1m?symbol=" + sym
 	kbGM7 := "api1m?symbol=" + sym + "&time_stamp=true"
 	kbGM8 := "api1m?symbol=" + sym + "&sdate=" + strPreDay + "&edate=" + today
@@ -264,11 +324,35 @@ func BuildHTML2(cfg HTMLConfig) string {
 	)
 	builder.WriteString(strGMs)
 
+	//=============================================================
+	ind1 := "indicators?symbol=" + sym
+	ind2 := "indicators?symbol=" + sym + "&sdate=" + strPreYear + "&edate=" + today + "&names=ma,boll,rsi,atr" + "&params=ma=5,20|rsi=6" + "&adjust=qfq"
+	ind3 := "indicators?symbol=" + sym + "&tag=1m" + "&sdate=" + strPreDay5 + "&edate=" + today + "&names=vwap,amv,obv"
+	ind4 := "indicators?symbol=" + sym + "&tag=vv" + "&sdate=" + strPreMonth + "&edate=" + today + "&names=ema,macd" + "&time_stamp=true"
+
+	strInds := fmt.Sprintf(`
+    <h3>技术指标</h3>
+    <ul>
+		<li>ma, ema, macd, kdj, rsi, boll, atr, obv, vwap, amv  <br>
+			<a href="http://%s/%s" target="_blank">http://%s/%s</a><br>
+			<a href="http://%s/%s" target="_blank">http://%s/%s</a><br>
+			<a href="http://%s/%s" target="_blank">http://%s/%s</a><br>
+			<a href="http://%s/%s" target="_blank">http://%s/%s</a><br>
+		</li>
+    </ul>
+`,
+		url, ind1, url, ind1,
+		url, ind2, url, ind2,
+		url, ind3, url, ind3,
+		url, ind4, url, ind4,
+	)
+	builder.WriteString(strInds)
+
 	//=============================================================
 	kbCSV1 := "csvyear?symbol=" + sym + "&year=" + strCurYear
 	kbCSV2 := "csvyear?symbol=" + sym + "&year=" + strCurYear + "&time_stamp=true"
 	kbCSV3 := "csvmonth?symbol=" + sym + "&year=" + strCurYear + "&month=" + strPreMonth2
-	kbCSV4 := "csv1m?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + strPreMonth
+	kbCSV4 := "csv1m?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + strPreMonth + "&tag=15m"
 	kbCSV5 := "csvtag?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + today
 	kbCSV6 := "csvtag?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + today + "&tag=pe"
 	kbCSV7 := "csvtag?symbol=" + sym + "&sdate=" + strPreMonth + "&edate=" + today + "&tag=vv" + "&clip=false"
@@ -596,6 +680,11 @@ func BuildHTML2(cfg HTMLConfig) string {
 
 	//=============================================================
 	strTail := `
+    <h3>错误响应</h3>
+    <ul>
+		<li>格式：{"code": "invalid_param", "error": "参数错误: symbol 参数为必须"}</li>
+		<li>400 invalid_param；404 no_data / not_found；502 upstream_status / upstream_unavailable / decode_error；504 timeout</li>
+    </ul>
 	<br><br>
 </body>
 </html>`
@@ -716,27 +805,27 @@ func RouteCalendar2(c *gin.Context) {
 	}
 	rawData, err := GetURLWithoutRetry(url, pars, 30*time.Second, 0)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	jsonBytes, marshalErr := json.Marshal(rawData)
 	if marshalErr != nil {
-		c.JSON(http.StatusNotFound, marshalErr)
+		respondError(c, marshalErr)
 		return
 	}
 
 	var rcd RawColData
 	unmarshalErr := json.Unmarshal(jsonBytes, &rcd)
 	if unmarshalErr != nil {
-		c.JSON(http.StatusNotFound, unmarshalErr)
+		respondError(c, fmt.Errorf("%w: %w", gm.ErrDecode, unmarshalErr))
 		return
 	}
 
 	// 处理获取到的 JSON 数据为 records 形式
 	records, transformErr := rcd.TransformToRecords()
 	if transformErr != nil {
-		c.JSON(http.StatusNotFound, transformErr)
+		respondError(c, fmt.Errorf("%w: %w", gm.ErrDecode, transformErr))
 		return
 	}
 
@@ -759,23 +848,23 @@ func RouteCalendar(c *gin.Context) {
 	exchange := c.DefaultQuery("exchange", "")
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetCalendar(gmapi, syear, eyear, exchange, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetCalendar(c.Request.Context(), syear, eyear, exchange)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.Calendar)": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	var rcd RawColData
 	unmarshalErr := json.Unmarshal(rawData, &rcd)
 	if unmarshalErr != nil {
-		c.JSON(http.StatusNotFound, unmarshalErr)
+		respondError(c, fmt.Errorf("%w: %w", gm.ErrDecode, unmarshalErr))
 		return
 	}
 
 	// 处理获取到的 JSON 数据为 records 形式
 	records, transformErr := rcd.TransformToRecords()
 	if transformErr != nil {
-		c.JSON(http.StatusNotFound, gin.H{" Err(TransformToRecords)": transformErr.Error()})
+		respondError(c, fmt.Errorf("%w: %w", gm.ErrDecode, transformErr))
 		return
 	}
 
@@ -787,9 +876,9 @@ func RouteDatesList(c *gin.Context) {
 	edate := c.DefaultQuery("edate", "")
 
 	timeoutSeconds := 30
-	rawData, err := gm.GetDatesList(gmapi, sdate, edate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetDatesList(c.Request.Context(), sdate, edate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetDatesList)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -804,7 +893,7 @@ func RouteDatesPrevN(c *gin.Context) {
 	scount := c.DefaultQuery("count", "10")
 	count, err := strconv.Atoi(scount)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{" Err(count)": err.Error()})
+		respondError(c, fmt.Errorf("%w: count: %w", gm.ErrInvalidParam, err))
 		return
 	}
 
@@ -816,9 +905,9 @@ func RouteDatesPrevN(c *gin.Context) {
 
 	timeoutSeconds := 30
 	// rawData, err := gm.GetPrevNByte(gmapi, date, count, timeoutSeconds, isinclude)
-	rawData, err := gm.GetPrevN(gmapi, date, count, isinclude, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetPrevN(c.Request.Context(), date, count, isinclude)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetPrevN)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -834,7 +923,7 @@ func RouteDatesNextN(c *gin.Context) {
 	scount := c.DefaultQuery("count", "10")
 	count, err := strconv.Atoi(scount)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{" Err(count)": err.Error()})
+		respondError(c, fmt.Errorf("%w: count: %w", gm.ErrInvalidParam, err))
 		return
 	}
 
@@ -846,9 +935,9 @@ func RouteDatesNextN(c *gin.Context) {
 
 	timeoutSeconds := 30
 	// rawData, err := gm.GetNextNByte(gmapi, date, count, timeoutSeconds, isinclude)
-	rawData, err := gm.GetNextN(gmapi, date, count, isinclude, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetNextN(c.Request.Context(), date, count, isinclude)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetNextN)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -857,7 +946,7 @@ func RouteDatesNextN(c *gin.Context) {
 func RouteCurrent(c *gin.Context) {
 	symbols := c.DefaultQuery("symbols", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -869,13 +958,17 @@ func RouteCurrent(c *gin.Context) {
 
 	timeoutSeconds := 30
 
-	// rawData, err := gm.GetCurrentByte(gmapi, symbols, timeoutSeconds, issplit)
-	rawData, err := gm.GetCurrent(gmapi, symbols, timeoutSeconds, issplit)
+	// 原样返回 gm-api 的数据(结构化解码见 gm.Client.GetCurrent)
+	rawData, err := gmClient(timeoutSeconds).GetCurrentByte(c.Request.Context(), symbols, issplit)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetCurrent)": err.Error()})
+		respondError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, rawData)
+	if !json.Valid(rawData) {
+		respondError(c, fmt.Errorf("%w: 解析 JSON 数据失败(GetCurrent())", gm.ErrDecode))
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", rawData)
 
 	// // 将获取到的字符串数据解析为 JSON 格式
 	// var data any
@@ -890,7 +983,7 @@ func RouteCurrent(c *gin.Context) {
 func RouteDailyValuation(c *gin.Context) {
 	symbols := c.DefaultQuery("symbol", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -899,9 +992,9 @@ func RouteDailyValuation(c *gin.Context) {
 	edate := c.DefaultQuery("edate", "")
 	fields := c.DefaultQuery("fields", "")
 
-	rawData, err := gm.GetDailyValuation(gmapi, symbols, sdate, edate, fields, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetDailyValuation(c.Request.Context(), symbols, sdate, edate, fields)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetDailyValuation)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -910,7 +1003,7 @@ func RouteDailyValuation(c *gin.Context) {
 func RouteDailyBasic(c *gin.Context) {
 	symbols := c.DefaultQuery("symbol", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -919,9 +1012,9 @@ func RouteDailyBasic(c *gin.Context) {
 	edate := c.DefaultQuery("edate", "")
 	fields := c.DefaultQuery("fields", "")
 
-	rawData, err := gm.GetDailyBasic(gmapi, symbols, sdate, edate, fields, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetDailyBasic(c.Request.Context(), symbols, sdate, edate, fields)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetDailyBasic)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -930,7 +1023,7 @@ func RouteDailyBasic(c *gin.Context) {
 func RouteDailyMktvalue(c *gin.Context) {
 	symbols := c.DefaultQuery("symbol", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -939,9 +1032,9 @@ func RouteDailyMktvalue(c *gin.Context) {
 	edate := c.DefaultQuery("edate", "")
 	fields := c.DefaultQuery("fields", "")
 
-	rawData, err := gm.GetDailyMktvalue(gmapi, symbols, sdate, edate, fields, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetDailyMktvalue(c.Request.Context(), symbols, sdate, edate, fields)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetDailyMktvalue)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -950,7 +1043,7 @@ func RouteDailyMktvalue(c *gin.Context) {
 func RouteFinancePrime(c *gin.Context) {
 	symbols := c.DefaultQuery("symbol", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -961,9 +1054,9 @@ func RouteFinancePrime(c *gin.Context) {
 	rpt_type := c.DefaultQuery("rpt_type", "")
 	data_type := c.DefaultQuery("data_type", "")
 
-	rawData, err := gm.GetFinancePrime(gmapi, symbols, sdate, edate, fields, rpt_type, data_type, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetFinancePrime(c.Request.Context(), symbols, sdate, edate, fields, rpt_type, data_type)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetFinancePrime)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -972,7 +1065,7 @@ func RouteFinancePrime(c *gin.Context) {
 func RouteFinanceDeriv(c *gin.Context) {
 	symbols := c.DefaultQuery("symbol", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -983,9 +1076,9 @@ func RouteFinanceDeriv(c *gin.Context) {
 	rpt_type := c.DefaultQuery("rpt_type", "")
 	data_type := c.DefaultQuery("data_type", "")
 
-	rawData, err := gm.GetFinanceDeriv(gmapi, symbols, sdate, edate, fields, rpt_type, data_type, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetFinanceDeriv(c.Request.Context(), symbols, sdate, edate, fields, rpt_type, data_type)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetFinanceDeriv)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -993,7 +1086,7 @@ func RouteFinanceDeriv(c *gin.Context) {
 func RouteFundamentalsCashflow(c *gin.Context) {
 	symbols := c.DefaultQuery("symbol", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1004,9 +1097,9 @@ func RouteFundamentalsCashflow(c *gin.Context) {
 	rpt_type := c.DefaultQuery("rpt_type", "")
 	data_type := c.DefaultQuery("data_type", "")
 
-	rawData, err := gm.GetFundamentalsCashflow(gmapi, symbols, sdate, edate, fields, rpt_type, data_type, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetFundamentalsCashflow(c.Request.Context(), symbols, sdate, edate, fields, rpt_type, data_type)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetFundamentalsCashflow)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1015,7 +1108,7 @@ func RouteFundamentalsCashflow(c *gin.Context) {
 func RouteFundamentalsIncome(c *gin.Context) {
 	symbols := c.DefaultQuery("symbol", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1026,9 +1119,9 @@ func RouteFundamentalsIncome(c *gin.Context) {
 	rpt_type := c.DefaultQuery("rpt_type", "")
 	data_type := c.DefaultQuery("data_type", "")
 
-	rawData, err := gm.GetFundamentalsIncome(gmapi, symbols, sdate, edate, fields, rpt_type, data_type, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetFundamentalsIncome(c.Request.Context(), symbols, sdate, edate, fields, rpt_type, data_type)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetFundamentalsIncome)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1037,7 +1130,7 @@ func RouteFundamentalsIncome(c *gin.Context) {
 func RouteFundamentalsBalance(c *gin.Context) {
 	symbols := c.DefaultQuery("symbol", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1048,9 +1141,9 @@ func RouteFundamentalsBalance(c *gin.Context) {
 	rpt_type := c.DefaultQuery("rpt_type", "")
 	data_type := c.DefaultQuery("data_type", "")
 
-	rawData, err := gm.GetFundamentalsBalance(gmapi, symbols, sdate, edate, fields, rpt_type, data_type, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetFundamentalsBalance(c.Request.Context(), symbols, sdate, edate, fields, rpt_type, data_type)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetFundamentalsBalance)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1059,7 +1152,7 @@ func RouteFundamentalsBalance(c *gin.Context) {
 func RouteFundamentalsBalancePt(c *gin.Context) {
 	symbols := c.DefaultQuery("symbols", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1069,9 +1162,9 @@ func RouteFundamentalsBalancePt(c *gin.Context) {
 	rpt_type := c.DefaultQuery("rpt_type", "")
 	data_type := c.DefaultQuery("data_type", "")
 
-	rawData, err := gm.GetFundamentalsBalancePt(gmapi, symbols, edate, fields, rpt_type, data_type, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetFundamentalsBalancePt(c.Request.Context(), symbols, edate, fields, rpt_type, data_type)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetFundamentalsBalancePt)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1080,7 +1173,7 @@ func RouteFundamentalsBalancePt(c *gin.Context) {
 func RouteFundamentalsCashflowPt(c *gin.Context) {
 	symbols := c.DefaultQuery("symbols", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1090,9 +1183,9 @@ func RouteFundamentalsCashflowPt(c *gin.Context) {
 	rpt_type := c.DefaultQuery("rpt_type", "")
 	data_type := c.DefaultQuery("data_type", "")
 
-	rawData, err := gm.GetFundamentalsCashflowPt(gmapi, symbols, edate, fields, rpt_type, data_type, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetFundamentalsCashflowPt(c.Request.Context(), symbols, edate, fields, rpt_type, data_type)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetFundamentalsCashflowPt)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1101,7 +1194,7 @@ func RouteFundamentalsCashflowPt(c *gin.Context) {
 func RouteFundamentalsIncomePt(c *gin.Context) {
 	symbols := c.DefaultQuery("symbols", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1111,9 +1204,9 @@ func RouteFundamentalsIncomePt(c *gin.Context) {
 	rpt_type := c.DefaultQuery("rpt_type", "")
 	data_type := c.DefaultQuery("data_type", "")
 
-	rawData, err := gm.GetFundamentalsIncomePt(gmapi, symbols, edate, fields, rpt_type, data_type, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetFundamentalsIncomePt(c.Request.Context(), symbols, edate, fields, rpt_type, data_type)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetFundamentalsIncomePt)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1122,7 +1215,7 @@ func RouteFundamentalsIncomePt(c *gin.Context) {
 func RouteFinancePrimePt(c *gin.Context) {
 	symbols := c.DefaultQuery("symbols", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1132,9 +1225,9 @@ func RouteFinancePrimePt(c *gin.Context) {
 	rpt_type := c.DefaultQuery("rpt_type", "")
 	data_type := c.DefaultQuery("data_type", "")
 
-	rawData, err := gm.GetFinancePrimePt(gmapi, symbols, edate, fields, rpt_type, data_type, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetFinancePrimePt(c.Request.Context(), symbols, edate, fields, rpt_type, data_type)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetFinancePrimePt)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1143,7 +1236,7 @@ func RouteFinancePrimePt(c *gin.Context) {
 func RouteFinanceDerivPt(c *gin.Context) {
 	symbols := c.DefaultQuery("symbols", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1153,9 +1246,9 @@ func RouteFinanceDerivPt(c *gin.Context) {
 	rpt_type := c.DefaultQuery("rpt_type", "")
 	data_type := c.DefaultQuery("data_type", "")
 
-	rawData, err := gm.GetFinanceDerivPt(gmapi, symbols, edate, fields, rpt_type, data_type, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetFinanceDerivPt(c.Request.Context(), symbols, edate, fields, rpt_type, data_type)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetFinanceDerivPt)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1164,7 +1257,7 @@ func RouteFinanceDerivPt(c *gin.Context) {
 func RouteDailyValuationPt(c *gin.Context) {
 	symbols := c.DefaultQuery("symbols", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1172,9 +1265,9 @@ func RouteDailyValuationPt(c *gin.Context) {
 	edate := c.DefaultQuery("date", "")
 	fields := c.DefaultQuery("fields", "")
 
-	rawData, err := gm.GetDailyValuationPt(gmapi, symbols, edate, fields, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetDailyValuationPt(c.Request.Context(), symbols, edate, fields)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetDailyValuationPt)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1183,7 +1276,7 @@ func RouteDailyValuationPt(c *gin.Context) {
 func RouteDailyBasicPt(c *gin.Context) {
 	symbols := c.DefaultQuery("symbols", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1191,9 +1284,9 @@ func RouteDailyBasicPt(c *gin.Context) {
 	edate := c.DefaultQuery("date", "")
 	fields := c.DefaultQuery("fields", "")
 
-	rawData, err := gm.GetDailyBasicPt(gmapi, symbols, edate, fields, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetDailyBasicPt(c.Request.Context(), symbols, edate, fields)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetDailyBasicPt)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1202,7 +1295,7 @@ func RouteDailyBasicPt(c *gin.Context) {
 func RouteDailyMktvaluePt(c *gin.Context) {
 	symbols := c.DefaultQuery("symbols", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1210,9 +1303,9 @@ func RouteDailyMktvaluePt(c *gin.Context) {
 	edate := c.DefaultQuery("date", "")
 	fields := c.DefaultQuery("fields", "")
 
-	rawData, err := gm.GetDailyMktvaluePt(gmapi, symbols, edate, fields, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetDailyMktvaluePt(c.Request.Context(), symbols, edate, fields)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetDailyMktvaluePt)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1221,14 +1314,14 @@ func RouteDailyMktvaluePt(c *gin.Context) {
 func RouteSectorCategory(c *gin.Context) {
 	symbols := c.DefaultQuery("sector_type", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("sector_type 参数为必须"))
+		respondError(c, fmt.Errorf("%w: sector_type 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
 	timeoutSeconds := 30
-	rawData, err := gm.GetSectorCategory(gmapi, symbols, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetSectorCategory(c.Request.Context(), symbols)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetSectorCategory)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1237,14 +1330,14 @@ func RouteSectorCategory(c *gin.Context) {
 func RouteSectorConstituents(c *gin.Context) {
 	symbols := c.DefaultQuery("sector_code", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("sector_code 参数为必须"))
+		respondError(c, fmt.Errorf("%w: sector_code 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
 	timeoutSeconds := 30
-	rawData, err := gm.GetSectorConstituents(gmapi, symbols, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetSectorConstituents(c.Request.Context(), symbols)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetSectorConstituents)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1253,7 +1346,7 @@ func RouteSectorConstituents(c *gin.Context) {
 func RouteSymbolsSector(c *gin.Context) {
 	symbols := c.DefaultQuery("symbols", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1263,9 +1356,9 @@ func RouteSymbolsSector(c *gin.Context) {
 	// edate := c.DefaultQuery("edate", "")
 	// bdate := c.DefaultQuery("bdate", "")
 
-	rawData, err := gm.GetSymbolsSector(gmapi, symbols, sdate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetSymbolsSector(c.Request.Context(), symbols, sdate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetSymbolsSector)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1274,7 +1367,7 @@ func RouteSymbolsSector(c *gin.Context) {
 func RouteDvidend(c *gin.Context) {
 	symbols := c.DefaultQuery("symbol", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1284,9 +1377,9 @@ func RouteDvidend(c *gin.Context) {
 	edate := c.DefaultQuery("edate", "")
 	// bdate := c.DefaultQuery("bdate", "")
 
-	rawData, err := gm.GetDividend(gmapi, symbols, sdate, edate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetDividend(c.Request.Context(), symbols, sdate, edate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetDividend)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1295,7 +1388,7 @@ func RouteDvidend(c *gin.Context) {
 func RouteRation(c *gin.Context) {
 	symbols := c.DefaultQuery("symbol", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1305,9 +1398,9 @@ func RouteRation(c *gin.Context) {
 	edate := c.DefaultQuery("edate", "")
 	// bdate := c.DefaultQuery("bdate", "")
 
-	rawData, err := gm.GetRation(gmapi, symbols, sdate, edate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetRation(c.Request.Context(), symbols, sdate, edate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetRation)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1316,7 +1409,7 @@ func RouteRation(c *gin.Context) {
 func RouteShareholderNum(c *gin.Context) {
 	symbols := c.DefaultQuery("symbol", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1326,9 +1419,9 @@ func RouteShareholderNum(c *gin.Context) {
 	edate := c.DefaultQuery("edate", "")
 	// bdate := c.DefaultQuery("bdate", "")
 
-	rawData, err := gm.GetShareholderNum(gmapi, symbols, sdate, edate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetShareholderNum(c.Request.Context(), symbols, sdate, edate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetShareholderNum)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1337,7 +1430,7 @@ func RouteShareholderNum(c *gin.Context) {
 func RouteShareChange(c *gin.Context) {
 	symbols := c.DefaultQuery("symbol", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1347,9 +1440,9 @@ func RouteShareChange(c *gin.Context) {
 	edate := c.DefaultQuery("edate", "")
 	// bdate := c.DefaultQuery("bdate", "")
 
-	rawData, err := gm.GetShareChange(gmapi, symbols, sdate, edate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetShareChange(c.Request.Context(), symbols, sdate, edate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetShareChange)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1358,7 +1451,7 @@ func RouteShareChange(c *gin.Context) {
 func RouteAdjFactor(c *gin.Context) {
 	symbols := c.DefaultQuery("symbol", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1368,9 +1461,9 @@ func RouteAdjFactor(c *gin.Context) {
 	edate := c.DefaultQuery("edate", "")
 	bdate := c.DefaultQuery("bdate", "")
 
-	rawData, err := gm.GetAdjFactor(gmapi, symbols, sdate, edate, bdate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetAdjFactor(c.Request.Context(), symbols, sdate, edate, bdate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetAdjFactor)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1379,7 +1472,7 @@ func RouteAdjFactor(c *gin.Context) {
 func RouteTopShareholder(c *gin.Context) {
 	symbols := c.DefaultQuery("symbol", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1389,9 +1482,9 @@ func RouteTopShareholder(c *gin.Context) {
 	tradable_holder := c.DefaultQuery("tradable_holder", "")
 	edate := c.DefaultQuery("edate", "")
 
-	rawData, err := gm.GetTopShareholder(gmapi, symbols, sdate, edate, tradable_holder, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetTopShareholder(c.Request.Context(), symbols, sdate, edate, tradable_holder)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetTopShareholder)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1411,9 +1504,9 @@ func RouteAbnorChangeStocks(c *gin.Context) {
 	change_types := c.DefaultQuery("change_types", "")
 	// edate := c.DefaultQuery("edate", "")
 
-	rawData, err := gm.GetAbnorChangeStocks(gmapi, symbols, change_types, sdate, fields, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetAbnorChangeStocks(c.Request.Context(), symbols, change_types, sdate, fields)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetAbnorChangeStocks)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1433,9 +1526,9 @@ func RouteAbnorChangeDetail(c *gin.Context) {
 	change_types := c.DefaultQuery("change_types", "")
 	// edate := c.DefaultQuery("edate", "")
 
-	rawData, err := gm.GetAbnorChangeDetail(gmapi, symbols, change_types, sdate, fields, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetAbnorChangeDetail(c.Request.Context(), symbols, change_types, sdate, fields)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetAbnorChangeDetail)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1454,9 +1547,9 @@ func RouteHKInstHoldingInfo(c *gin.Context) {
 	// count := c.DefaultQuery("count", "")
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetHKInstHoldingInfo(gmapi, symbols, sdate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetHKInstHoldingInfo(c.Request.Context(), symbols, sdate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetHKInstHoldingDetailInfo)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1475,9 +1568,9 @@ func RouteHKInstHoldingDetailInfo(c *gin.Context) {
 	// count := c.DefaultQuery("count", "")
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetHKInstHoldingDetailInfo(gmapi, symbols, sdate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetHKInstHoldingDetailInfo(c.Request.Context(), symbols, sdate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetHKInstHoldingDetailInfo)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1496,9 +1589,9 @@ func RouteSHZSZHKActiveStockTop10Info(c *gin.Context) {
 	// count := c.DefaultQuery("count", "")
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetSHSZHKActiveStockTop10Info(gmapi, symbols, sdate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetSHSZHKActiveStockTop10Info(c.Request.Context(), symbols, sdate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetSHSZHKActiveStockTop10Info)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1517,9 +1610,9 @@ func RouteSHZSZHKQuotaInfo(c *gin.Context) {
 	count := c.DefaultQuery("count", "")
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetSHSZHKQuotaInfo(gmapi, symbols, sdate, edate, count, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetSHSZHKQuotaInfo(c.Request.Context(), symbols, sdate, edate, count)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetSHSZHKQuotaInfo)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1528,7 +1621,7 @@ func RouteSHZSZHKQuotaInfo(c *gin.Context) {
 func RouteFndNetValue(c *gin.Context) {
 	symbols := c.DefaultQuery("fund", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("fund 参数为必须"))
+		respondError(c, fmt.Errorf("%w: fund 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1537,9 +1630,9 @@ func RouteFndNetValue(c *gin.Context) {
 	edate := c.DefaultQuery("edate", today)
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetFndNetValue(gmapi, symbols, sdate, edate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetFndNetValue(c.Request.Context(), symbols, sdate, edate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetFndNetValue)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1547,7 +1640,7 @@ func RouteFndNetValue(c *gin.Context) {
 func RouteFndSplit(c *gin.Context) {
 	symbols := c.DefaultQuery("fund", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("fund 参数为必须"))
+		respondError(c, fmt.Errorf("%w: fund 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1556,9 +1649,9 @@ func RouteFndSplit(c *gin.Context) {
 	edate := c.DefaultQuery("edate", today)
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetFndSplit(gmapi, symbols, sdate, edate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetFndSplit(c.Request.Context(), symbols, sdate, edate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetFndSplit)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1567,7 +1660,7 @@ func RouteFndSplit(c *gin.Context) {
 func RouteFndPortfolio(c *gin.Context) {
 	symbols := c.DefaultQuery("fund", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("fund 参数为必须"))
+		respondError(c, fmt.Errorf("%w: fund 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1578,9 +1671,9 @@ func RouteFndPortfolio(c *gin.Context) {
 	portfolio_type := c.DefaultQuery("portfolio_type", "")
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetFndPortfolio(gmapi, symbols, report_type, portfolio_type, sdate, edate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetFndPortfolio(c.Request.Context(), symbols, report_type, portfolio_type, sdate, edate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetFndPortfolio)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1589,14 +1682,14 @@ func RouteFndPortfolio(c *gin.Context) {
 func RouteFndConstituents(c *gin.Context) {
 	symbols := c.DefaultQuery("fund", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("fund 参数为必须"))
+		respondError(c, fmt.Errorf("%w: fund 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
 	timeoutSeconds := 30
-	rawData, err := gm.GetFndConstituents(gmapi, symbols, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetFndConstituents(c.Request.Context(), symbols)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetFndPortfolio)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1605,7 +1698,7 @@ func RouteFndConstituents(c *gin.Context) {
 func RouteFndDividend(c *gin.Context) {
 	symbols := c.DefaultQuery("fund", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("fund 参数为必须"))
+		respondError(c, fmt.Errorf("%w: fund 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1614,9 +1707,9 @@ func RouteFndDividend(c *gin.Context) {
 	edate := c.DefaultQuery("edate", today)
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetFndDividend(gmapi, symbols, sdate, edate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetFndDividend(c.Request.Context(), symbols, sdate, edate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetFndDividend)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1625,7 +1718,7 @@ func RouteFndDividend(c *gin.Context) {
 func RouteFndAdjFactor(c *gin.Context) {
 	symbols := c.DefaultQuery("fund", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("fund 参数为必须"))
+		respondError(c, fmt.Errorf("%w: fund 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1635,9 +1728,9 @@ func RouteFndAdjFactor(c *gin.Context) {
 	bdate := c.DefaultQuery("bdate", "")
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetFndAdjFactor(gmapi, symbols, sdate, edate, bdate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetFndAdjFactor(c.Request.Context(), symbols, sdate, edate, bdate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetFndAdjFactor)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1645,7 +1738,7 @@ func RouteFndAdjFactor(c *gin.Context) {
 func RouteIndustryCategory(c *gin.Context) {
 	symbols := c.DefaultQuery("source", "zjh2012")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("source 参数为必须"))
+		respondError(c, fmt.Errorf("%w: source 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1653,9 +1746,9 @@ func RouteIndustryCategory(c *gin.Context) {
 	sdate := c.DefaultQuery("level", "1")
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetIndustryCategory(gmapi, symbols, sdate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetIndustryCategory(c.Request.Context(), symbols, sdate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetIndustryCategory)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1663,7 +1756,7 @@ func RouteIndustryCategory(c *gin.Context) {
 func RouteIndustryConstituents(c *gin.Context) {
 	symbols := c.DefaultQuery("industry_code", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("industry_code 参数为必须"))
+		respondError(c, fmt.Errorf("%w: industry_code 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1671,9 +1764,9 @@ func RouteIndustryConstituents(c *gin.Context) {
 	sdate := c.DefaultQuery("date", "")
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetIndustryConstituents(gmapi, symbols, sdate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetIndustryConstituents(c.Request.Context(), symbols, sdate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetIndustryConstituents)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1682,7 +1775,7 @@ func RouteIndustryConstituents(c *gin.Context) {
 func RouteSymbolsIndustry(c *gin.Context) {
 	symbols := c.DefaultQuery("symbols", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1692,9 +1785,9 @@ func RouteSymbolsIndustry(c *gin.Context) {
 	sdate := c.DefaultQuery("date", "")
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetSymbolIndustry(gmapi, symbols, source, level, sdate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetSymbolIndustry(c.Request.Context(), symbols, source, level, sdate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetSymbolIndustry)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1703,7 +1796,7 @@ func RouteSymbolsIndustry(c *gin.Context) {
 func RouteIndexConstituents(c *gin.Context) {
 	symbols := c.DefaultQuery("index", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("index 参数为必须"))
+		respondError(c, fmt.Errorf("%w: index 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1713,9 +1806,9 @@ func RouteIndexConstituents(c *gin.Context) {
 	// sec := c.DefaultQuery("sec", "stock")
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetIndexConstituents(gmapi, symbols, sdate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetIndexConstituents(c.Request.Context(), symbols, sdate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetIndexConstituents)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1724,7 +1817,7 @@ func RouteIndexConstituents(c *gin.Context) {
 func RouteTradingSessions(c *gin.Context) {
 	symbols := c.DefaultQuery("symbols", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1734,9 +1827,9 @@ func RouteTradingSessions(c *gin.Context) {
 	// sec := c.DefaultQuery("sec", "stock")
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetTradingSessions(gmapi, symbols, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetTradingSessions(c.Request.Context(), symbols)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetTradingSessions)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1755,9 +1848,9 @@ func RouteMarketInfo(c *gin.Context) {
 	exchange := c.DefaultQuery("exchange", "")
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetMarketInfo(gmapi, symbols, sec, exchange, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetMarketInfo(c.Request.Context(), symbols, sec, exchange)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetMarketInfo)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1778,9 +1871,9 @@ func RouteSymbolsInfo(c *gin.Context) {
 	trade_date := c.DefaultQuery("trade_date", "")
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetSymbolsInfo(gmapi, symbols, sec, exchange, trade_date, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetSymbolsInfo(c.Request.Context(), symbols, sec, exchange, trade_date)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetSymbolsInfo)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1789,7 +1882,7 @@ func RouteSymbolsInfo(c *gin.Context) {
 func RouteHistoryInfo(c *gin.Context) {
 	symbol := c.DefaultQuery("symbol", "")
 	if symbol == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1798,9 +1891,9 @@ func RouteHistoryInfo(c *gin.Context) {
 	edate := c.DefaultQuery("edate", today)
 	timeoutSeconds := 30
 
-	rawData, err := gm.GetHistoryInfo(gmapi, symbol, sdate, edate, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetHistoryInfo(c.Request.Context(), symbol, sdate, edate)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetHistoryInfo)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1809,7 +1902,7 @@ func RouteHistoryInfo(c *gin.Context) {
 func RouteGMApi1m(c *gin.Context) {
 	symbols := c.DefaultQuery("symbol", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1824,14 +1917,18 @@ func RouteGMApi1m(c *gin.Context) {
 	if timestamp == "true" {
 		istimestamp = true
 	}
-	datesList, _ := gm.GetDatesList(gmapi, sdate, edate, timeoutSeconds)
+	datesList, err := gmClient(timeoutSeconds).GetDatesList(c.Request.Context(), sdate, edate)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
 	if len(datesList) == 0 {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetDatesList)": "日期列表为空 " + sdate + "~" + edate})
+		respondError(c, fmt.Errorf("%w: 日期列表为空 %s~%s", gm.ErrNoData, sdate, edate))
 		return
 	}
-	rawData, err := gm.Get1mByDatelist(gmapi, symbols, datesList, istimestamp, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).Get1mByDatelist(c.Request.Context(), symbols, datesList, istimestamp)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.Get1mByDatelist)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1840,7 +1937,7 @@ func RouteGMApi1m(c *gin.Context) {
 func RouteKbars(c *gin.Context) {
 	symbols := c.DefaultQuery("symbols", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1856,9 +1953,9 @@ func RouteKbars(c *gin.Context) {
 		istimestamp = true
 	}
 	// rawData, err := gm.GetKbarsHisByte(gmapi, symbols, tag, sdate, edate, timeoutSeconds)
-	rawData, err := gm.GetKbarsHis(gmapi, symbols, tag, sdate, edate, istimestamp, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetKbarsHis(c.Request.Context(), symbols, tag, sdate, edate, istimestamp)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetKbarsHis2)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1881,7 +1978,7 @@ func RouteKbars(c *gin.Context) {
 func RouteKbars2(c *gin.Context) {
 	symbols := c.DefaultQuery("symbols", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1897,9 +1994,9 @@ func RouteKbars2(c *gin.Context) {
 		istimestamp = true
 	}
 	// rawData, err := gm.GetKbarsHisByte(gmapi, symbols, tag, sdate, edate, timeoutSeconds)
-	rawData, err := gm.GetKbarsHis2(gmapi, symbols, tag, sdate, edate, istimestamp, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetKbarsHis2(c.Request.Context(), symbols, tag, sdate, edate, istimestamp)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetKbarsHis)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1909,7 +2006,7 @@ func RouteKbars2(c *gin.Context) {
 func RouteKBDict(c *gin.Context) {
 	symbols := c.DefaultQuery("symbols", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1925,9 +2022,9 @@ func RouteKBDict(c *gin.Context) {
 		istimestamp = true
 	}
 
-	rawData, err := gm.GetKbarsHis(gmapi, symbols, tag, sdate, edate, istimestamp, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetKbarsHis(c.Request.Context(), symbols, tag, sdate, edate, istimestamp)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetKbarsHis)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gm.ConvertRecords2Dict(rawData))
@@ -1937,7 +2034,7 @@ func RouteKBDict(c *gin.Context) {
 func RouteKBDictTS(c *gin.Context) {
 	symbols := c.DefaultQuery("symbols", "")
 	if symbols == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1953,9 +2050,9 @@ func RouteKBDictTS(c *gin.Context) {
 		istimestamp = true
 	}
 
-	rawData, err := gm.GetKbarsHis(gmapi, symbols, tag, sdate, edate, istimestamp, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetKbarsHis(c.Request.Context(), symbols, tag, sdate, edate, istimestamp)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetKbarsHis)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	if istimestamp {
@@ -1969,7 +2066,7 @@ func RouteKBDictTS(c *gin.Context) {
 func RouteKbarsN(c *gin.Context) {
 	symbol := c.DefaultQuery("symbol", "")
 	if symbol == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -1986,9 +2083,9 @@ func RouteKbarsN(c *gin.Context) {
 	}
 
 	// rawData, err := gm.GetKbarsHisNByte(gmapi, symbol, tag, count, edate, timeoutSeconds)
-	rawData, err := gm.GetKbarsHisN(gmapi, symbol, tag, count, edate, istimestamp, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetKbarsHisN(c.Request.Context(), symbol, tag, count, edate, istimestamp)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetKbarsHisN)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -1997,7 +2094,7 @@ func RouteKbarsN(c *gin.Context) {
 func RouteKbars2N(c *gin.Context) {
 	symbol := c.DefaultQuery("symbol", "")
 	if symbol == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbols 参数为必须"))
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须", gm.ErrInvalidParam))
 		return
 	}
 
@@ -2013,9 +2110,9 @@ func RouteKbars2N(c *gin.Context) {
 		istimestamp = true
 	}
 
-	rawData, err := gm.GetKbarsHis2N(gmapi, symbol, tag, count, edate, istimestamp, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetKbarsHis2N(c.Request.Context(), symbol, tag, count, edate, istimestamp)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetKbarsHis2N)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -2024,7 +2121,7 @@ func RouteKbars2N(c *gin.Context) {
 func RouteCSVxzMonth(c *gin.Context) {
 	symbol := c.DefaultQuery("symbol", "")
 	if symbol == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须参数"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须参数", gm.ErrInvalidParam))
 		return
 	}
 
@@ -2046,13 +2143,13 @@ func RouteCSVxzMonth(c *gin.Context) {
 	}
 
 	// rawData, err := gm.GetCSVMonthJson(gmcsv, symbol, imonth, iyear, timeoutSeconds)
-	rawData, err := gm.GetCSVMonth(gmcsv, symbol, imonth, iyear, istimestamp, timeoutSeconds)
+	bars, err := gmClient(timeoutSeconds).GetCSVMonth(c.Request.Context(), symbol, imonth, iyear)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetCSVMonth)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	// c.JSON(http.StatusOK, string(rawData))
-	c.JSON(http.StatusOK, rawData)
+	c.JSON(http.StatusOK, bars.ToRecords(istimestamp, false))
 
 	// 将获取到的字符串数据解析为 JSON 格式
 	// var data any
@@ -2066,7 +2163,7 @@ func RouteCSVxzMonth(c *gin.Context) {
 func RouteCSVxzYear(c *gin.Context) {
 	symbol := c.DefaultQuery("symbol", "")
 	if symbol == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须参数"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须参数", gm.ErrInvalidParam))
 		return
 	}
 
@@ -2090,9 +2187,18 @@ func RouteCSVxzYear(c *gin.Context) {
 		"pe": "trade_date",
 	}
 
-	rawData, err := gm.GetCSVYear(gmcsv, symbol, tag, iyear, istimestamp, lookuptab[tag], timeoutSeconds)
+	if tag == "1m" {
+		bars, err := gmClient(timeoutSeconds).GetCSVYear(c.Request.Context(), symbol, iyear)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, bars.ToRecords(istimestamp, false))
+		return
+	}
+	rawData, err := gmClient(timeoutSeconds).GetCSVTagYear(c.Request.Context(), symbol, tag, iyear, istimestamp, lookuptab[tag])
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetCSVYear)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	// c.JSON(http.StatusOK, string(rawData))
@@ -2110,7 +2216,7 @@ func RouteCSVxzYear(c *gin.Context) {
 func RouteCSVxz1m(c *gin.Context) {
 	symbol := c.DefaultQuery("symbol", "")
 	if symbol == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须参数"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须参数", gm.ErrInvalidParam))
 		return
 	}
 
@@ -2120,7 +2226,11 @@ func RouteCSVxz1m(c *gin.Context) {
 
 	sdate := c.DefaultQuery("sdate", today)
 	edate := c.DefaultQuery("edate", today)
-	// tag := c.DefaultQuery("tag", "1m")
+	tag := c.DefaultQuery("tag", "1m")
+	if !gm.IsResampleFreq(tag) {
+		respondError(c, fmt.Errorf("%w: 不支持的周期: %s", gm.ErrInvalidParam, tag))
+		return
+	}
 
 	timestamp := c.DefaultQuery("time_stamp", "false")
 	istimestamp := false
@@ -2134,9 +2244,14 @@ func RouteCSVxz1m(c *gin.Context) {
 		isclip = true
 	}
 
-	rawData, err := gm.GetCSV1m(gmcsv, symbol, sdate, edate, istimestamp, isclip, timeoutSeconds)
+	bars, err := gmClient(timeoutSeconds).GetCSV1m(c.Request.Context(), symbol, sdate, edate, isclip)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	rawData, err := bars.ResampleRecords(tag, istimestamp)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetCSV1m)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -2153,7 +2268,7 @@ func RouteCSVxz1m(c *gin.Context) {
 func RouteCSVxzTag(c *gin.Context) {
 	symbol := c.DefaultQuery("symbol", "")
 	if symbol == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须参数"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须参数", gm.ErrInvalidParam))
 		return
 	}
 
@@ -2177,28 +2292,46 @@ func RouteCSVxzTag(c *gin.Context) {
 		isclip = true
 	}
 
-	rawData, err := gm.GetCSVTag(gmcsv, tag, symbol, sdate, edate, istimestamp, isclip, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetCSVTag(c.Request.Context(), tag, symbol, sdate, edate, istimestamp, isclip)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetCSVTag)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
 }
 
+// 对 records 做复权处理: adjust 为 none 时直接返回
+func adjustRecords(c *gin.Context, client *gm.Client, symbol string,
+	sdate string, edate string, adjust string, records ...[]map[string]any) error {
+	if adjust == gm.AdjustNone {
+		return nil
+	}
+	factors, err := client.GetSymbolAdjFactor(c.Request.Context(), symbol, sdate, edate)
+	if err != nil {
+		return fmt.Errorf("获取复权因子失败: %w", err)
+	}
+	for _, rec := range records {
+		if err := gm.AdjustRecords(rec, factors, adjust); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func RouteGM1m(c *gin.Context) {
 	symbol := c.DefaultQuery("symbol", "")
 	if symbol == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须参数"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须参数", gm.ErrInvalidParam))
 		return
 	}
 
 	timeoutSeconds := 300
-	now := time.Now()
-	today := now.Format("2006-01-02")
-	prday := now.AddDate(0, 0, -1)
-	yesterday := prday.Format("2006-01-02")
 
-	// tag := c.DefaultQuery("tag", "1m")
+	tag := c.DefaultQuery("tag", "1m")
+	if !gm.IsResampleFreq(tag) {
+		respondError(c, fmt.Errorf("%w: 不支持的周期: %s", gm.ErrInvalidParam, tag))
+		return
+	}
 
 	timestamp := c.DefaultQuery("time_stamp", "false")
 	istimestamp := false
@@ -2210,16 +2343,33 @@ func RouteGM1m(c *gin.Context) {
 	if include == "false" {
 		isinclude = false
 	}
-	cday := yesterday
-	if isinclude && gm.IsAOpen() {
-		cday = today
-	}
+	cday := defaultTradeDay(isinclude)
 	sdate := c.DefaultQuery("sdate", cday)
 	edate := c.DefaultQuery("edate", cday)
+	adjust, err := gm.ParseAdjust(c.DefaultQuery("adjust", gm.AdjustNone))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
 
-	rawData, err := gm.GetGM1m(gmcsv, gmapi, symbol, sdate, edate, istimestamp, isinclude, timeoutSeconds)
+	client := gmClient(timeoutSeconds)
+	client.MergePrefer = c.DefaultQuery("prefer", gm.SourceCSV)
+	rawData, cov, err := client.GetGM1mCoverage(c.Request.Context(), symbol, sdate, edate, istimestamp, isinclude)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetGM1m)": err.Error()})
+		respondError(c, err)
+		return
+	}
+	if err := adjustRecords(c, client, symbol, sdate, edate, adjust, rawData); err != nil {
+		respondError(c, err)
+		return
+	}
+	rawData, err = gm.ResampleRecords(rawData, tag, istimestamp)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if c.DefaultQuery("coverage", "false") == "true" {
+		c.JSON(http.StatusOK, gin.H{"data": rawData, "coverage": cov})
 		return
 	}
 	c.JSON(http.StatusOK, rawData)
@@ -2228,15 +2378,11 @@ func RouteGM1m(c *gin.Context) {
 func RouteGM1d(c *gin.Context) {
 	symbol := c.DefaultQuery("symbol", "")
 	if symbol == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须参数"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须参数", gm.ErrInvalidParam))
 		return
 	}
 
 	timeoutSeconds := 60
-	now := time.Now()
-	today := now.Format("2006-01-02")
-	prday := now.AddDate(0, 0, -1)
-	yesterday := prday.Format("2006-01-02")
 
 	// tag := c.DefaultQuery("tag", "1m")
 
@@ -2255,16 +2401,24 @@ func RouteGM1d(c *gin.Context) {
 	if isdic == "true" {
 		bdict = true
 	}
-	cday := yesterday
-	if isinclude && gm.IsAOpen() {
-		cday = today
-	}
+	cday := defaultTradeDay(isinclude)
 	sdate := c.DefaultQuery("sdate", cday)
 	edate := c.DefaultQuery("edate", cday)
 
-	rawData, err := gm.GetGM1d(gmcsv, gmapi, symbol, sdate, edate, istimestamp, isinclude, timeoutSeconds)
+	adjust, err := gm.ParseAdjust(c.DefaultQuery("adjust", gm.AdjustNone))
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetGM1d)": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	client := gmClient(timeoutSeconds)
+	rawData, err := client.GetGM1d(c.Request.Context(), symbol, sdate, edate, istimestamp, isinclude)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if err := adjustRecords(c, client, symbol, sdate, edate, adjust, rawData); err != nil {
+		respondError(c, err)
 		return
 	}
 	if bdict {
@@ -2282,15 +2436,11 @@ func RouteGM1d(c *gin.Context) {
 func RouteGMvv(c *gin.Context) {
 	symbol := c.DefaultQuery("symbol", "")
 	if symbol == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须参数"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须参数", gm.ErrInvalidParam))
 		return
 	}
 
 	timeoutSeconds := 300
-	now := time.Now()
-	today := now.Format("2006-01-02")
-	prday := now.AddDate(0, 0, -1)
-	yesterday := prday.Format("2006-01-02")
 
 	// tag := c.DefaultQuery("tag", "1m")
 	timestamp := c.DefaultQuery("time_stamp", "false")
@@ -2310,33 +2460,87 @@ func RouteGMvv(c *gin.Context) {
 	}
 	indicators := c.DefaultQuery("indicators", "pvj,v931,vmed")
 
-	cday := yesterday
-	if isinclude && gm.IsAOpen() {
-		cday = today
-	}
+	cday := defaultTradeDay(isinclude)
 	sdate := c.DefaultQuery("sdate", cday)
 	edate := c.DefaultQuery("edate", cday)
 
-	rawData, err := gm.GetGMvv(gmcsv, gmapi, symbol, sdate, edate, indicators, istimestamp, isinclude, b1m, timeoutSeconds)
+	adjust, err := gm.ParseAdjust(c.DefaultQuery("adjust", gm.AdjustNone))
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetGM1d)": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	client := gmClient(timeoutSeconds)
+	client.MergePrefer = c.DefaultQuery("prefer", gm.SourceCSV)
+	rawData, cov, err := client.GetGMvvCoverage(c.Request.Context(), symbol, sdate, edate, indicators, istimestamp, isinclude, b1m)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	d1v, _ := rawData["1dvv"].([]map[string]any)
+	d1m, _ := rawData["1mkb"].([]map[string]any)
+	if err := adjustRecords(c, client, symbol, sdate, edate, adjust, d1v, d1m); err != nil {
+		respondError(c, err)
+		return
+	}
+	if c.DefaultQuery("coverage", "false") == "true" {
+		rawData["coverage"] = cov
+	}
+
 	c.JSON(http.StatusOK, rawData)
 }
-func RouteGMpe(c *gin.Context) {
+
+// 技术指标: names 如 ma,macd,kdj; params 如 ma=5,10,20|macd=12,26,9
+func RouteIndicators(c *gin.Context) {
 	symbol := c.DefaultQuery("symbol", "")
 	if symbol == "" {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("symbol 参数为必须参数"))
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须参数", gm.ErrInvalidParam))
 		return
 	}
 
-	timeoutSeconds := 60
+	timeoutSeconds := 300
 	now := time.Now()
 	today := now.Format("2006-01-02")
-	prday := now.AddDate(0, 0, -1)
-	yesterday := prday.Format("2006-01-02")
+	preDate := now.AddDate(0, -3, 0).Format("2006-01-02")
+
+	tag := c.DefaultQuery("tag", "1d")
+	timestamp := c.DefaultQuery("time_stamp", "false")
+	istimestamp := false
+	if timestamp == "true" {
+		istimestamp = true
+	}
+	sdate := c.DefaultQuery("sdate", preDate)
+	edate := c.DefaultQuery("edate", today)
+	names := c.DefaultQuery("names", "ma,macd,kdj")
+	params := c.DefaultQuery("params", "")
+
+	adjust, err := gm.ParseAdjust(c.DefaultQuery("adjust", gm.AdjustNone))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if _, err := (&gm.OHLCVList{}).CalcIndicators(names, params); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	rawData, err := gmClient(timeoutSeconds).GetIndicators(c.Request.Context(),
+		symbol, tag, sdate, edate, names, params, adjust, istimestamp)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rawData)
+}
+
+func RouteGMpe(c *gin.Context) {
+	symbol := c.DefaultQuery("symbol", "")
+	if symbol == "" {
+		respondError(c, fmt.Errorf("%w: symbol 参数为必须参数", gm.ErrInvalidParam))
+		return
+	}
+
+	timeoutSeconds := 60
 
 	// tag := c.DefaultQuery("tag", "1m")
 
@@ -2355,17 +2559,14 @@ func RouteGMpe(c *gin.Context) {
 	if isdic == "true" {
 		bdict = true
 	}
-	cday := yesterday
-	if isinclude && gm.IsAOpen() {
-		cday = today
-	}
+	cday := defaultTradeDay(isinclude)
 	sdate := c.DefaultQuery("sdate", cday)
 	edate := c.DefaultQuery("edate", cday)
 	fields := c.DefaultQuery("fields", "")
 
-	rawData, err := gm.GetGMpe(gmcsv, gmapi, symbol, sdate, edate, fields, istimestamp, isinclude, timeoutSeconds)
+	rawData, err := gmClient(timeoutSeconds).GetGMpe(c.Request.Context(), symbol, sdate, edate, fields, istimestamp, isinclude)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, gin.H{" Err(gm.GetGMpe)": err.Error()})
+		respondError(c, err)
 		return
 	}
 	if bdict {
@@ -0,0 +1,474 @@
+package srv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/lmzxtek/ths-go/gm"
+)
+
+// 推送消息类型
+const (
+	StreamSnapshot   = "snapshot"   // 行情快照
+	StreamBar        = "bar"        // 已完成的1m K线
+	StreamHeartbeat  = "heartbeat"  // 心跳
+	StreamSubscribed = "subscribed" // 订阅/退订后的当前订阅列表
+	StreamError      = "error"
+)
+
+// 推送消息
+//
+// time 为毫秒时间戳: snapshot 为快照时间, bar 为K线结束时间。断线重连时以最后收到的
+// snapshot/bar 的 time 作为 since 参数(SSE 为 Last-Event-ID), 服务端补发此后完成的K线。
+type StreamMessage struct {
+	Type     string        `json:"type"`
+	Symbol   string        `json:"symbol,omitempty"`
+	Time     int64         `json:"time"`
+	Snapshot *gm.SnapData  `json:"snapshot,omitempty"`
+	Bar      *gm.OHLCVData `json:"bar,omitempty"`
+	Symbols  []string      `json:"symbols,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// WebSocket 客户端消息
+//
+//	{"action":"subscribe","symbols":["SHSE.600000"],"since":1719800000000}
+//	{"action":"unsubscribe","symbols":["SHSE.600000"]}
+type StreamRequest struct {
+	Action  string   `json:"action"`
+	Symbols []string `json:"symbols"`
+	Since   int64    `json:"since"`
+}
+
+// 实时行情推送: 按订阅的标的定时请求 get_current, 推送快照及由快照合成的1m K线
+//
+// 所有连接共用一个 gm.SnapPoller, 没有订阅时停止请求。
+// 客户端消费过慢(缓冲区满)时断开连接, 客户端以 since 重连补发K线。
+// Replay 不为空时改为回放历史交易日的1m K线(不推送快照), 收盘后也可测试前端。
+type StreamHub struct {
+	Interval  time.Duration // 快照请求间隔
+	Heartbeat time.Duration // 心跳间隔
+	Buffer    int           // 每个连接的消息缓冲数
+	Replay    *gm.Replay    // 回放数据源, 由 NewReplayStreamHub 设置
+
+	mu     sync.Mutex
+	poller *gm.SnapPoller
+	cancel context.CancelFunc
+	subs   map[*streamSub]bool
+	refs   map[string]int         // 标的 -> 订阅的连接数
+	last   map[string]gm.SnapData // 标的 -> 最新快照
+}
+
+// 一个连接的订阅
+type streamSub struct {
+	ch      chan StreamMessage
+	symbols map[string]bool
+	closed  bool
+	dropped bool // 因缓冲区满被断开
+}
+
+func NewStreamHub() *StreamHub {
+	return &StreamHub{
+		Interval:  3 * time.Second,
+		Heartbeat: 15 * time.Second,
+		Buffer:    256,
+		subs:      map[*streamSub]bool{},
+		refs:      map[string]int{},
+		last:      map[string]gm.SnapData{},
+	}
+}
+
+// 回放历史交易日的推送: 每个回放连接单独使用一个, 已加载数据的 r 在有订阅时开始运行
+func NewReplayStreamHub(r *gm.Replay) *StreamHub {
+	h := NewStreamHub()
+	h.Replay = r
+	r.OnBar = func(symbol string, bar gm.OHLCVData) {
+		h.publish(StreamMessage{Type: StreamBar, Symbol: symbol, Time: bar.Timestamp.UnixMilli(), Bar: &bar})
+	}
+	return h
+}
+
+var streamHub = NewStreamHub()
+
+// 新连接
+func (h *StreamHub) join() *streamSub {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sub := &streamSub{ch: make(chan StreamMessage, h.Buffer), symbols: map[string]bool{}}
+	h.subs[sub] = true
+	return sub
+}
+
+// 连接断开
+func (h *StreamHub) leave(sub *streamSub) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(sub)
+}
+
+func (h *StreamHub) removeLocked(sub *streamSub) {
+	if !h.subs[sub] {
+		return
+	}
+	delete(h.subs, sub)
+	for sym := range sub.symbols {
+		h.unrefLocked(sym)
+	}
+	if !sub.closed {
+		sub.closed = true
+		close(sub.ch)
+	}
+	h.updateLocked()
+}
+
+func (h *StreamHub) unrefLocked(sym string) {
+	if h.refs[sym]--; h.refs[sym] <= 0 {
+		delete(h.refs, sym)
+	}
+}
+
+// 订阅标的, since > 0 时补发此后完成的K线; 之后发送最新快照及当前订阅列表
+func (h *StreamHub) subscribe(sub *streamSub, symbols []string, since int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.subs[sub] {
+		return
+	}
+	for _, sym := range symbols {
+		if !sub.symbols[sym] {
+			sub.symbols[sym] = true
+			h.refs[sym]++
+		}
+	}
+	h.updateLocked()
+
+	for _, sym := range symbols {
+		if since > 0 {
+			bars := h.barsLocked(sym)
+			for i := range bars {
+				if ts := bars[i].Timestamp.UnixMilli(); ts > since {
+					h.sendLocked(sub, StreamMessage{Type: StreamBar, Symbol: sym, Time: ts, Bar: &bars[i]})
+				}
+			}
+		}
+		if s, ok := h.last[sym]; ok && s.CreatedAt.UnixMilli() > since {
+			h.sendLocked(sub, StreamMessage{Type: StreamSnapshot, Symbol: sym, Time: s.CreatedAt.UnixMilli(), Snapshot: &s})
+		}
+	}
+	h.sendLocked(sub, StreamMessage{Type: StreamSubscribed, Time: time.Now().UnixMilli(), Symbols: sub.list()})
+}
+
+// 已完成的K线
+func (h *StreamHub) barsLocked(sym string) gm.OHLCVList {
+	if h.Replay != nil {
+		return h.Replay.Bars(sym)
+	}
+	if h.poller == nil {
+		return nil
+	}
+	bars := h.poller.Bars(sym)
+	if len(bars) == 0 {
+		return nil
+	}
+	return bars[:len(bars)-1] // 最后一根K线尚未完成
+}
+
+// 退订标的
+func (h *StreamHub) unsubscribe(sub *streamSub, symbols []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.subs[sub] {
+		return
+	}
+	for _, sym := range symbols {
+		if sub.symbols[sym] {
+			delete(sub.symbols, sym)
+			h.unrefLocked(sym)
+		}
+	}
+	h.updateLocked()
+	h.sendLocked(sub, StreamMessage{Type: StreamSubscribed, Time: time.Now().UnixMilli(), Symbols: sub.list()})
+}
+
+// 向单个连接发送消息
+func (h *StreamHub) send(sub *streamSub, msg StreamMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sendLocked(sub, msg)
+}
+
+// 缓冲区满时断开该连接
+func (h *StreamHub) sendLocked(sub *streamSub, msg StreamMessage) {
+	if sub.closed {
+		return
+	}
+	select {
+	case sub.ch <- msg:
+	default:
+		sub.dropped = true
+		h.removeLocked(sub)
+	}
+}
+
+// 向订阅了该标的的连接广播
+func (h *StreamHub) publish(msg StreamMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if msg.Type == StreamSnapshot {
+		h.last[msg.Symbol] = *msg.Snapshot
+	}
+	for sub := range h.subs {
+		if sub.symbols[msg.Symbol] {
+			h.sendLocked(sub, msg)
+		}
+	}
+}
+
+// 按订阅的标的更新 poller(或回放): 有订阅时启动, 没有订阅时停止
+func (h *StreamHub) updateLocked() {
+	symbols := make([]string, 0, len(h.refs))
+	for sym := range h.refs {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+
+	if h.Replay != nil {
+		h.runLocked(len(symbols) > 0, h.Replay.Run)
+		return
+	}
+	if h.poller == nil {
+		if len(symbols) == 0 {
+			return
+		}
+		p := gm.NewSnapPoller(gmClient(10), nil, h.Interval)
+		p.OnBar = func(symbol string, bar gm.OHLCVData) {
+			h.publish(StreamMessage{Type: StreamBar, Symbol: symbol, Time: bar.Timestamp.UnixMilli(), Bar: &bar})
+		}
+		p.OnSnap = func(s gm.SnapData) {
+			h.publish(StreamMessage{Type: StreamSnapshot, Symbol: s.Symbol, Time: s.CreatedAt.UnixMilli(), Snapshot: &s})
+		}
+		h.poller = p
+	}
+	h.poller.SetSymbols(symbols)
+	h.runLocked(len(symbols) > 0, h.poller.Run)
+}
+
+// active 时在后台运行 run, 否则停止
+func (h *StreamHub) runLocked(active bool, run func(ctx context.Context) error) {
+	switch {
+	case active && h.cancel == nil:
+		ctx, cancel := context.WithCancel(context.Background())
+		h.cancel = cancel
+		go run(ctx)
+	case !active && h.cancel != nil:
+		h.cancel()
+		h.cancel = nil
+	}
+}
+
+// 当前订阅的标的
+func (sub *streamSub) list() []string {
+	list := make([]string, 0, len(sub.symbols))
+	for sym := range sub.symbols {
+		list = append(list, sym)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// 逗号分隔的标的列表
+func splitSymbols(s string) []string {
+	var list []string
+	for _, sym := range strings.Split(s, ",") {
+		if sym = strings.TrimSpace(sym); sym != "" {
+			list = append(list, sym)
+		}
+	}
+	return list
+}
+
+var streamUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true }, // 允许跨域
+}
+
+// 实时行情推送: WebSocket 连接时使用 WebSocket, 否则使用 SSE
+//
+//	ws://host/stream?symbols=SHSE.600000,SZSE.000001&since=1719800000000
+//	curl -N http://host/stream?symbols=SHSE.600000
+//
+// replay 为日期时按 speed 倍速(默认1)回放该交易日的1m K线, 回放的标的为 symbols:
+//
+//	ws://host/stream?symbols=SHSE.600000&replay=2025-07-01&speed=60
+func RouteStream(c *gin.Context) {
+	symbols := splitSymbols(c.Query("symbols"))
+	since, _ := strconv.ParseInt(c.Query("since"), 10, 64)
+	if id := c.GetHeader("Last-Event-ID"); id != "" {
+		since, _ = strconv.ParseInt(id, 10, 64)
+	}
+
+	h := streamHub
+	if date := c.Query("replay"); date != "" {
+		speed := 1.0
+		if s := c.Query("speed"); s != "" {
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil || v <= 0 {
+				respondError(c, fmt.Errorf("%w: speed 参数错误: %s", gm.ErrInvalidParam, s))
+				return
+			}
+			speed = v
+		}
+		r := gm.NewReplay(gmClient(10), symbols, date, speed)
+		if err := r.Load(c.Request.Context()); err != nil {
+			respondError(c, err)
+			return
+		}
+		h = NewReplayStreamHub(r)
+	}
+
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		streamWS(c, h, symbols, since)
+		return
+	}
+	if len(symbols) == 0 {
+		respondError(c, fmt.Errorf("%w: symbols 参数为必须参数", gm.ErrInvalidParam))
+		return
+	}
+	streamSSE(c, h, symbols, since)
+}
+
+// WebSocket: 客户端可随时发送 subscribe/unsubscribe, 服务端定时发送 heartbeat 消息及 ping
+func streamWS(c *gin.Context, h *StreamHub, symbols []string, since int64) {
+	conn, err := streamUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return // Upgrade 已返回错误响应
+	}
+	defer conn.Close()
+
+	sub := h.join()
+	defer h.leave(sub)
+	if len(symbols) > 0 {
+		h.subscribe(sub, symbols, since)
+	}
+
+	// 读取客户端消息, 超过两个心跳周期没有任何消息(含 pong)时断开
+	readTimeout := 2 * h.Heartbeat
+	conn.SetReadDeadline(time.Now().Add(readTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(readTimeout))
+	})
+	go func() {
+		defer h.leave(sub)
+		for {
+			var req StreamRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				var syntaxErr *json.SyntaxError
+				var typeErr *json.UnmarshalTypeError
+				if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+					h.send(sub, StreamMessage{Type: StreamError, Time: time.Now().UnixMilli(), Error: "消息格式错误: " + err.Error()})
+					continue
+				}
+				return
+			}
+			conn.SetReadDeadline(time.Now().Add(readTimeout))
+			switch req.Action {
+			case "subscribe":
+				h.subscribe(sub, req.Symbols, req.Since)
+			case "unsubscribe":
+				h.unsubscribe(sub, req.Symbols)
+			case "ping":
+				h.send(sub, StreamMessage{Type: StreamHeartbeat, Time: time.Now().UnixMilli()})
+			default:
+				h.send(sub, StreamMessage{Type: StreamError, Time: time.Now().UnixMilli(),
+					Error: "不支持的 action: " + req.Action + " (可选: subscribe, unsubscribe, ping)"})
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(h.Heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg, ok := <-sub.ch:
+			if !ok {
+				// 读取端断开时 ch 同样会被关闭, 仅缓冲区满时告知客户端稍后重连
+				if sub.dropped {
+					conn.WriteControl(websocket.CloseMessage,
+						websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "slow consumer"), time.Now().Add(time.Second))
+				}
+				return
+			}
+			if err := conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteJSON(StreamMessage{Type: StreamHeartbeat, Time: time.Now().UnixMilli()}); err != nil {
+				return
+			}
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+				return
+			}
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// SSE: 订阅在连接时确定; snapshot/bar 事件带 id, 浏览器重连时通过 Last-Event-ID 续传
+func streamSSE(c *gin.Context, h *StreamHub, symbols []string, since int64) {
+	sub := h.join()
+	defer h.leave(sub)
+	h.subscribe(sub, symbols, since)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 关闭 nginx 缓冲
+	c.Status(http.StatusOK)
+
+	ticker := time.NewTicker(h.Heartbeat)
+	defer ticker.Stop()
+	write := func(msg StreamMessage) error {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		if msg.Type == StreamSnapshot || msg.Type == StreamBar {
+			fmt.Fprintf(c.Writer, "id: %d\n", msg.Time)
+		}
+		if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", msg.Type, data); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+	for {
+		select {
+		case msg, ok := <-sub.ch:
+			if !ok {
+				if sub.dropped {
+					write(StreamMessage{Type: StreamError, Time: time.Now().UnixMilli(), Error: "slow consumer"})
+				}
+				return
+			}
+			if err := write(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := write(StreamMessage{Type: StreamHeartbeat, Time: time.Now().UnixMilli()}); err != nil {
+				return
+			}
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
@@ -1,12 +1,43 @@
 package srv
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/lmzxtek/ths-go/gm"
+	"github.com/lmzxtek/ths-go/gmfake"
 )
 
+var gmURL string
+
+// 测试使用 gmfake 回放 testdata/gmfake 中记录的响应;
+// 设置 GMFAKE_API 时转发未命中的请求到真实服务并录制夹具
+func TestMain(m *testing.M) {
+	dir := filepath.Join("testdata", "gmfake")
+	fake := gmfake.New(dir)
+	if api := os.Getenv("GMFAKE_API"); api != "" {
+		fake = gmfake.NewRecorder(dir, api, "")
+	}
+	ts := httptest.NewServer(fake)
+	gmURL = ts.URL
+	code := m.Run()
+	ts.Close()
+	os.Exit(code)
+}
+
 // 示例用法
 func TestSmartURLHandler(t *testing.T) {
 	// 测试用例
@@ -38,11 +69,7 @@ func TestSmartURLHandler(t *testing.T) {
 func TestGetURLRetry(t *testing.T) {
 	fmt.Println("\n >>> Start Test GetURL... ")
 
-	// 示例 URL 和参数
-	// 注意：这些是示例URL，实际使用时请替换为可访问的API端点。
-	// 例如，一个返回JSON的公共API，如 JSONPlaceholder。
-	// 假设我们有一个返回 {"message": "Hello, world!"} 的端点
-	exampleURL := "https://jsonplaceholder.typicode.com/posts/1" // 这是一个返回JSON的公共测试API
+	exampleURL := gmURL + "/test2"
 	exampleParams := map[string]string{
 		"userId": "1",
 		"id":     "1",
@@ -53,127 +80,350 @@ func TestGetURLRetry(t *testing.T) {
 	// connectTimeout 设置为 5 秒，dataTimeout 默认为 connectTimeout
 	dataWithoutRetry, err := GetURLWithoutRetry(exampleURL, exampleParams, 5*time.Second, 0)
 	if err != nil {
-		fmt.Printf("getURLWithoutRetry 发生错误: %v\n", err)
-	} else {
-		fmt.Printf("getURLWithoutRetry 成功响应: %+v\n", dataWithoutRetry)
+		t.Fatalf("getURLWithoutRetry 发生错误: %v", err)
 	}
+	fmt.Printf("getURLWithoutRetry 成功响应: %+v\n", dataWithoutRetry)
 
 	fmt.Println("\n--- 调用 getURLWithoutRetry (模拟失败情况) ---")
-	// 尝试一个不存在的 URL 或端口，模拟连接失败或HTTP错误
-	// 注意：这个URL可能无法访问，从而触发错误
-	badURL := "http://localhost:9999/nonexistent"
+	// 没有夹具的接口返回 404
+	badURL := gmURL + "/nonexistent"
 	_, err = GetURLWithoutRetry(badURL, nil, 2*time.Second, 0)
-	if err != nil {
-		fmt.Printf("getURLWithoutRetry 模拟失败错误: %v\n", err)
-	} else {
-		fmt.Println("getURLWithoutRetry 模拟失败情况意外成功。")
+	var status *gm.ErrUpstreamStatus
+	if !errors.As(err, &status) || status.Code != 404 {
+		t.Fatalf("getURLWithoutRetry 模拟失败情况: %v", err)
 	}
+	fmt.Printf("getURLWithoutRetry 模拟失败错误: %v\n", err)
 
 	// 2. 使用 getURLWithRetry 函数的例子
 	fmt.Println("\n--- 调用 getURLWithRetry ---")
 	// connectTimeout 设置为 5 秒，dataTimeout 默认为 connectTimeout
 	dataWithRetry, err := GetURLWithRetry(exampleURL, exampleParams, 5*time.Second, 0)
 	if err != nil {
-		fmt.Printf("getURLWithRetry 发生错误: %v\n", err)
-	} else {
-		fmt.Printf("getURLWithRetry 成功响应: %+v\n", dataWithRetry)
+		t.Fatalf("getURLWithRetry 发生错误: %v", err)
 	}
+	fmt.Printf("getURLWithRetry 成功响应: %+v\n", dataWithRetry)
+}
 
-	fmt.Println("\n--- 调用 getURLWithRetry (模拟重试失败情况) ---")
-	// 尝试一个会持续失败的 URL，观察重试机制
-	// 这个URL很可能在多次重试后仍然失败
-	const alwaysFailURL = "http://localhost:12345/alwaysfail" // 假设这是一个永远无法连接的地址
-	_, err = GetURLWithRetry(alwaysFailURL, nil, 1*time.Second, 0)
+// 获取原始数据并转换为 records
+func getRecords(t *testing.T, url string, pars map[string]string) []map[string]any {
+	t.Helper()
+	rawData, err := GetURLWithoutRetry(url, pars, 5*time.Second, 0)
+	if err != nil {
+		t.Fatalf("getURLWithoutRetry 发生错误: %v", err)
+	}
+
+	// 将获取到的原始数据转换为 InputData 结构体
+	jsonBytes, err := json.Marshal(rawData)
 	if err != nil {
-		fmt.Printf("getURLWithRetry 模拟重试失败错误: %v\n", err)
-	} else {
-		fmt.Println("getURLWithRetry 模拟重试失败情况意外成功。")
+		t.Fatalf("将原始数据编组为字节失败: %v", err)
 	}
+	var inputData RawColData
+	if err := json.Unmarshal(jsonBytes, &inputData); err != nil {
+		t.Fatalf("将字节解组为 InputData 结构体失败: %v", err)
+	}
+	// 处理获取到的 JSON 数据为 records 形式
+	records, err := inputData.TransformToRecords()
+	if err != nil {
+		t.Fatalf("转换为 records 格式失败: %v", err)
+	}
+	return records
 }
 
 // TestReadCSV is a test function to read CSV file and print the dataframe
 func TestGetGMURL(t *testing.T) {
 	fmt.Println("\n >>> Start TestGetGMURL ... ")
 
-	// gmapi := "http://localhost:5000/test"
-	gmapi := "http://localhost:5000/test2"
-	// gmapi := "http://localhost:5002/test3"
-	pars := map[string]string{
-		"userId": "1",
+	records := getRecords(t, gmURL+"/test2", map[string]string{"userId": "1"})
+	if len(records) != 2 || records[0]["name"] != "a" {
+		t.Fatalf("records: %v", records)
 	}
+	fmt.Println("\n--- 转换后的 records 格式 ---")
+	recordsJSON, _ := json.MarshalIndent(records, "", "  ") // 格式化输出 JSON
+	fmt.Printf("%s\n", recordsJSON)
+}
 
-	// 1. 使用 getURLWithoutRetry 函数的例子
-	fmt.Println("--- 调用 getURLWithoutRetry ---")
-	// connectTimeout 设置为 5 秒，dataTimeout 默认为 connectTimeout
-	rawData, err := GetURLWithoutRetry(gmapi, pars, 5*time.Second, 0)
+// TestReadCSV is a test function to read CSV file and print the dataframe
+func TestGetDatesByYear(t *testing.T) {
+	fmt.Println("\n >>> Start TestGetDatesByYear ... ")
+
+	records := getRecords(t, gmURL+"/get_dates_by_year", map[string]string{"syear": "2025"})
+	if len(records) < 5 {
+		t.Fatalf("记录数不足: %d", len(records))
+	}
+	fmt.Println("\n--- 转换后的 records 格式 ---")
+	recordsJSON, _ := json.MarshalIndent(records[:5], "", "  ") // 格式化输出 JSON
+	fmt.Printf("%s\n", recordsJSON)
+}
+
+func TestRespondError(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+		key  string
+	}{
+		{fmt.Errorf("%w: symbol 参数为必须", gm.ErrInvalidParam), 400, CodeInvalidParam},
+		{fmt.Errorf("x: %w", gm.ErrNoData), 404, CodeNoData},
+		{&gm.ErrUpstreamStatus{Code: 404, Status: "404 Not Found"}, 404, CodeNotFound},
+		{fmt.Errorf("%w: /data/gmcsv/a.csv.xz", gm.ErrNotFound), 404, CodeNotFound},
+		{fmt.Errorf("x: %w", &gm.ErrUpstreamStatus{Code: 500, Status: "500"}), 502, CodeUpstreamStatus},
+		{fmt.Errorf("%w: %w", gm.ErrUpstreamUnavailable, context.DeadlineExceeded), 504, CodeTimeout},
+		{fmt.Errorf("%w: dial", gm.ErrUpstreamUnavailable), 502, CodeUpstreamUnavailable},
+		{fmt.Errorf("%w: bad json", gm.ErrDecode), 502, CodeDecode},
+		{fmt.Errorf("other"), 500, CodeInternal},
+	}
+	for _, tc := range cases {
+		code, body := errorStatus(tc.err)
+		if code != tc.code || body.Code != tc.key || body.Error != tc.err.Error() {
+			t.Errorf("%v: got %d %+v, want %d %s", tc.err, code, body, tc.code, tc.key)
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/gm1d", RouteGM1d)
+	r.GET("/dvidend", RouteDvidend)
+	for _, path := range []string{"/gm1d", "/dvidend"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var body ErrorBody
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != 400 || body.Code != CodeInvalidParam || body.Error == "" {
+			t.Errorf("%s: %d %s", path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	// gm-api: 每次 get_current 快照时间递增1分钟, 当日无历史K线, 交易日历为空(Run 不请求)
+	var calls atomic.Int32
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/get_current":
+			n := calls.Add(1)
+			fmt.Fprintf(w, `[{"symbol":"SHSE.600000","price":%g,"cum_volume":%d,"created_at":"2025-07-01T10:%02d:03+08:00"}]`,
+				10+float64(n)/10, 1000*n, n)
+		default:
+			fmt.Fprint(w, `{"columns":[],"data":[]}`)
+		}
+	}))
+	defer up.Close()
+	oldAPI, oldHub := gmapi, streamHub
+	defer func() { gmapi, streamHub = oldAPI, oldHub }()
+	SetURL(up.URL, "")
+	defer gm.SetDefaultCalendar(gm.DefaultCalendar())
+	gm.SetDefaultCalendar(gm.NewCalendar(gm.NewClient(up.URL, gm.WithRetry(gm.RetryPolicy{})), ""))
+
+	h := NewStreamHub()
+	h.Interval, h.Heartbeat = time.Hour, time.Hour
+	streamHub = h
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/stream", RouteStream)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/stream?symbols=SHSE.600000", nil)
 	if err != nil {
-		fmt.Printf("getURLWithoutRetry 发生错误: %v\n", err)
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	read := func() StreamMessage {
+		t.Helper()
+		var msg StreamMessage
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+	if msg := read(); msg.Type != StreamSubscribed || len(msg.Symbols) != 1 {
+		t.Fatalf("subscribed: %+v", msg)
+	}
+
+	h.mu.Lock()
+	p := h.poller
+	h.mu.Unlock()
+	for i := 0; i < 2; i++ {
+		if err := p.Poll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{StreamSnapshot, StreamBar, StreamSnapshot}
+	var bar StreamMessage
+	for _, typ := range want {
+		msg := read()
+		if msg.Type != typ || msg.Symbol != "SHSE.600000" {
+			t.Fatalf("got %+v, want %s", msg, typ)
+		}
+		if typ == StreamBar {
+			bar = msg
+		}
+	}
+	if bar.Bar == nil || bar.Bar.Close != 10.1 || bar.Bar.Volume != 0 ||
+		bar.Time != time.Date(2025, 7, 1, 10, 2, 0, 0, time.FixedZone("CST", 8*3600)).UnixMilli() {
+		t.Errorf("bar: %+v %+v", bar, bar.Bar)
 	}
 
-	fmt.Printf("getURLWithoutRetry 成功响应: %+v\n", rawData)
+	// 不支持的 action 返回 error, 退订后不再推送
+	conn.WriteJSON(StreamRequest{Action: "foo"})
+	if msg := read(); msg.Type != StreamError {
+		t.Errorf("foo: %+v", msg)
+	}
+	// 字段类型不符同样返回 error, 连接保持
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"action":"subscribe","symbols":"SHSE.600000"}`))
+	if msg := read(); msg.Type != StreamError {
+		t.Errorf("type mismatch: %+v", msg)
+	}
+	conn.WriteJSON(StreamRequest{Action: "unsubscribe", Symbols: []string{"SHSE.600000"}})
+	if msg := read(); msg.Type != StreamSubscribed || len(msg.Symbols) != 0 {
+		t.Errorf("unsubscribe: %+v", msg)
+	}
 
-	// 将获取到的原始数据转换为 InputData 结构体
-	jsonBytes, marshalErr := json.Marshal(rawData)
-	if marshalErr != nil {
-		fmt.Printf("将原始数据编组为字节失败: %v\n", marshalErr)
-	} else {
-		var inputData RawColData
-		unmarshalErr := json.Unmarshal(jsonBytes, &inputData)
-		if unmarshalErr != nil {
-			fmt.Printf("将字节解组为 InputData 结构体失败: %v\n", unmarshalErr)
-		} else {
-			// 处理获取到的 JSON 数据为 records 形式
-			records, transformErr := inputData.TransformToRecords()
-			if transformErr != nil {
-				fmt.Printf("转换为 records 格式失败: %v\n", transformErr)
-			} else {
-				fmt.Println("\n--- 转换后的 records 格式 (getURLWithRetry) ---")
-				recordsJSON, _ := json.MarshalIndent(records, "", "  ") // 格式化输出 JSON
-				fmt.Printf("%s\n", recordsJSON)
+	// 仅缓冲区满时标记为 slow consumer, 正常断开不标记
+	h2 := NewStreamHub()
+	h2.Buffer = 0
+	left := h2.join()
+	h2.leave(left)
+	dropped := h2.join()
+	h2.send(dropped, StreamMessage{Type: StreamHeartbeat})
+	if left.dropped || !dropped.dropped {
+		t.Errorf("dropped: leave=%v overflow=%v", left.dropped, dropped.dropped)
+	}
+
+	// SSE: 缺少 symbols 返回 400; since 之后完成的K线补发
+	resp, err := http.Get(ts.URL + "/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 400 {
+		t.Errorf("sse without symbols: %d", resp.StatusCode)
+	}
+	req, _ := http.NewRequest("GET", ts.URL+"/stream?symbols=SHSE.600000", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("content-type: %s", ct)
+	}
+	var events []string
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		if ev, ok := strings.CutPrefix(sc.Text(), "event: "); ok {
+			if events = append(events, ev); ev == StreamSubscribed {
+				break
 			}
 		}
 	}
+	if strings.Join(events, ",") != "bar,snapshot,subscribed" {
+		t.Errorf("sse events: %v", events)
+	}
 }
 
-// TestReadCSV is a test function to read CSV file and print the dataframe
-func TestGetDatesByYear(t *testing.T) {
-	fmt.Println("\n >>> Start TestGetDatesByYear ... ")
+func TestStreamReplay(t *testing.T) {
+	// gm-api: 2025-07-01 两根1m K线
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/get_dates_prev_n":
+			fmt.Fprint(w, `["2025-07-01"]`)
+		case "/get_his":
+			fmt.Fprint(w, `{"columns":["symbol","eob","open","high","low","close","volume"],"data":[`+
+				`["SHSE.600000","2025-07-01T09:31:00+08:00",10,10.2,9.9,10.1,1000],`+
+				`["SHSE.600000","2025-07-01T09:32:00+08:00",10.1,10.3,10,10.2,2000]]}`)
+		default:
+			fmt.Fprint(w, `{"columns":[],"data":[]}`)
+		}
+	}))
+	defer up.Close()
+	oldAPI := gmapi
+	defer func() { gmapi = oldAPI }()
+	SetURL(up.URL, "")
+	defer gm.SetDefaultCalendar(gm.DefaultCalendar())
+	gm.SetDefaultCalendar(gm.NewCalendar(gm.NewClient(up.URL, gm.WithRetry(gm.RetryPolicy{})), ""))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/stream", RouteStream)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
 
-	year := "2025"
-	gmapi := "http://localhost:5000/get_dates_by_year"
-	pars := map[string]string{
-		"syear": year,
+	resp, err := http.Get(ts.URL + "/stream?symbols=SHSE.600000&replay=2025-07-01&speed=x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 400 {
+		t.Errorf("invalid speed: %d", resp.StatusCode)
 	}
 
-	// 1. 使用 getURLWithoutRetry 函数的例子
-	fmt.Println("--- 调用 getURLWithoutRetry ---")
-	// connectTimeout 设置为 5 秒，dataTimeout 默认为 connectTimeout
-	rawData, err := GetURLWithoutRetry(gmapi, pars, 5*time.Second, 0)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+
+		"/stream?symbols=SHSE.600000&replay=2025-07-01&speed=6000", nil)
 	if err != nil {
-		fmt.Printf("getURLWithoutRetry 发生错误: %v\n", err)
+		t.Fatal(err)
 	}
+	defer conn.Close()
+	var closes []float64
+	for _, typ := range []string{StreamSubscribed, StreamBar, StreamBar} {
+		var msg StreamMessage
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Type != typ {
+			t.Fatalf("got %+v, want %s", msg, typ)
+		}
+		if msg.Bar != nil {
+			closes = append(closes, msg.Bar.Close)
+		}
+	}
+	if fmt.Sprint(closes) != "[10.1 10.2]" {
+		t.Errorf("replay bars: %v", closes)
+	}
+}
 
-	// fmt.Printf("getURLWithoutRetry 成功响应: %+v\n", rawData)
+func TestDashboard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/dashboard/*filepath", RouteDashboard)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
 
-	// 将获取到的原始数据转换为 InputData 结构体
-	jsonBytes, marshalErr := json.Marshal(rawData)
-	if marshalErr != nil {
-		fmt.Printf("将原始数据编组为字节失败: %v\n", marshalErr)
-	} else {
-		var inputData RawColData
-		unmarshalErr := json.Unmarshal(jsonBytes, &inputData)
-		if unmarshalErr != nil {
-			fmt.Printf("将字节解组为 InputData 结构体失败: %v\n", unmarshalErr)
-		} else {
-			// 处理获取到的 JSON 数据为 records 形式
-			records, transformErr := inputData.TransformToRecords()
-			if transformErr != nil {
-				fmt.Printf("转换为 records 格式失败: %v\n", transformErr)
-			} else {
-				fmt.Println("\n--- 转换后的 records 格式 (getURLWithRetry) ---")
-				recordsJSON, _ := json.MarshalIndent(records[:5], "", "  ") // 格式化输出 JSON
-				fmt.Printf("%s\n", recordsJSON)
-			}
+	w := get("/dashboard/")
+	if w.Code != 200 || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("index: %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	// 资源均来自内嵌文件, 不引用外网地址
+	page := w.Body.String()
+	if strings.Contains(page, "http://") || strings.Contains(page, "https://") {
+		t.Error("index.html references external assets")
+	}
+	for _, name := range []string{"kchart.js", "app.js", "dashboard.css"} {
+		if !strings.Contains(page, `"`+name+`"`) {
+			t.Errorf("index.html does not load %s", name)
+		}
+		if w := get("/dashboard/" + name); w.Code != 200 || w.Body.Len() == 0 {
+			t.Errorf("%s: %d", name, w.Code)
 		}
 	}
+	app := get("/dashboard/app.js").Body.String()
+	for _, route := range []string{"'gm1m'", "'gm1d'", "'gmvv'", "'symbols_info'"} {
+		if !strings.Contains(app, route) {
+			t.Errorf("app.js does not use %s", route)
+		}
+	}
+
+	if w := get("/dashboard/missing.js"); w.Code != 404 {
+		t.Errorf("missing: %d", w.Code)
+	}
+	if w := get("/dashboard"); w.Code != http.StatusMovedPermanently {
+		t.Errorf("/dashboard: %d", w.Code)
+	}
 }
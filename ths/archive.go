@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/lmzxtek/ths-go/gm"
+)
+
+// ths archive build|verify ...
+func archiveCmd(args []string) error {
+	// 配置文件可选, 提供 gmapi/gmcsv/symbols 的默认值
+	var cfg Config
+	if _, err := os.Stat("cfg.toml"); err == nil {
+		if _, err := toml.DecodeFile("cfg.toml", &cfg); err != nil {
+			return fmt.Errorf("读取配置文件失败: %w", err)
+		}
+	}
+	if len(args) > 0 && args[0] == "build" {
+		return archiveBuild(cfg, args[1:])
+	}
+	if len(args) > 0 && args[0] == "verify" {
+		return archiveVerify(cfg, args[1:])
+	}
+	return fmt.Errorf("用法: ths archive build --symbols ... --from ... --to ... --tag 1m|vv|pe\n" +
+		"      ths archive verify --symbols ... --from ... --to ... [--report r.json] [--plan p.json]")
+}
+
+// 逗号分隔的股票代码
+func splitSymbols(s string) []string {
+	var syms []string
+	for _, sym := range strings.Split(s, ",") {
+		if sym = strings.TrimSpace(sym); sym != "" {
+			syms = append(syms, sym)
+		}
+	}
+	return syms
+}
+
+// 交易日历缓存文件: {DataDir}/calendar.json, 未配置数据目录时不缓存
+func calendarFile(dataDir string) string {
+	if dataDir == "" {
+		return ""
+	}
+	return filepath.Join(dataDir, "calendar.json")
+}
+
+// ths archive build --symbols SHSE.600000,SZSE.000001 --from 2024-01 --to 2024-12 --tag 1m
+//
+// 从 gm-api 生成 gm-csv 归档文件, 中断后重新运行时从断点继续。
+func archiveBuild(cfg Config, args []string) error {
+	defOut := "gmcsv"
+	if dir, ok := gm.LocalCSVDir(cfg.THS.Gmcsv); ok {
+		defOut = dir
+	}
+	today := time.Now().Format("2006-01-02")
+
+	fs := flag.NewFlagSet("archive build", flag.ContinueOnError)
+	symbols := fs.String("symbols", strings.Join(cfg.THS.Symbols, ","), "股票代码, 逗号分隔")
+	from := fs.String("from", today[:7], "开始日期: 2024, 2024-01 或 2024-01-02")
+	to := fs.String("to", today, "结束日期")
+	tag := fs.String("tag", "1m", "归档类型: 1m, vv, pe")
+	out := fs.String("out", defOut, "输出目录(对应 gm-csv 的 /download/ 目录)")
+	gmapi := fs.String("gmapi", cfg.THS.Gmapi, "gm-api 服务地址")
+	indicator := fs.String("indicator", cfg.THS.Indicator, "vv指标定义")
+	force := fs.Bool("force", false, "忽略断点记录, 重新生成所有文件")
+	calendar := fs.String("calendar", calendarFile(cfg.THS.DataDir), "交易日历缓存文件, 为空时不缓存")
+	concurrency := fs.Int("concurrency", gm.DefaultConcurrency, "并发请求数")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *gmapi == "" {
+		return fmt.Errorf("gmapi 为必须参数")
+	}
+	syms := splitSymbols(*symbols)
+	if len(syms) == 0 {
+		return fmt.Errorf("symbols 为必须参数")
+	}
+
+	logger := log.New(os.Stdout, " -=> ", 0)
+	client := gm.NewClient(fixURL(*gmapi),
+		gm.WithTimeout(timeoutSeconds*time.Second),
+		gm.WithConcurrency(*concurrency),
+		gm.WithLogger(logger),
+	)
+	gm.SetDefaultCalendar(gm.NewCalendar(client, *calendar))
+
+	builder := gm.NewArchiveBuilder(*out, client)
+	builder.Force = *force
+	if *indicator != "" {
+		spec, _, _, _, err := gm.ParseIndicators(*indicator)
+		if err != nil {
+			return err
+		}
+		builder.Spec = spec
+	}
+
+	// Ctrl+C 时保留断点, 下次从断点继续
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf(" >>> Build archive: tag=%s, %s ~ %s, %d symbols -> %s\n", *tag, *from, *to, len(syms), *out)
+	report, err := builder.Build(ctx, syms, *tag, *from, *to)
+	if report != nil {
+		fmt.Printf(" >>> 写入 %d 个文件, 跳过 %d 个, 无数据 %d 个, 失败 %d 个\n",
+			report.Written, report.Skipped, report.Empty, len(report.Errors))
+	}
+	return err
+}
+
+// ths archive verify --symbols SHSE.600000 --from 2024-01-01 --to 2024-12-31 --plan repair.json
+//
+// 校验 gm-csv 归档(本地目录或 gm-csv 服务)的分时数据, 输出 JSON 格式的校验结果和修复计划。
+func archiveVerify(cfg Config, args []string) error {
+	today := time.Now().Format("2006-01-02")
+
+	fs := flag.NewFlagSet("archive verify", flag.ContinueOnError)
+	symbols := fs.String("symbols", strings.Join(cfg.THS.Symbols, ","), "股票代码, 逗号分隔")
+	from := fs.String("from", today[:8]+"01", "开始日期")
+	to := fs.String("to", today, "结束日期(当日数据不校验)")
+	gmcsv := fs.String("gmcsv", cfg.THS.Gmcsv, "gm-csv 服务地址或本地目录")
+	gmapi := fs.String("gmapi", cfg.THS.Gmapi, "gm-api 服务地址(获取交易日历)")
+	monthOnly := fs.Bool("month-only", false, "只校验月文件")
+	zeroRun := fs.Int("zero-run", 30, "连续零成交量分钟数达到该值时提示")
+	reportFile := fs.String("report", "", "校验结果输出文件, 为空时输出到标准输出")
+	planFile := fs.String("plan", "", "修复计划输出文件")
+	calendar := fs.String("calendar", calendarFile(cfg.THS.DataDir), "交易日历缓存文件, 为空时不缓存")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *gmcsv == "" {
+		return fmt.Errorf("gmcsv 为必须参数")
+	}
+	syms := splitSymbols(*symbols)
+	if len(syms) == 0 {
+		return fmt.Errorf("symbols 为必须参数")
+	}
+
+	client := gm.NewClient(fixURL(*gmapi), gm.WithCSVURL(fixURL(*gmcsv)), gm.WithTimeout(timeoutSeconds*time.Second))
+	gm.SetDefaultCalendar(gm.NewCalendar(client, *calendar))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var reports []*gm.VerifyReport
+	var plans []*gm.RepairPlan
+	opts := gm.VerifyOptions{MonthOnly: *monthOnly, ZeroVolumeRun: *zeroRun}
+	for _, sym := range syms {
+		r, err := client.VerifyArchive(ctx, sym, *from, *to, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", sym, err)
+		}
+		fmt.Fprintf(os.Stderr, " >>> %s: %d 个交易日, %d 根K线, %d 个问题, %d 个交易日需要修复\n",
+			sym, r.TradingDays, r.Bars, len(r.Issues), len(r.BadDays))
+		reports = append(reports, r)
+		if !r.OK() {
+			plans = append(plans, r.RepairPlan())
+		}
+	}
+
+	if err := writeJSON(*reportFile, reports); err != nil {
+		return err
+	}
+	if *planFile != "" {
+		return writeJSON(*planFile, plans)
+	}
+	return nil
+}
+
+// 输出 JSON, fpath 为空时输出到标准输出
+func writeJSON(fpath string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if fpath == "" {
+		_, err = os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+	return os.WriteFile(fpath, data, 0o644)
+}
@@ -23,7 +23,7 @@ for %%p in (%PLATFORMS%) do (
         echo [BUILDING] %%a/%%b → %OUTPUT%
         set GOOS=%%a
         set GOARCH=%%b
-        go build -o %OUTPUT% main.go
+        go build -o %OUTPUT% .
     )
 )
 
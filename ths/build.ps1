@@ -36,7 +36,7 @@ foreach ($Platform in $Platforms) {
     
     # 执行编译
     Write-Host "Building → $($env:GOOS)/$($env:GOARCH)" -ForegroundColor Cyan
-    go build -ldflags="-s -w" -trimpath -o $OutputFile main.go
+    go build -ldflags="-s -w" -trimpath -o $OutputFile .
 
     # 编译结果检查
     if ($LASTEXITCODE -ne 0) {
@@ -15,5 +15,5 @@ for platform in "${platforms[@]}"; do
     if [ $GOOS = "windows" ]; then
         output_name+='.exe'
     fi
-    go build -o $output_name main.go
+    go build -o $output_name .
 done
\ No newline at end of file
@@ -3,7 +3,9 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/lmzxtek/ths-go/cxz"
 	"github.com/lmzxtek/ths-go/gm"
 )
@@ -12,6 +14,23 @@ var gmURL = "http://45.154.14.186:5000" // locVPS-kr
 // var gmURL = "http://38.55.125.67:5000" // locVPS-hk2
 // var gmURL = "http://111.67.205.166:5000" // uDouYun-bj
 
+const timeoutSeconds = 300
+
+// 配置结构体
+type Config struct {
+	THS struct {
+		Debug     bool     `toml:"debug"`
+		DataDir   string   `toml:"data_dir"`
+		ThsDir    string   `toml:"ths_dir"`
+		Gmapi     string   `toml:"gmapi"`
+		Gmcsv     string   `toml:"gmcsv"`
+		Count     int      `toml:"count"`
+		Symbols   []string `toml:"symbols"`
+		IndexList []string `toml:"index_list"`
+		Indicator string   `toml:"indicator"`
+	} `toml:"ths"`
+}
+
 func main() {
 	args := os.Args[1:] // os.Args[0] 是脚本名，后面是参数
 
@@ -33,20 +52,22 @@ func main() {
 
 	} else if len(args) > 0 && args[0] == "update" {
 		fmt.Println(" >>> Start update THS daily indications ...")
-		// 或者使用更高效的版本
-		records, err := cxz.ReadCSVFileEfficient(filePath)
-		if err != nil {
-			fmt.Printf("错误: %v\n", err)
+
+		// 读取配置文件
+		var cfg Config
+		if _, err := toml.DecodeFile("cfg.toml", &cfg); err != nil {
+			fmt.Println("Error loading config file:", err)
 			return
 		}
+		fmt.Println(` -=> Loading params from: cfg.toml`)
+		cfg.THS.Gmapi = fixURL(cfg.THS.Gmapi)
+		cfg.THS.Gmcsv = fixURL(cfg.THS.Gmcsv)
 
-		fmt.Println("CSV 文件内容:")
-		for i, record := range records {
-			fmt.Printf("%d: %v\n", i+1, record)
+		if err := thsProcSymbols(&cfg); err != nil {
+			fmt.Printf("错误: %v\n", err)
+			return
 		}
-
-		// df := dataframe.ReadCSV(records)
-		// fmt.Println(df)
+		fmt.Printf("\n !!! Nice, All mission finished at %s. \n", time.Now().Format("2006-01-02 15:04:05"))
 
 	} else if len(args) > 0 && args[0] == "url" {
 		fmt.Println(" -=> Start fetch url... ")
@@ -114,11 +135,18 @@ func main() {
 		}
 		fmt.Println(df)
 
+	} else if len(args) > 0 && args[0] == "archive" {
+		if err := archiveCmd(args[1:]); err != nil {
+			fmt.Printf("错误: %v\n", err)
+		}
+
 	} else {
 		fmt.Println()
 		fmt.Println(" >>> Opps: cmd options or params error:", args, "...")
 		fmt.Println("           > go run main.go test ")
 		fmt.Println("           > go run main.go update ")
+		fmt.Println("           > go run main.go archive build --symbols SHSE.600000 --from 2024-01 --to 2024-12 --tag 1m ")
+		fmt.Println("           > go run main.go archive verify --symbols SHSE.600000 --from 2024-01-01 --to 2024-12-31 --plan repair.json ")
 		fmt.Println()
 	}
 }
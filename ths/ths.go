@@ -0,0 +1,409 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lmzxtek/ths-go/gm"
+)
+
+// 个股基本信息(来自 gm-api: get_infos)
+type symbolInfo struct {
+	Symbol  string
+	SecName string
+	SecAbbr string
+	IsStock bool // sec_type1 == 1010
+}
+
+// 补全URL协议头
+func fixURL(url string) string {
+	if url == "" || strings.Contains(url, "://") {
+		return url
+	}
+	if _, ok := gm.LocalCSVDir(url); ok {
+		return url // 本地 csv.xz 目录
+	}
+	return "http://" + url
+}
+
+// 从 records 中读取数值(兼容 int64/float64)
+func toFloat(v any) float64 {
+	switch x := v.(type) {
+	case float64:
+		return x
+	case int64:
+		return float64(x)
+	case int:
+		return float64(x)
+	}
+	return 0.0
+}
+
+// 按 Python 的 round(x,2) + str() 格式输出浮点数
+func pyFloat(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "0.0"
+	}
+	s := strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
+
+// 按 Python 的 int(x) 格式输出整数
+func pyInt(v float64) string {
+	return strconv.FormatInt(int64(v), 10)
+}
+
+// 查询股票池的个股信息: 股票、基金、指数
+func readSymbolsInfo(gmapi string, symbols []string) (map[string]symbolInfo, error) {
+	infos := make(map[string]symbolInfo)
+	syms := strings.Join(symbols, ",")
+	for _, sec := range []string{"stock", "fund", "index"} {
+		rsp, err := gm.GetMarketInfoList(gmapi, syms, sec, "", timeoutSeconds)
+		var derrs gm.DecodeErrors
+		if errors.As(err, &derrs) {
+			// 个别记录解码失败时使用其余记录
+			fmt.Printf("  -> 个股信息部分记录解码失败(%s): %v\n", sec, err)
+		} else if err != nil {
+			return nil, fmt.Errorf("获取个股信息失败(%s): %w", sec, err)
+		}
+		for _, rec := range rsp {
+			if !slices.Contains(symbols, rec.Symbol) {
+				continue
+			}
+			infos[rec.Symbol] = symbolInfo{
+				Symbol:  rec.Symbol,
+				SecName: rec.SecName,
+				SecAbbr: rec.SecAbbr,
+				IsStock: rec.SecType1 == 1010,
+			}
+		}
+	}
+	return infos, nil
+}
+
+// 最近count个交易日的起止日期
+func getTradeRange(gmapi string, count int) (sdate string, edate string, err error) {
+	today := time.Now().Format("2006-01-02")
+	rsp, err := gm.GetPrevN(gmapi, today, count, true, timeoutSeconds)
+	if err != nil {
+		return "", "", err
+	}
+	var dates []string
+	for _, d := range rsp {
+		if s, ok := d.(string); ok {
+			dates = append(dates, s)
+		}
+	}
+	if len(dates) == 0 {
+		return "", "", fmt.Errorf("交易日列表为空: %s", today)
+	}
+	sort.Strings(dates)
+	return dates[0], today, nil
+}
+
+// 读取日频vv指标数据，并按日期合并pe_ttm
+func readSymbolVV(cfg *Config, info symbolInfo, sdate, edate string) ([]map[string]any, error) {
+	spec, ok := gm.GetIndicatorSpec(cfg.THS.Indicator)
+	if !ok {
+		return nil, fmt.Errorf("未知的指标定义: %s", cfg.THS.Indicator)
+	}
+
+	var vvs []map[string]any
+	if cfg.THS.DataDir != "" {
+		// 本地增量存储: 只获取缺失的交易日
+		store := gm.NewVVStore(cfg.THS.DataDir, cfg.THS.Gmcsv, cfg.THS.Gmapi, timeoutSeconds)
+		store.Spec = spec
+		vvl, err := store.Update(info.Symbol, sdate, edate)
+		if err != nil {
+			return nil, err
+		}
+		vvs = vvl.ToRecords(true, true, true, false)
+	} else {
+		rsp, err := gm.GetGMvv(cfg.THS.Gmcsv, cfg.THS.Gmapi, info.Symbol, sdate, edate, spec.Name, false, true, false, timeoutSeconds)
+		if err != nil {
+			return nil, err
+		}
+		vvs, _ = rsp["1dvv"].([]map[string]any)
+	}
+
+	pes := make(map[string]float64)
+	if info.IsStock {
+		dpe, err := gm.GetGMpe(cfg.THS.Gmcsv, cfg.THS.Gmapi, info.Symbol, sdate, edate, "pe_ttm", false, true, timeoutSeconds)
+		if err != nil {
+			fmt.Printf("  -> 获取PE数据失败(%s): %s\n", info.Symbol, err)
+		}
+		for _, rec := range dpe {
+			if ts, ok := rec["timestamp"].(string); ok {
+				pes[ts] = toFloat(rec["pe_ttm"])
+			}
+		}
+	}
+	for _, rec := range vvs {
+		ts, _ := rec["timestamp"].(string)
+		rec["pe"] = pes[ts]
+	}
+
+	// 按日期倒序，与同花顺脚本一致
+	sort.Slice(vvs, func(i, j int) bool {
+		return vvs[i]["timestamp"].(string) > vvs[j]["timestamp"].(string)
+	})
+	return vvs, nil
+}
+
+// 日期键: 2006-01-02 -> 20060102
+func thsDateKey(ts string) string {
+	return strings.ReplaceAll(ts, "-", "")
+}
+
+// 写入同花顺 candle 指标文件
+func writeThsFile(fpath string, info symbolInfo, dataIndex string, rows []string, script string) error {
+	var builder strings.Builder
+	builder.WriteString("\n# " + info.Symbol + "-" + info.SecName)
+	builder.WriteString("\n# Common used params")
+	builder.WriteString("\n#  Data index: " + dataIndex)
+	builder.WriteString("\nbarsdata={")
+	for _, row := range rows {
+		builder.WriteString(row)
+	}
+	builder.WriteString("\n}\n\n")
+	builder.WriteString(script)
+
+	if err := os.WriteFile(fpath, []byte(builder.String()), 0o644); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	fmt.Println("  -> 导出: ", fpath)
+	return nil
+}
+
+// 输出文件名: sec_abbr后5位 + 后缀
+func thsFileName(fld string, info symbolInfo, suffix string) string {
+	return filepath.Join(fld, gm.LastNChars(info.SecAbbr, 5)+suffix+".py")
+}
+
+// 导出首尾量: [v931,v932,v150]
+func toThsVV1(vvs []map[string]any, info symbolInfo, fld string) error {
+	var rows []string
+	for _, rec := range vvs {
+		rows = append(rows, fmt.Sprintf("\n\"%s\" : [%s,%s,%s],",
+			thsDateKey(rec["timestamp"].(string)),
+			pyInt(toFloat(rec["v931"])),
+			pyInt(toFloat(rec["v932"])),
+			pyInt(toFloat(rec["v150"])),
+		))
+	}
+	return writeThsFile(thsFileName(fld, info, "1"), info, "[v931,v932,v150]", rows, thsScript1m)
+}
+
+// 导出首尾量和PETTM: [v935,v940,pettm]
+func toThsVV5(vvs []map[string]any, info symbolInfo, fld string) error {
+	var rows []string
+	for _, rec := range vvs {
+		rows = append(rows, fmt.Sprintf("\n\"%s\" : [%s,%s,%s],",
+			thsDateKey(rec["timestamp"].(string)),
+			pyInt(toFloat(rec["v935"])),
+			pyInt(toFloat(rec["v940"])),
+			pyFloat(toFloat(rec["pe"])),
+		))
+	}
+	return writeThsFile(thsFileName(fld, info, "5"), info, "[v935,v940,pettm]", rows, thsScript5m)
+}
+
+// 导出首尾量比日成交量: [v931,v932,v150]
+func toThsVrd(vvs []map[string]any, info symbolInfo, fld string) error {
+	var rows []string
+	for _, rec := range vvs {
+		rows = append(rows, fmt.Sprintf("\n\"%s\" : [%s,%s,%s],",
+			thsDateKey(rec["timestamp"].(string)),
+			pyInt(toFloat(rec["v931"])),
+			pyInt(toFloat(rec["v932"])),
+			pyInt(toFloat(rec["v150"])),
+		))
+	}
+	return writeThsFile(thsFileName(fld, info, "r"), info, "[v931,v932,v150]", rows, thsScriptVr)
+}
+
+// 导出涨跌数量占比: [up,down]
+func toThsVud(vvs []map[string]any, info symbolInfo, fld string) error {
+	nkbars := 240.0
+	var rows []string
+	for _, rec := range vvs {
+		up := max(toFloat(rec["nup"])/nkbars*100, 0)
+		down := max(toFloat(rec["ndown"])/nkbars*100, 0)
+		rows = append(rows, fmt.Sprintf("\n\"%s\" : [%s,%s],",
+			thsDateKey(rec["timestamp"].(string)),
+			pyFloat(up),
+			pyFloat(down),
+		))
+	}
+	return writeThsFile(thsFileName(fld, info, "u"), info, "[up,down]", rows, thsScriptUd)
+}
+
+// 导出主图指标：量均价 [PVJ,]
+func toThsVpvj(vvs []map[string]any, info symbolInfo, fld string) error {
+	var rows []string
+	for _, rec := range vvs {
+		rows = append(rows, fmt.Sprintf("\n\"%s\" : [%s],",
+			thsDateKey(rec["timestamp"].(string)),
+			pyFloat(toFloat(rec["pvj"])),
+		))
+	}
+	return writeThsFile(thsFileName(fld, info, "p"), info, "[PVJ,]", rows, thsScriptPvj)
+}
+
+// 成本价数据行: [cbj0,cbj1,cbj2]
+func thsCbjRows(vvs []map[string]any) []string {
+	var rows []string
+	for _, rec := range vvs {
+		rows = append(rows, fmt.Sprintf("\n\"%s\" : [%s,%s,%s],",
+			thsDateKey(rec["timestamp"].(string)),
+			pyFloat(toFloat(rec["cbj"])),
+			pyFloat(toFloat(rec["cb1"])),
+			pyFloat(toFloat(rec["cb2"])),
+		))
+	}
+	return rows
+}
+
+// 导出主图指标：成本价
+func toThsCbj(vvs []map[string]any, info symbolInfo, fld string) error {
+	return writeThsFile(thsFileName(fld, info, "c"), info, "[cbj0,cbj1,cbj2]", thsCbjRows(vvs), thsScriptCbj)
+}
+
+// 导出副图指标：成本价差
+func toThsCbf(vvs []map[string]any, info symbolInfo, fld string) error {
+	return writeThsFile(thsFileName(fld, info, "f"), info, "[cbj0,cbj1,cbj2]", thsCbjRows(vvs), thsScriptCbf)
+}
+
+// 导出日成交量和分时成交量, 主要用于大盘指数: [open,close,vol]
+func toThsVol(vvs []map[string]any, d1m []map[string]any, info symbolInfo, fld string) error {
+	var rows []string
+	for _, rec := range vvs {
+		rows = append(rows, fmt.Sprintf("\n\"%s\" : [%s,%s,%s],",
+			thsDateKey(rec["timestamp"].(string)),
+			pyInt(toFloat(rec["open"])),
+			pyInt(toFloat(rec["close"])),
+			pyInt(toFloat(rec["volume"])),
+		))
+	}
+
+	ohlcv := gm.OHLCVList{}
+	ohlcv.FromMapList(d1m)
+	ohlcv.Sort(true)
+	// barsdata 之后紧接 bars1m 字典
+	rows = append(rows, "\n}\n\n\nbars1m={")
+	for _, kb := range ohlcv {
+		rows = append(rows, fmt.Sprintf("\n\"%s\" : [%s,%s,%s],",
+			kb.Timestamp.Format("20060102 15:04:05"),
+			pyInt(kb.Open),
+			pyInt(kb.Close),
+			pyInt(float64(kb.Volume)),
+		))
+	}
+	return writeThsFile(thsFileName(fld, info, "v"), info, "[open,close,vol]", rows, thsScript1d)
+}
+
+// 更新股票池内所有个股最新指标，并导出同花顺 candle 文件
+func thsProcSymbols(cfg *Config) error {
+	ths := cfg.THS
+	if len(ths.Symbols) == 0 {
+		return fmt.Errorf("symbols 为必须参数")
+	}
+	if ths.Count <= 0 {
+		ths.Count = 360
+	}
+	if cfg.THS.Indicator == "" {
+		cfg.THS.Indicator = gm.PyIndicatorSpec.Name // 与Python脚本一致
+	}
+	thsDir := ths.ThsDir
+	if thsDir == "" {
+		thsDir = "candle"
+	}
+	parentDir := filepath.Dir(thsDir)
+	if _, err := os.Stat(parentDir); err != nil {
+		return fmt.Errorf("输出目录的上级目录不存在: %s", parentDir)
+	}
+	mainDir := filepath.Join(thsDir, "Main")
+	if err := os.MkdirAll(mainDir, 0o755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	if ths.DataDir != "" {
+		if err := os.MkdirAll(ths.DataDir, 0o755); err != nil {
+			return fmt.Errorf("创建数据目录失败: %w", err)
+		}
+		gm.SetDefaultCalendar(gm.NewCalendar(gm.NewClient(ths.Gmapi), calendarFile(ths.DataDir)))
+	}
+
+	infos, err := readSymbolsInfo(ths.Gmapi, ths.Symbols)
+	if err != nil {
+		return err
+	}
+	sdate, edate, err := getTradeRange(ths.Gmapi, ths.Count)
+	if err != nil {
+		return fmt.Errorf("获取交易日历失败: %w", err)
+	}
+	if ths.Debug {
+		fmt.Printf("\n >>> Stock pool: %d symbols, %s ~ %s\n", len(infos), sdate, edate)
+	}
+
+	for num, sym := range ths.Symbols {
+		info, ok := infos[sym]
+		if !ok {
+			fmt.Printf("\n -=> %2d. symbol(%s): 未找到个股信息，跳过\n", num+1, sym)
+			continue
+		}
+		fmt.Printf("\n -=> %2d. symbol(%s-%s)\n", num+1, sym, info.SecName)
+
+		vvs, err := readSymbolVV(cfg, info, sdate, edate)
+		if err != nil {
+			fmt.Printf("  -> 获取vv数据失败(%s): %s\n", sym, err)
+			continue
+		}
+		if len(vvs) == 0 {
+			fmt.Printf("  -> vv数据为空(%s)\n", sym)
+			continue
+		}
+
+		exports := []func([]map[string]any, symbolInfo, string) error{
+			toThsVV1, toThsVV5, toThsVrd, toThsVud, toThsCbf,
+		}
+		for _, export := range exports {
+			if err := export(vvs, info, thsDir); err != nil {
+				fmt.Printf("  -> 导出失败(%s): %s\n", sym, err)
+			}
+		}
+		if err := toThsVpvj(vvs, info, mainDir); err != nil {
+			fmt.Printf("  -> 导出失败(%s): %s\n", sym, err)
+		}
+
+		isIndex := slices.Contains(ths.IndexList, sym)
+		if !isIndex {
+			if err := toThsCbj(vvs, info, mainDir); err != nil {
+				fmt.Printf("  -> 导出失败(%s): %s\n", sym, err)
+			}
+			continue
+		}
+
+		// 对于指数行情，额外输出日成交量和最近10个交易日的分时成交量，
+		// 用于分析个股与大盘之间的强弱
+		s1m := vvs[min(9, len(vvs)-1)]["timestamp"].(string)
+		d1m, err := gm.GetGM1m(ths.Gmcsv, ths.Gmapi, sym, s1m, edate, false, true, timeoutSeconds)
+		if err != nil {
+			fmt.Printf("  -> 获取分时数据失败(%s): %s\n", sym, err)
+		}
+		if err := toThsVol(vvs, d1m, info, thsDir); err != nil {
+			fmt.Printf("  -> 导出失败(%s): %s\n", sym, err)
+		}
+	}
+	return nil
+}